@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"math/big"
 
+	"github.com/ava-labs/subnet-evm/utils"
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -60,6 +61,9 @@ var EmptyFeeConfig = FeeConfig{}
 
 // Verify checks fields of this config to ensure a valid fee configuration is provided.
 func (f *FeeConfig) Verify() error {
+	if err := f.checkNilFields(); err != nil {
+		return err
+	}
 	switch {
 	case f.GasLimit.Cmp(common.Big0) != 1:
 		return fmt.Errorf("gasLimit = %d cannot be less than or equal to 0", f.GasLimit)
@@ -81,6 +85,42 @@ func (f *FeeConfig) Verify() error {
 	return f.checkByteLens()
 }
 
+// Equal returns true iff [other] specifies the same fee configuration as [f].
+func (f *FeeConfig) Equal(other *FeeConfig) bool {
+	if f == nil || other == nil {
+		return f == other
+	}
+	return utils.BigNumEqual(f.GasLimit, other.GasLimit) &&
+		f.TargetBlockRate == other.TargetBlockRate &&
+		utils.BigNumEqual(f.MinBaseFee, other.MinBaseFee) &&
+		utils.BigNumEqual(f.TargetGas, other.TargetGas) &&
+		utils.BigNumEqual(f.BaseFeeChangeDenominator, other.BaseFeeChangeDenominator) &&
+		utils.BigNumEqual(f.MinBlockGasCost, other.MinBlockGasCost) &&
+		utils.BigNumEqual(f.MaxBlockGasCost, other.MaxBlockGasCost) &&
+		utils.BigNumEqual(f.BlockGasCostStep, other.BlockGasCostStep)
+}
+
+// checkNilFields returns an error if any of the big integer fields of [f] is missing.
+func (f *FeeConfig) checkNilFields() error {
+	for _, field := range []struct {
+		name  string
+		value *big.Int
+	}{
+		{"gasLimit", f.GasLimit},
+		{"minBaseFee", f.MinBaseFee},
+		{"targetGas", f.TargetGas},
+		{"baseFeeChangeDenominator", f.BaseFeeChangeDenominator},
+		{"minBlockGasCost", f.MinBlockGasCost},
+		{"maxBlockGasCost", f.MaxBlockGasCost},
+		{"blockGasCostStep", f.BlockGasCostStep},
+	} {
+		if field.value == nil {
+			return fmt.Errorf("%s cannot be nil", field.name)
+		}
+	}
+	return nil
+}
+
 // checkByteLens checks byte lengths against common.HashLen (32 bytes) and returns error
 func (f *FeeConfig) checkByteLens() error {
 	if isBiggerThanHashLen(f.GasLimit) {
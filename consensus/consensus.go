@@ -57,6 +57,10 @@ type ChainHeaderReader interface {
 
 	// GetFeeConfigAt retrieves the fee config and last changed block number at block header.
 	GetFeeConfigAt(parent *types.Header) (commontype.FeeConfig, *big.Int, error)
+
+	// GetCoinbaseAt retrieves the configured coinbase address for a block at [timestamp]
+	// built on [parent] and whether block producers may choose their own fee recipient.
+	GetCoinbaseAt(parent *types.Header, timestamp uint64) (common.Address, bool, error)
 }
 
 // ChainReader defines a small collection of methods needed to access the local
@@ -15,6 +15,7 @@ import (
 	"github.com/ava-labs/subnet-evm/core/state"
 	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
 	"github.com/ava-labs/subnet-evm/trie"
 	"github.com/ethereum/go-ethereum/common"
 )
@@ -27,6 +28,7 @@ var (
 	errBlockGasCostNil      = errors.New("block gas cost is nil")
 	errBlockGasCostTooLarge = errors.New("block gas cost is not uint64")
 	errBaseFeeNil           = errors.New("base fee is nil")
+	errInvalidCoinbase      = errors.New("invalid coinbase")
 )
 
 type Mode uint
@@ -275,7 +277,31 @@ func (self *DummyEngine) verifyBlockFee(
 	return nil
 }
 
+// verifyCoinbase checks that the coinbase of [header] matches the coinbase configured at [parent],
+// unless block producers are allowed to choose their own fee recipient.
+// Note: if the RewardManager precompile is not enabled at [header], the coinbase is verified by the
+// VM against AllowFeeRecipients in the chain config instead.
+func (self *DummyEngine) verifyCoinbase(config *params.ChainConfig, header *types.Header, parent *types.Header, chain consensus.ChainHeaderReader) error {
+	if !config.IsPrecompileEnabled(precompile.RewardManagerAddress, new(big.Int).SetUint64(header.Time)) {
+		return nil
+	}
+	configuredCoinbase, allowFeeRecipients, err := chain.GetCoinbaseAt(parent, header.Time)
+	if err != nil {
+		return fmt.Errorf("failed to get coinbase at %s: %w", parent.Hash(), err)
+	}
+	if allowFeeRecipients {
+		return nil
+	}
+	if header.Coinbase != configuredCoinbase {
+		return fmt.Errorf("%w: %s does not match required coinbase address %s", errInvalidCoinbase, header.Coinbase, configuredCoinbase)
+	}
+	return nil
+}
+
 func (self *DummyEngine) Finalize(chain consensus.ChainHeaderReader, block *types.Block, parent *types.Header, state *state.StateDB, receipts []*types.Receipt) error {
+	if err := self.verifyCoinbase(chain.Config(), block.Header(), parent, chain); err != nil {
+		return err
+	}
 	if chain.Config().IsSubnetEVM(new(big.Int).SetUint64(block.Time())) {
 		feeConfig, _, err := chain.GetFeeConfigAt(parent)
 		if err != nil {
@@ -2,6 +2,10 @@
 pragma solidity ^0.8.0;
 
 interface IAllowList {
+  // Emitted when [sender] sets the role of [account] to [role]
+  // Note: only emitted once the precompileLogsTimestamp network upgrade is activated
+  event RoleSet(uint256 indexed role, address indexed account, address indexed sender);
+
   // Set [addr] to have the admin role over the minter list
   function setAdmin(address addr) external;
 
@@ -11,6 +15,15 @@ interface IAllowList {
   // Set [addr] to have no role over the minter list
   function setNone(address addr) external;
 
+  // Set [addr] to have the manager role over the minter list. Managers may only enable
+  // and remove enabled addresses.
+  // Note: only available once the allowListRolesTimestamp network upgrade is activated
+  function setManager(address addr) external;
+
+  // Set [addr] to be enabled on the minter list until the block with timestamp [expiry]
+  // Note: only available once the allowListRolesTimestamp network upgrade is activated
+  function setEnabledUntil(address addr, uint64 expiry) external;
+
   // Read the status of [addr]
   function readAllowList(address addr) external view returns (uint256);
 }
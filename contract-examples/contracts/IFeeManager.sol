@@ -3,6 +3,21 @@ pragma solidity ^0.8.0;
 import "./IAllowList.sol";
 
 interface IFeeManager is IAllowList {
+  struct FeeConfig {
+    uint256 gasLimit;
+    uint256 targetBlockRate;
+    uint256 minBaseFee;
+    uint256 targetGas;
+    uint256 baseFeeChangeDenominator;
+    uint256 minBlockGasCost;
+    uint256 maxBlockGasCost;
+    uint256 blockGasCostStep;
+  }
+
+  // Emitted when [sender] changes the fee config from [oldFeeConfig] to [newFeeConfig]
+  // Note: only emitted once the precompileLogsTimestamp network upgrade is activated
+  event FeeConfigChanged(address indexed sender, FeeConfig oldFeeConfig, FeeConfig newFeeConfig);
+
   // Set fee config fields to contract storage
   function setFeeConfig(
     uint256 gasLimit,
@@ -0,0 +1,57 @@
+//SPDX-License-Identifier: MIT
+pragma solidity ^0.8.0;
+import "./IAllowList.sol";
+
+interface IGasSponsor is IAllowList {
+  // Emitted when [sponsor] deposits [amount]
+  // Note: only emitted once the precompileLogsTimestamp network upgrade is activated
+  event SponsorDeposited(address indexed sponsor, uint256 amount);
+
+  // Emitted when [sponsor] withdraws [amount]
+  // Note: only emitted once the precompileLogsTimestamp network upgrade is activated
+  event SponsorWithdrawn(address indexed sponsor, uint256 amount);
+
+  // Emitted when [sponsor] starts or stops sponsoring transactions to [target]
+  // Note: only emitted once the precompileLogsTimestamp network upgrade is activated
+  event SponsoredTargetSet(address indexed sponsor, address indexed target, bool sponsored);
+
+  // Emitted when [sponsor] starts or stops sponsoring transactions from [sender]
+  // Note: only emitted once the precompileLogsTimestamp network upgrade is activated
+  event SponsoredSenderSet(address indexed sponsor, address indexed sender, bool sponsored);
+
+  // Emitted when [sponsor] sets its spending limit for [sender] to [limit]
+  // Note: only emitted once the precompileLogsTimestamp network upgrade is activated
+  event SpendingLimitSet(address indexed sponsor, address indexed sender, uint256 limit);
+
+  // Move [amount] from the balance of the caller to its deposit, which pays for the gas of
+  // the transactions it sponsors. Deposits are refunded if the gas sponsor is disabled.
+  function deposit(uint256 amount) external;
+
+  // Move [amount] from the deposit of the caller back to its balance
+  function withdraw(uint256 amount) external;
+
+  // Start or stop paying for the gas of transactions to [target]. Only enabled addresses with a
+  // deposit can register sponsorships and each target can only have one sponsor, which can be replaced
+  // once its deposit is empty. Admins can remove any sponsorship.
+  function setSponsoredTarget(address target, bool sponsored) external;
+
+  // Start or stop paying for the gas of transactions from [sender]. Only enabled addresses with a
+  // deposit can register sponsorships and each sender can only have one sponsor, which can be replaced
+  // once its deposit is empty. Admins can remove any sponsorship.
+  function setSponsoredSender(address sender, bool sponsored) external;
+
+  // Limit the total amount the caller spends on the gas of transactions from [sender].
+  // A limit of 0 removes the limit and the limit of the zero address applies to all senders
+  // without a limit of their own.
+  function setSpendingLimit(address sender, uint256 limit) external;
+
+  // Get the deposit of [sponsor]
+  function depositOf(address sponsor) external view returns (uint256 amount);
+
+  // Get the sponsor of transactions from [sender] to [target]. The sponsor of the target takes
+  // precedence over the sponsor of the sender.
+  function sponsorOf(address sender, address target) external view returns (address sponsor);
+
+  // Get the amount [sponsor] may still spend on the gas of transactions from [sender]
+  function remainingAllowance(address sponsor, address sender) external view returns (uint256 amount);
+}
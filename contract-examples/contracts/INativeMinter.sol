@@ -3,6 +3,28 @@ pragma solidity ^0.8.0;
 import "./IAllowList.sol";
 
 interface INativeMinter is IAllowList {
+  // Emitted when [sender] mints [amount] native coins to [recipient]
+  // Note: only emitted once the precompileLogsTimestamp network upgrade is activated
+  event NativeCoinMinted(address indexed sender, address indexed recipient, uint256 amount);
+
+  // Emitted when [sender] burns [amount] native coins from its balance
+  // Note: only emitted once the precompileLogsTimestamp network upgrade is activated
+  event NativeCoinBurned(address indexed sender, uint256 amount);
+
   // Mint [amount] number of native coins and send to [addr]
+  // Note: once the nativeMinterSupplyTimestamp network upgrade is activated, this reverts
+  // if it exceeds the max total supply or the quota of the caller
   function mintNativeCoin(address addr, uint256 amount) external;
+
+  // Burn [amount] number of native coins from the balance of the caller
+  // Note: only available once the nativeMinterSupplyTimestamp network upgrade is activated
+  function burnNativeCoin(uint256 amount) external;
+
+  // Read the total amount minted less the amount burned
+  // Note: only available once the nativeMinterSupplyTimestamp network upgrade is activated
+  function totalMinted() external view returns (uint256);
+
+  // Read the amount [addr] may still mint under its quota and the max total supply
+  // Note: only available once the nativeMinterSupplyTimestamp network upgrade is activated
+  function remainingQuota(address addr) external view returns (uint256);
 }
@@ -0,0 +1,23 @@
+//SPDX-License-Identifier: MIT
+pragma solidity ^0.8.0;
+import "./IAllowList.sol";
+
+interface IPauser is IAllowList {
+  // Emitted when [sender] pauses the chain
+  // Note: only emitted once the precompileLogsTimestamp network upgrade is activated
+  event Paused(address indexed sender);
+
+  // Emitted when [sender] unpauses the chain
+  // Note: only emitted once the precompileLogsTimestamp network upgrade is activated
+  event Unpaused(address indexed sender);
+
+  // Pause the chain. While paused, only transactions from exempt senders to exempt
+  // targets are accepted.
+  function pause() external;
+
+  // Unpause the chain
+  function unpause() external;
+
+  // Get whether the chain is paused
+  function isPaused() external view returns (bool paused);
+}
@@ -0,0 +1,33 @@
+//SPDX-License-Identifier: MIT
+pragma solidity ^0.8.0;
+import "./IAllowList.sol";
+
+interface IRewardManager is IAllowList {
+  // Emitted when [sender] changes the reward address from [oldRewardAddress] to [newRewardAddress]
+  // Note: only emitted once the precompileLogsTimestamp network upgrade is activated
+  event RewardAddressChanged(address indexed sender, address indexed oldRewardAddress, address indexed newRewardAddress);
+
+  // Emitted when [sender] allows block producers to choose their fee recipient
+  // Note: only emitted once the precompileLogsTimestamp network upgrade is activated
+  event FeeRecipientsAllowed(address indexed sender);
+
+  // Emitted when [sender] disables rewards so that fees are burned
+  // Note: only emitted once the precompileLogsTimestamp network upgrade is activated
+  event RewardsDisabled(address indexed sender);
+
+  // Set [addr] to receive the fees of every block
+  function setRewardAddress(address addr) external;
+
+  // Allow block producers to choose the fee recipient of their blocks
+  function allowFeeRecipients() external;
+
+  // Burn the fees of every block by sending them to the blackhole address
+  function disableRewards() external;
+
+  // Get the address that receives the fees of every block
+  // Returns the zero address if fee recipients are allowed
+  function currentRewardAddress() external view returns (address rewardAddress);
+
+  // Get whether block producers may choose the fee recipient of their blocks
+  function areFeeRecipientsAllowed() external view returns (bool isAllowed);
+}
@@ -0,0 +1,173 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var (
+	errAirdropHashMissing  = errors.New("airdrop allocations given without an airdrop hash")
+	errMultipleAirdrops    = errors.New("cannot specify both inline airdrop allocations and an airdrop path")
+	errAirdropAmountNotSet = errors.New("airdrop allocation has no amount and no airdrop amount is set")
+	errAirdropHashMismatch = errors.New("airdrop hash does not match the committed genesis")
+)
+
+// Airdrop is a single allocation of an airdrop. The airdrop is a JSON array of
+// these allocations.
+type Airdrop struct {
+	// Address strings are hex-formatted common.Address
+	Address common.Address `json:"address"`
+	// Amount allocated to [Address]. If nil, the genesis AirdropAmount is
+	// allocated instead.
+	Amount *math.HexOrDecimal256 `json:"amount,omitempty"`
+}
+
+// openAirdrop returns a reader of the airdrop allocations specified by [g].
+// Inline allocations are read from their canonical encoding, which their
+// [AirdropHash] is computed over: the compact JSON encoding of [Airdrop], with
+// the lowercase hex "address" followed by the hex "amount" if it is set, as in
+// [{"address":"0x1000000000000000000000000000000000000000","amount":"0x64"}].
+// A file with the same bytes has the same hash. Allocations in
+// [AirdropPath] are streamed from the file, and if neither is given the
+// embedded [AirdropData] is used.
+func (g *Genesis) openAirdrop() (io.ReadCloser, error) {
+	switch {
+	case len(g.Airdrop) > 0 && len(g.AirdropPath) > 0:
+		return nil, errMultipleAirdrops
+	case len(g.Airdrop) > 0:
+		airdropBytes, err := json.Marshal(g.Airdrop)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(bytes.NewReader(airdropBytes)), nil
+	case len(g.AirdropPath) > 0:
+		f, err := os.Open(g.AirdropPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open airdrop file: %w", err)
+		}
+		return f, nil
+	default:
+		return io.NopCloser(bytes.NewReader(AirdropData)), nil
+	}
+}
+
+// LoadAirdrop verifies the airdrop allocations of [g] against [AirdropHash]
+// by streaming them without applying them or building the genesis block.
+func (g *Genesis) LoadAirdrop() error {
+	return g.readAirdrop(func(common.Address, *big.Int) {})
+}
+
+// ComputeAirdropHash returns the hash of the airdrop allocations specified by
+// [g], to be used as [AirdropHash]. Inline allocations are hashed by their
+// canonical encoding (see [openAirdrop]) and files by their contents.
+func (g *Genesis) ComputeAirdropHash() (common.Hash, error) {
+	r, err := g.openAirdrop()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	defer r.Close()
+
+	hash, _, err := readAirdrop(r, func(Airdrop) error { return nil })
+	return hash, err
+}
+
+// applyAirdrop sets the balances of the airdrop allocations in [statedb] if
+// [AirdropHash] is set, and verifies the allocations against it.
+func (g *Genesis) applyAirdrop(statedb *state.StateDB) error {
+	return g.readAirdrop(func(addr common.Address, amount *big.Int) {
+		statedb.SetBalance(addr, amount)
+	})
+}
+
+// readAirdrop streams the airdrop allocations of [g] if [AirdropHash] is set,
+// calling [onAlloc] with the amount allocated to each address, and verifies
+// the allocations against [AirdropHash].
+func (g *Genesis) readAirdrop(onAlloc func(common.Address, *big.Int)) error {
+	if g.AirdropHash == (common.Hash{}) {
+		if len(g.Airdrop) > 0 || len(g.AirdropPath) > 0 {
+			return errAirdropHashMissing
+		}
+		return nil
+	}
+
+	t := time.Now()
+	r, err := g.openAirdrop()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	h, count, err := readAirdrop(r, func(alloc Airdrop) error {
+		amount := g.AirdropAmount
+		if alloc.Amount != nil {
+			amount = (*big.Int)(alloc.Amount)
+		}
+		if amount == nil {
+			return fmt.Errorf("%w: %s", errAirdropAmountNotSet, alloc.Address)
+		}
+		onAlloc(alloc.Address, amount)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if g.AirdropHash != h {
+		return fmt.Errorf("expected airdrop allocation %s but got %s", g.AirdropHash, h)
+	}
+	log.Debug(
+		"read airdrop allocation",
+		"hash", h, "addrs", count, "balance", g.AirdropAmount,
+		"t", time.Since(t),
+	)
+	return nil
+}
+
+// readAirdrop decodes the JSON array of airdrop allocations from [r] one at a
+// time, calling [onAlloc] for each of them, so the allocations are never held
+// in memory together.
+// Returns the keccak256 hash of all bytes in [r] and the number of allocations.
+func readAirdrop(r io.Reader, onAlloc func(Airdrop) error) (common.Hash, int, error) {
+	hasher := crypto.NewKeccakState()
+	dec := json.NewDecoder(io.TeeReader(r, hasher))
+
+	if tok, err := dec.Token(); err != nil {
+		return common.Hash{}, 0, fmt.Errorf("failed to read airdrop: %w", err)
+	} else if tok != json.Delim('[') {
+		return common.Hash{}, 0, fmt.Errorf("expected airdrop to be a JSON array, found %v", tok)
+	}
+	count := 0
+	for dec.More() {
+		var alloc Airdrop
+		if err := dec.Decode(&alloc); err != nil {
+			return common.Hash{}, 0, fmt.Errorf("failed to decode airdrop allocation %d: %w", count, err)
+		}
+		if err := onAlloc(alloc); err != nil {
+			return common.Hash{}, 0, err
+		}
+		count++
+	}
+	if _, err := dec.Token(); err != nil {
+		return common.Hash{}, 0, fmt.Errorf("failed to read airdrop: %w", err)
+	}
+	// The decoder may stop reading before the end of [r], so hash any
+	// trailing bytes as well.
+	if _, err := io.Copy(hasher, r); err != nil {
+		return common.Hash{}, 0, fmt.Errorf("failed to read airdrop: %w", err)
+	}
+	return common.BytesToHash(hasher.Sum(nil)), count, nil
+}
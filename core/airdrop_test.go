@@ -0,0 +1,207 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenesisAirdrop(t *testing.T) {
+	var (
+		addr1 = common.HexToAddress("0x1000000000000000000000000000000000000000")
+		addr2 = common.HexToAddress("0x2000000000000000000000000000000000000000")
+		// Whitespace is included in the file contents, so it is hashed too.
+		airdropFile = `[
+  {"address": "0x1000000000000000000000000000000000000000"},
+  {"address": "0x2000000000000000000000000000000000000000", "amount": "0x64"}
+]
+`
+		airdropPath = filepath.Join(t.TempDir(), "airdrop.json")
+	)
+	assert.NoError(t, os.WriteFile(airdropPath, []byte(airdropFile), 0o600))
+	fileHash := common.BytesToHash(crypto.Keccak256([]byte(airdropFile)))
+
+	inline := []Airdrop{
+		{Address: addr1},
+		{Address: addr2, Amount: (*math.HexOrDecimal256)(big.NewInt(100))},
+	}
+	inlineHash, err := (&Genesis{Airdrop: inline}).ComputeAirdropHash()
+	assert.NoError(t, err)
+
+	tests := map[string]struct {
+		genesis     Genesis
+		expectedErr string
+	}{
+		"inline": {
+			genesis: Genesis{AirdropHash: inlineHash, AirdropAmount: big.NewInt(10), Airdrop: inline},
+		},
+		"file": {
+			genesis: Genesis{AirdropHash: fileHash, AirdropAmount: big.NewInt(10), AirdropPath: airdropPath},
+		},
+		"hash mismatch": {
+			genesis:     Genesis{AirdropHash: inlineHash, AirdropAmount: big.NewInt(10), AirdropPath: airdropPath},
+			expectedErr: "expected airdrop allocation",
+		},
+		"missing hash": {
+			genesis:     Genesis{AirdropAmount: big.NewInt(10), Airdrop: inline},
+			expectedErr: errAirdropHashMissing.Error(),
+		},
+		"inline and file": {
+			genesis:     Genesis{AirdropHash: inlineHash, AirdropAmount: big.NewInt(10), Airdrop: inline, AirdropPath: airdropPath},
+			expectedErr: errMultipleAirdrops.Error(),
+		},
+		"missing amount": {
+			genesis:     Genesis{AirdropHash: inlineHash, Airdrop: inline},
+			expectedErr: errAirdropAmountNotSet.Error(),
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+			assert.NoError(t, err)
+
+			err = test.genesis.applyAirdrop(statedb)
+			if len(test.expectedErr) > 0 {
+				assert.ErrorContains(t, err, test.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, big.NewInt(10), statedb.GetBalance(addr1))
+			assert.Equal(t, big.NewInt(100), statedb.GetBalance(addr2))
+		})
+	}
+
+	// The airdrop is applied before the genesis alloc, which takes precedence.
+	genesis := &Genesis{
+		Config:        params.TestChainConfig,
+		Alloc:         GenesisAlloc{addr1: {Balance: big.NewInt(1)}},
+		AirdropHash:   fileHash,
+		AirdropAmount: big.NewInt(10),
+		AirdropPath:   airdropPath,
+	}
+	db := rawdb.NewMemoryDatabase()
+	block := genesis.MustCommit(db)
+	statedb, err := state.New(block.Root(), state.NewDatabase(db), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(1), statedb.GetBalance(addr1))
+	assert.Equal(t, big.NewInt(100), statedb.GetBalance(addr2))
+}
+
+func TestInlineAirdropHash(t *testing.T) {
+	inline := []Airdrop{
+		{Address: common.HexToAddress("0x1000000000000000000000000000000000000000")},
+		{Address: common.HexToAddress("0x2000000000000000000000000000000000000000"), Amount: (*math.HexOrDecimal256)(big.NewInt(100))},
+		{Address: common.HexToAddress("0xABCDEF0000000000000000000000000000000000"), Amount: (*math.HexOrDecimal256)(big.NewInt(0))},
+	}
+	// Inline allocations are hashed by their canonical encoding, so changes to
+	// it would change the airdrop hash of existing genesis blocks.
+	canonical := `[{"address":"0x1000000000000000000000000000000000000000"},` +
+		`{"address":"0x2000000000000000000000000000000000000000","amount":"0x64"},` +
+		`{"address":"0xabcdef0000000000000000000000000000000000","amount":"0x0"}]`
+
+	hash, err := (&Genesis{Airdrop: inline}).ComputeAirdropHash()
+	assert.NoError(t, err)
+	assert.Equal(t, common.BytesToHash(crypto.Keccak256([]byte(canonical))), hash)
+
+	// A file holding the canonical encoding has the same hash.
+	airdropPath := filepath.Join(t.TempDir(), "airdrop.json")
+	assert.NoError(t, os.WriteFile(airdropPath, []byte(canonical), 0o600))
+	fileHash, err := (&Genesis{AirdropPath: airdropPath}).ComputeAirdropHash()
+	assert.NoError(t, err)
+	assert.Equal(t, hash, fileHash)
+}
+
+func TestReadAirdrop(t *testing.T) {
+	_, _, err := readAirdrop(strings.NewReader(`{"address": "0x1000000000000000000000000000000000000000"}`), func(Airdrop) error { return nil })
+	assert.ErrorContains(t, err, "expected airdrop to be a JSON array")
+
+	_, _, err = readAirdrop(strings.NewReader(`[{"address": 1}]`), func(Airdrop) error { return nil })
+	assert.ErrorContains(t, err, "failed to decode airdrop allocation 0")
+
+	hash, count, err := readAirdrop(strings.NewReader("[]\n"), func(Airdrop) error { return nil })
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+	assert.Equal(t, common.BytesToHash(crypto.Keccak256([]byte("[]\n"))), hash)
+}
+
+func TestLoadAirdrop(t *testing.T) {
+	airdropFile := `[{"address": "0x1000000000000000000000000000000000000000"}]`
+	airdropPath := filepath.Join(t.TempDir(), "airdrop.json")
+	assert.NoError(t, os.WriteFile(airdropPath, []byte(airdropFile), 0o600))
+	fileHash := common.BytesToHash(crypto.Keccak256([]byte(airdropFile)))
+
+	missingAmount := &Genesis{AirdropHash: fileHash, AirdropPath: airdropPath}
+	assert.ErrorIs(t, missingAmount.LoadAirdrop(), errAirdropAmountNotSet)
+
+	missing := &Genesis{AirdropHash: fileHash, AirdropAmount: big.NewInt(10), AirdropPath: filepath.Join(t.TempDir(), "missing.json")}
+	assert.ErrorContains(t, missing.LoadAirdrop(), "failed to open airdrop file")
+
+	mismatch := &Genesis{AirdropHash: common.Hash{1}, AirdropAmount: big.NewInt(10), AirdropPath: airdropPath}
+	assert.ErrorContains(t, mismatch.LoadAirdrop(), "expected airdrop allocation")
+
+	// Loading verifies the allocations without applying them, and the genesis
+	// block reads the file again to apply them.
+	genesis := &Genesis{
+		Config:        params.TestChainConfig,
+		AirdropHash:   fileHash,
+		AirdropAmount: big.NewInt(10),
+		AirdropPath:   airdropPath,
+	}
+	assert.NoError(t, genesis.LoadAirdrop())
+	db := rawdb.NewMemoryDatabase()
+	block := genesis.MustCommit(db)
+	statedb, err := state.New(block.Root(), state.NewDatabase(db), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(10), statedb.GetBalance(common.HexToAddress("0x1000000000000000000000000000000000000000")))
+}
+
+func TestSetupGenesisAirdropFile(t *testing.T) {
+	airdropFile := `[{"address": "0x1000000000000000000000000000000000000000"}]`
+	airdropPath := filepath.Join(t.TempDir(), "airdrop.json")
+	assert.NoError(t, os.WriteFile(airdropPath, []byte(airdropFile), 0o600))
+	fileHash := common.BytesToHash(crypto.Keccak256([]byte(airdropFile)))
+
+	newGenesis := func(airdropHash common.Hash) *Genesis {
+		return &Genesis{
+			Config:        params.TestChainConfig,
+			GasLimit:      params.TestChainConfig.FeeConfig.GasLimit.Uint64(),
+			AirdropHash:   airdropHash,
+			AirdropAmount: big.NewInt(10),
+			AirdropPath:   airdropPath,
+		}
+	}
+
+	// Building the genesis block reports airdrop errors.
+	_, err := newGenesis(common.Hash{1}).ToBlock(nil)
+	assert.ErrorContains(t, err, "expected airdrop allocation")
+
+	db := rawdb.NewMemoryDatabase()
+	_, err = SetupGenesisBlock(db, newGenesis(fileHash))
+	assert.NoError(t, err)
+	stored := rawdb.ReadCanonicalHash(db, 0)
+	airdropHash, ok := rawdb.ReadAirdropHash(db, stored)
+	assert.True(t, ok)
+	assert.Equal(t, fileHash, airdropHash)
+
+	// Once the genesis is committed, the airdrop file is no longer read.
+	assert.NoError(t, os.Remove(airdropPath))
+	_, err = SetupGenesisBlock(db, newGenesis(fileHash))
+	assert.NoError(t, err)
+
+	// A different airdrop is still rejected.
+	_, err = SetupGenesisBlock(db, newGenesis(common.Hash{1}))
+	assert.ErrorIs(t, err, errAirdropHashMismatch)
+}
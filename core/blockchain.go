@@ -42,12 +42,14 @@ import (
 	"github.com/ava-labs/subnet-evm/consensus"
 	"github.com/ava-labs/subnet-evm/core/rawdb"
 	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ava-labs/subnet-evm/core/state/pruner"
 	"github.com/ava-labs/subnet-evm/core/state/snapshot"
 	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/core/vm"
 	"github.com/ava-labs/subnet-evm/ethdb"
 	"github.com/ava-labs/subnet-evm/metrics"
 	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
 	"github.com/ava-labs/subnet-evm/trie"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/event"
@@ -62,16 +64,18 @@ var (
 
 	errFutureBlockUnsupported  = errors.New("future block insertion not supported")
 	errCacheConfigNotSpecified = errors.New("must specify cache config")
+	errOnlinePruningNotPruning = errors.New("cannot enable online pruning with pruning disabled")
 )
 
 const (
-	bodyCacheLimit      = 256
-	blockCacheLimit     = 256
-	receiptsCacheLimit  = 32
-	txLookupCacheLimit  = 1024
-	feeConfigCacheLimit = 256
-	badBlockLimit       = 10
-	TriesInMemory       = 128
+	bodyCacheLimit           = 256
+	blockCacheLimit          = 256
+	receiptsCacheLimit       = 32
+	txLookupCacheLimit       = 1024
+	feeConfigCacheLimit      = 256
+	coinbaseConfigCacheLimit = 256
+	badBlockLimit            = 10
+	TriesInMemory            = 128
 
 	// BlockChainVersion ensures that an incompatible database forces a resync from scratch.
 	//
@@ -110,6 +114,13 @@ type cacheableFeeConfig struct {
 	lastChangedAt *big.Int
 }
 
+// cacheableCoinbaseConfig encapsulates the coinbase address and whether fee recipients are allowed,
+// in order to cache them together.
+type cacheableCoinbaseConfig struct {
+	coinbaseAddress    common.Address
+	allowFeeRecipients bool
+}
+
 // CacheConfig contains the configuration values for the trie caching/pruning
 // that's resident in a blockchain.
 type CacheConfig struct {
@@ -128,6 +139,11 @@ type CacheConfig struct {
 	SnapshotVerify                  bool    // Verify generated snapshots
 	SkipSnapshotRebuild             bool    // Whether to skip rebuilding the snapshot in favor of returning an error (only set to true for tests)
 	Preimages                       bool    // Whether to store preimage of trie key to the disk
+	OnlinePruning                   bool    // Whether to delete the trie nodes unreachable from the most recently committed roots while running (requires [Pruning])
+	OnlinePruningRetention          uint64  // Number of most recently committed state roots to keep with online pruning
+	OnlinePruningBatchSize          int     // Maximum number of trie nodes and database keys processed by online pruning per accepted block
+	OnlinePruningBloomFilterSize    uint64  // Size (MB) of each bloom filter used by online pruning
+	TxLookupLimit                   uint64  // Number of most recent accepted blocks whose transactions are indexed (0 = all blocks)
 }
 
 var DefaultCacheConfig = &CacheConfig{
@@ -181,13 +197,16 @@ type BlockChain struct {
 
 	currentBlock atomic.Value // Current head of the block chain
 
-	stateCache     state.Database // State database to reuse between imports (contains state cache)
-	stateManager   TrieWriter
-	bodyCache      *lru.Cache // Cache for the most recent block bodies
-	receiptsCache  *lru.Cache // Cache for the most recent receipts per block
-	blockCache     *lru.Cache // Cache for the most recent entire blocks
-	txLookupCache  *lru.Cache // Cache for the most recent transaction lookup data.
-	feeConfigCache *lru.Cache // Cache for the most recent feeConfig lookup data.
+	stateCache          state.Database // State database to reuse between imports (contains state cache)
+	stateManager        TrieWriter
+	bodyCache           *lru.Cache // Cache for the most recent block bodies
+	receiptsCache       *lru.Cache // Cache for the most recent receipts per block
+	blockCache          *lru.Cache // Cache for the most recent entire blocks
+	txLookupCache       *lru.Cache // Cache for the most recent transaction lookup data.
+	feeConfigCache      *lru.Cache // Cache for the most recent feeConfig lookup data.
+	coinbaseConfigCache *lru.Cache // Cache for the most recent coinbase config lookup data.
+
+	onlinePruner *pruner.OnlinePruner // Online pruner of the state, nil if online pruning is disabled
 
 	running int32 // 0 if chain is running, 1 when stopped
 
@@ -227,6 +246,9 @@ type BlockChain struct {
 	// processed blocks. This may be equal to [lastAccepted].
 	acceptorTip     *types.Block
 	acceptorTipLock sync.Mutex
+
+	quit chan struct{} // shutdown signal, closed in Stop.
+	wg   sync.WaitGroup
 }
 
 // NewBlockChain returns a fully initialised block chain using information
@@ -239,32 +261,48 @@ func NewBlockChain(
 	if cacheConfig == nil {
 		return nil, errCacheConfigNotSpecified
 	}
+	var onlinePruner *pruner.OnlinePruner
+	if cacheConfig.OnlinePruning {
+		if !cacheConfig.Pruning {
+			return nil, errOnlinePruningNotPruning
+		}
+		var err error
+		onlinePruner, err = pruner.NewOnlinePruner(db, pruner.OnlinePrunerConfig{
+			Retention: cacheConfig.OnlinePruningRetention,
+			BatchSize: cacheConfig.OnlinePruningBatchSize,
+			BloomSize: cacheConfig.OnlinePruningBloomFilterSize,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create online pruner: %w", err)
+		}
+	}
 	bodyCache, _ := lru.New(bodyCacheLimit)
 	receiptsCache, _ := lru.New(receiptsCacheLimit)
 	blockCache, _ := lru.New(blockCacheLimit)
 	txLookupCache, _ := lru.New(txLookupCacheLimit)
 	feeConfigCache, _ := lru.New(feeConfigCacheLimit)
+	coinbaseConfigCache, _ := lru.New(coinbaseConfigCacheLimit)
 	badBlocks, _ := lru.New(badBlockLimit)
 
 	bc := &BlockChain{
-		chainConfig: chainConfig,
-		cacheConfig: cacheConfig,
-		db:          db,
-		stateCache: state.NewDatabaseWithConfig(db, &trie.Config{
-			Cache:     cacheConfig.TrieCleanLimit,
-			Preimages: cacheConfig.Preimages,
-		}),
-		bodyCache:      bodyCache,
-		receiptsCache:  receiptsCache,
-		blockCache:     blockCache,
-		txLookupCache:  txLookupCache,
-		feeConfigCache: feeConfigCache,
-		engine:         engine,
-		vmConfig:       vmConfig,
-		badBlocks:      badBlocks,
-		senderCacher:   newTxSenderCacher(runtime.NumCPU()),
-		acceptorQueue:  make(chan *types.Block, cacheConfig.AcceptorQueueLimit),
-	}
+		chainConfig:         chainConfig,
+		cacheConfig:         cacheConfig,
+		db:                  db,
+		onlinePruner:        onlinePruner,
+		bodyCache:           bodyCache,
+		receiptsCache:       receiptsCache,
+		blockCache:          blockCache,
+		txLookupCache:       txLookupCache,
+		feeConfigCache:      feeConfigCache,
+		coinbaseConfigCache: coinbaseConfigCache,
+		engine:              engine,
+		vmConfig:            vmConfig,
+		badBlocks:           badBlocks,
+		senderCacher:        newTxSenderCacher(runtime.NumCPU()),
+		acceptorQueue:       make(chan *types.Block, cacheConfig.AcceptorQueueLimit),
+		quit:                make(chan struct{}),
+	}
+	bc.stateCache = bc.newStateCache()
 	bc.validator = NewBlockValidator(chainConfig, bc, engine)
 	bc.prefetcher = newStatePrefetcher(chainConfig, bc, engine)
 	bc.processor = NewStateProcessor(chainConfig, bc, engine)
@@ -283,7 +321,7 @@ func NewBlockChain(
 	bc.currentBlock.Store(nilBlock)
 
 	// Create the state manager
-	bc.stateManager = NewTrieWriter(bc.stateCache.TrieDB(), cacheConfig)
+	bc.stateManager = bc.newTrieWriter()
 
 	// Re-generate current block state if it is missing
 	if err := bc.loadLastState(lastAcceptedHash); err != nil {
@@ -321,19 +359,37 @@ func NewBlockChain(
 		bc.initSnapshot(head)
 	}
 
+	// The fee config history index is only maintained for blocks accepted after
+	// this node started indexing them, so record where the index starts if this
+	// database accepted blocks before.
+	if rawdb.ReadFeeConfigIndexTail(bc.db) == nil {
+		rawdb.WriteFeeConfigIndexTail(bc.db, bc.lastAccepted.NumberU64()+1)
+	}
+
 	// Start processing accepted blocks effects in the background
 	go bc.startAcceptor()
 
+	// Start maintaining the transaction index in the background, unless the
+	// transactions of all blocks are indexed and must remain so.
+	if tail := rawdb.ReadTxIndexTail(bc.db); cacheConfig.TxLookupLimit != 0 || (tail != nil && *tail > 0) {
+		bc.wg.Add(1)
+		go bc.maintainTxIndex()
+	}
+
 	return bc, nil
 }
 
 // writeBlockAcceptedIndices writes any indices that must be persisted for accepted block.
 // This includes the following:
 // - transaction lookup indices
+// - fee config history index
 // - updating the acceptor tip index
 func (bc *BlockChain) writeBlockAcceptedIndices(b *types.Block) error {
 	batch := bc.db.NewBatch()
 	rawdb.WriteTxLookupEntriesByBlock(batch, b)
+	if err := bc.writeFeeConfigChange(batch, b); err != nil {
+		return err
+	}
 	if err := rawdb.WriteAcceptorTip(batch, b.Hash()); err != nil {
 		return fmt.Errorf("%w: failed to write acceptor tip key", err)
 	}
@@ -343,6 +399,24 @@ func (bc *BlockChain) writeBlockAcceptedIndices(b *types.Block) error {
 	return nil
 }
 
+// writeFeeConfigChange adds the fee config set in [b] to the fee config history index, if the
+// fee config manager set the fee config in [b].
+func (bc *BlockChain) writeFeeConfigChange(batch ethdb.KeyValueWriter, b *types.Block) error {
+	if !bc.chainConfig.IsPrecompileEnabled(precompile.FeeConfigManagerAddress, new(big.Int).SetUint64(b.Time())) {
+		return nil
+	}
+	statedb, err := bc.StateAt(b.Root())
+	if err != nil {
+		return fmt.Errorf("%w: failed to open state of accepted block", err)
+	}
+	if !feeConfigChangedIn(bc.chainConfig, b, statedb) {
+		return nil
+	}
+	receipts := rawdb.ReadReceipts(bc.db, b.Hash(), b.NumberU64(), bc.chainConfig)
+	rawdb.WriteFeeConfigChange(batch, newFeeConfigChange(bc.chainConfig, b, statedb, receipts))
+	return nil
+}
+
 // flattenSnapshot attempts to flatten a block of [hash] to disk.
 func (bc *BlockChain) flattenSnapshot(postAbortWork func() error, hash common.Hash) error {
 	// If snapshots are not initialized, perform [postAbortWork] immediately.
@@ -420,6 +494,12 @@ func (bc *BlockChain) addAcceptorQueue(b *types.Block) {
 	bc.acceptorQueue <- b
 }
 
+// AcceptorQueueSize returns the number of accepted blocks that are waiting
+// to be processed by the acceptor.
+func (bc *BlockChain) AcceptorQueueSize() int {
+	return len(bc.acceptorQueue)
+}
+
 // DrainAcceptorQueue blocks until all items in [acceptorQueue] have been
 // processed.
 func (bc *BlockChain) DrainAcceptorQueue() {
@@ -599,6 +679,7 @@ func (bc *BlockChain) ValidateCanonicalChain() error {
 	bc.DrainAcceptorQueue()
 
 	current := bc.CurrentBlock()
+	txIndexTail := rawdb.ReadTxIndexTail(bc.db)
 	i := 0
 	log.Info("Beginning to validate canonical chain", "startBlock", current.NumberU64())
 
@@ -635,10 +716,10 @@ func (bc *BlockChain) ValidateCanonicalChain() error {
 
 		txs := current.Body().Transactions
 
-		// Transactions are only indexed beneath the last accepted block, so we only check
-		// that the transactions have been indexed, if we are checking below the last accepted
-		// block.
-		if current.NumberU64() <= bc.lastAccepted.NumberU64() {
+		// Transactions are only indexed beneath the last accepted block and above the
+		// transaction index tail, so we only check that the transactions have been indexed,
+		// if we are checking within that range.
+		if current.NumberU64() <= bc.lastAccepted.NumberU64() && (txIndexTail == nil || current.NumberU64() >= *txIndexTail) {
 			// Ensure that all of the transactions have been stored correctly in the canonical
 			// chain
 			for txIndex, tx := range txs {
@@ -702,6 +783,10 @@ func (bc *BlockChain) Stop() {
 	bc.stopAcceptor()
 	log.Info("Acceptor queue drained", "t", time.Since(start))
 
+	// Stop the transaction indexer, interrupting any indexing in progress
+	close(bc.quit)
+	bc.wg.Wait()
+
 	log.Info("Shutting down state manager")
 	start = time.Now()
 	if err := bc.stateManager.Shutdown(); err != nil {
@@ -1732,6 +1817,9 @@ func (bc *BlockChain) ResetState(block *types.Block) error {
 	rawdb.WriteHeadHeaderHash(batch, block.Hash())
 	rawdb.WriteSnapshotBlockHash(batch, block.Hash())
 	rawdb.WriteSnapshotRoot(batch, block.Root())
+	// The fee config changes of the blocks before [block] are not known, so the
+	// fee config history is only indexed for the blocks accepted after it.
+	rawdb.WriteFeeConfigIndexTail(batch, block.NumberU64()+1)
 	if err := batch.Write(); err != nil {
 		return err
 	}
@@ -1743,15 +1831,12 @@ func (bc *BlockChain) ResetState(block *types.Block) error {
 	bc.hc.SetCurrentHeader(block.Header())
 
 	lastAcceptedHash := block.Hash()
-	bc.stateCache = state.NewDatabaseWithConfig(bc.db, &trie.Config{
-		Cache:     bc.cacheConfig.TrieCleanLimit,
-		Preimages: bc.cacheConfig.Preimages,
-	})
+	bc.stateCache = bc.newStateCache()
 	if err := bc.loadLastState(lastAcceptedHash); err != nil {
 		return err
 	}
 	// Create the state manager
-	bc.stateManager = NewTrieWriter(bc.stateCache.TrieDB(), bc.cacheConfig)
+	bc.stateManager = bc.newTrieWriter()
 
 	// Make sure the state associated with the block is available
 	head := bc.CurrentBlock()
@@ -1774,3 +1859,124 @@ func (bc *BlockChain) ResetState(block *types.Block) error {
 	}
 	return nil
 }
+
+// newStateCache creates the state database of [bc]. If online pruning is enabled, the
+// trie nodes are written through the online pruner.
+func (bc *BlockChain) newStateCache() state.Database {
+	db := bc.db
+	if bc.onlinePruner != nil {
+		db = bc.onlinePruner.Database()
+	}
+	return state.NewDatabaseWithConfig(db, &trie.Config{
+		Cache:     bc.cacheConfig.TrieCleanLimit,
+		Preimages: bc.cacheConfig.Preimages,
+	})
+}
+
+// newTrieWriter creates the TrieWriter of the trie database of [bc.stateCache].
+func (bc *BlockChain) newTrieWriter() TrieWriter {
+	if bc.onlinePruner != nil {
+		return NewOnlinePruningTrieWriter(bc.stateCache.TrieDB(), bc.onlinePruner, bc.cacheConfig)
+	}
+	return NewTrieWriter(bc.stateCache.TrieDB(), bc.cacheConfig)
+}
+
+// maintainTxIndex is responsible for the construction and deletion of the
+// transaction index.
+//
+// User can use flag `TxLookupLimit` to specify a "recentness" block, below
+// which ancient tx indices get deleted. If `TxLookupLimit` is 0, it means
+// all tx indices will be reserved.
+//
+// The user can adjust the txlookuplimit value for each launch, the tx indices
+// of the blocks which were unindexed with a lower limit are indexed again.
+func (bc *BlockChain) maintainTxIndex() {
+	defer bc.wg.Done()
+
+	txLookupLimit := bc.cacheConfig.TxLookupLimit
+
+	// indexBlocks reindexes or unindexes transactions depending on user configuration
+	indexBlocks := func(tail *uint64, head uint64, done chan struct{}) {
+		defer func() { done <- struct{}{} }()
+
+		// If the user just upgraded to a new version which supports transaction
+		// index pruning, write the new tail and remove anything older.
+		if tail == nil {
+			if txLookupLimit == 0 || head < txLookupLimit {
+				// Nothing to delete, write the tail and return
+				rawdb.WriteTxIndexTail(bc.db, 0)
+			} else {
+				// Prune all stale tx indices and record the tx index tail
+				rawdb.UnindexTransactions(bc.db, 0, head-txLookupLimit+1, bc.quit)
+			}
+			return
+		}
+		// If a previous indexing existed, make sure that we fill in any missing entries
+		if txLookupLimit == 0 || head < txLookupLimit {
+			if *tail > 0 {
+				// The tail may be above the head if the limit was lowered below the
+				// number of accepted blocks, recap the indexing target to the head to
+				// avoid reading non-existent block bodies.
+				end := *tail
+				if end > head+1 {
+					end = head + 1
+				}
+				rawdb.IndexTransactions(bc.db, 0, end, bc.quit)
+			}
+			return
+		}
+		// Update the transaction index to the new chain state
+		if head-txLookupLimit+1 < *tail {
+			// Reindex a part of missing indices and rewind index tail to HEAD-limit
+			rawdb.IndexTransactions(bc.db, head-txLookupLimit+1, *tail, bc.quit)
+		} else {
+			// Unindex a part of stale indices and forward index tail to HEAD-limit
+			rawdb.UnindexTransactions(bc.db, *tail, head-txLookupLimit+1, bc.quit)
+		}
+	}
+
+	// Start listening to accepted blocks and moving the index window, starting
+	// from the last accepted block so the index is repaired without waiting for
+	// a new block to be accepted.
+	var (
+		done       chan struct{} // Non-nil if background unindexing or reindexing routine is active.
+		pending    bool          // Whether a block was accepted while the routine was active.
+		head       = bc.LastAcceptedBlock().NumberU64()
+		acceptedCh = make(chan ChainEvent, 1) // Buffered to avoid locking up the event feed
+	)
+	sub := bc.SubscribeChainAcceptedEvent(acceptedCh)
+	if sub == nil {
+		return
+	}
+	defer sub.Unsubscribe()
+	startIndexing := func() {
+		done = make(chan struct{})
+		go indexBlocks(rawdb.ReadTxIndexTail(bc.db), head, done)
+	}
+	startIndexing()
+
+	for {
+		select {
+		case accepted := <-acceptedCh:
+			head = accepted.Block.NumberU64()
+			if done == nil {
+				startIndexing()
+			} else {
+				pending = true
+			}
+		case <-done:
+			done = nil
+			// Move the index window to the blocks accepted in the meantime
+			if pending {
+				pending = false
+				startIndexing()
+			}
+		case <-bc.quit:
+			if done != nil {
+				log.Info("Waiting background transaction indexer to exit")
+				<-done
+			}
+			return
+		}
+	}
+}
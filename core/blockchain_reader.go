@@ -32,6 +32,7 @@ import (
 
 	"github.com/ava-labs/subnet-evm/commontype"
 	"github.com/ava-labs/subnet-evm/consensus"
+	"github.com/ava-labs/subnet-evm/constants"
 	"github.com/ava-labs/subnet-evm/core/rawdb"
 	"github.com/ava-labs/subnet-evm/core/state"
 	"github.com/ava-labs/subnet-evm/core/state/snapshot"
@@ -211,6 +212,25 @@ func (bc *BlockChain) GetTransactionLookup(hash common.Hash) *rawdb.LegacyTxLook
 	return lookup
 }
 
+// TxLookupError returns the error to report for a transaction that is missing from
+// the transaction index. This is [ErrTxIndexing] if the transactions of older blocks
+// are being indexed, [ErrTxNotIndexed] if only the transactions of the most recent
+// blocks are indexed, and nil if the transactions of all accepted blocks are indexed.
+func (bc *BlockChain) TxLookupError() error {
+	tail := rawdb.ReadTxIndexTail(bc.db)
+	if tail == nil || *tail == 0 {
+		return nil
+	}
+	var (
+		limit = bc.cacheConfig.TxLookupLimit
+		head  = bc.LastAcceptedBlock().NumberU64()
+	)
+	if limit == 0 || head < limit || head-limit+1 < *tail {
+		return fmt.Errorf("%w: the transactions of blocks below %d are not indexed yet", ErrTxIndexing, *tail)
+	}
+	return fmt.Errorf("%w: only the transactions of the last %d accepted blocks are indexed", ErrTxNotIndexed, limit)
+}
+
 // HasState checks if state trie is fully present in the database or not.
 func (bc *BlockChain) HasState(hash common.Hash) bool {
 	_, err := bc.stateCache.OpenTrie(hash)
@@ -356,7 +376,7 @@ func (bc *BlockChain) SubscribeAcceptedTransactionEvent(ch chan<- NewTxsEvent) e
 func (bc *BlockChain) GetFeeConfigAt(parent *types.Header) (commontype.FeeConfig, *big.Int, error) {
 	config := bc.Config()
 	bigTime := new(big.Int).SetUint64(parent.Time)
-	if !config.IsFeeConfigManager(bigTime) {
+	if !config.IsPrecompileEnabled(precompile.FeeConfigManagerAddress, bigTime) {
 		return config.FeeConfig, common.Big0, nil
 	}
 
@@ -381,3 +401,48 @@ func (bc *BlockChain) GetFeeConfigAt(parent *types.Header) (commontype.FeeConfig
 	bc.feeConfigCache.Add(parent.Root, cacheable)
 	return storedFeeConfig, lastChangedAt, nil
 }
+
+// GetFeeConfigChanges returns the fee config changes made in accepted blocks [from, to], ordered
+// by block number. Changes are indexed as blocks are accepted, so [ErrFeeConfigNotIndexed] is
+// returned if [from] is below the first block accepted while the node maintained the index, such
+// as blocks that were skipped by state sync.
+func (bc *BlockChain) GetFeeConfigChanges(from uint64, to uint64) ([]*rawdb.FeeConfigChange, error) {
+	if tail := rawdb.ReadFeeConfigIndexTail(bc.db); tail != nil && from < *tail {
+		return nil, fmt.Errorf("%w: the fee config history is only indexed from block %d", ErrFeeConfigNotIndexed, *tail)
+	}
+	return rawdb.ReadFeeConfigChanges(bc.db, from, to), nil
+}
+
+// GetCoinbaseAt returns the configured coinbase address for a block at [timestamp] built on
+// [parent] and whether block producers may choose their own fee recipient.
+// If RewardManager is enabled at both [timestamp] and [parent], returns the reward configuration
+// in the precompile contract state of [parent]. Otherwise, including the block that activates
+// RewardManager, returns the blackhole address and AllowFeeRecipients from the chain config.
+// If fee recipients are allowed, the returned address should be ignored.
+func (bc *BlockChain) GetCoinbaseAt(parent *types.Header, timestamp uint64) (common.Address, bool, error) {
+	config := bc.Config()
+	if !config.IsPrecompileEnabled(precompile.RewardManagerAddress, new(big.Int).SetUint64(timestamp)) ||
+		!config.IsPrecompileEnabled(precompile.RewardManagerAddress, new(big.Int).SetUint64(parent.Time)) {
+		return constants.BlackholeAddr, config.AllowFeeRecipients, nil
+	}
+
+	// try to return it from the cache
+	if cached, hit := bc.coinbaseConfigCache.Get(parent.Root); hit {
+		cachedCoinbaseConfig, ok := cached.(*cacheableCoinbaseConfig)
+		if !ok {
+			return common.Address{}, false, fmt.Errorf("expected type cacheableCoinbaseConfig, got %T", cached)
+		}
+		return cachedCoinbaseConfig.coinbaseAddress, cachedCoinbaseConfig.allowFeeRecipients, nil
+	}
+
+	stateDB, err := bc.StateAt(parent.Root)
+	if err != nil {
+		return common.Address{}, false, err
+	}
+
+	rewardAddress, allowFeeRecipients := precompile.GetStoredRewardAddress(stateDB)
+	cacheable := &cacheableCoinbaseConfig{coinbaseAddress: rewardAddress, allowFeeRecipients: allowFeeRecipients}
+	// add it to the cache
+	bc.coinbaseConfigCache.Add(parent.Root, cacheable)
+	return rewardAddress, allowFeeRecipients, nil
+}
@@ -4,9 +4,13 @@
 package core
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"math/big"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/ava-labs/subnet-evm/consensus/dummy"
 	"github.com/ava-labs/subnet-evm/core/rawdb"
@@ -128,6 +132,285 @@ func TestPruningBlockChainSnapsDisabled(t *testing.T) {
 	}
 }
 
+func TestOnlinePruningBlockChain(t *testing.T) {
+	create := func(db ethdb.Database, chainConfig *params.ChainConfig, lastAcceptedHash common.Hash) (*BlockChain, error) {
+		return createBlockChain(
+			db,
+			&CacheConfig{
+				TrieCleanLimit:               256,
+				TrieDirtyLimit:               256,
+				TrieDirtyCommitTarget:        20,
+				Pruning:                      true, // Enable pruning
+				CommitInterval:               2,
+				SnapshotLimit:                256,
+				AcceptorQueueLimit:           64,
+				OnlinePruning:                true,
+				OnlinePruningRetention:       2,
+				OnlinePruningBatchSize:       16,
+				OnlinePruningBloomFilterSize: 1,
+			},
+			chainConfig,
+			lastAcceptedHash,
+		)
+	}
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.testFunc(t, create)
+		})
+	}
+}
+
+func TestOnlinePruningDeletesUnreachableTries(t *testing.T) {
+	var (
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		key2, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		addr2   = crypto.PubkeyToAddress(key2.PublicKey)
+		// We use two separate databases since GenerateChain commits the state roots to its underlying
+		// database.
+		genDB       = rawdb.NewMemoryDatabase()
+		chainDB     = rawdb.NewMemoryDatabase()
+		cacheConfig = &CacheConfig{
+			TrieCleanLimit:               256,
+			TrieDirtyLimit:               256,
+			TrieDirtyCommitTarget:        20,
+			Pruning:                      true, // Enable pruning
+			CommitInterval:               4,
+			SnapshotLimit:                256,
+			AcceptorQueueLimit:           64,
+			OnlinePruning:                true,
+			OnlinePruningRetention:       2,
+			OnlinePruningBatchSize:       64,
+			OnlinePruningBloomFilterSize: 1,
+		}
+	)
+
+	// Ensure that key1 has some funds in the genesis block, and deploy a contract whose
+	// code is also stored under its raw hash, as by the legacy code scheme.
+	var (
+		genesisBalance = big.NewInt(1000000000)
+		contractAddr   = common.HexToAddress("0x1000000000000000000000000000000000000000")
+		contractCode   = []byte{byte(vm.PUSH1), 0x01, byte(vm.STOP)}
+		codeHash       = crypto.Keccak256Hash(contractCode)
+	)
+	gspec := &Genesis{
+		Config: &params.ChainConfig{HomesteadBlock: new(big.Int)},
+		Alloc: GenesisAlloc{
+			addr1:        {Balance: genesisBalance},
+			contractAddr: {Balance: common.Big0, Code: contractCode},
+		},
+	}
+	genesis := gspec.MustCommit(genDB)
+	_ = gspec.MustCommit(chainDB)
+	if err := chainDB.Put(codeHash[:], contractCode); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := createBlockChain(chainDB, &CacheConfig{OnlinePruning: true}, gspec.Config, common.Hash{}); err != errOnlinePruningNotPruning {
+		t.Fatalf("expected online pruning without pruning to fail with %q, got %v", errOnlinePruningNotPruning, err)
+	}
+	blockchain, err := createBlockChain(chainDB, cacheConfig, gspec.Config, common.Hash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer blockchain.Stop()
+
+	// Generate enough blocks for the committed roots to leave both the retention window
+	// and the tip buffer of the trie database.
+	numBlocks := 2*tipBufferSize + 8
+	signer := types.HomesteadSigner{}
+	chain, _, err := GenerateChain(gspec.Config, genesis, blockchain.engine, genDB, numBlocks, 10, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr1), addr2, big.NewInt(10000), params.TxGas, nil, nil), signer, key1)
+		gen.AddTx(tx)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatal(err)
+	}
+	for _, block := range chain {
+		if err := blockchain.Accept(block); err != nil {
+			t.Fatal(err)
+		}
+	}
+	blockchain.DrainAcceptorQueue()
+	// Complete the pruning cycle started by the last committed root.
+	for i := 0; i < 1024; i++ {
+		if err := blockchain.onlinePruner.Step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lastCommitted := chain[numBlocks-1]
+	retained := []common.Hash{chain[numBlocks-1-int(cacheConfig.CommitInterval)].Root(), lastCommitted.Root()}
+	if have := blockchain.onlinePruner.Retained(); !reflect.DeepEqual(have, retained) {
+		t.Fatalf("expected retained roots %v, got %v", retained, have)
+	}
+	// The retained roots and the genesis must be complete on disk, while the roots
+	// committed before the retention window and out of the tip buffer must be pruned.
+	diskState := state.NewDatabase(chainDB)
+	for _, root := range append([]common.Hash{genesis.Root()}, retained...) {
+		tr, err := diskState.OpenTrie(root)
+		if err != nil {
+			t.Fatalf("failed to open retained root %s: %v", root, err)
+		}
+		it := tr.NodeIterator(nil)
+		for it.Next(true) {
+		}
+		if err := it.Error(); err != nil {
+			t.Fatalf("retained root %s is incomplete: %v", root, err)
+		}
+	}
+	for _, block := range chain[:numBlocks-tipBufferSize] {
+		if block.NumberU64()%cacheConfig.CommitInterval != 0 {
+			continue
+		}
+		if rawdb.HasTrieNode(chainDB, block.Root()) {
+			t.Fatalf("expected committed root of block %d to be pruned", block.NumberU64())
+		}
+	}
+	if code, _ := chainDB.Get(codeHash[:]); !bytes.Equal(code, contractCode) {
+		t.Fatal("expected contract code stored by the legacy scheme to be kept")
+	}
+	lastAcceptedHash := blockchain.LastConsensusAcceptedBlock().Hash()
+	blockchain.Stop()
+
+	// Restart the chain and check that the retained roots survive the restart.
+	blockchain, err = createBlockChain(chainDB, cacheConfig, gspec.Config, lastAcceptedHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have := blockchain.onlinePruner.Retained(); !reflect.DeepEqual(have, retained) {
+		t.Fatalf("expected retained roots %v after restart, got %v", retained, have)
+	}
+	statedb, err := blockchain.StateAt(lastCommitted.Root())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance := statedb.GetBalance(addr2); balance.Cmp(big.NewInt(int64(10000*numBlocks))) != 0 {
+		t.Fatalf("expected balance %d, got %d", 10000*numBlocks, balance)
+	}
+}
+
+func TestOnlinePruningConcurrentCommit(t *testing.T) {
+	var (
+		db = rawdb.NewMemoryDatabase()
+		// A key of the length of a hash which does not hold a trie node must not be swept.
+		otherKey   = crypto.Keccak256([]byte("key"))
+		otherValue = []byte("value")
+	)
+	if err := db.Put(otherKey, otherValue); err != nil {
+		t.Fatal(err)
+	}
+	onlinePruner, err := pruner.NewOnlinePruner(db, pruner.OnlinePrunerConfig{Retention: 2, BatchSize: 8, BloomSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stateDB := state.NewDatabase(onlinePruner.Database())
+	onlinePruner.Attach(stateDB.TrieDB())
+
+	// commit commits a new root on top of [parent] with all the balances set to [balance],
+	// retaining it first as the blockchain does.
+	commit := func(parent common.Hash, balance int64) common.Hash {
+		statedb, err := state.New(parent, stateDB, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j := 0; j < 16; j++ {
+			statedb.SetBalance(common.BigToAddress(big.NewInt(int64(j))), big.NewInt(balance))
+		}
+		root, err := statedb.Commit(false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := onlinePruner.Retain(root); err != nil {
+			t.Fatal(err)
+		}
+		if err := stateDB.TrieDB().Commit(root, false, nil); err != nil {
+			t.Fatal(err)
+		}
+		return root
+	}
+	first := commit(common.Hash{}, 0)
+
+	// Prune while new roots are committed. The balances cycle, so the trie nodes of the
+	// roots out of the retention window are written again while they are swept.
+	var (
+		stop = make(chan struct{})
+		errs = make(chan error, 1)
+	)
+	go func() {
+		defer close(errs)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if err := onlinePruner.Step(); err != nil {
+				errs <- err
+				return
+			}
+		}
+	}()
+	root := first
+	for i := 0; i < 128; i++ {
+		root = commit(root, int64(1+i%16))
+	}
+	close(stop)
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	// Complete the cycle in progress, then commit another root and complete its cycle,
+	// which no longer keeps the nodes written before the previous cycle started.
+	drain := func() {
+		for i := 0; i < 1024; i++ {
+			if err := onlinePruner.Step(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	drain()
+	root = commit(root, 17)
+	drain()
+
+	retained := onlinePruner.Retained()
+	if len(retained) != 2 || retained[1] != root {
+		t.Fatalf("expected the last 2 committed roots to be retained, got %v", retained)
+	}
+	diskState := state.NewDatabase(db)
+	for i, root := range retained {
+		expected := []*big.Int{big.NewInt(16), big.NewInt(17)}[i]
+		statedb, err := state.New(root, diskState, nil)
+		if err != nil {
+			t.Fatalf("failed to open retained root %s: %v", root, err)
+		}
+		for j := 0; j < 16; j++ {
+			balance := statedb.GetBalance(common.BigToAddress(big.NewInt(int64(j))))
+			if balance.Cmp(expected) != 0 {
+				t.Fatalf("expected balance %d in retained root %s, got %d", expected, root, balance)
+			}
+		}
+		tr, err := diskState.OpenTrie(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		it := tr.NodeIterator(nil)
+		for it.Next(true) {
+		}
+		if err := it.Error(); err != nil {
+			t.Fatalf("retained root %s is incomplete: %v", root, err)
+		}
+	}
+	if rawdb.HasTrieNode(db, first) {
+		t.Fatal("expected the first committed root to be pruned")
+	}
+	if value, _ := db.Get(otherKey); !bytes.Equal(value, otherValue) {
+		t.Fatal("expected a key of the length of a hash which does not hold a trie node to be kept")
+	}
+}
+
 type wrappedStateManager struct {
 	TrieWriter
 }
@@ -525,3 +808,116 @@ func TestUngracefulAsyncShutdown(t *testing.T) {
 		}
 	}
 }
+
+func TestTxLookupLimit(t *testing.T) {
+	var (
+		key1, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		key2, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		addr2   = crypto.PubkeyToAddress(key2.PublicKey)
+		// We use two separate databases since GenerateChain commits the state roots to its underlying
+		// database.
+		genDB   = rawdb.NewMemoryDatabase()
+		chainDB = rawdb.NewMemoryDatabase()
+	)
+	cacheConfig := func(txLookupLimit uint64) *CacheConfig {
+		config := *pruningConfig
+		config.TxLookupLimit = txLookupLimit
+		return &config
+	}
+
+	// Ensure that key1 has some funds in the genesis block.
+	genesisBalance := big.NewInt(1000000)
+	gspec := &Genesis{
+		Config: &params.ChainConfig{HomesteadBlock: new(big.Int)},
+		Alloc:  GenesisAlloc{addr1: {Balance: genesisBalance}},
+	}
+	genesis := gspec.MustCommit(genDB)
+	_ = gspec.MustCommit(chainDB)
+
+	blockchain, err := createBlockChain(chainDB, cacheConfig(4), gspec.Config, common.Hash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// This call generates a chain of 10 blocks.
+	signer := types.HomesteadSigner{}
+	// Generate chain of blocks using [genDB] instead of [chainDB] to avoid writing
+	// to the BlockChain's database while generating blocks.
+	chain, _, err := GenerateChain(gspec.Config, genesis, blockchain.engine, genDB, 10, 10, func(i int, gen *BlockGen) {
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr1), addr2, big.NewInt(10000), params.TxGas, nil, nil), signer, key1)
+		gen.AddTx(tx)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := blockchain.InsertChain(chain); err != nil {
+		t.Fatal(err)
+	}
+	for _, block := range chain {
+		if err := blockchain.Accept(block); err != nil {
+			t.Fatal(err)
+		}
+	}
+	blockchain.DrainAcceptorQueue()
+
+	// waitTail waits for the transaction index tail to reach [tail].
+	waitTail := func(tail uint64) {
+		for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+			if have := rawdb.ReadTxIndexTail(chainDB); have != nil && *have == tail {
+				return
+			}
+			if time.Since(start) > 10*time.Second {
+				t.Fatalf("timed out waiting for the transaction index tail to reach %d", tail)
+			}
+		}
+	}
+	// verify checks that the transactions of the blocks from [tail] are indexed, and that
+	// the transactions of the older blocks are not.
+	verify := func(tail uint64) {
+		for _, block := range chain {
+			for _, tx := range block.Transactions() {
+				indexed := rawdb.ReadTxLookupEntry(chainDB, tx.Hash()) != nil
+				if block.NumberU64() >= tail && !indexed {
+					t.Fatalf("expected transaction of block %d to be indexed", block.NumberU64())
+				}
+				if block.NumberU64() < tail && indexed {
+					t.Fatalf("expected transaction of block %d to be unindexed", block.NumberU64())
+				}
+			}
+		}
+	}
+
+	// Only the transactions of the last 4 blocks are indexed.
+	waitTail(7)
+	verify(7)
+	if err := blockchain.TxLookupError(); !errors.Is(err, ErrTxNotIndexed) {
+		t.Fatalf("expected %q, got %v", ErrTxNotIndexed, err)
+	}
+	if err := blockchain.ValidateCanonicalChain(); err != nil {
+		t.Fatal(err)
+	}
+	lastAcceptedHash := blockchain.LastConsensusAcceptedBlock().Hash()
+	blockchain.Stop()
+
+	// Raising the limit reindexes the transactions of the older blocks.
+	blockchain, err = createBlockChain(chainDB, cacheConfig(8), gspec.Config, lastAcceptedHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitTail(3)
+	verify(3)
+	blockchain.Stop()
+
+	// Removing the limit reindexes the transactions of all blocks.
+	blockchain, err = createBlockChain(chainDB, cacheConfig(0), gspec.Config, lastAcceptedHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer blockchain.Stop()
+	waitTail(0)
+	verify(0)
+	if err := blockchain.TxLookupError(); err != nil {
+		t.Fatalf("expected all transactions to be indexed, got %v", err)
+	}
+}
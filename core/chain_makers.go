@@ -33,6 +33,7 @@ import (
 	"github.com/ava-labs/subnet-evm/commontype"
 	"github.com/ava-labs/subnet-evm/consensus"
 	"github.com/ava-labs/subnet-evm/consensus/dummy"
+	"github.com/ava-labs/subnet-evm/constants"
 	"github.com/ava-labs/subnet-evm/core/state"
 	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/core/vm"
@@ -216,6 +217,10 @@ func GenerateChain(config *params.ChainConfig, parent *types.Block, engine conse
 		b := &BlockGen{i: i, chain: blocks, parent: parent, statedb: statedb, config: config, engine: engine}
 		b.header = makeHeader(chainreader, config, parent, gap, statedb, b.engine)
 
+		// Configure any stateful precompiles that go into effect during this block, as
+		// the state processor does.
+		config.CheckConfigurePrecompiles(new(big.Int).SetUint64(parent.Time()), types.NewBlockWithHeader(b.header), statedb)
+
 		// Execute any user modifications to the block
 		if gen != nil {
 			gen(i, b)
@@ -312,3 +317,6 @@ func (cr *fakeChainReader) GetBlock(hash common.Hash, number uint64) *types.Bloc
 func (cr *fakeChainReader) GetFeeConfigAt(parent *types.Header) (commontype.FeeConfig, *big.Int, error) {
 	return cr.config.FeeConfig, nil, nil
 }
+func (cr *fakeChainReader) GetCoinbaseAt(parent *types.Header, timestamp uint64) (common.Address, bool, error) {
+	return constants.BlackholeAddr, cr.config.AllowFeeRecipients, nil
+}
@@ -38,6 +38,18 @@ var (
 
 	// ErrNoGenesis is returned when there is no Genesis Block.
 	ErrNoGenesis = errors.New("genesis not found in chain")
+
+	// ErrTxIndexing is returned when a transaction is looked up while the
+	// transactions of older blocks are still being indexed.
+	ErrTxIndexing = errors.New("transaction indexing is in progress")
+
+	// ErrTxNotIndexed is returned when a transaction is looked up while only the
+	// transactions of the most recent blocks are indexed.
+	ErrTxNotIndexed = errors.New("transaction not indexed")
+
+	// ErrFeeConfigNotIndexed is returned when the fee config history is requested for blocks
+	// accepted before the node maintained the fee config history index.
+	ErrFeeConfigNotIndexed = errors.New("fee config history not indexed")
 )
 
 // List of evm-call-message pre-checking errors. All state transition messages will
@@ -75,6 +87,10 @@ var (
 	// ErrGasUintOverflow is returned when calculating gas usage.
 	ErrGasUintOverflow = errors.New("gas uint64 overflow")
 
+	// ErrMaxInitCodeSizeExceeded is returned if creation transaction provides the init code bigger
+	// than init code size limit.
+	ErrMaxInitCodeSizeExceeded = errors.New("max initcode size exceeded")
+
 	// ErrIntrinsicGas is returned if the transaction is specified to use less gas
 	// than required to start the invocation.
 	ErrIntrinsicGas = errors.New("intrinsic gas too low")
@@ -0,0 +1,68 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"math/big"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// feeConfigChangedIn returns true if the fee config manager set the fee config in [block],
+// where [statedb] is the state after [block].
+func feeConfigChangedIn(config *params.ChainConfig, block *types.Block, statedb *state.StateDB) bool {
+	return config.IsPrecompileEnabled(precompile.FeeConfigManagerAddress, new(big.Int).SetUint64(block.Time())) &&
+		precompile.GetFeeConfigLastChangedAt(statedb).Cmp(block.Number()) == 0
+}
+
+// newFeeConfigChange returns the entry of the fee config history index for [block], in which the
+// fee config manager set the fee config. [statedb] is the state after [block] and [receipts] are
+// its receipts.
+//
+// The transaction that set the fee config is identified by its FeeConfigChanged log once the
+// PrecompileLogs upgrade is activated. Before that, it is the last successful transaction in
+// [block] that called setFeeConfig on the fee config manager directly.
+func newFeeConfigChange(config *params.ChainConfig, block *types.Block, statedb *state.StateDB, receipts types.Receipts) *rawdb.FeeConfigChange {
+	change := &rawdb.FeeConfigChange{
+		BlockNumber: block.NumberU64(),
+		BlockHash:   block.Hash(),
+		Timestamp:   block.Time(),
+		FeeConfig:   precompile.GetStoredFeeConfig(statedb),
+	}
+
+	for _, receipt := range receipts {
+		for _, log := range receipt.Logs {
+			if log.Address == precompile.FeeConfigManagerAddress && len(log.Topics) > 1 && log.Topics[0] == precompile.FeeConfigChangedEventID {
+				change.TxHash = log.TxHash
+				change.Sender = common.BytesToAddress(log.Topics[1].Bytes())
+			}
+		}
+	}
+	txs := block.Transactions()
+	if change.TxHash != (common.Hash{}) || len(receipts) != len(txs) {
+		return change
+	}
+
+	signer := types.MakeSigner(config, block.Number(), new(big.Int).SetUint64(block.Time()))
+	for i := len(txs) - 1; i >= 0; i-- {
+		tx := txs[i]
+		if receipts[i].Status != types.ReceiptStatusSuccessful || tx.To() == nil ||
+			*tx.To() != precompile.FeeConfigManagerAddress || !precompile.IsSetFeeConfigInput(tx.Data()) {
+			continue
+		}
+		sender, err := types.Sender(signer, tx)
+		if err != nil {
+			continue
+		}
+		change.TxHash = tx.Hash()
+		change.Sender = sender
+		break
+	}
+	return change
+}
@@ -0,0 +1,133 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/consensus/dummy"
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/core/vm"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeeConfigHistory(t *testing.T) {
+	var (
+		adminKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		adminAddr   = crypto.PubkeyToAddress(adminKey.PublicKey)
+		gasPrice    = big.NewInt(225000000000)
+
+		config = &params.ChainConfig{
+			ChainID:             big.NewInt(1),
+			FeeConfig:           params.DefaultFeeConfig,
+			HomesteadBlock:      big.NewInt(0),
+			EIP150Block:         big.NewInt(0),
+			EIP150Hash:          common.Hash{},
+			EIP155Block:         big.NewInt(0),
+			EIP158Block:         big.NewInt(0),
+			ByzantiumBlock:      big.NewInt(0),
+			ConstantinopleBlock: big.NewInt(0),
+			PetersburgBlock:     big.NewInt(0),
+			IstanbulBlock:       big.NewInt(0),
+			MuirGlacierBlock:    big.NewInt(0),
+			NetworkUpgrades: params.NetworkUpgrades{
+				SubnetEVMTimestamp: big.NewInt(0),
+				// Blocks are 10 seconds apart, so the fee config manager emits logs from block 3.
+				PrecompileLogsTimestamp: big.NewInt(25),
+			},
+			GenesisPrecompiles: params.Precompiles{
+				precompile.FeeConfigManagerConfigKey: precompile.NewFeeManagerConfig(big.NewInt(0), []common.Address{adminAddr}),
+			},
+		}
+		signer = types.LatestSigner(config)
+
+		gspec = &Genesis{
+			Config:   config,
+			Alloc:    GenesisAlloc{adminAddr: GenesisAccount{Balance: big.NewInt(1000000000000000000)}},
+			GasLimit: config.FeeConfig.GasLimit.Uint64(),
+		}
+		db      = rawdb.NewMemoryDatabase()
+		genesis = gspec.MustCommit(db)
+	)
+	blockchain, err := NewBlockChain(db, DefaultCacheConfig, gspec.Config, dummy.NewFaker(), vm.Config{}, common.Hash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer blockchain.Stop()
+
+	// Setting the same fee config still counts as a change, which keeps the blocks valid for
+	// the fee config of the chain config used to generate them.
+	setFeeConfig, err := precompile.PackSetFeeConfig(config.FeeConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	genDB := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(genDB)
+	var txHashes []common.Hash
+	blocks, _, err := GenerateChain(gspec.Config, genesis, dummy.NewFaker(), genDB, 3, 10, func(i int, gen *BlockGen) {
+		to, data := precompile.FeeConfigManagerAddress, setFeeConfig
+		if i == 1 {
+			to, data = adminAddr, nil
+		}
+		tx, err := types.SignTx(types.NewTransaction(gen.TxNonce(adminAddr), to, big.NewInt(0), 500_000, gasPrice, data), signer, adminKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gen.AddTx(tx)
+		txHashes = append(txHashes, tx.Hash())
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := blockchain.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+	for _, block := range blocks {
+		if err := blockchain.Accept(block); err != nil {
+			t.Fatal(err)
+		}
+	}
+	blockchain.DrainAcceptorQueue()
+
+	// The fee config set at genesis is not attributed to a transaction, the change in block 1
+	// is identified by the transaction calling the precompile and the change in block 3 by
+	// the FeeConfigChanged log.
+	expected := []*rawdb.FeeConfigChange{
+		{BlockNumber: 0, BlockHash: genesis.Hash(), Timestamp: genesis.Time(), FeeConfig: config.FeeConfig},
+		{BlockNumber: 1, BlockHash: blocks[0].Hash(), Timestamp: blocks[0].Time(), FeeConfig: config.FeeConfig, TxHash: txHashes[0], Sender: adminAddr},
+		{BlockNumber: 3, BlockHash: blocks[2].Hash(), Timestamp: blocks[2].Time(), FeeConfig: config.FeeConfig, TxHash: txHashes[2], Sender: adminAddr},
+	}
+	changes, err := blockchain.GetFeeConfigChanges(0, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, changes)
+	changes, err = blockchain.GetFeeConfigChanges(1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, expected[1:2], changes)
+	changes, err = blockchain.GetFeeConfigChanges(2, 2)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+
+	// A database that accepted blocks before the index was maintained only
+	// indexes the blocks accepted after restarting.
+	blockchain.Stop()
+	assert.NoError(t, db.Delete([]byte("FeeConfigIndexTail")))
+	blockchain, err = NewBlockChain(db, DefaultCacheConfig, gspec.Config, dummy.NewFaker(), vm.Config{}, blocks[2].Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer blockchain.Stop()
+	_, err = blockchain.GetFeeConfigChanges(3, 3)
+	assert.ErrorIs(t, err, ErrFeeConfigNotIndexed)
+	changes, err = blockchain.GetFeeConfigChanges(4, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+}
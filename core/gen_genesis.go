@@ -29,6 +29,8 @@ func (g Genesis) MarshalJSON() ([]byte, error) {
 		Alloc         map[common.UnprefixedAddress]GenesisAccount `json:"alloc"      gencodec:"required"`
 		AirdropHash   common.Hash                                 `json:"airdropHash"`
 		AirdropAmount *math.HexOrDecimal256                       `json:"airdropAmount"`
+		Airdrop       []Airdrop                                   `json:"airdrop,omitempty"`
+		AirdropPath   string                                      `json:"airdropPath,omitempty"`
 		Number        math.HexOrDecimal64                         `json:"number"`
 		GasUsed       math.HexOrDecimal64                         `json:"gasUsed"`
 		ParentHash    common.Hash                                 `json:"parentHash"`
@@ -51,6 +53,8 @@ func (g Genesis) MarshalJSON() ([]byte, error) {
 	}
 	enc.AirdropHash = g.AirdropHash
 	enc.AirdropAmount = (*math.HexOrDecimal256)(g.AirdropAmount)
+	enc.Airdrop = g.Airdrop
+	enc.AirdropPath = g.AirdropPath
 	enc.Number = math.HexOrDecimal64(g.Number)
 	enc.GasUsed = math.HexOrDecimal64(g.GasUsed)
 	enc.ParentHash = g.ParentHash
@@ -72,6 +76,8 @@ func (g *Genesis) UnmarshalJSON(input []byte) error {
 		Alloc         map[common.UnprefixedAddress]GenesisAccount `json:"alloc"      gencodec:"required"`
 		AirdropHash   *common.Hash                                `json:"airdropHash"`
 		AirdropAmount *math.HexOrDecimal256                       `json:"airdropAmount"`
+		Airdrop       []Airdrop                                   `json:"airdrop,omitempty"`
+		AirdropPath   *string                                     `json:"airdropPath,omitempty"`
 		Number        *math.HexOrDecimal64                        `json:"number"`
 		GasUsed       *math.HexOrDecimal64                        `json:"gasUsed"`
 		ParentHash    *common.Hash                                `json:"parentHash"`
@@ -120,6 +126,12 @@ func (g *Genesis) UnmarshalJSON(input []byte) error {
 	if dec.AirdropAmount != nil {
 		g.AirdropAmount = (*big.Int)(dec.AirdropAmount)
 	}
+	if dec.Airdrop != nil {
+		g.Airdrop = dec.Airdrop
+	}
+	if dec.AirdropPath != nil {
+		g.AirdropPath = *dec.AirdropPath
+	}
 	if dec.Number != nil {
 		g.Number = uint64(*dec.Number)
 	}
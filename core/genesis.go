@@ -34,7 +34,6 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
-	"time"
 
 	"github.com/ava-labs/subnet-evm/core/rawdb"
 	"github.com/ava-labs/subnet-evm/core/state"
@@ -45,7 +44,6 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/common/math"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -61,11 +59,6 @@ var AirdropData []byte
 
 var errGenesisNoConfig = errors.New("genesis has no chain configuration")
 
-type Airdrop struct {
-	// Address strings are hex-formatted common.Address
-	Address common.Address `json:"address"`
-}
-
 // Genesis specifies the header fields, state of a genesis block. It also defines hard
 // fork switch-over blocks through the chain configuration.
 type Genesis struct {
@@ -80,6 +73,8 @@ type Genesis struct {
 	Alloc         GenesisAlloc        `json:"alloc"      gencodec:"required"`
 	AirdropHash   common.Hash         `json:"airdropHash"`
 	AirdropAmount *big.Int            `json:"airdropAmount"`
+	Airdrop       []Airdrop           `json:"airdrop,omitempty"`     // Inline airdrop allocations, verified against [AirdropHash]
+	AirdropPath   string              `json:"airdropPath,omitempty"` // Path of a file containing the airdrop allocations, verified against [AirdropHash]
 
 	// These fields are used for consensus tests. Please don't use them
 	// in actual genesis blocks.
@@ -212,17 +207,19 @@ func SetupGenesisBlock(db ethdb.Database, genesis *Genesis) (*params.ChainConfig
 	header := rawdb.ReadHeader(db, stored, 0)
 	if _, err := state.New(header.Root, state.NewDatabase(db), nil); err != nil {
 		// Ensure the stored genesis matches with the given one.
-		hash := genesis.ToBlock(nil).Hash()
-		if hash != stored {
+		block, err := genesis.ToBlock(nil)
+		if err != nil {
+			return genesis.Config, err
+		}
+		if hash := block.Hash(); hash != stored {
 			return genesis.Config, &GenesisMismatchError{stored, hash}
 		}
-		_, err := genesis.Commit(db)
+		_, err = genesis.Commit(db)
 		return genesis.Config, err
 	}
 	// Check whether the genesis block is already written.
-	hash := genesis.ToBlock(nil).Hash()
-	if hash != stored {
-		return genesis.Config, &GenesisMismatchError{stored, hash}
+	if err := genesis.verifyStored(db, stored); err != nil {
+		return genesis.Config, err
 	}
 	// Get the existing chain configuration.
 	newcfg := genesis.Config
@@ -254,34 +251,47 @@ func SetupGenesisBlock(db ethdb.Database, genesis *Genesis) (*params.ChainConfig
 	return newcfg, nil
 }
 
+// verifyStored returns an error if [g] does not match the genesis block
+// [stored] committed to [db].
+// The airdrop allocations are only read when the genesis is first committed,
+// so that an airdrop file does not need to remain available afterwards. If the
+// airdrop hash verified at that point was recorded, it is compared to the one
+// of [g] instead of building the genesis block again. Otherwise, it is recorded
+// once the genesis block built from [g] matches [stored].
+func (g *Genesis) verifyStored(db ethdb.KeyValueStore, stored common.Hash) error {
+	if airdropHash, ok := rawdb.ReadAirdropHash(db, stored); ok {
+		if airdropHash != g.AirdropHash {
+			return fmt.Errorf("%w (have %s, new %s)", errAirdropHashMismatch, airdropHash, g.AirdropHash)
+		}
+		return nil
+	}
+	block, err := g.ToBlock(nil)
+	if err != nil {
+		return err
+	}
+	if hash := block.Hash(); hash != stored {
+		return &GenesisMismatchError{stored, hash}
+	}
+	if g.AirdropHash != (common.Hash{}) {
+		rawdb.WriteAirdropHash(db, stored, g.AirdropHash)
+	}
+	return nil
+}
+
 // ToBlock creates the genesis block and writes state of a genesis specification
 // to the given database (or discards it if nil).
-func (g *Genesis) ToBlock(db ethdb.Database) *types.Block {
+// Returns an error if the airdrop allocations cannot be read or do not match
+// [AirdropHash].
+func (g *Genesis) ToBlock(db ethdb.Database) (*types.Block, error) {
 	if db == nil {
 		db = rawdb.NewMemoryDatabase()
 	}
 	statedb, err := state.New(common.Hash{}, state.NewDatabase(db), nil)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
-	if g.AirdropHash != (common.Hash{}) {
-		t := time.Now()
-		h := common.BytesToHash(crypto.Keccak256(AirdropData))
-		if g.AirdropHash != h {
-			panic(fmt.Sprintf("expected standard allocation %s but got %s", g.AirdropHash, h))
-		}
-		airdrop := []*Airdrop{}
-		if err := json.Unmarshal(AirdropData, &airdrop); err != nil {
-			panic(err)
-		}
-		for _, alloc := range airdrop {
-			statedb.SetBalance(alloc.Address, g.AirdropAmount)
-		}
-		log.Debug(
-			"applied airdrop allocation",
-			"hash", h, "addrs", len(airdrop), "balance", g.AirdropAmount,
-			"t", time.Since(t),
-		)
+	if err := g.applyAirdrop(statedb); err != nil {
+		return nil, err
 	}
 
 	head := &types.Header{
@@ -329,16 +339,19 @@ func (g *Genesis) ToBlock(db ethdb.Database) *types.Block {
 	}
 	statedb.Commit(false)
 	if err := statedb.Database().TrieDB().Commit(root, true, nil); err != nil {
-		panic(fmt.Sprintf("unable to commit genesis block: %v", err))
+		return nil, fmt.Errorf("unable to commit genesis block: %w", err)
 	}
 
-	return types.NewBlock(head, nil, nil, nil, trie.NewStackTrie(nil))
+	return types.NewBlock(head, nil, nil, nil, trie.NewStackTrie(nil)), nil
 }
 
 // Commit writes the block and state of a genesis specification to the database.
 // The block is committed as the canonical head block.
 func (g *Genesis) Commit(db ethdb.Database) (*types.Block, error) {
-	block := g.ToBlock(db)
+	block, err := g.ToBlock(db)
+	if err != nil {
+		return nil, err
+	}
 	if block.Number().Sign() != 0 {
 		return nil, errors.New("can't commit genesis block with number > 0")
 	}
@@ -356,6 +369,21 @@ func (g *Genesis) Commit(db ethdb.Database) (*types.Block, error) {
 	rawdb.WriteHeadBlockHash(batch, block.Hash())
 	rawdb.WriteHeadHeaderHash(batch, block.Hash())
 	rawdb.WriteChainConfig(batch, block.Hash(), config)
+	// Index the initial fee config if the fee config manager is enabled in the genesis. The
+	// fee config history of the chain is indexed from the genesis onwards.
+	rawdb.WriteFeeConfigIndexTail(batch, 0)
+	// The airdrop allocations were verified against [AirdropHash] when
+	// building the block, so later starts compare [AirdropHash] instead.
+	if g.AirdropHash != (common.Hash{}) {
+		rawdb.WriteAirdropHash(batch, block.Hash(), g.AirdropHash)
+	}
+	statedb, err := state.New(block.Root(), state.NewDatabase(db), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open genesis state: %w", err)
+	}
+	if feeConfigChangedIn(config, block, statedb) {
+		rawdb.WriteFeeConfigChange(batch, newFeeConfigChange(config, block, statedb, nil))
+	}
 	if err := batch.Write(); err != nil {
 		return nil, fmt.Errorf("failed to write genesis block: %w", err)
 	}
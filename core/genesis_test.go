@@ -183,11 +183,11 @@ func TestStatefulPrecompilesConfigure(t *testing.T) {
 		"allow list enabled in genesis": {
 			getConfig: func() *params.ChainConfig {
 				config := *params.TestChainConfig
-				config.ContractDeployerAllowListConfig = precompile.NewContractDeployerAllowListConfig(big.NewInt(0), []common.Address{addr})
+				config.GenesisPrecompiles = params.Precompiles{precompile.ContractDeployerAllowListConfigKey: precompile.NewContractDeployerAllowListConfig(big.NewInt(0), []common.Address{addr})}
 				return &config
 			},
 			assertState: func(t *testing.T, sdb *state.StateDB) {
-				assert.Equal(t, precompile.AllowListAdmin, precompile.GetContractDeployerAllowListStatus(sdb, addr), "unexpected allow list status for modified address")
+				assert.Equal(t, precompile.AllowListAdmin, precompile.GetContractDeployerAllowListStatus(sdb, addr, common.Big0), "unexpected allow list status for modified address")
 				assert.Equal(t, uint64(1), sdb.GetNonce(precompile.ContractDeployerAllowListAddress))
 			},
 		},
@@ -209,7 +209,10 @@ func TestStatefulPrecompilesConfigure(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			genesisBlock := genesis.ToBlock(nil)
+			genesisBlock, err := genesis.ToBlock(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
 			genesisRoot := genesisBlock.Root()
 
 			statedb, err := state.New(genesisRoot, state.NewDatabase(db), nil)
@@ -0,0 +1,84 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/ava-labs/subnet-evm/commontype"
+	"github.com/ava-labs/subnet-evm/ethdb"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// FeeConfigChange is an entry of the fee config history index, which records the fee config
+// set by the fee config manager precompile in an accepted block.
+type FeeConfigChange struct {
+	BlockNumber uint64
+	BlockHash   common.Hash
+	Timestamp   uint64
+	FeeConfig   commontype.FeeConfig
+	// TxHash and Sender identify the transaction that set the fee config. They are empty if
+	// the fee config was set when the fee config manager activated, or if the transaction
+	// that set it could not be identified.
+	TxHash common.Hash
+	Sender common.Address
+}
+
+// WriteFeeConfigChange stores the fee config [change] in the fee config history index.
+func WriteFeeConfigChange(db ethdb.KeyValueWriter, change *FeeConfigChange) {
+	data, err := rlp.EncodeToBytes(change)
+	if err != nil {
+		log.Crit("Failed to RLP encode fee config change", "err", err)
+	}
+	if err := db.Put(feeConfigChangeKey(change.BlockNumber), data); err != nil {
+		log.Crit("Failed to store fee config change", "err", err)
+	}
+}
+
+// ReadFeeConfigIndexTail retrieves the number of the oldest block from which the fee config
+// changes of all accepted blocks are in the fee config history index. Returns nil if the
+// tail has not been recorded.
+func ReadFeeConfigIndexTail(db ethdb.KeyValueReader) *uint64 {
+	data, _ := db.Get(feeConfigIndexTailKey)
+	if len(data) != 8 {
+		return nil
+	}
+	number := binary.BigEndian.Uint64(data)
+	return &number
+}
+
+// WriteFeeConfigIndexTail stores the number of the oldest block from which the fee config
+// history is indexed into database.
+func WriteFeeConfigIndexTail(db ethdb.KeyValueWriter, number uint64) {
+	if err := db.Put(feeConfigIndexTailKey, encodeBlockNumber(number)); err != nil {
+		log.Crit("Failed to store the fee config index tail", "err", err)
+	}
+}
+
+// ReadFeeConfigChanges retrieves the fee config changes in the fee config history index
+// made in blocks [from, to], ordered by block number.
+func ReadFeeConfigChanges(db ethdb.Iteratee, from uint64, to uint64) []*FeeConfigChange {
+	it := db.NewIterator(feeConfigChangePrefix, encodeBlockNumber(from))
+	defer it.Release()
+
+	var changes []*FeeConfigChange
+	for it.Next() {
+		key := it.Key()
+		if len(key) != len(feeConfigChangePrefix)+8 {
+			continue
+		}
+		if binary.BigEndian.Uint64(key[len(feeConfigChangePrefix):]) > to {
+			break
+		}
+		change := new(FeeConfigChange)
+		if err := rlp.DecodeBytes(it.Value(), change); err != nil {
+			log.Error("Invalid fee config change RLP", "key", key, "err", err)
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
@@ -0,0 +1,60 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/commontype"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Tests that fee config changes are read back in order and within the requested range.
+func TestFeeConfigChangeStorage(t *testing.T) {
+	db := NewMemoryDatabase()
+
+	var changes []*FeeConfigChange
+	for _, number := range []uint64{0, 3, 4, 256} {
+		change := &FeeConfigChange{
+			BlockNumber: number,
+			BlockHash:   common.BigToHash(new(big.Int).SetUint64(number + 1)),
+			Timestamp:   number * 10,
+			FeeConfig: commontype.FeeConfig{
+				GasLimit:                 big.NewInt(8_000_000),
+				TargetBlockRate:          2,
+				MinBaseFee:               new(big.Int).SetUint64(number),
+				TargetGas:                big.NewInt(15_000_000),
+				BaseFeeChangeDenominator: big.NewInt(36),
+				MinBlockGasCost:          big.NewInt(0),
+				MaxBlockGasCost:          big.NewInt(1_000_000),
+				BlockGasCostStep:         big.NewInt(200_000),
+			},
+		}
+		if number != 0 {
+			change.TxHash = common.BigToHash(new(big.Int).SetUint64(number))
+			change.Sender = common.BigToAddress(new(big.Int).SetUint64(number))
+		}
+		WriteFeeConfigChange(db, change)
+		changes = append(changes, change)
+	}
+
+	tests := []struct {
+		from, to uint64
+		want     []*FeeConfigChange
+	}{
+		{0, 256, changes},
+		{0, 0, changes[:1]},
+		{1, 2, nil},
+		{3, 255, changes[1:3]},
+		{4, 1000, changes[2:]},
+		{257, 1000, nil},
+	}
+	for _, test := range tests {
+		if have := ReadFeeConfigChanges(db, test.from, test.to); !reflect.DeepEqual(have, test.want) {
+			t.Errorf("fee config changes [%d, %d] mismatch: have %v, want %v", test.from, test.to, have, test.want)
+		}
+	}
+}
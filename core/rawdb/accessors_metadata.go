@@ -111,6 +111,25 @@ func WriteChainConfig(db ethdb.KeyValueWriter, hash common.Hash, cfg *params.Cha
 	}
 }
 
+// ReadAirdropHash retrieves the airdrop hash the allocations of the genesis block
+// [hash] were verified against when it was committed. Returns false if none was
+// recorded.
+func ReadAirdropHash(db ethdb.KeyValueReader, hash common.Hash) (common.Hash, bool) {
+	data, _ := db.Get(airdropHashKey(hash))
+	if len(data) != common.HashLength {
+		return common.Hash{}, false
+	}
+	return common.BytesToHash(data), true
+}
+
+// WriteAirdropHash stores the airdrop hash the allocations of the genesis block
+// [hash] were verified against.
+func WriteAirdropHash(db ethdb.KeyValueWriter, hash common.Hash, airdropHash common.Hash) {
+	if err := db.Put(airdropHashKey(hash), airdropHash.Bytes()); err != nil {
+		log.Crit("Failed to store airdrop hash", "err", err)
+	}
+}
+
 // crashList is a list of unclean-shutdown-markers, for rlp-encoding to the
 // database
 type crashList struct {
@@ -296,3 +315,31 @@ func ReadAcceptorTip(db ethdb.KeyValueReader) (common.Hash, error) {
 	}
 	return common.BytesToHash(h), nil
 }
+
+// WriteOnlinePruningRoots writes the state roots retained by online pruning.
+func WriteOnlinePruningRoots(db ethdb.KeyValueWriter, roots []common.Hash) error {
+	data, err := rlp.EncodeToBytes(roots)
+	if err != nil {
+		return err
+	}
+	return db.Put(onlinePruningRootsKey, data)
+}
+
+// ReadOnlinePruningRoots reads the state roots retained by online pruning, from the
+// oldest to the most recently committed. If online pruning has not retained any root
+// yet, nil is returned.
+func ReadOnlinePruningRoots(db ethdb.KeyValueReader) ([]common.Hash, error) {
+	has, err := db.Has(onlinePruningRootsKey)
+	if !has || err != nil {
+		return nil, err
+	}
+	data, err := db.Get(onlinePruningRootsKey)
+	if err != nil {
+		return nil, err
+	}
+	var roots []common.Hash
+	if err := rlp.DecodeBytes(data, &roots); err != nil {
+		return nil, err
+	}
+	return roots, nil
+}
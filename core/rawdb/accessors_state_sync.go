@@ -0,0 +1,134 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package rawdb
+
+import (
+	"github.com/ava-labs/subnet-evm/ethdb"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ReadSyncRoot reads the root corresponding to the main trie of an in-progress
+// sync and returns common.Hash{} if no in-progress sync was found.
+func ReadSyncRoot(db ethdb.KeyValueReader) (common.Hash, error) {
+	has, err := db.Has(syncRootKey)
+	if err != nil || !has {
+		return common.Hash{}, err
+	}
+	root, err := db.Get(syncRootKey)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(root), nil
+}
+
+// WriteSyncRoot writes root as the root of the main trie of the in-progress sync.
+func WriteSyncRoot(db ethdb.KeyValueWriter, root common.Hash) error {
+	return db.Put(syncRootKey, root[:])
+}
+
+// DeleteSyncRoot removes the marker of the in-progress sync.
+func DeleteSyncRoot(db ethdb.KeyValueWriter) error {
+	return db.Delete(syncRootKey)
+}
+
+// NewSyncStorageTriesIterator returns a KeyLength iterator over all storage tries
+// added for syncing (beginning at seek). It is the caller's responsibility to unpack
+// the key and call Release on the returned iterator.
+func NewSyncStorageTriesIterator(db ethdb.Iteratee, seek []byte) ethdb.Iterator {
+	return NewKeyLengthIterator(db.NewIterator(syncStorageTriesPrefix, seek), syncStorageTriesKeyLength)
+}
+
+// WriteSyncStorageTrie adds a storage trie for account (with the given root) to be synced.
+func WriteSyncStorageTrie(db ethdb.KeyValueWriter, root common.Hash, account common.Hash) error {
+	return db.Put(packSyncStorageTrieKey(root, account), []byte{0x01})
+}
+
+// ClearSyncStorageTrie removes all storage trie accounts (with the given root) from db.
+// Intended for use when the trie with root has completed syncing.
+func ClearSyncStorageTrie(db ethdb.KeyValueStore, root common.Hash) error {
+	accountsPrefix := make([]byte, len(syncStorageTriesPrefix)+common.HashLength)
+	copy(accountsPrefix, syncStorageTriesPrefix)
+	copy(accountsPrefix[len(syncStorageTriesPrefix):], root[:])
+	return ClearPrefix(db, accountsPrefix)
+}
+
+// ClearAllSyncStorageTries removes all storage tries added for syncing from db
+func ClearAllSyncStorageTries(db ethdb.KeyValueStore) error {
+	return ClearPrefix(db, syncStorageTriesPrefix)
+}
+
+// UnpackSyncStorageTrieKey returns the root and account for a storage trie
+// key returned from NewSyncStorageTriesIterator.
+func UnpackSyncStorageTrieKey(keyBytes []byte) (common.Hash, common.Hash) {
+	keyBytes = keyBytes[len(syncStorageTriesPrefix):] // skip prefix
+	root := common.BytesToHash(keyBytes[:common.HashLength])
+	account := common.BytesToHash(keyBytes[common.HashLength:])
+	return root, account
+}
+
+// packSyncStorageTrieKey packs root and account into a key for storage in db.
+func packSyncStorageTrieKey(root common.Hash, account common.Hash) []byte {
+	bytes := make([]byte, 0, syncStorageTriesKeyLength)
+	bytes = append(bytes, syncStorageTriesPrefix...)
+	bytes = append(bytes, root[:]...)
+	bytes = append(bytes, account[:]...)
+	return bytes
+}
+
+// ReadSyncTrieProgress returns the serialized progress of the trie with [root]
+// that is being synced, or nil if no progress has been recorded.
+func ReadSyncTrieProgress(db ethdb.KeyValueReader, root common.Hash) []byte {
+	data, _ := db.Get(syncTrieProgressKey(root))
+	return data
+}
+
+// WriteSyncTrieProgress stores the serialized progress of the trie with [root]
+// that is being synced.
+func WriteSyncTrieProgress(db ethdb.KeyValueWriter, root common.Hash, progress []byte) {
+	if err := db.Put(syncTrieProgressKey(root), progress); err != nil {
+		log.Crit("Failed to store sync trie progress", "root", root, "err", err)
+	}
+}
+
+// DeleteSyncTrieProgress removes the progress marker of the trie with [root].
+func DeleteSyncTrieProgress(db ethdb.KeyValueWriter, root common.Hash) {
+	if err := db.Delete(syncTrieProgressKey(root)); err != nil {
+		log.Crit("Failed to delete sync trie progress", "root", root, "err", err)
+	}
+}
+
+// ClearAllSyncTrieProgress removes the progress markers of all tries being synced.
+func ClearAllSyncTrieProgress(db ethdb.KeyValueStore) error {
+	return ClearPrefix(db, syncTrieProgressPrefix)
+}
+
+// AddCodeToFetch adds a marker that we need to fetch the code for [hash].
+func AddCodeToFetch(db ethdb.KeyValueWriter, hash common.Hash) {
+	if err := db.Put(codeToFetchKey(hash), nil); err != nil {
+		log.Crit("Failed to put code to fetch", "codeHash", hash, "err", err)
+	}
+}
+
+// DeleteCodeToFetch removes the marker that the code corresponding to [hash] needs to be fetched.
+func DeleteCodeToFetch(db ethdb.KeyValueWriter, hash common.Hash) {
+	if err := db.Delete(codeToFetchKey(hash)); err != nil {
+		log.Crit("Failed to delete code to fetch", "codeHash", hash, "err", err)
+	}
+}
+
+// NewCodeToFetchIterator returns a KeyLength iterator over all code
+// hashes that are pending syncing. It is the caller's responsibility to
+// unpack the key and call Release on the returned iterator.
+func NewCodeToFetchIterator(db ethdb.Iteratee) ethdb.Iterator {
+	return NewKeyLengthIterator(
+		db.NewIterator(CodeToFetchPrefix, nil),
+		codeToFetchKeyLength,
+	)
+}
+
+// ClearAllCodeToFetch removes all markers of code hashes pending syncing.
+func ClearAllCodeToFetch(db ethdb.KeyValueStore) error {
+	return ClearPrefix(db, CodeToFetchPrefix)
+}
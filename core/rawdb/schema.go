@@ -46,6 +46,12 @@ var (
 	// headBlockKey tracks the latest known full block's hash.
 	headBlockKey = []byte("LastBlock")
 
+	// txIndexTailKey tracks the oldest block whose transactions have been indexed.
+	txIndexTailKey = []byte("TransactionIndexTail")
+
+	// feeConfigIndexTailKey tracks the oldest block from which the fee config history is indexed.
+	feeConfigIndexTailKey = []byte("FeeConfigIndexTail")
+
 	// snapshotRootKey tracks the hash of the last snapshot.
 	snapshotRootKey = []byte("SnapshotRoot")
 
@@ -71,6 +77,9 @@ var (
 	// acceptorTipKey tracks the tip of the last accepted block that has been fully processed.
 	acceptorTipKey = []byte("AcceptorTipKey")
 
+	// onlinePruningRootsKey tracks the state roots retained by online pruning.
+	onlinePruningRootsKey = []byte("OnlinePruningRoots")
+
 	// Data item prefixes (use single byte to avoid mixing data types, avoid `i`, used for indexes).
 	headerPrefix       = []byte("h") // headerPrefix + num (uint64 big endian) + hash -> header
 	headerHashSuffix   = []byte("n") // headerPrefix + num (uint64 big endian) + headerHashSuffix -> hash
@@ -80,14 +89,26 @@ var (
 	blockReceiptsPrefix = []byte("r") // blockReceiptsPrefix + num (uint64 big endian) + hash -> block receipts
 
 	txLookupPrefix        = []byte("l") // txLookupPrefix + hash -> transaction/receipt lookup metadata
+	feeConfigChangePrefix = []byte("f") // feeConfigChangePrefix + num (uint64 big endian) -> fee config change
 	bloomBitsPrefix       = []byte("B") // bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash -> bloom bits
 	SnapshotAccountPrefix = []byte("a") // SnapshotAccountPrefix + account hash -> account trie value
 	SnapshotStoragePrefix = []byte("o") // SnapshotStoragePrefix + account hash + storage hash -> storage trie value
 	CodePrefix            = []byte("c") // CodePrefix + code hash -> account code
 
+	// State sync progress keys and prefixes
+	syncRootKey            = []byte("sync_root")     // indicates the root of the main account trie currently being synced
+	syncStorageTriesPrefix = []byte("sync_storage")  // syncStorageTriesPrefix + trie root + account hash: indicates a storage trie must be fetched for the account
+	syncTrieProgressPrefix = []byte("sync_progress") // syncTrieProgressPrefix + trie root -> serialized progress of the trie being synced
+	CodeToFetchPrefix      = []byte("CP")            // CodeToFetchPrefix + code hash -> empty value tracks the outstanding code hashes we need to fetch.
+
+	// State sync progress key lengths
+	syncStorageTriesKeyLength = len(syncStorageTriesPrefix) + 2*common.HashLength
+	codeToFetchKeyLength      = len(CodeToFetchPrefix) + common.HashLength
+
 	preimagePrefix      = []byte("secure-key-")      // preimagePrefix + hash -> preimage
 	configPrefix        = []byte("ethereum-config-") // config prefix for the db
 	upgradeConfigPrefix = []byte("upgrade-config-")  // upgrade bytes passed to the chain are stored with this prefix
+	airdropHashPrefix   = []byte("airdrop-hash-")    // airdropHashPrefix + genesis hash -> airdrop hash verified when committing the genesis
 
 	// Chain index prefixes (use `i` + single byte to avoid mixing data types).
 	BloomBitsIndexPrefix = []byte("iB") // BloomBitsIndexPrefix is the data table of a chain indexer to track its progress
@@ -146,6 +167,11 @@ func txLookupKey(hash common.Hash) []byte {
 	return append(txLookupPrefix, hash.Bytes()...)
 }
 
+// feeConfigChangeKey = feeConfigChangePrefix + num (uint64 big endian)
+func feeConfigChangeKey(number uint64) []byte {
+	return append(feeConfigChangePrefix, encodeBlockNumber(number)...)
+}
+
 // accountSnapshotKey = SnapshotAccountPrefix + hash
 func accountSnapshotKey(hash common.Hash) []byte {
 	return append(SnapshotAccountPrefix, hash.Bytes()...)
@@ -190,6 +216,16 @@ func IsCodeKey(key []byte) (bool, []byte) {
 	return false, nil
 }
 
+// codeToFetchKey = CodeToFetchPrefix + hash
+func codeToFetchKey(hash common.Hash) []byte {
+	return append(CodeToFetchPrefix, hash.Bytes()...)
+}
+
+// syncTrieProgressKey = syncTrieProgressPrefix + root
+func syncTrieProgressKey(root common.Hash) []byte {
+	return append(syncTrieProgressPrefix, root.Bytes()...)
+}
+
 // configKey = configPrefix + hash
 func configKey(hash common.Hash) []byte {
 	return append(configPrefix, hash.Bytes()...)
@@ -199,3 +235,8 @@ func configKey(hash common.Hash) []byte {
 func upgradeConfigKey(hash common.Hash) []byte {
 	return append(upgradeConfigPrefix, hash.Bytes()...)
 }
+
+// airdropHashKey = airdropHashPrefix + hash
+func airdropHashKey(hash common.Hash) []byte {
+	return append(airdropHashPrefix, hash.Bytes()...)
+}
@@ -277,3 +277,95 @@ func (ch accessListAddSlotChange) revert(s *StateDB) {
 func (ch accessListAddSlotChange) dirtied() *common.Address {
 	return nil
 }
+
+// DirtyAccount holds the values of an account before the changes recorded in the
+// journal, that is before the changes made since the state was last finalised.
+type DirtyAccount struct {
+	Existed bool     // Whether the account existed before the changes
+	Balance *big.Int // Original balance, nil if the balance was not changed
+	Nonce   *uint64  // Original nonce, nil if the nonce was not changed
+	Code    []byte   // Original code, only set if CodeChanged is true
+	// CodeChanged is true if the code of the account was changed.
+	CodeChanged bool
+	// Storage holds the original values of the changed storage slots.
+	Storage map[common.Hash]common.Hash
+}
+
+// DirtyAccounts returns the accounts changed since the state was last finalised along
+// with their original values, as recorded by the journal. This includes the changes
+// made directly by stateful precompiles, which are not visible to the EVM.
+func (s *StateDB) DirtyAccounts() map[common.Address]*DirtyAccount {
+	var (
+		accounts = make(map[common.Address]*DirtyAccount)
+		resets   = make(map[common.Address]*stateObject)
+	)
+	// account returns the entry of [addr], adding it if this is its first change.
+	account := func(addr common.Address) *DirtyAccount {
+		if dirty, ok := accounts[addr]; ok {
+			return dirty
+		}
+		dirty := &DirtyAccount{Existed: true, Storage: make(map[common.Hash]common.Hash)}
+		accounts[addr] = dirty
+		return dirty
+	}
+	// The first change of each value in the journal holds its original value.
+	for _, entry := range s.journal.entries {
+		switch ch := entry.(type) {
+		case createObjectChange:
+			if _, ok := accounts[*ch.account]; !ok {
+				account(*ch.account).Existed = false
+			}
+		case resetObjectChange:
+			addr := ch.prev.address
+			if _, ok := accounts[addr]; ok {
+				if _, ok := resets[addr]; !ok {
+					resets[addr] = ch.prev
+				}
+				continue
+			}
+			dirty := account(addr)
+			if ch.prev.deleted {
+				dirty.Existed = false
+				continue
+			}
+			// The account is replaced by a new, empty object, so its previous values
+			// are lost unless they are recorded here.
+			resets[addr] = ch.prev
+			balance, nonce := new(big.Int).Set(ch.prev.Balance()), ch.prev.Nonce()
+			dirty.Balance, dirty.Nonce = balance, &nonce
+			dirty.Code, dirty.CodeChanged = common.CopyBytes(ch.prev.Code(s.db)), true
+		case suicideChange:
+			if dirty := account(*ch.account); dirty.Balance == nil {
+				dirty.Balance = new(big.Int).Set(ch.prevbalance)
+			}
+		case balanceChange:
+			if dirty := account(*ch.account); dirty.Balance == nil {
+				dirty.Balance = new(big.Int).Set(ch.prev)
+			}
+		case nonceChange:
+			if dirty := account(*ch.account); dirty.Nonce == nil {
+				nonce := ch.prev
+				dirty.Nonce = &nonce
+			}
+		case codeChange:
+			if dirty := account(*ch.account); !dirty.CodeChanged {
+				dirty.Code, dirty.CodeChanged = common.CopyBytes(ch.prevcode), true
+			}
+		case storageChange:
+			dirty := account(*ch.account)
+			if _, ok := dirty.Storage[ch.key]; ok {
+				continue
+			}
+			// The storage of a reset account starts out empty, so the original value
+			// of the slot is held by the replaced object.
+			if prev, ok := resets[*ch.account]; ok {
+				dirty.Storage[ch.key] = prev.GetState(s.db, ch.key)
+			} else {
+				dirty.Storage[ch.key] = ch.prevalue
+			}
+		case touchChange:
+			account(*ch.account)
+		}
+	}
+	return accounts
+}
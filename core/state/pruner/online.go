@@ -0,0 +1,599 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package pruner
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/ethdb"
+	"github.com/ava-labs/subnet-evm/metrics"
+	"github.com/ava-labs/subnet-evm/trie"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Phases of an online pruning cycle, as reported by [onlinePruningPhaseGauge].
+const (
+	onlinePruningIdle = iota
+	onlinePruningMarking
+	onlinePruningSweeping
+)
+
+var (
+	onlinePruningPhaseGauge         = metrics.NewRegisteredGauge("state/pruner/online/phase", nil)
+	onlinePruningProgressGauge      = metrics.NewRegisteredGauge("state/pruner/online/progress", nil) // Percentage of the database swept by the current cycle
+	onlinePruningRetainedGauge      = metrics.NewRegisteredGauge("state/pruner/online/retained", nil)
+	onlinePruningCyclesCounter      = metrics.NewRegisteredCounter("state/pruner/online/cycles", nil)
+	onlinePruningMarkedCounter      = metrics.NewRegisteredCounter("state/pruner/online/marked", nil)
+	onlinePruningSweptCounter       = metrics.NewRegisteredCounter("state/pruner/online/swept", nil)
+	onlinePruningDeletedCounter     = metrics.NewRegisteredCounter("state/pruner/online/deleted", nil)
+	onlinePruningDeletedSizeCounter = metrics.NewRegisteredCounter("state/pruner/online/deleted/size", nil)
+)
+
+// OnlinePrunerConfig configures an [OnlinePruner].
+type OnlinePrunerConfig struct {
+	Retention uint64 // Number of most recently committed state roots to retain
+	BatchSize int    // Maximum number of trie nodes and database keys processed per step
+	// Size (MB) of each bloom filter of the trie nodes to keep. Two bloom filters are in
+	// memory while pruning, and a third one briefly while a cycle starts.
+	BloomSize uint64
+}
+
+// OnlinePruner deletes the trie nodes unreachable from the most recently committed
+// state roots while the node is running, as opposed to the offline [Pruner].
+//
+// Pruning runs in cycles, which start once a new root has been retained. A cycle first
+// marks the trie nodes reachable from the genesis, the retained roots and the roots still
+// referenced in the trie database into a bloom filter, then sweeps the database for the
+// trie nodes missing from the bloom filter and deletes them. Each call to [Step] processes
+// at most [OnlinePrunerConfig.BatchSize] trie nodes or database keys, bounding the I/O of
+// pruning per accepted block.
+//
+// Trie nodes and contract code stored by the legacy scheme are keyed by the hash of their
+// value, so the sweep only considers the keys of the length of a hash holding a value of
+// that hash. Other data sharing the database is never deleted, even under such keys.
+//
+// The trie nodes written since the start of the previous cycle are added to the bloom
+// filter as well, so the nodes of the tries built while a cycle is in progress are kept.
+// This requires the trie database to write to [Database].
+//
+// Pruning is crash safe: the nodes reachable from the retained roots are never deleted
+// and the retained roots are persisted before they are committed, so a cycle interrupted
+// by a shutdown is simply restarted.
+type OnlinePruner struct {
+	db     ethdb.Database
+	config OnlinePrunerConfig
+
+	// [lock] serializes the trie nodes written through [Database] with the deletions
+	// of the sweep, and guards the fields below.
+	lock     sync.Mutex
+	triedb   *trie.Database
+	retained []common.Hash       // Retained roots, from the oldest to the most recently committed
+	live     map[common.Hash]int // Roots referenced in [triedb], with their number of references
+	pending  bool                // Whether a root was retained since the current cycle started
+	cycle    *pruningCycle       // Cycle in progress, nil if idle
+	next     *stateBloom         // Trie nodes written since the start of the current cycle
+}
+
+// pruningCycle is the state of an online pruning cycle.
+type pruningCycle struct {
+	triedb *trie.Database
+	bloom  *stateBloom   // Trie nodes to keep
+	marker *trieMarker   // Marker of the trie nodes to keep, nil once marking completed
+	pinned []common.Hash // Roots referenced in [triedb] until marking completes
+	cursor []byte        // Next database key to sweep
+
+	marked, swept, deleted int
+	size                   common.StorageSize
+	start                  time.Time
+}
+
+// NewOnlinePruner creates an online pruner of [db], retaining the roots retained by any
+// previous run.
+func NewOnlinePruner(db ethdb.Database, config OnlinePrunerConfig) (*OnlinePruner, error) {
+	if config.Retention == 0 {
+		return nil, errors.New("online pruning must retain at least one state root")
+	}
+	if config.BatchSize <= 0 {
+		return nil, errors.New("online pruning batch size must be positive")
+	}
+	roots, err := rawdb.ReadOnlinePruningRoots(db)
+	if err != nil {
+		return nil, err
+	}
+	// A root is persisted right before it is committed, so the most recent root may be
+	// missing after an unclean shutdown.
+	retained := make([]common.Hash, 0, len(roots))
+	for _, root := range roots {
+		if rawdb.HasTrieNode(db, root) {
+			retained = append(retained, root)
+		}
+	}
+	if uint64(len(retained)) > config.Retention {
+		retained = retained[uint64(len(retained))-config.Retention:]
+	}
+	next, err := newStateBloomWithSize(config.BloomSize)
+	if err != nil {
+		return nil, err
+	}
+	onlinePruningRetainedGauge.Update(int64(len(retained)))
+	onlinePruningPhaseGauge.Update(onlinePruningIdle)
+	return &OnlinePruner{
+		db:       db,
+		config:   config,
+		retained: retained,
+		live:     make(map[common.Hash]int),
+		next:     next,
+	}, nil
+}
+
+// Database returns the database the trie database must write to, which records the trie
+// nodes written so they are not deleted by the cycle in progress or the next one.
+func (p *OnlinePruner) Database() ethdb.Database {
+	return &recordingDatabase{Database: p.db, pruner: p}
+}
+
+// Attach sets the trie database whose unreachable nodes are pruned, discarding the cycle
+// in progress if any.
+func (p *OnlinePruner) Attach(triedb *trie.Database) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.triedb = triedb
+	p.live = make(map[common.Hash]int)
+	if p.cycle != nil {
+		p.discard()
+	}
+}
+
+// Retained returns the retained roots, from the oldest to the most recently committed.
+func (p *OnlinePruner) Retained() []common.Hash {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return append([]common.Hash(nil), p.retained...)
+}
+
+// Retain adds [root] to the retained roots, evicting the oldest roots beyond the
+// retention. It must be called before [root] is committed.
+func (p *OnlinePruner) Retain(root common.Hash) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if len(p.retained) > 0 && p.retained[len(p.retained)-1] == root {
+		return nil
+	}
+	retained := append(append(make([]common.Hash, 0, len(p.retained)+1), p.retained...), root)
+	if uint64(len(retained)) > p.config.Retention {
+		retained = retained[uint64(len(retained))-p.config.Retention:]
+	}
+	if err := rawdb.WriteOnlinePruningRoots(p.db, retained); err != nil {
+		return err
+	}
+	p.retained = retained
+	p.pending = true
+	onlinePruningRetainedGauge.Update(int64(len(retained)))
+	return nil
+}
+
+// Insert records a reference to [root] in the trie database.
+func (p *OnlinePruner) Insert(root common.Hash) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.live[root]++
+}
+
+// Release records the removal of a reference to [root] from the trie database. It must
+// be called before the reference is removed.
+func (p *OnlinePruner) Release(root common.Hash) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.live[root] <= 1 {
+		delete(p.live, root)
+		return
+	}
+	p.live[root]--
+}
+
+// Step performs a bounded amount of pruning work, starting a new cycle if a root was
+// retained since the last one started.
+func (p *OnlinePruner) Step() error {
+	p.lock.Lock()
+	if p.cycle == nil {
+		if !p.pending || p.triedb == nil {
+			p.lock.Unlock()
+			return nil
+		}
+		// The bloom filter of the next cycle is allocated without holding [p.lock],
+		// which would block the trie nodes being written in the meantime.
+		p.lock.Unlock()
+		next, err := newStateBloomWithSize(p.config.BloomSize)
+		if err != nil {
+			return err
+		}
+		p.lock.Lock()
+		if p.cycle == nil {
+			if !p.pending || p.triedb == nil {
+				p.lock.Unlock()
+				return nil
+			}
+			p.startCycle(next)
+		}
+	}
+	cycle := p.cycle
+	p.lock.Unlock()
+
+	if cycle.marker != nil {
+		return p.mark(cycle)
+	}
+	return p.sweep(cycle)
+}
+
+// startCycle starts a new cycle, marking the nodes of the genesis, the retained roots
+// and the roots referenced in the trie database. The referenced roots are pinned in the
+// trie database until marking completes. [next] records the trie nodes written from now
+// on, to be kept by the next cycle.
+// Assumes [p.lock] is held.
+func (p *OnlinePruner) startCycle(next *stateBloom) {
+	var (
+		roots  []common.Hash
+		seen   = make(map[common.Hash]struct{})
+		pinned []common.Hash
+	)
+	addRoot := func(root common.Hash) bool {
+		if _, ok := seen[root]; ok {
+			return false
+		}
+		seen[root] = struct{}{}
+		roots = append(roots, root)
+		return true
+	}
+	if hash := rawdb.ReadCanonicalHash(p.db, 0); hash != (common.Hash{}) {
+		if genesis := rawdb.ReadHeader(p.db, hash, 0); genesis != nil {
+			addRoot(genesis.Root)
+		}
+	}
+	for _, root := range p.retained {
+		addRoot(root)
+	}
+	for root := range p.live {
+		if addRoot(root) {
+			p.triedb.Reference(root, common.Hash{})
+			pinned = append(pinned, root)
+		}
+	}
+	p.cycle = &pruningCycle{
+		triedb: p.triedb,
+		bloom:  p.next,
+		marker: &trieMarker{triedb: p.triedb, roots: roots},
+		pinned: pinned,
+		start:  time.Now(),
+	}
+	p.next = next
+	p.pending = false
+	onlinePruningPhaseGauge.Update(onlinePruningMarking)
+	onlinePruningProgressGauge.Update(0)
+	log.Info("Started online pruning cycle", "roots", len(roots), "retained", len(p.retained))
+}
+
+// discard discards the cycle in progress. The trie nodes it was keeping are kept by the
+// next cycle, since they include the nodes written since the start of the previous one.
+// Assumes [p.lock] is held.
+func (p *OnlinePruner) discard() {
+	p.cycle.unpin()
+	p.next = p.cycle.bloom
+	p.cycle = nil
+	onlinePruningPhaseGauge.Update(onlinePruningIdle)
+}
+
+// mark marks a batch of the trie nodes to keep.
+func (p *OnlinePruner) mark(cycle *pruningCycle) error {
+	hashes, done, err := cycle.marker.mark(p.config.BatchSize)
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.cycle != cycle {
+		return nil
+	}
+	if err != nil {
+		// Sweeping without all the nodes to keep would corrupt the state, so the cycle
+		// is dropped and restarted once the next root is retained.
+		log.Error("Aborting online pruning cycle, failed to mark trie nodes", "err", err)
+		p.discard()
+		return nil
+	}
+	for _, hash := range hashes {
+		cycle.bloom.add(hash.Bytes())
+	}
+	cycle.marked += len(hashes)
+	onlinePruningMarkedCounter.Inc(int64(len(hashes)))
+	if done {
+		cycle.unpin()
+		cycle.marker = nil
+		onlinePruningPhaseGauge.Update(onlinePruningSweeping)
+		log.Info("Marked trie nodes to keep", "nodes", cycle.marked, "elapsed", common.PrettyDuration(time.Since(cycle.start)))
+	}
+	return nil
+}
+
+// sweep deletes the trie nodes not kept by [cycle] from a batch of database keys, ending
+// the cycle once the whole database has been swept.
+func (p *OnlinePruner) sweep(cycle *pruningCycle) error {
+	// The database is iterated without holding [p.lock], which would block the trie
+	// nodes being written in the meantime.
+	var (
+		iter  = p.db.NewIterator(nil, cycle.cursor)
+		keys  [][]byte
+		sizes []common.StorageSize
+		swept int
+		done  = true
+	)
+	for iter.Next() {
+		if swept >= p.config.BatchSize {
+			done = false
+			break
+		}
+		swept++
+		key := iter.Key()
+		cycle.cursor = append(common.CopyBytes(key), 0)
+		if len(key) != common.HashLength || crypto.Keccak256Hash(iter.Value()) != common.BytesToHash(key) {
+			continue
+		}
+		keys = append(keys, common.CopyBytes(key))
+		sizes = append(sizes, common.StorageSize(len(key)+len(iter.Value())))
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return err
+	}
+
+	// The bloom filter is checked and the deletions are written while holding [p.lock].
+	// A trie node written since it was iterated has been added to the bloom filter by
+	// then, so it is not deleted.
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.cycle != cycle {
+		return nil
+	}
+	var (
+		batch = p.db.NewBatch()
+		size  common.StorageSize
+		count int
+	)
+	for i, key := range keys {
+		if ok, _ := cycle.bloom.Contain(key); ok {
+			continue
+		}
+		if err := batch.Delete(key); err != nil {
+			return err
+		}
+		count++
+		size += sizes[i]
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	cycle.swept += swept
+	cycle.deleted += count
+	cycle.size += size
+	onlinePruningSweptCounter.Inc(int64(swept))
+	onlinePruningDeletedCounter.Inc(int64(count))
+	onlinePruningDeletedSizeCounter.Inc(int64(size))
+	if len(cycle.cursor) >= 2 {
+		onlinePruningProgressGauge.Update(int64(binary.BigEndian.Uint16(cycle.cursor)) * 100 / (1 << 16))
+	}
+	if !done {
+		return nil
+	}
+
+	p.cycle = nil
+	onlinePruningCyclesCounter.Inc(1)
+	onlinePruningProgressGauge.Update(100)
+	onlinePruningPhaseGauge.Update(onlinePruningIdle)
+	log.Info("Finished online pruning cycle", "marked", cycle.marked, "swept", cycle.swept, "deleted", cycle.deleted,
+		"size", cycle.size, "elapsed", common.PrettyDuration(time.Since(cycle.start)))
+	return nil
+}
+
+// record adds the trie node [key] being written to the trie nodes to keep.
+// Assumes [p.lock] is held.
+func (p *OnlinePruner) record(key []byte) {
+	if p.cycle != nil {
+		p.cycle.bloom.add(key)
+	}
+	p.next.add(key)
+}
+
+// unpin removes the references to the roots pinned by [c] from the trie database.
+func (c *pruningCycle) unpin() {
+	for _, root := range c.pinned {
+		c.triedb.Dereference(root)
+	}
+	c.pinned = nil
+}
+
+// add adds the trie node [key] to [bloom].
+func (bloom *stateBloom) add(key []byte) {
+	bloom.bloom.Add(stateBloomHasher(key))
+}
+
+// trieMarker iterates the trie nodes reachable from a list of state roots, a bounded
+// number of nodes at a time. Each root is iterated as a difference from the previous
+// one, so the subtries shared by consecutive roots are only iterated once.
+type trieMarker struct {
+	triedb *trie.Database
+	roots  []common.Hash
+	index  int // Index of the root being iterated
+
+	prev        common.Hash       // Previously iterated root, empty for the first root
+	accountIter trie.NodeIterator // Iterator of the account trie of the root
+	storageIter trie.NodeIterator // Iterator of the storage trie of the current account
+}
+
+// mark iterates up to [limit] trie nodes, returning the hashes of the nodes not embedded
+// in their parent and of the code of the accounts iterated, and whether all the roots
+// have been iterated.
+func (m *trieMarker) mark(limit int) ([]common.Hash, bool, error) {
+	var hashes []common.Hash
+	for step := 0; step < limit; step++ {
+		switch {
+		case m.storageIter != nil:
+			if !m.storageIter.Next(true) {
+				if err := m.storageIter.Error(); err != nil {
+					return nil, false, err
+				}
+				m.storageIter = nil
+				continue
+			}
+			if hash := m.storageIter.Hash(); hash != (common.Hash{}) {
+				hashes = append(hashes, hash)
+			}
+		case m.accountIter != nil:
+			if !m.accountIter.Next(true) {
+				if err := m.accountIter.Error(); err != nil {
+					return nil, false, err
+				}
+				m.prev = m.roots[m.index]
+				m.accountIter = nil
+				m.index++
+				continue
+			}
+			if hash := m.accountIter.Hash(); hash != (common.Hash{}) {
+				hashes = append(hashes, hash)
+			}
+			if m.accountIter.Leaf() {
+				var account types.StateAccount
+				if err := rlp.DecodeBytes(m.accountIter.LeafBlob(), &account); err != nil {
+					return nil, false, err
+				}
+				// The sweep also deletes the contract code stored by the legacy scheme,
+				// which is keyed by its hash like trie nodes, so the code is kept too.
+				if !bytes.Equal(account.CodeHash, emptyCode) {
+					hashes = append(hashes, common.BytesToHash(account.CodeHash))
+				}
+				if err := m.openStorage(m.accountIter.LeafKey(), &account); err != nil {
+					return nil, false, err
+				}
+			}
+		case m.index < len(m.roots):
+			if err := m.openAccounts(); err != nil {
+				return nil, false, err
+			}
+		default:
+			return hashes, true, nil
+		}
+	}
+	return hashes, false, nil
+}
+
+// openAccounts opens the iterator of the account trie of the next root.
+func (m *trieMarker) openAccounts() error {
+	t, err := trie.New(m.roots[m.index], m.triedb)
+	if err != nil {
+		return err
+	}
+	m.accountIter = t.NodeIterator(nil)
+	if m.prev == (common.Hash{}) || m.prev == emptyRoot {
+		return nil
+	}
+	prev, err := trie.New(m.prev, m.triedb)
+	if err != nil {
+		return err
+	}
+	m.accountIter, _ = trie.NewDifferenceIterator(prev.NodeIterator(nil), m.accountIter)
+	return nil
+}
+
+// openStorage opens the iterator of the storage trie of the account [key], skipping the
+// nodes of the storage trie of the account in the previous root.
+func (m *trieMarker) openStorage(key []byte, account *types.StateAccount) error {
+	if account.Root == emptyRoot {
+		return nil
+	}
+	var prevRoot common.Hash
+	if m.prev != (common.Hash{}) && m.prev != emptyRoot {
+		prev, err := trie.New(m.prev, m.triedb)
+		if err != nil {
+			return err
+		}
+		prevBlob, err := prev.TryGet(key)
+		if err != nil {
+			return err
+		}
+		if len(prevBlob) > 0 {
+			var prevAccount types.StateAccount
+			if err := rlp.DecodeBytes(prevBlob, &prevAccount); err != nil {
+				return err
+			}
+			prevRoot = prevAccount.Root
+		}
+	}
+	if prevRoot == account.Root {
+		return nil
+	}
+	t, err := trie.New(account.Root, m.triedb)
+	if err != nil {
+		return err
+	}
+	m.storageIter = t.NodeIterator(nil)
+	if prevRoot == (common.Hash{}) || prevRoot == emptyRoot {
+		return nil
+	}
+	prev, err := trie.New(prevRoot, m.triedb)
+	if err != nil {
+		return err
+	}
+	m.storageIter, _ = trie.NewDifferenceIterator(prev.NodeIterator(nil), m.storageIter)
+	return nil
+}
+
+// recordingDatabase records the trie nodes written to [ethdb.Database] by the trie
+// database into [pruner].
+type recordingDatabase struct {
+	ethdb.Database
+	pruner *OnlinePruner
+}
+
+func (db *recordingDatabase) Put(key []byte, value []byte) error {
+	if len(key) != common.HashLength {
+		return db.Database.Put(key, value)
+	}
+	db.pruner.lock.Lock()
+	defer db.pruner.lock.Unlock()
+
+	db.pruner.record(key)
+	return db.Database.Put(key, value)
+}
+
+func (db *recordingDatabase) NewBatch() ethdb.Batch {
+	return &recordingBatch{Batch: db.Database.NewBatch(), pruner: db.pruner}
+}
+
+// recordingBatch records the trie nodes written to [ethdb.Batch] into [pruner] when they
+// are added to the batch, which is before they are written.
+type recordingBatch struct {
+	ethdb.Batch
+	pruner *OnlinePruner
+}
+
+func (b *recordingBatch) Put(key []byte, value []byte) error {
+	if len(key) != common.HashLength {
+		return b.Batch.Put(key, value)
+	}
+	b.pruner.lock.Lock()
+	defer b.pruner.lock.Unlock()
+
+	b.pruner.record(key)
+	return b.Batch.Put(key, value)
+}
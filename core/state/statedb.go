@@ -207,12 +207,21 @@ func (s *StateDB) Error() error {
 	return s.dbErr
 }
 
-func (s *StateDB) AddLog(log *types.Log) {
+// AddLog adds a log with the specified parameters to the statedb
+// Note: blockNumber is a required argument because StateDB does not
+// know the current block number.
+func (s *StateDB) AddLog(addr common.Address, topics []common.Hash, data []byte, blockNumber uint64) {
 	s.journal.append(addLogChange{txhash: s.thash})
 
-	log.TxHash = s.thash
-	log.TxIndex = uint(s.txIndex)
-	log.Index = s.logSize
+	log := &types.Log{
+		Address:     addr,
+		Topics:      topics,
+		Data:        data,
+		BlockNumber: blockNumber,
+		TxHash:      s.thash,
+		TxIndex:     uint(s.txIndex),
+		Index:       s.logSize,
+	}
 	s.logs[s.thash] = append(s.logs[s.thash], log)
 	s.logSize++
 }
@@ -837,6 +846,32 @@ func (s *StateDB) Finalise(deleteEmptyObjects bool) {
 	s.clearJournalAndRefund()
 }
 
+// PendingAccounts returns the accounts finalised since the state was last committed,
+// along with the keys of their storage slots changed since the intermediate root was
+// last computed. Replaying transactions without computing the intermediate root, the
+// keys are those of all the slots changed by the transactions.
+func (s *StateDB) PendingAccounts() map[common.Address][]common.Hash {
+	accounts := make(map[common.Address][]common.Hash, len(s.stateObjectsDirty))
+	for addr := range s.stateObjectsDirty {
+		var keys []common.Hash
+		if obj, exist := s.stateObjects[addr]; exist {
+			for key := range obj.pendingStorage {
+				keys = append(keys, key)
+			}
+			// The dirty slots of a deleted object are not moved to its pending slots.
+			if obj.deleted {
+				for key := range obj.dirtyStorage {
+					if _, ok := obj.pendingStorage[key]; !ok {
+						keys = append(keys, key)
+					}
+				}
+			}
+		}
+		accounts[addr] = keys
+	}
+	return accounts
+}
+
 // IntermediateRoot computes the current root hash of the state trie.
 // It is called in between transactions to get the root hash that
 // goes into transaction receipts.
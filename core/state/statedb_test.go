@@ -34,13 +34,13 @@ import (
 	"math/big"
 	"math/rand"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"testing"
 	"testing/quick"
 
 	"github.com/ava-labs/subnet-evm/core/rawdb"
-	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -325,7 +325,7 @@ func newTestAction(addr common.Address, r *rand.Rand) testAction {
 			fn: func(a testAction, s *StateDB) {
 				data := make([]byte, 2)
 				binary.BigEndian.PutUint16(data, uint16(a.args[0]))
-				s.AddLog(&types.Log{Address: addr, Data: data})
+				s.AddLog(addr, nil, data, 0)
 			},
 			args: make([]int64, 1),
 		},
@@ -924,3 +924,97 @@ func TestStateDBAccessList(t *testing.T) {
 		t.Fatalf("expected empty, got %d", got)
 	}
 }
+
+// Tests that the dirty accounts report the values from before the changes made since
+// the state was last finalised, excluding reverted changes.
+func TestDirtyAccounts(t *testing.T) {
+	state, _ := New(common.Hash{}, NewDatabase(rawdb.NewMemoryDatabase()), nil)
+
+	var (
+		existing  = common.BytesToAddress([]byte("existing"))
+		created   = common.BytesToAddress([]byte("created"))
+		destroyed = common.BytesToAddress([]byte("destroyed"))
+		reverted  = common.BytesToAddress([]byte("reverted"))
+		key       = common.BytesToHash([]byte("key"))
+	)
+	state.SetBalance(existing, big.NewInt(1))
+	state.SetNonce(existing, 2)
+	state.SetCode(existing, []byte{0x01})
+	state.SetState(existing, key, common.BytesToHash([]byte{0x03}))
+	state.SetBalance(destroyed, big.NewInt(4))
+	state.SetBalance(reverted, big.NewInt(5))
+	state.Finalise(true)
+	if dirty := state.DirtyAccounts(); len(dirty) != 0 {
+		t.Fatalf("expected no dirty accounts after finalising the state, got %d", len(dirty))
+	}
+
+	state.AddBalance(existing, big.NewInt(10))
+	state.AddBalance(existing, big.NewInt(10))
+	state.SetNonce(existing, 3)
+	state.SetCode(existing, []byte{0x02})
+	state.SetState(existing, key, common.BytesToHash([]byte{0x04}))
+	state.AddBalance(created, big.NewInt(6))
+	state.Suicide(destroyed)
+	id := state.Snapshot()
+	state.SetBalance(reverted, big.NewInt(7))
+	state.RevertToSnapshot(id)
+
+	nonce := uint64(2)
+	want := map[common.Address]*DirtyAccount{
+		existing: {
+			Existed:     true,
+			Balance:     big.NewInt(1),
+			Nonce:       &nonce,
+			Code:        []byte{0x01},
+			CodeChanged: true,
+			Storage:     map[common.Hash]common.Hash{key: common.BytesToHash([]byte{0x03})},
+		},
+		created: {
+			Existed: false,
+			Balance: big.NewInt(0),
+			Storage: map[common.Hash]common.Hash{},
+		},
+		destroyed: {
+			Existed: true,
+			Balance: big.NewInt(4),
+			Storage: map[common.Hash]common.Hash{},
+		},
+	}
+	if have := state.DirtyAccounts(); !reflect.DeepEqual(have, want) {
+		t.Fatalf("dirty accounts mismatch: have %v, want %v", have, want)
+	}
+}
+
+func TestPendingAccounts(t *testing.T) {
+	state, _ := New(common.Hash{}, NewDatabase(rawdb.NewMemoryDatabase()), nil)
+
+	var (
+		addr1 = common.BytesToAddress([]byte("addr1"))
+		addr2 = common.BytesToAddress([]byte("addr2"))
+		key1  = common.BytesToHash([]byte("key1"))
+		key2  = common.BytesToHash([]byte("key2"))
+	)
+	state.SetBalance(addr1, big.NewInt(1))
+	state.SetState(addr1, key1, common.BytesToHash([]byte{0x01}))
+	state.Finalise(true)
+	state.SetState(addr1, key2, common.BytesToHash([]byte{0x02}))
+	state.SetBalance(addr2, big.NewInt(2))
+	if pending := state.PendingAccounts(); len(pending) != 1 || len(pending[addr1]) != 1 {
+		t.Fatalf("expected the changes of the first transaction to be pending, got %v", pending)
+	}
+	state.Finalise(true)
+
+	pending := state.PendingAccounts()
+	sort.Slice(pending[addr1], func(i, j int) bool { return bytes.Compare(pending[addr1][i][:], pending[addr1][j][:]) < 0 })
+	want := map[common.Address][]common.Hash{
+		addr1: {key1, key2},
+		addr2: nil,
+	}
+	if !reflect.DeepEqual(pending, want) {
+		t.Fatalf("pending accounts mismatch: have %v, want %v", pending, want)
+	}
+	state.IntermediateRoot(true)
+	if pending := state.PendingAccounts(); len(pending[addr1]) != 0 {
+		t.Fatalf("expected no pending storage slots after computing the intermediate root, got %v", pending[addr1])
+	}
+}
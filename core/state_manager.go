@@ -31,8 +31,10 @@ import (
 	"math/rand"
 	"time"
 
+	"github.com/ava-labs/subnet-evm/core/state/pruner"
 	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/ethdb"
+	"github.com/ava-labs/subnet-evm/trie"
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -74,16 +76,7 @@ type TrieDB interface {
 
 func NewTrieWriter(db TrieDB, config *CacheConfig) TrieWriter {
 	if config.Pruning {
-		cm := &cappedMemoryTrieWriter{
-			TrieDB:           db,
-			memoryCap:        common.StorageSize(config.TrieDirtyLimit) * 1024 * 1024,
-			targetCommitSize: common.StorageSize(config.TrieDirtyCommitTarget) * 1024 * 1024,
-			imageCap:         4 * 1024 * 1024,
-			commitInterval:   config.CommitInterval,
-			tipBuffer:        NewBoundedBuffer(tipBufferSize, db.Dereference),
-		}
-		cm.flushStepSize = (cm.memoryCap - cm.targetCommitSize) / common.StorageSize(flushWindow)
-		return cm
+		return newCappedMemoryTrieWriter(db, config, db.Dereference)
 	} else {
 		return &noPruningTrieWriter{
 			TrieDB: db,
@@ -91,6 +84,35 @@ func NewTrieWriter(db TrieDB, config *CacheConfig) TrieWriter {
 	}
 }
 
+// NewOnlinePruningTrieWriter returns a TrieWriter behaving as in [pruning] mode, which
+// additionally deletes the trie nodes unreachable from the roots retained by [pruner]
+// after each accepted block. [db] must write to [pruner.Database].
+func NewOnlinePruningTrieWriter(db *trie.Database, pruner *pruner.OnlinePruner, config *CacheConfig) TrieWriter {
+	pruner.Attach(db)
+	return &onlinePruningTrieWriter{
+		cappedMemoryTrieWriter: newCappedMemoryTrieWriter(db, config, func(root common.Hash) {
+			pruner.Release(root)
+			db.Dereference(root)
+		}),
+		pruner: pruner,
+	}
+}
+
+// newCappedMemoryTrieWriter returns a cappedMemoryTrieWriter calling [dereference] on the
+// roots evicted from its tip buffer.
+func newCappedMemoryTrieWriter(db TrieDB, config *CacheConfig, dereference func(common.Hash)) *cappedMemoryTrieWriter {
+	cm := &cappedMemoryTrieWriter{
+		TrieDB:           db,
+		memoryCap:        common.StorageSize(config.TrieDirtyLimit) * 1024 * 1024,
+		targetCommitSize: common.StorageSize(config.TrieDirtyCommitTarget) * 1024 * 1024,
+		imageCap:         4 * 1024 * 1024,
+		commitInterval:   config.CommitInterval,
+		tipBuffer:        NewBoundedBuffer(tipBufferSize, dereference),
+	}
+	cm.flushStepSize = (cm.memoryCap - cm.targetCommitSize) / common.StorageSize(flushWindow)
+	return cm
+}
+
 type noPruningTrieWriter struct {
 	TrieDB
 }
@@ -205,3 +227,38 @@ func (cm *cappedMemoryTrieWriter) Shutdown() error {
 	// re-processing the state on the next startup.
 	return cm.TrieDB.Commit(last, true, nil)
 }
+
+type onlinePruningTrieWriter struct {
+	*cappedMemoryTrieWriter
+	pruner *pruner.OnlinePruner
+}
+
+func (op *onlinePruningTrieWriter) InsertTrie(block *types.Block) error {
+	if err := op.cappedMemoryTrieWriter.InsertTrie(block); err != nil {
+		return err
+	}
+	op.pruner.Insert(block.Root())
+	return nil
+}
+
+func (op *onlinePruningTrieWriter) AcceptTrie(block *types.Block) error {
+	// Retain the root before it is committed, so it is never pruned even if the node
+	// stops in between.
+	if block.NumberU64()%op.commitInterval == 0 {
+		if err := op.pruner.Retain(block.Root()); err != nil {
+			return fmt.Errorf("failed to retain trie for block %s: %w", block.Hash().Hex(), err)
+		}
+	}
+	if err := op.cappedMemoryTrieWriter.AcceptTrie(block); err != nil {
+		return err
+	}
+	if err := op.pruner.Step(); err != nil {
+		return fmt.Errorf("failed to prune tries after block %s: %w", block.Hash().Hex(), err)
+	}
+	return nil
+}
+
+func (op *onlinePruningTrieWriter) RejectTrie(block *types.Block) error {
+	op.pruner.Release(block.Root())
+	return op.cappedMemoryTrieWriter.RejectTrie(block)
+}
@@ -27,11 +27,15 @@
 package core
 
 import (
+	"crypto/ecdsa"
+	"errors"
 	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/ava-labs/subnet-evm/consensus"
 	"github.com/ava-labs/subnet-evm/consensus/dummy"
+	"github.com/ava-labs/subnet-evm/constants"
 	"github.com/ava-labs/subnet-evm/core/rawdb"
 	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/core/vm"
@@ -63,7 +67,9 @@ func TestStateProcessorErrors(t *testing.T) {
 			IstanbulBlock:       big.NewInt(0),
 			MuirGlacierBlock:    big.NewInt(0),
 			NetworkUpgrades: params.NetworkUpgrades{
-				SubnetEVMTimestamp: big.NewInt(0),
+				SubnetEVMTimestamp:      big.NewInt(0),
+				PrecompileLogsTimestamp: big.NewInt(0),
+				DurangoTimestamp:        big.NewInt(0),
 			},
 		}
 		signer     = types.LatestSigner(config)
@@ -84,6 +90,17 @@ func TestStateProcessorErrors(t *testing.T) {
 		}), signer, testKey)
 		return tx
 	}
+	mkContractCreationTx := func(nonce uint64, gasLimit uint64, gasTipCap, gasFeeCap *big.Int, data []byte) *types.Transaction {
+		tx, _ := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+			Nonce:     nonce,
+			GasTipCap: gasTipCap,
+			GasFeeCap: gasFeeCap,
+			Gas:       gasLimit,
+			Value:     big.NewInt(0),
+			Data:      data,
+		}), signer, testKey)
+		return tx
+	}
 	config.FeeConfig.MinBaseFee = params.TestMaxBaseFee
 	{ // Tests against a 'recent' chain definition
 		var (
@@ -197,6 +214,12 @@ func TestStateProcessorErrors(t *testing.T) {
 				},
 				want: "could not apply tx 0 [0xd82a0c2519acfeac9a948258c47e784acd20651d9d80f9a1c67b4137651c3a24]: insufficient funds for gas * price + value: address 0x71562b71999873DB5b286dF957af199Ec94617F7 have 2000000000000000000 want 2431633873983640103894990685182446064918669677978451844828609264166175722438635000",
 			},
+			{ // ErrMaxInitCodeSizeExceeded
+				txs: []*types.Transaction{
+					mkContractCreationTx(0, 500000, common.Big0, params.TestMaxBaseFee, make([]byte, params.MaxInitCodeSize+1)),
+				},
+				want: "could not apply tx 0 [0x18a05f40f29ff16d5287f6f88b21c9f3c7fbc268f707251144996294552c4cd6]: max initcode size exceeded: code size 49153 limit 49152",
+			},
 		} {
 			block := GenerateBadBlock(genesis, dummy.NewFaker(), tt.txs, gspec.Config)
 			_, err := blockchain.InsertChain(types.Blocks{block})
@@ -331,8 +354,8 @@ func TestBadTxAllowListBlock(t *testing.T) {
 			NetworkUpgrades: params.NetworkUpgrades{
 				SubnetEVMTimestamp: big.NewInt(0),
 			},
-			PrecompileUpgrade: params.PrecompileUpgrade{
-				TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(0), []common.Address{}),
+			GenesisPrecompiles: params.Precompiles{
+				precompile.TxAllowListConfigKey: precompile.NewTxAllowListConfig(big.NewInt(0), []common.Address{}),
 			},
 		}
 		signer     = types.LatestSigner(config)
@@ -388,6 +411,298 @@ func TestBadTxAllowListBlock(t *testing.T) {
 	}
 }
 
+func TestRewardManagerCoinbase(t *testing.T) {
+	var (
+		testAddr   = common.HexToAddress("0x71562b71999873DB5b286dF957af199Ec94617F7")
+		rewardAddr = common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567")
+		otherAddr  = common.HexToAddress("0x0987654321000000000000000000000000000000")
+
+		config = &params.ChainConfig{
+			ChainID:             big.NewInt(1),
+			FeeConfig:           params.DefaultFeeConfig,
+			HomesteadBlock:      big.NewInt(0),
+			EIP150Block:         big.NewInt(0),
+			EIP150Hash:          common.Hash{},
+			EIP155Block:         big.NewInt(0),
+			EIP158Block:         big.NewInt(0),
+			ByzantiumBlock:      big.NewInt(0),
+			ConstantinopleBlock: big.NewInt(0),
+			PetersburgBlock:     big.NewInt(0),
+			IstanbulBlock:       big.NewInt(0),
+			MuirGlacierBlock:    big.NewInt(0),
+			NetworkUpgrades: params.NetworkUpgrades{
+				SubnetEVMTimestamp: big.NewInt(0),
+			},
+			GenesisPrecompiles: params.Precompiles{
+				precompile.RewardManagerConfigKey: precompile.NewRewardManagerConfig(big.NewInt(0), nil, &precompile.InitialRewardConfig{RewardAddress: rewardAddr}),
+			},
+		}
+		signer     = types.LatestSigner(config)
+		testKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+
+		gspec = &Genesis{
+			Config: config,
+			Alloc: GenesisAlloc{
+				testAddr: GenesisAccount{
+					Balance: big.NewInt(1000000000000000000), // 1 ether
+					Nonce:   0,
+				},
+			},
+			GasLimit: params.TestChainConfig.FeeConfig.GasLimit.Uint64(),
+		}
+	)
+
+	for name, test := range map[string]struct {
+		coinbase    common.Address
+		expectedErr string
+	}{
+		"configured reward address": {coinbase: rewardAddr},
+		"other coinbase":            {coinbase: otherAddr, expectedErr: "invalid coinbase"},
+		"blackhole coinbase":        {coinbase: constants.BlackholeAddr, expectedErr: "invalid coinbase"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			db := rawdb.NewMemoryDatabase()
+			genesis := gspec.MustCommit(db)
+			blockchain, err := NewBlockChain(db, DefaultCacheConfig, gspec.Config, dummy.NewFaker(), vm.Config{}, common.Hash{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer blockchain.Stop()
+
+			genDB := rawdb.NewMemoryDatabase()
+			gspec.MustCommit(genDB)
+			blocks, _, err := GenerateChain(gspec.Config, genesis, dummy.NewFaker(), genDB, 1, 10, func(i int, gen *BlockGen) {
+				gen.SetCoinbase(test.coinbase)
+				tx, err := types.SignTx(types.NewTransaction(gen.TxNonce(testAddr), otherAddr, big.NewInt(1), params.TxGas, big.NewInt(225000000000), nil), signer, testKey)
+				if err != nil {
+					t.Fatal(err)
+				}
+				gen.AddTx(tx)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = blockchain.InsertChain(blocks)
+			if len(test.expectedErr) != 0 {
+				if err == nil || !strings.Contains(err.Error(), test.expectedErr) {
+					t.Fatalf("expected error %q, got %v", test.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			state, err := blockchain.State()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if state.GetBalance(rewardAddr).Sign() <= 0 {
+				t.Fatal("expected reward address to receive fees")
+			}
+		})
+	}
+}
+
+func TestPauserBlocks(t *testing.T) {
+	var (
+		adminKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		adminAddr   = crypto.PubkeyToAddress(adminKey.PublicKey)
+		otherKey, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+		otherAddr   = crypto.PubkeyToAddress(otherKey.PublicKey)
+
+		config = &params.ChainConfig{
+			ChainID:             big.NewInt(1),
+			FeeConfig:           params.DefaultFeeConfig,
+			HomesteadBlock:      big.NewInt(0),
+			EIP150Block:         big.NewInt(0),
+			EIP150Hash:          common.Hash{},
+			EIP155Block:         big.NewInt(0),
+			EIP158Block:         big.NewInt(0),
+			ByzantiumBlock:      big.NewInt(0),
+			ConstantinopleBlock: big.NewInt(0),
+			PetersburgBlock:     big.NewInt(0),
+			IstanbulBlock:       big.NewInt(0),
+			MuirGlacierBlock:    big.NewInt(0),
+			NetworkUpgrades: params.NetworkUpgrades{
+				SubnetEVMTimestamp: big.NewInt(0),
+			},
+			GenesisPrecompiles: params.Precompiles{
+				precompile.PauserConfigKey: precompile.NewPauserConfig(big.NewInt(0), []common.Address{adminAddr}),
+			},
+		}
+		signer = types.LatestSigner(config)
+
+		gspec = &Genesis{
+			Config: config,
+			Alloc: GenesisAlloc{
+				adminAddr: GenesisAccount{Balance: big.NewInt(1000000000000000000)}, // 1 ether
+				otherAddr: GenesisAccount{Balance: big.NewInt(1000000000000000000)}, // 1 ether
+			},
+			GasLimit: params.TestChainConfig.FeeConfig.GasLimit.Uint64(),
+		}
+		db            = rawdb.NewMemoryDatabase()
+		genesis       = gspec.MustCommit(db)
+		blockchain, _ = NewBlockChain(db, DefaultCacheConfig, gspec.Config, dummy.NewFaker(), vm.Config{}, common.Hash{})
+	)
+	defer blockchain.Stop()
+
+	signTx := func(nonce uint64, to common.Address, data []byte, key *ecdsa.PrivateKey) *types.Transaction {
+		tx, err := types.SignTx(types.NewTransaction(nonce, to, big.NewInt(0), 100_000, big.NewInt(225000000000), data), signer, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tx
+	}
+
+	// The admin pauses the chain in the first block, then unpauses it in the second block,
+	// after which other senders may issue transactions again.
+	genDB := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(genDB)
+	blocks, _, err := GenerateChain(gspec.Config, genesis, dummy.NewFaker(), genDB, 2, 10, func(i int, gen *BlockGen) {
+		switch i {
+		case 0:
+			gen.AddTx(signTx(gen.TxNonce(adminAddr), precompile.PauserAddress, precompile.PackPause(), adminKey))
+		case 1:
+			gen.AddTx(signTx(gen.TxNonce(adminAddr), precompile.PauserAddress, precompile.PackUnpause(), adminKey))
+			gen.AddTx(signTx(gen.TxNonce(otherAddr), adminAddr, nil, otherKey))
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := blockchain.InsertChain(blocks[:1]); err != nil {
+		t.Fatal(err)
+	}
+	state, err := blockchain.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !precompile.IsPaused(state) {
+		t.Fatal("expected chain to be paused")
+	}
+
+	// A block with a transaction from a non-exempt sender is rejected while the chain is paused.
+	badBlock := GenerateBadBlock(blocks[0], dummy.NewFaker(), types.Transactions{signTx(0, adminAddr, nil, otherKey)}, gspec.Config)
+	if _, err := blockchain.InsertChain(types.Blocks{badBlock}); !errors.Is(err, precompile.ErrChainPaused) {
+		t.Fatalf("expected error %q, got %v", precompile.ErrChainPaused, err)
+	}
+
+	if _, err := blockchain.InsertChain(blocks[1:]); err != nil {
+		t.Fatal(err)
+	}
+	state, err = blockchain.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if precompile.IsPaused(state) {
+		t.Fatal("expected chain to be unpaused")
+	}
+}
+
+func TestGasSponsorBlocks(t *testing.T) {
+	var (
+		sponsorKey, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		sponsorAddr     = crypto.PubkeyToAddress(sponsorKey.PublicKey)
+		sponsoredKey, _ = crypto.HexToECDSA("8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a")
+		sponsoredAddr   = crypto.PubkeyToAddress(sponsoredKey.PublicKey)
+		gasPrice        = big.NewInt(225000000000)
+		gasLimit        = uint64(200_000)
+		deposit         = big.NewInt(1000000000000000000) // 1 ether
+		// The spending limit only covers the gas limit of a single transaction.
+		spendingLimit = new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gasLimit))
+
+		config = &params.ChainConfig{
+			ChainID:             big.NewInt(1),
+			FeeConfig:           params.DefaultFeeConfig,
+			HomesteadBlock:      big.NewInt(0),
+			EIP150Block:         big.NewInt(0),
+			EIP150Hash:          common.Hash{},
+			EIP155Block:         big.NewInt(0),
+			EIP158Block:         big.NewInt(0),
+			ByzantiumBlock:      big.NewInt(0),
+			ConstantinopleBlock: big.NewInt(0),
+			PetersburgBlock:     big.NewInt(0),
+			IstanbulBlock:       big.NewInt(0),
+			MuirGlacierBlock:    big.NewInt(0),
+			NetworkUpgrades: params.NetworkUpgrades{
+				SubnetEVMTimestamp: big.NewInt(0),
+			},
+			GenesisPrecompiles: params.Precompiles{
+				precompile.GasSponsorConfigKey: precompile.NewGasSponsorConfig(big.NewInt(0), []common.Address{sponsorAddr}),
+			},
+		}
+		signer = types.LatestSigner(config)
+
+		gspec = &Genesis{
+			Config: config,
+			Alloc: GenesisAlloc{
+				sponsorAddr: GenesisAccount{Balance: new(big.Int).Mul(deposit, big.NewInt(2))},
+			},
+			GasLimit: params.TestChainConfig.FeeConfig.GasLimit.Uint64(),
+		}
+		db            = rawdb.NewMemoryDatabase()
+		genesis       = gspec.MustCommit(db)
+		blockchain, _ = NewBlockChain(db, DefaultCacheConfig, gspec.Config, dummy.NewFaker(), vm.Config{}, common.Hash{})
+	)
+	defer blockchain.Stop()
+
+	signTx := func(nonce uint64, to common.Address, data []byte, key *ecdsa.PrivateKey) *types.Transaction {
+		tx, err := types.SignTx(types.NewTransaction(nonce, to, big.NewInt(0), gasLimit, gasPrice, data), signer, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tx
+	}
+
+	// The sponsor deposits and sponsors the sender in the first block, so that the sender
+	// can issue a transaction without any balance in the second block.
+	genDB := rawdb.NewMemoryDatabase()
+	gspec.MustCommit(genDB)
+	blocks, _, err := GenerateChain(gspec.Config, genesis, dummy.NewFaker(), genDB, 2, 10, func(i int, gen *BlockGen) {
+		switch i {
+		case 0:
+			gen.AddTx(signTx(gen.TxNonce(sponsorAddr), precompile.GasSponsorAddress, precompile.PackSponsorDeposit(deposit), sponsorKey))
+			gen.AddTx(signTx(gen.TxNonce(sponsorAddr), precompile.GasSponsorAddress, precompile.PackSetSponsoredSender(sponsoredAddr, true), sponsorKey))
+			gen.AddTx(signTx(gen.TxNonce(sponsorAddr), precompile.GasSponsorAddress, precompile.PackSetSpendingLimit(sponsoredAddr, spendingLimit), sponsorKey))
+		case 1:
+			gen.AddTx(signTx(0, sponsorAddr, nil, sponsoredKey))
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := blockchain.InsertChain(blocks); err != nil {
+		t.Fatal(err)
+	}
+	state, err := blockchain.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The sponsor is only charged for the gas used by the transaction.
+	spent := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(params.TxGas))
+	if balance := state.GetBalance(sponsoredAddr); balance.Sign() != 0 {
+		t.Fatalf("expected sponsored sender to have no balance, got %d", balance)
+	}
+	if nonce := state.GetNonce(sponsoredAddr); nonce != 1 {
+		t.Fatalf("expected sponsored sender nonce 1, got %d", nonce)
+	}
+	if have, want := precompile.GetSponsorDeposit(state, sponsorAddr), new(big.Int).Sub(deposit, spent); have.Cmp(want) != 0 {
+		t.Fatalf("sponsor deposit mismatch: have %d, want %d", have, want)
+	}
+	if have, want := state.GetBalance(precompile.GasSponsorAddress), precompile.GetSponsorDeposit(state, sponsorAddr); have.Cmp(want) != 0 {
+		t.Fatalf("gas sponsor balance mismatch: have %d, want %d", have, want)
+	}
+
+	// Once the spending limit of the sender is exhausted, the sender has to pay for its own gas.
+	badBlock := GenerateBadBlock(blocks[1], dummy.NewFaker(), types.Transactions{signTx(1, sponsorAddr, nil, sponsoredKey)}, gspec.Config)
+	if _, err := blockchain.InsertChain(types.Blocks{badBlock}); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("expected error %q, got %v", ErrInsufficientFunds, err)
+	}
+}
+
 // GenerateBadBlock constructs a "block" which contains the transactions. The transactions are not expected to be
 // valid, and no proper post-state can be made. But from the perspective of the blockchain, the block is sufficiently
 // valid to be considered for import:
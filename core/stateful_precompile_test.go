@@ -4,13 +4,16 @@
 package core
 
 import (
+	"fmt"
 	"math/big"
 	"strings"
 	"testing"
 
 	"github.com/ava-labs/subnet-evm/commontype"
+	"github.com/ava-labs/subnet-evm/constants"
 	"github.com/ava-labs/subnet-evm/core/rawdb"
 	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ava-labs/subnet-evm/params"
 	"github.com/ava-labs/subnet-evm/precompile"
 	"github.com/ava-labs/subnet-evm/vmerrs"
 	"github.com/ethereum/go-ethereum/common"
@@ -21,6 +24,7 @@ import (
 var (
 	_ precompile.BlockContext              = &mockBlockContext{}
 	_ precompile.PrecompileAccessibleState = &mockAccessibleState{}
+	_ precompile.ChainConfig               = &mockChainConfig{}
 
 	testFeeConfig = commontype.FeeConfig{
 		GasLimit:        big.NewInt(8_000_000),
@@ -41,20 +45,48 @@ var (
 type mockBlockContext struct {
 	blockNumber *big.Int
 	timestamp   uint64
+	coinbase    common.Address
+	baseFee     *big.Int
 }
 
-func (mb *mockBlockContext) Number() *big.Int    { return mb.blockNumber }
-func (mb *mockBlockContext) Timestamp() *big.Int { return new(big.Int).SetUint64(mb.timestamp) }
+func (mb *mockBlockContext) Number() *big.Int         { return mb.blockNumber }
+func (mb *mockBlockContext) Timestamp() *big.Int      { return new(big.Int).SetUint64(mb.timestamp) }
+func (mb *mockBlockContext) Coinbase() common.Address { return mb.coinbase }
+func (mb *mockBlockContext) BaseFee() *big.Int        { return mb.baseFee }
+
+type mockChainConfig struct {
+	precompileLogs       bool
+	allowedFeeRecipients bool
+	allowListRoles       bool
+	nativeMinterSupply   bool
+}
+
+func (mc *mockChainConfig) GetFeeConfig() commontype.FeeConfig            { return testFeeConfig }
+func (mc *mockChainConfig) AllowedFeeRecipients() bool                    { return mc.allowedFeeRecipients }
+func (mc *mockChainConfig) IsPrecompileLogs(blockTimestamp *big.Int) bool { return mc.precompileLogs }
+func (mc *mockChainConfig) IsAllowListRoles(blockTimestamp *big.Int) bool { return mc.allowListRoles }
+func (mc *mockChainConfig) IsNativeMinterSupply(blockTimestamp *big.Int) bool {
+	return mc.nativeMinterSupply
+}
 
 type mockAccessibleState struct {
 	state        *state.StateDB
 	blockContext *mockBlockContext
+	chainConfig  mockChainConfig
+	txOrigin     common.Address
+	chainID      *big.Int
 }
 
 func (m *mockAccessibleState) GetStateDB() precompile.StateDB { return m.state }
 
 func (m *mockAccessibleState) GetBlockContext() precompile.BlockContext { return m.blockContext }
 
+func (m *mockAccessibleState) GetChainConfig() precompile.ChainConfig { return &m.chainConfig }
+
+func (m *mockAccessibleState) GetTxOrigin() common.Address { return m.txOrigin }
+
+func (m *mockAccessibleState) GetChainID() *big.Int { return m.chainID }
+
 // This test is added within the core package so that it can import all of the required code
 // without creating any import cycles
 func TestContractDeployerAllowListRun(t *testing.T) {
@@ -89,10 +121,10 @@ func TestContractDeployerAllowListRun(t *testing.T) {
 			readOnly:    false,
 			expectedRes: []byte{},
 			assertState: func(t *testing.T, state *state.StateDB) {
-				res := precompile.GetContractDeployerAllowListStatus(state, adminAddr)
+				res := precompile.GetContractDeployerAllowListStatus(state, adminAddr, common.Big0)
 				assert.Equal(t, precompile.AllowListAdmin, res)
 
-				res = precompile.GetContractDeployerAllowListStatus(state, noRoleAddr)
+				res = precompile.GetContractDeployerAllowListStatus(state, noRoleAddr, common.Big0)
 				assert.Equal(t, precompile.AllowListAdmin, res)
 			},
 		},
@@ -110,10 +142,10 @@ func TestContractDeployerAllowListRun(t *testing.T) {
 			readOnly:    false,
 			expectedRes: []byte{},
 			assertState: func(t *testing.T, state *state.StateDB) {
-				res := precompile.GetContractDeployerAllowListStatus(state, adminAddr)
+				res := precompile.GetContractDeployerAllowListStatus(state, adminAddr, common.Big0)
 				assert.Equal(t, precompile.AllowListAdmin, res)
 
-				res = precompile.GetContractDeployerAllowListStatus(state, noRoleAddr)
+				res = precompile.GetContractDeployerAllowListStatus(state, noRoleAddr, common.Big0)
 				assert.Equal(t, precompile.AllowListEnabled, res)
 			},
 		},
@@ -131,7 +163,7 @@ func TestContractDeployerAllowListRun(t *testing.T) {
 			readOnly:    false,
 			expectedRes: []byte{},
 			assertState: func(t *testing.T, state *state.StateDB) {
-				res := precompile.GetContractDeployerAllowListStatus(state, adminAddr)
+				res := precompile.GetContractDeployerAllowListStatus(state, adminAddr, common.Big0)
 				assert.Equal(t, precompile.AllowListNoRole, res)
 			},
 		},
@@ -215,7 +247,7 @@ func TestContractDeployerAllowListRun(t *testing.T) {
 			readOnly:    false,
 			expectedRes: common.Hash(precompile.AllowListNoRole).Bytes(),
 			assertState: func(t *testing.T, state *state.StateDB) {
-				res := precompile.GetContractDeployerAllowListStatus(state, noRoleAddr)
+				res := precompile.GetContractDeployerAllowListStatus(state, noRoleAddr, common.Big0)
 				assert.Equal(t, precompile.AllowListNoRole, res)
 			},
 		},
@@ -229,7 +261,7 @@ func TestContractDeployerAllowListRun(t *testing.T) {
 			readOnly:    false,
 			expectedRes: common.Hash(precompile.AllowListNoRole).Bytes(),
 			assertState: func(t *testing.T, state *state.StateDB) {
-				res := precompile.GetContractDeployerAllowListStatus(state, adminAddr)
+				res := precompile.GetContractDeployerAllowListStatus(state, adminAddr, common.Big0)
 				assert.Equal(t, precompile.AllowListAdmin, res)
 			},
 		},
@@ -243,7 +275,7 @@ func TestContractDeployerAllowListRun(t *testing.T) {
 			readOnly:    true,
 			expectedRes: common.Hash(precompile.AllowListNoRole).Bytes(),
 			assertState: func(t *testing.T, state *state.StateDB) {
-				res := precompile.GetContractDeployerAllowListStatus(state, adminAddr)
+				res := precompile.GetContractDeployerAllowListStatus(state, adminAddr, common.Big0)
 				assert.Equal(t, precompile.AllowListAdmin, res)
 			},
 		},
@@ -325,10 +357,10 @@ func TestTxAllowListRun(t *testing.T) {
 			readOnly:    false,
 			expectedRes: []byte{},
 			assertState: func(t *testing.T, state *state.StateDB) {
-				res := precompile.GetTxAllowListStatus(state, adminAddr)
+				res := precompile.GetTxAllowListStatus(state, adminAddr, common.Big0)
 				assert.Equal(t, precompile.AllowListAdmin, res)
 
-				res = precompile.GetTxAllowListStatus(state, noRoleAddr)
+				res = precompile.GetTxAllowListStatus(state, noRoleAddr, common.Big0)
 				assert.Equal(t, precompile.AllowListAdmin, res)
 			},
 		},
@@ -346,10 +378,10 @@ func TestTxAllowListRun(t *testing.T) {
 			readOnly:    false,
 			expectedRes: []byte{},
 			assertState: func(t *testing.T, state *state.StateDB) {
-				res := precompile.GetTxAllowListStatus(state, adminAddr)
+				res := precompile.GetTxAllowListStatus(state, adminAddr, common.Big0)
 				assert.Equal(t, precompile.AllowListAdmin, res)
 
-				res = precompile.GetTxAllowListStatus(state, noRoleAddr)
+				res = precompile.GetTxAllowListStatus(state, noRoleAddr, common.Big0)
 				assert.Equal(t, precompile.AllowListEnabled, res)
 			},
 		},
@@ -367,7 +399,7 @@ func TestTxAllowListRun(t *testing.T) {
 			readOnly:    false,
 			expectedRes: []byte{},
 			assertState: func(t *testing.T, state *state.StateDB) {
-				res := precompile.GetTxAllowListStatus(state, adminAddr)
+				res := precompile.GetTxAllowListStatus(state, adminAddr, common.Big0)
 				assert.Equal(t, precompile.AllowListNoRole, res)
 			},
 		},
@@ -451,7 +483,7 @@ func TestTxAllowListRun(t *testing.T) {
 			readOnly:    false,
 			expectedRes: common.Hash(precompile.AllowListNoRole).Bytes(),
 			assertState: func(t *testing.T, state *state.StateDB) {
-				res := precompile.GetTxAllowListStatus(state, noRoleAddr)
+				res := precompile.GetTxAllowListStatus(state, noRoleAddr, common.Big0)
 				assert.Equal(t, precompile.AllowListNoRole, res)
 			},
 		},
@@ -465,7 +497,7 @@ func TestTxAllowListRun(t *testing.T) {
 			readOnly:    false,
 			expectedRes: common.Hash(precompile.AllowListNoRole).Bytes(),
 			assertState: func(t *testing.T, state *state.StateDB) {
-				res := precompile.GetTxAllowListStatus(state, adminAddr)
+				res := precompile.GetTxAllowListStatus(state, adminAddr, common.Big0)
 				assert.Equal(t, precompile.AllowListAdmin, res)
 			},
 		},
@@ -479,7 +511,7 @@ func TestTxAllowListRun(t *testing.T) {
 			readOnly:    true,
 			expectedRes: common.Hash(precompile.AllowListNoRole).Bytes(),
 			assertState: func(t *testing.T, state *state.StateDB) {
-				res := precompile.GetTxAllowListStatus(state, adminAddr)
+				res := precompile.GetTxAllowListStatus(state, adminAddr, common.Big0)
 				assert.Equal(t, precompile.AllowListAdmin, res)
 			},
 		},
@@ -575,7 +607,7 @@ func TestContractNativeMinterRun(t *testing.T) {
 			readOnly:    false,
 			expectedRes: []byte{},
 			assertState: func(t *testing.T, state *state.StateDB) {
-				res := precompile.GetContractNativeMinterStatus(state, allowAddr)
+				res := precompile.GetContractNativeMinterStatus(state, allowAddr, common.Big0)
 				assert.Equal(t, precompile.AllowListEnabled, res)
 
 				assert.Equal(t, common.Big1, state.GetBalance(allowAddr), "expected minted funds")
@@ -595,7 +627,7 @@ func TestContractNativeMinterRun(t *testing.T) {
 			readOnly:    false,
 			expectedRes: []byte{},
 			assertState: func(t *testing.T, state *state.StateDB) {
-				res := precompile.GetContractNativeMinterStatus(state, adminAddr)
+				res := precompile.GetContractNativeMinterStatus(state, adminAddr, common.Big0)
 				assert.Equal(t, precompile.AllowListAdmin, res)
 
 				assert.Equal(t, common.Big1, state.GetBalance(adminAddr), "expected minted funds")
@@ -615,7 +647,7 @@ func TestContractNativeMinterRun(t *testing.T) {
 			readOnly:    false,
 			expectedRes: []byte{},
 			assertState: func(t *testing.T, state *state.StateDB) {
-				res := precompile.GetContractNativeMinterStatus(state, adminAddr)
+				res := precompile.GetContractNativeMinterStatus(state, adminAddr, common.Big0)
 				assert.Equal(t, precompile.AllowListAdmin, res)
 
 				assert.Equal(t, math.MaxBig256, state.GetBalance(adminAddr), "expected minted funds")
@@ -723,10 +755,10 @@ func TestContractNativeMinterRun(t *testing.T) {
 			readOnly:    false,
 			expectedRes: []byte{},
 			assertState: func(t *testing.T, state *state.StateDB) {
-				res := precompile.GetContractNativeMinterStatus(state, adminAddr)
+				res := precompile.GetContractNativeMinterStatus(state, adminAddr, common.Big0)
 				assert.Equal(t, precompile.AllowListAdmin, res)
 
-				res = precompile.GetContractNativeMinterStatus(state, noRoleAddr)
+				res = precompile.GetContractNativeMinterStatus(state, noRoleAddr, common.Big0)
 				assert.Equal(t, precompile.AllowListEnabled, res)
 			},
 		},
@@ -780,6 +812,122 @@ func TestContractNativeMinterRun(t *testing.T) {
 	}
 }
 
+func TestContractNativeMinterSupply(t *testing.T) {
+	adminAddr := common.HexToAddress("0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC")
+	quotaAddr := common.HexToAddress("0xAb5801a7D398351b8bE11C439e05C5B3259aeC9B")
+	noRoleAddr := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+
+	state, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := precompile.NewContractNativeMinterConfig(common.Big0, []common.Address{adminAddr})
+	config.MaxTotalSupply = big.NewInt(100)
+	config.MinterQuotas = map[common.Address]*big.Int{quotaAddr: big.NewInt(30)}
+	config.QuotaWindow = 10
+	assert.NoError(t, config.Verify())
+	config.Configure(&mockChainConfig{}, state, &mockBlockContext{blockNumber: testBlockNumber})
+	precompile.SetContractNativeMinterStatus(state, quotaAddr, precompile.AllowListEnabled)
+
+	run := func(timestamp uint64, nativeMinterSupply bool, caller common.Address, input []byte, suppliedGas uint64) ([]byte, error) {
+		accessibleState := &mockAccessibleState{
+			state:        state,
+			blockContext: &mockBlockContext{blockNumber: testBlockNumber, timestamp: timestamp},
+			chainConfig:  mockChainConfig{nativeMinterSupply: nativeMinterSupply},
+		}
+		ret, remainingGas, err := precompile.ContractNativeMinterPrecompile.Run(accessibleState, caller, precompile.ContractNativeMinterAddress, input, suppliedGas, false)
+		if err == nil {
+			assert.Equal(t, uint64(0), remainingGas)
+		}
+		return ret, err
+	}
+	mint := func(timestamp uint64, caller common.Address, amount int64) error {
+		input, err := precompile.PackMintInput(caller, big.NewInt(amount))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = run(timestamp, true, caller, input, precompile.MintGasCost+precompile.MintSupplyGasCost)
+		return err
+	}
+	totalMinted := func() *big.Int {
+		ret, err := run(0, true, noRoleAddr, precompile.PackTotalMinted(), precompile.TotalMintedGasCost)
+		assert.NoError(t, err)
+		return new(big.Int).SetBytes(ret)
+	}
+	remainingQuota := func(timestamp uint64, address common.Address) *big.Int {
+		ret, err := run(timestamp, true, noRoleAddr, precompile.PackRemainingQuota(address), precompile.RemainingQuotaGasCost)
+		assert.NoError(t, err)
+		return new(big.Int).SetBytes(ret)
+	}
+
+	// Before the upgrade, the supply is neither tracked nor limited and burning is not supported.
+	input, err := precompile.PackMintInput(quotaAddr, big.NewInt(1000))
+	assert.NoError(t, err)
+	_, err = run(0, false, quotaAddr, input, precompile.MintGasCost)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(1000), state.GetBalance(quotaAddr))
+	_, err = run(0, false, quotaAddr, precompile.PackBurnInput(common.Big1), precompile.BurnGasCost)
+	assert.ErrorContains(t, err, "invalid function selector")
+	_, err = run(0, false, noRoleAddr, precompile.PackTotalMinted(), precompile.TotalMintedGasCost)
+	assert.ErrorContains(t, err, "invalid function selector")
+	assert.Zero(t, totalMinted().Sign())
+
+	// Quotas apply within a quota window.
+	assert.NoError(t, mint(100, quotaAddr, 20))
+	assert.Equal(t, big.NewInt(10), remainingQuota(109, quotaAddr))
+	assert.ErrorIs(t, mint(109, quotaAddr, 11), precompile.ErrMinterQuotaExceeded)
+	assert.Equal(t, big.NewInt(30), remainingQuota(110, quotaAddr))
+	assert.NoError(t, mint(110, quotaAddr, 30))
+	assert.Equal(t, big.NewInt(50), totalMinted())
+
+	// Minters without a quota are limited by the max total supply.
+	assert.Equal(t, big.NewInt(50), remainingQuota(110, adminAddr))
+	assert.NoError(t, mint(110, adminAddr, 50))
+	assert.ErrorIs(t, mint(120, adminAddr, 1), precompile.ErrMaxTotalSupplyExceeded)
+	assert.Zero(t, remainingQuota(120, quotaAddr).Sign())
+	assert.Zero(t, remainingQuota(120, noRoleAddr).Sign())
+
+	// Burning reduces the tracked supply.
+	_, err = run(120, true, adminAddr, precompile.PackBurnInput(big.NewInt(40)), precompile.BurnGasCost)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(10), state.GetBalance(adminAddr))
+	assert.Equal(t, big.NewInt(60), totalMinted())
+	assert.Equal(t, big.NewInt(40), remainingQuota(120, adminAddr))
+
+	_, err = run(120, true, adminAddr, precompile.PackBurnInput(big.NewInt(11)), precompile.BurnGasCost)
+	assert.ErrorIs(t, err, vmerrs.ErrInsufficientBalance)
+	_, err = run(120, true, noRoleAddr, precompile.PackBurnInput(common.Big0), precompile.BurnGasCost)
+	assert.ErrorIs(t, err, precompile.ErrCannotBurn)
+
+	// Burning more than the tracked supply reduces it to 0.
+	_, err = run(120, true, quotaAddr, precompile.PackBurnInput(big.NewInt(1000)), precompile.BurnGasCost)
+	assert.NoError(t, err)
+	assert.Zero(t, totalMinted().Sign())
+}
+
+func TestContractNativeMinterConfig(t *testing.T) {
+	minterAddr := common.HexToAddress("0xAb5801a7D398351b8bE11C439e05C5B3259aeC9B")
+	newConfig := func(maxTotalSupply *big.Int, quota *big.Int) *precompile.ContractNativeMinterConfig {
+		config := precompile.NewContractNativeMinterConfig(common.Big0, nil)
+		config.MaxTotalSupply = maxTotalSupply
+		if quota != nil {
+			config.MinterQuotas = map[common.Address]*big.Int{minterAddr: quota}
+		}
+		return config
+	}
+
+	assert.NoError(t, newConfig(nil, nil).Verify())
+	assert.NoError(t, newConfig(big.NewInt(100), big.NewInt(10)).Verify())
+	assert.ErrorIs(t, newConfig(common.Big0, nil).Verify(), precompile.ErrInvalidMaxTotalSupply)
+	assert.ErrorIs(t, newConfig(new(big.Int).Lsh(common.Big1, 256), nil).Verify(), precompile.ErrInvalidMaxTotalSupply)
+	assert.ErrorIs(t, newConfig(nil, big.NewInt(-1)).Verify(), precompile.ErrInvalidMinterQuota)
+
+	assert.True(t, newConfig(big.NewInt(100), big.NewInt(10)).Equal(newConfig(big.NewInt(100), big.NewInt(10))))
+	assert.False(t, newConfig(big.NewInt(100), big.NewInt(10)).Equal(newConfig(nil, big.NewInt(10))))
+	assert.False(t, newConfig(big.NewInt(100), big.NewInt(10)).Equal(newConfig(big.NewInt(100), big.NewInt(11))))
+	assert.False(t, newConfig(big.NewInt(100), big.NewInt(10)).Equal(newConfig(big.NewInt(100), nil)))
+}
+
 func TestFeeConfigManagerRun(t *testing.T) {
 	type test struct {
 		caller         common.Address
@@ -828,7 +976,7 @@ func TestFeeConfigManagerRun(t *testing.T) {
 			readOnly:    false,
 			expectedRes: []byte{},
 			assertState: func(t *testing.T, state *state.StateDB) {
-				res := precompile.GetFeeConfigManagerStatus(state, allowAddr)
+				res := precompile.GetFeeConfigManagerStatus(state, allowAddr, common.Big0)
 				assert.Equal(t, precompile.AllowListEnabled, res)
 
 				feeConfig := precompile.GetStoredFeeConfig(state)
@@ -852,7 +1000,7 @@ func TestFeeConfigManagerRun(t *testing.T) {
 			expectedRes: []byte{},
 			expectedErr: "cannot be greater than maxBlockGasCost",
 			assertState: func(t *testing.T, state *state.StateDB) {
-				res := precompile.GetFeeConfigManagerStatus(state, allowAddr)
+				res := precompile.GetFeeConfigManagerStatus(state, allowAddr, common.Big0)
 				assert.Equal(t, precompile.AllowListEnabled, res)
 
 				feeConfig := precompile.GetStoredFeeConfig(state)
@@ -873,7 +1021,7 @@ func TestFeeConfigManagerRun(t *testing.T) {
 			readOnly:    false,
 			expectedRes: []byte{},
 			assertState: func(t *testing.T, state *state.StateDB) {
-				res := precompile.GetFeeConfigManagerStatus(state, adminAddr)
+				res := precompile.GetFeeConfigManagerStatus(state, adminAddr, common.Big0)
 				assert.Equal(t, precompile.AllowListAdmin, res)
 
 				feeConfig := precompile.GetStoredFeeConfig(state)
@@ -1000,10 +1148,10 @@ func TestFeeConfigManagerRun(t *testing.T) {
 			readOnly:    false,
 			expectedRes: []byte{},
 			assertState: func(t *testing.T, state *state.StateDB) {
-				res := precompile.GetFeeConfigManagerStatus(state, adminAddr)
+				res := precompile.GetFeeConfigManagerStatus(state, adminAddr, common.Big0)
 				assert.Equal(t, precompile.AllowListAdmin, res)
 
-				res = precompile.GetFeeConfigManagerStatus(state, noRoleAddr)
+				res = precompile.GetFeeConfigManagerStatus(state, noRoleAddr, common.Big0)
 				assert.Equal(t, precompile.AllowListEnabled, res)
 			},
 		},
@@ -1061,3 +1209,1260 @@ func TestFeeConfigManagerRun(t *testing.T) {
 		})
 	}
 }
+
+func TestFeeConfigManagerConfigure(t *testing.T) {
+	adminAddr := common.HexToAddress("0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC")
+	managerAddr := common.HexToAddress("0x1234567890000000000000000000000000000000")
+	enabledAddr := common.HexToAddress("0xAb5801a7D398351b8bE11C439e05C5B3259aeC9B")
+
+	initialFeeConfig := testFeeConfig
+	initialFeeConfig.GasLimit = big.NewInt(20_000_000)
+	for name, test := range map[string]struct {
+		initialFeeConfig  *commontype.FeeConfig
+		expectedFeeConfig commontype.FeeConfig
+	}{
+		"chain fee config by default": {
+			expectedFeeConfig: testFeeConfig,
+		},
+		"initial fee config": {
+			initialFeeConfig:  &initialFeeConfig,
+			expectedFeeConfig: initialFeeConfig,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			state, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			config := precompile.NewFeeManagerConfig(common.Big0, []common.Address{adminAddr})
+			config.ManagerAddresses = []common.Address{managerAddr}
+			config.EnabledAddresses = []common.Address{enabledAddr}
+			config.InitialFeeConfig = test.initialFeeConfig
+			assert.NoError(t, config.Verify())
+			config.Configure(&mockChainConfig{}, state, &mockBlockContext{blockNumber: testBlockNumber})
+
+			assert.Equal(t, test.expectedFeeConfig, precompile.GetStoredFeeConfig(state))
+			assert.Equal(t, testBlockNumber, precompile.GetFeeConfigLastChangedAt(state))
+			assert.Equal(t, precompile.AllowListAdmin, precompile.GetFeeConfigManagerStatus(state, adminAddr, common.Big0))
+			assert.Equal(t, precompile.AllowListManager, precompile.GetFeeConfigManagerStatus(state, managerAddr, common.Big0))
+			assert.Equal(t, precompile.AllowListEnabled, precompile.GetFeeConfigManagerStatus(state, enabledAddr, common.Big0))
+		})
+	}
+}
+
+func TestRewardManagerRun(t *testing.T) {
+	type test struct {
+		caller      common.Address
+		input       func() []byte
+		suppliedGas uint64
+		readOnly    bool
+
+		expectedRes []byte
+		expectedErr string
+
+		assertState func(t *testing.T, state *state.StateDB)
+	}
+
+	adminAddr := common.HexToAddress("0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC")
+	allowAddr := common.HexToAddress("0xAb5801a7D398351b8bE11C439e05C5B3259aeC9B")
+	noRoleAddr := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	rewardAddr := common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567")
+
+	for name, test := range map[string]test{
+		"set reward address from no role fails": {
+			caller: noRoleAddr,
+			input: func() []byte {
+				return precompile.PackSetRewardAddress(rewardAddr)
+			},
+			suppliedGas: precompile.SetRewardAddressGasCost,
+			expectedErr: precompile.ErrCannotSetRewardAddress.Error(),
+		},
+		"set reward address from enabled address": {
+			caller: allowAddr,
+			input: func() []byte {
+				return precompile.PackSetRewardAddress(rewardAddr)
+			},
+			suppliedGas: precompile.SetRewardAddressGasCost,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *state.StateDB) {
+				address, allowFeeRecipients := precompile.GetStoredRewardAddress(state)
+				assert.Equal(t, rewardAddr, address)
+				assert.False(t, allowFeeRecipients)
+			},
+		},
+		"set empty reward address fails": {
+			caller: adminAddr,
+			input: func() []byte {
+				return precompile.PackSetRewardAddress(common.Address{})
+			},
+			suppliedGas: precompile.SetRewardAddressGasCost,
+			expectedErr: precompile.ErrEmptyRewardAddress.Error(),
+		},
+		"set reward address readOnly fails": {
+			caller: adminAddr,
+			input: func() []byte {
+				return precompile.PackSetRewardAddress(rewardAddr)
+			},
+			suppliedGas: precompile.SetRewardAddressGasCost,
+			readOnly:    true,
+			expectedErr: vmerrs.ErrWriteProtection.Error(),
+		},
+		"set reward address insufficient gas": {
+			caller: adminAddr,
+			input: func() []byte {
+				return precompile.PackSetRewardAddress(rewardAddr)
+			},
+			suppliedGas: precompile.SetRewardAddressGasCost - 1,
+			expectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+		"allow fee recipients from enabled address": {
+			caller: allowAddr,
+			input: func() []byte {
+				return precompile.PackAllowFeeRecipients()
+			},
+			suppliedGas: precompile.AllowFeeRecipientsGasCost,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *state.StateDB) {
+				_, allowFeeRecipients := precompile.GetStoredRewardAddress(state)
+				assert.True(t, allowFeeRecipients)
+			},
+		},
+		"allow fee recipients from no role fails": {
+			caller: noRoleAddr,
+			input: func() []byte {
+				return precompile.PackAllowFeeRecipients()
+			},
+			suppliedGas: precompile.AllowFeeRecipientsGasCost,
+			expectedErr: precompile.ErrCannotAllowFeeRecipients.Error(),
+		},
+		"disable rewards from admin": {
+			caller: adminAddr,
+			input: func() []byte {
+				return precompile.PackDisableRewards()
+			},
+			suppliedGas: precompile.DisableRewardsGasCost,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *state.StateDB) {
+				address, allowFeeRecipients := precompile.GetStoredRewardAddress(state)
+				assert.Equal(t, constants.BlackholeAddr, address)
+				assert.False(t, allowFeeRecipients)
+			},
+		},
+		"disable rewards from no role fails": {
+			caller: noRoleAddr,
+			input: func() []byte {
+				return precompile.PackDisableRewards()
+			},
+			suppliedGas: precompile.DisableRewardsGasCost,
+			expectedErr: precompile.ErrCannotDisableRewards.Error(),
+		},
+		"read current reward address": {
+			caller: noRoleAddr,
+			input: func() []byte {
+				return precompile.PackCurrentRewardAddress()
+			},
+			suppliedGas: precompile.CurrentRewardAddressGasCost,
+			readOnly:    true,
+			expectedRes: constants.BlackholeAddr.Hash().Bytes(),
+		},
+		"read are fee recipients allowed": {
+			caller: noRoleAddr,
+			input: func() []byte {
+				return precompile.PackAreFeeRecipientsAllowed()
+			},
+			suppliedGas: precompile.AreFeeRecipientsAllowedGasCost,
+			readOnly:    true,
+			expectedRes: common.Hash{}.Bytes(),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			db := rawdb.NewMemoryDatabase()
+			state, err := state.New(common.Hash{}, state.NewDatabase(db), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Set up the state so that each address has the expected permissions at the start
+			// and fees are burned.
+			precompile.SetRewardManagerAllowListStatus(state, adminAddr, precompile.AllowListAdmin)
+			precompile.SetRewardManagerAllowListStatus(state, allowAddr, precompile.AllowListEnabled)
+			precompile.DisableFeeRewards(state)
+
+			blockContext := &mockBlockContext{blockNumber: testBlockNumber}
+			ret, remainingGas, err := precompile.RewardManagerPrecompile.Run(&mockAccessibleState{state: state, blockContext: blockContext}, test.caller, precompile.RewardManagerAddress, test.input(), test.suppliedGas, test.readOnly)
+			if len(test.expectedErr) != 0 {
+				if err == nil {
+					assert.Failf(t, "run expectedly passed without error", "expected error %q", test.expectedErr)
+				} else {
+					assert.True(t, strings.Contains(err.Error(), test.expectedErr), "expected error (%s) to contain substring (%s)", err, test.expectedErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, uint64(0), remainingGas)
+			assert.Equal(t, test.expectedRes, ret)
+
+			if test.assertState != nil {
+				test.assertState(t, state)
+			}
+		})
+	}
+}
+
+func TestRewardManagerConfigure(t *testing.T) {
+	rewardAddr := common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567")
+	for name, test := range map[string]struct {
+		config                     *precompile.RewardManagerConfig
+		chainAllowsFeeRecipients   bool
+		expectedAddress            common.Address
+		expectedAllowFeeRecipients bool
+	}{
+		"burn by default": {
+			config:          precompile.NewRewardManagerConfig(common.Big0, nil, nil),
+			expectedAddress: constants.BlackholeAddr,
+		},
+		"allow fee recipients from chain config": {
+			config:                     precompile.NewRewardManagerConfig(common.Big0, nil, nil),
+			chainAllowsFeeRecipients:   true,
+			expectedAllowFeeRecipients: true,
+		},
+		"initial reward address": {
+			config:          precompile.NewRewardManagerConfig(common.Big0, nil, &precompile.InitialRewardConfig{RewardAddress: rewardAddr}),
+			expectedAddress: rewardAddr,
+		},
+		"initial config overrides chain config": {
+			config:                   precompile.NewRewardManagerConfig(common.Big0, nil, &precompile.InitialRewardConfig{}),
+			chainAllowsFeeRecipients: true,
+			expectedAddress:          constants.BlackholeAddr,
+		},
+		"initial allow fee recipients": {
+			config:                     precompile.NewRewardManagerConfig(common.Big0, nil, &precompile.InitialRewardConfig{AllowFeeRecipients: true}),
+			expectedAllowFeeRecipients: true,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			state, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.NoError(t, test.config.Verify())
+			blockContext := &mockBlockContext{blockNumber: testBlockNumber}
+			test.config.Configure(&mockChainConfig{allowedFeeRecipients: test.chainAllowsFeeRecipients}, state, blockContext)
+
+			address, allowFeeRecipients := precompile.GetStoredRewardAddress(state)
+			assert.Equal(t, test.expectedAllowFeeRecipients, allowFeeRecipients)
+			if !allowFeeRecipients {
+				assert.Equal(t, test.expectedAddress, address)
+			}
+		})
+	}
+
+	invalidConfig := precompile.NewRewardManagerConfig(common.Big0, nil, &precompile.InitialRewardConfig{AllowFeeRecipients: true, RewardAddress: rewardAddr})
+	assert.ErrorIs(t, invalidConfig.Verify(), precompile.ErrCannotEnableBothRewards)
+}
+
+func TestPauserRun(t *testing.T) {
+	type test struct {
+		caller      common.Address
+		input       func() []byte
+		suppliedGas uint64
+		readOnly    bool
+		paused      bool
+
+		expectedRes []byte
+		expectedErr string
+
+		assertState func(t *testing.T, state *state.StateDB)
+	}
+
+	adminAddr := common.HexToAddress("0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC")
+	allowAddr := common.HexToAddress("0xAb5801a7D398351b8bE11C439e05C5B3259aeC9B")
+	noRoleAddr := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+
+	for name, test := range map[string]test{
+		"pause from enabled address": {
+			caller:      allowAddr,
+			input:       precompile.PackPause,
+			suppliedGas: precompile.PauseGasCost,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *state.StateDB) {
+				assert.True(t, precompile.IsPaused(state))
+			},
+		},
+		"pause from no role fails": {
+			caller:      noRoleAddr,
+			input:       precompile.PackPause,
+			suppliedGas: precompile.PauseGasCost,
+			expectedErr: precompile.ErrCannotPause.Error(),
+		},
+		"pause readOnly fails": {
+			caller:      adminAddr,
+			input:       precompile.PackPause,
+			suppliedGas: precompile.PauseGasCost,
+			readOnly:    true,
+			expectedErr: vmerrs.ErrWriteProtection.Error(),
+		},
+		"pause insufficient gas": {
+			caller:      adminAddr,
+			input:       precompile.PackPause,
+			suppliedGas: precompile.PauseGasCost - 1,
+			expectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+		"pause with input fails": {
+			caller: adminAddr,
+			input: func() []byte {
+				return append(precompile.PackPause(), make([]byte, common.HashLength)...)
+			},
+			suppliedGas: precompile.PauseGasCost,
+			expectedErr: "invalid input length",
+		},
+		"unpause from admin": {
+			caller:      adminAddr,
+			input:       precompile.PackUnpause,
+			suppliedGas: precompile.UnpauseGasCost,
+			paused:      true,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *state.StateDB) {
+				assert.False(t, precompile.IsPaused(state))
+			},
+		},
+		"unpause from no role fails": {
+			caller:      noRoleAddr,
+			input:       precompile.PackUnpause,
+			suppliedGas: precompile.UnpauseGasCost,
+			paused:      true,
+			expectedErr: precompile.ErrCannotUnpause.Error(),
+		},
+		"read is paused": {
+			caller:      noRoleAddr,
+			input:       precompile.PackIsPaused,
+			suppliedGas: precompile.IsPausedGasCost,
+			readOnly:    true,
+			paused:      true,
+			expectedRes: common.BigToHash(common.Big1).Bytes(),
+		},
+		"read is not paused": {
+			caller:      noRoleAddr,
+			input:       precompile.PackIsPaused,
+			suppliedGas: precompile.IsPausedGasCost,
+			readOnly:    true,
+			expectedRes: common.Hash{}.Bytes(),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			db := rawdb.NewMemoryDatabase()
+			state, err := state.New(common.Hash{}, state.NewDatabase(db), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Set up the state so that each address has the expected permissions at the start.
+			precompile.SetPauserAllowListStatus(state, adminAddr, precompile.AllowListAdmin)
+			precompile.SetPauserAllowListStatus(state, allowAddr, precompile.AllowListEnabled)
+			blockContext := &mockBlockContext{blockNumber: testBlockNumber}
+			if test.paused {
+				_, _, err := precompile.PauserPrecompile.Run(&mockAccessibleState{state: state, blockContext: blockContext}, adminAddr, precompile.PauserAddress, precompile.PackPause(), precompile.PauseGasCost, false)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ret, remainingGas, err := precompile.PauserPrecompile.Run(&mockAccessibleState{state: state, blockContext: blockContext}, test.caller, precompile.PauserAddress, test.input(), test.suppliedGas, test.readOnly)
+			if len(test.expectedErr) != 0 {
+				assert.ErrorContains(t, err, test.expectedErr)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, uint64(0), remainingGas)
+			assert.Equal(t, test.expectedRes, ret)
+
+			if test.assertState != nil {
+				test.assertState(t, state)
+			}
+		})
+	}
+}
+
+func TestCheckPaused(t *testing.T) {
+	adminAddr := common.HexToAddress("0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC")
+	exemptSender := common.HexToAddress("0xAb5801a7D398351b8bE11C439e05C5B3259aeC9B")
+	exemptTarget := common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567")
+	otherAddr := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	pauserAddr := precompile.PauserAddress
+
+	state, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := precompile.NewPauserConfig(common.Big0, []common.Address{adminAddr})
+	config.ExemptSenders = []common.Address{exemptSender}
+	config.ExemptTargets = []common.Address{exemptTarget}
+	assert.NoError(t, config.Verify())
+	config.Configure(&mockChainConfig{}, state, &mockBlockContext{blockNumber: testBlockNumber})
+
+	// Nothing is rejected before the chain is paused.
+	assert.NoError(t, precompile.CheckPaused(state, otherAddr, &otherAddr, common.Big0))
+	assert.NoError(t, precompile.CheckPaused(state, otherAddr, nil, common.Big0))
+
+	_, _, err = precompile.PauserPrecompile.Run(&mockAccessibleState{state: state, blockContext: &mockBlockContext{blockNumber: testBlockNumber}}, adminAddr, pauserAddr, precompile.PackPause(), precompile.PauseGasCost, false)
+	assert.NoError(t, err)
+
+	for name, test := range map[string]struct {
+		from      common.Address
+		to        *common.Address
+		expectErr bool
+	}{
+		"exempt sender to exempt target":     {from: exemptSender, to: &exemptTarget},
+		"exempt sender to pauser":            {from: exemptSender, to: &pauserAddr},
+		"pauser admin to pauser":             {from: adminAddr, to: &pauserAddr},
+		"pauser admin to exempt target":      {from: adminAddr, to: &exemptTarget},
+		"exempt sender to other target":      {from: exemptSender, to: &otherAddr, expectErr: true},
+		"exempt sender creating a contract":  {from: exemptSender, to: nil, expectErr: true},
+		"other sender to exempt target":      {from: otherAddr, to: &exemptTarget, expectErr: true},
+		"other sender to pauser":             {from: otherAddr, to: &pauserAddr, expectErr: true},
+		"exempt target is not exempt sender": {from: exemptTarget, to: &exemptTarget, expectErr: true},
+		"exempt sender is not exempt target": {from: exemptSender, to: &exemptSender, expectErr: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := precompile.CheckPaused(state, test.from, test.to, common.Big0)
+			if test.expectErr {
+				assert.ErrorIs(t, err, precompile.ErrChainPaused)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGasSponsorRun(t *testing.T) {
+	type test struct {
+		caller      common.Address
+		input       func() []byte
+		suppliedGas uint64
+		readOnly    bool
+
+		preCondition func(t *testing.T, state *state.StateDB)
+
+		expectedRes []byte
+		expectedErr string
+
+		assertState func(t *testing.T, state *state.StateDB)
+	}
+
+	adminAddr := common.HexToAddress("0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC")
+	sponsorAddr := common.HexToAddress("0xAb5801a7D398351b8bE11C439e05C5B3259aeC9B")
+	noRoleAddr := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	otherSponsorAddr := common.HexToAddress("0x7d2b24b3e7f2a3c1d6c3e8a9f0b1c2d3e4f5a6b7")
+	targetAddr := common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567")
+	senderAddr := common.HexToAddress("0x0987654321000000000000000000000000000000")
+	// sponsorAddr starts out with a deposit of 100, sponsoring [targetAddr] and spending at most 40 on [senderAddr].
+	initialDeposit := big.NewInt(100)
+	initialBalance := big.NewInt(1000)
+	senderLimit := big.NewInt(40)
+
+	for name, test := range map[string]test{
+		"deposit": {
+			caller:      noRoleAddr,
+			input:       func() []byte { return precompile.PackSponsorDeposit(big.NewInt(30)) },
+			suppliedGas: precompile.SponsorDepositGasCost + precompile.AddDepositorGasCost,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *state.StateDB) {
+				assert.Equal(t, big.NewInt(30), precompile.GetSponsorDeposit(state, noRoleAddr))
+				assert.Equal(t, big.NewInt(970), state.GetBalance(noRoleAddr))
+				assert.Equal(t, big.NewInt(130), state.GetBalance(precompile.GasSponsorAddress))
+			},
+		},
+		"deposit again": {
+			caller:      sponsorAddr,
+			input:       func() []byte { return precompile.PackSponsorDeposit(big.NewInt(30)) },
+			suppliedGas: precompile.SponsorDepositGasCost,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *state.StateDB) {
+				assert.Equal(t, big.NewInt(130), precompile.GetSponsorDeposit(state, sponsorAddr))
+				assert.Equal(t, big.NewInt(970), state.GetBalance(sponsorAddr))
+				assert.Equal(t, big.NewInt(130), state.GetBalance(precompile.GasSponsorAddress))
+			},
+		},
+		"first deposit without gas to list the depositor fails": {
+			caller:      noRoleAddr,
+			input:       func() []byte { return precompile.PackSponsorDeposit(big.NewInt(30)) },
+			suppliedGas: precompile.SponsorDepositGasCost,
+			expectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+		"deposit more than balance fails": {
+			caller:      noRoleAddr,
+			input:       func() []byte { return precompile.PackSponsorDeposit(big.NewInt(1001)) },
+			suppliedGas: precompile.SponsorDepositGasCost + precompile.AddDepositorGasCost,
+			expectedErr: vmerrs.ErrInsufficientBalance.Error(),
+		},
+		"deposit readOnly fails": {
+			caller:      noRoleAddr,
+			input:       func() []byte { return precompile.PackSponsorDeposit(big.NewInt(30)) },
+			suppliedGas: precompile.SponsorDepositGasCost,
+			readOnly:    true,
+			expectedErr: vmerrs.ErrWriteProtection.Error(),
+		},
+		"deposit insufficient gas": {
+			caller:      noRoleAddr,
+			input:       func() []byte { return precompile.PackSponsorDeposit(big.NewInt(30)) },
+			suppliedGas: precompile.SponsorDepositGasCost - 1,
+			expectedErr: vmerrs.ErrOutOfGas.Error(),
+		},
+		"withdraw": {
+			caller:      sponsorAddr,
+			input:       func() []byte { return precompile.PackSponsorWithdraw(big.NewInt(60)) },
+			suppliedGas: precompile.SponsorWithdrawGasCost,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *state.StateDB) {
+				assert.Equal(t, big.NewInt(40), precompile.GetSponsorDeposit(state, sponsorAddr))
+				assert.Equal(t, big.NewInt(1060), state.GetBalance(sponsorAddr))
+				assert.Equal(t, big.NewInt(40), state.GetBalance(precompile.GasSponsorAddress))
+			},
+		},
+		"withdraw more than deposit fails": {
+			caller:      sponsorAddr,
+			input:       func() []byte { return precompile.PackSponsorWithdraw(big.NewInt(101)) },
+			suppliedGas: precompile.SponsorWithdrawGasCost,
+			expectedErr: precompile.ErrInsufficientSponsorDeposit.Error(),
+		},
+		"sponsor sender from enabled address": {
+			caller:      sponsorAddr,
+			input:       func() []byte { return precompile.PackSetSponsoredSender(senderAddr, true) },
+			suppliedGas: precompile.SetSponsoredGasCost,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *state.StateDB) {
+				sponsor, ok := precompile.GetSponsorOf(state, senderAddr, nil)
+				assert.True(t, ok)
+				assert.Equal(t, sponsorAddr, sponsor)
+			},
+		},
+		"sponsor target from no role fails": {
+			caller:      noRoleAddr,
+			input:       func() []byte { return precompile.PackSetSponsoredTarget(noRoleAddr, true) },
+			suppliedGas: precompile.SetSponsoredGasCost,
+			expectedErr: precompile.ErrCannotSponsor.Error(),
+		},
+		"sponsor sender without deposit fails": {
+			caller:      adminAddr,
+			input:       func() []byte { return precompile.PackSetSponsoredSender(senderAddr, true) },
+			suppliedGas: precompile.SetSponsoredGasCost,
+			expectedErr: precompile.ErrInsufficientSponsorDeposit.Error(),
+		},
+		"sponsor target of another sponsor fails": {
+			caller: otherSponsorAddr,
+			input:  func() []byte { return precompile.PackSetSponsoredTarget(targetAddr, true) },
+			preCondition: func(t *testing.T, state *state.StateDB) {
+				precompile.SetGasSponsorAllowListStatus(state, otherSponsorAddr, precompile.AllowListEnabled)
+				state.SetBalance(otherSponsorAddr, initialBalance)
+				runGasSponsor(t, state, otherSponsorAddr, precompile.PackSponsorDeposit(initialDeposit))
+			},
+			suppliedGas: precompile.SetSponsoredGasCost,
+			expectedErr: precompile.ErrAlreadySponsored.Error(),
+		},
+		"sponsor target of sponsor with empty deposit": {
+			caller: otherSponsorAddr,
+			input:  func() []byte { return precompile.PackSetSponsoredTarget(targetAddr, true) },
+			preCondition: func(t *testing.T, state *state.StateDB) {
+				precompile.SetGasSponsorAllowListStatus(state, otherSponsorAddr, precompile.AllowListEnabled)
+				state.SetBalance(otherSponsorAddr, initialBalance)
+				runGasSponsor(t, state, otherSponsorAddr, precompile.PackSponsorDeposit(initialDeposit))
+				runGasSponsor(t, state, sponsorAddr, precompile.PackSponsorWithdraw(initialDeposit))
+			},
+			suppliedGas: precompile.SetSponsoredGasCost,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *state.StateDB) {
+				sponsor, ok := precompile.GetSponsorOf(state, senderAddr, &targetAddr)
+				assert.True(t, ok)
+				assert.Equal(t, otherSponsorAddr, sponsor)
+			},
+		},
+		"unsponsor target of another sponsor fails": {
+			caller: otherSponsorAddr,
+			input:  func() []byte { return precompile.PackSetSponsoredTarget(targetAddr, false) },
+			preCondition: func(t *testing.T, state *state.StateDB) {
+				precompile.SetGasSponsorAllowListStatus(state, otherSponsorAddr, precompile.AllowListEnabled)
+			},
+			suppliedGas: precompile.SetSponsoredGasCost,
+			expectedErr: precompile.ErrNotSponsor.Error(),
+		},
+		"unsponsor target of another sponsor from admin": {
+			caller:      adminAddr,
+			input:       func() []byte { return precompile.PackSetSponsoredTarget(targetAddr, false) },
+			suppliedGas: precompile.SetSponsoredGasCost,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *state.StateDB) {
+				_, ok := precompile.GetSponsorOf(state, senderAddr, &targetAddr)
+				assert.False(t, ok)
+			},
+		},
+		"unsponsor target": {
+			caller:      sponsorAddr,
+			input:       func() []byte { return precompile.PackSetSponsoredTarget(targetAddr, false) },
+			suppliedGas: precompile.SetSponsoredGasCost,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *state.StateDB) {
+				_, ok := precompile.GetSponsorOf(state, senderAddr, &targetAddr)
+				assert.False(t, ok)
+			},
+		},
+		"sponsor target with invalid bool fails": {
+			caller: sponsorAddr,
+			input: func() []byte {
+				input := precompile.PackSetSponsoredTarget(targetAddr, true)
+				input[len(input)-1] = 2
+				return input
+			},
+			suppliedGas: precompile.SetSponsoredGasCost,
+			expectedErr: "invalid bool input",
+		},
+		"sponsor target with invalid input length fails": {
+			caller: sponsorAddr,
+			input: func() []byte {
+				return precompile.PackSetSponsoredTarget(targetAddr, true)[:40]
+			},
+			suppliedGas: precompile.SetSponsoredGasCost,
+			expectedErr: "invalid input length",
+		},
+		"set spending limit": {
+			caller:      sponsorAddr,
+			input:       func() []byte { return precompile.PackSetSpendingLimit(senderAddr, big.NewInt(10)) },
+			suppliedGas: precompile.SetSpendingLimitGasCost,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *state.StateDB) {
+				assert.Equal(t, big.NewInt(10), precompile.GetRemainingAllowance(state, sponsorAddr, senderAddr))
+			},
+		},
+		"set default spending limit": {
+			caller:      sponsorAddr,
+			input:       func() []byte { return precompile.PackSetSpendingLimit(common.Address{}, big.NewInt(20)) },
+			suppliedGas: precompile.SetSpendingLimitGasCost,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *state.StateDB) {
+				// The limit of [senderAddr] takes precedence over the default limit.
+				assert.Equal(t, senderLimit, precompile.GetRemainingAllowance(state, sponsorAddr, senderAddr))
+				assert.Equal(t, big.NewInt(20), precompile.GetRemainingAllowance(state, sponsorAddr, noRoleAddr))
+			},
+		},
+		"remove spending limit": {
+			caller:      sponsorAddr,
+			input:       func() []byte { return precompile.PackSetSpendingLimit(senderAddr, common.Big0) },
+			suppliedGas: precompile.SetSpendingLimitGasCost,
+			expectedRes: []byte{},
+			assertState: func(t *testing.T, state *state.StateDB) {
+				assert.Equal(t, initialDeposit, precompile.GetRemainingAllowance(state, sponsorAddr, senderAddr))
+			},
+		},
+		"set spending limit readOnly fails": {
+			caller:      sponsorAddr,
+			input:       func() []byte { return precompile.PackSetSpendingLimit(senderAddr, big.NewInt(10)) },
+			suppliedGas: precompile.SetSpendingLimitGasCost,
+			readOnly:    true,
+			expectedErr: vmerrs.ErrWriteProtection.Error(),
+		},
+		"read deposit": {
+			caller:      noRoleAddr,
+			input:       func() []byte { return precompile.PackDepositOf(sponsorAddr) },
+			suppliedGas: precompile.DepositOfGasCost,
+			readOnly:    true,
+			expectedRes: common.BigToHash(initialDeposit).Bytes(),
+		},
+		"read sponsor of target": {
+			caller:      noRoleAddr,
+			input:       func() []byte { return precompile.PackSponsorOf(senderAddr, targetAddr) },
+			suppliedGas: precompile.SponsorOfGasCost,
+			readOnly:    true,
+			expectedRes: sponsorAddr.Hash().Bytes(),
+		},
+		"read sponsor of unsponsored transaction": {
+			caller:      noRoleAddr,
+			input:       func() []byte { return precompile.PackSponsorOf(senderAddr, noRoleAddr) },
+			suppliedGas: precompile.SponsorOfGasCost,
+			readOnly:    true,
+			expectedRes: common.Hash{}.Bytes(),
+		},
+		"read remaining allowance": {
+			caller:      noRoleAddr,
+			input:       func() []byte { return precompile.PackRemainingAllowance(sponsorAddr, senderAddr) },
+			suppliedGas: precompile.RemainingAllowanceGasCost,
+			readOnly:    true,
+			expectedRes: common.BigToHash(senderLimit).Bytes(),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			db := rawdb.NewMemoryDatabase()
+			state, err := state.New(common.Hash{}, state.NewDatabase(db), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Set up the state so that each address has the expected permissions at the start.
+			precompile.SetGasSponsorAllowListStatus(state, adminAddr, precompile.AllowListAdmin)
+			precompile.SetGasSponsorAllowListStatus(state, sponsorAddr, precompile.AllowListEnabled)
+			state.SetBalance(sponsorAddr, new(big.Int).Add(initialBalance, initialDeposit))
+			state.SetBalance(noRoleAddr, initialBalance)
+			accessibleState := &mockAccessibleState{state: state, blockContext: &mockBlockContext{blockNumber: testBlockNumber}}
+			for _, input := range [][]byte{
+				precompile.PackSponsorDeposit(initialDeposit),
+				precompile.PackSetSponsoredTarget(targetAddr, true),
+				precompile.PackSetSpendingLimit(senderAddr, senderLimit),
+			} {
+				runGasSponsor(t, state, sponsorAddr, input)
+			}
+			if test.preCondition != nil {
+				test.preCondition(t, state)
+			}
+
+			ret, remainingGas, err := precompile.GasSponsorPrecompile.Run(accessibleState, test.caller, precompile.GasSponsorAddress, test.input(), test.suppliedGas, test.readOnly)
+			if len(test.expectedErr) != 0 {
+				assert.ErrorContains(t, err, test.expectedErr)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, uint64(0), remainingGas)
+			assert.Equal(t, test.expectedRes, ret)
+
+			if test.assertState != nil {
+				test.assertState(t, state)
+			}
+		})
+	}
+}
+
+// runGasSponsor runs the gas sponsor precompile with [input] from [caller] on [state] with
+// unlimited gas and fails the test if it returns an error.
+func runGasSponsor(t *testing.T, state *state.StateDB, caller common.Address, input []byte) {
+	t.Helper()
+	accessibleState := &mockAccessibleState{state: state, blockContext: &mockBlockContext{blockNumber: testBlockNumber}}
+	if _, _, err := precompile.GasSponsorPrecompile.Run(accessibleState, caller, precompile.GasSponsorAddress, input, math.MaxUint64, false); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetGasSponsor(t *testing.T) {
+	targetSponsor := common.HexToAddress("0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC")
+	senderSponsor := common.HexToAddress("0xAb5801a7D398351b8bE11C439e05C5B3259aeC9B")
+	sender := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	target := common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567")
+	otherAddr := common.HexToAddress("0x0987654321000000000000000000000000000000")
+
+	state, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := precompile.NewGasSponsorConfig(common.Big0, nil)
+	config.EnabledAddresses = []common.Address{targetSponsor, senderSponsor}
+	assert.NoError(t, config.Verify())
+	config.Configure(&mockChainConfig{}, state, &mockBlockContext{blockNumber: testBlockNumber})
+
+	run := func(caller common.Address, input []byte) {
+		_, _, err := precompile.GasSponsorPrecompile.Run(&mockAccessibleState{state: state, blockContext: &mockBlockContext{blockNumber: testBlockNumber}}, caller, precompile.GasSponsorAddress, input, math.MaxUint64, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	state.SetBalance(targetSponsor, big.NewInt(100))
+	state.SetBalance(senderSponsor, big.NewInt(100))
+	run(targetSponsor, precompile.PackSponsorDeposit(big.NewInt(50)))
+	run(targetSponsor, precompile.PackSetSponsoredTarget(target, true))
+	run(senderSponsor, precompile.PackSponsorDeposit(big.NewInt(100)))
+	run(senderSponsor, precompile.PackSetSponsoredSender(sender, true))
+	run(senderSponsor, precompile.PackSetSpendingLimit(sender, big.NewInt(80)))
+
+	for name, test := range map[string]struct {
+		from            common.Address
+		to              *common.Address
+		gasCost         int64
+		expectedSponsor common.Address
+		expectSponsored bool
+	}{
+		"target sponsor takes precedence":                  {from: sender, to: &target, gasCost: 50, expectedSponsor: targetSponsor, expectSponsored: true},
+		"sender sponsor when target sponsor cannot afford": {from: sender, to: &target, gasCost: 51, expectedSponsor: senderSponsor, expectSponsored: true},
+		"sender sponsor of other target":                   {from: sender, to: &otherAddr, gasCost: 80, expectedSponsor: senderSponsor, expectSponsored: true},
+		"sender sponsor of contract creation":              {from: sender, to: nil, gasCost: 1, expectedSponsor: senderSponsor, expectSponsored: true},
+		"sender spending limit exceeded":                   {from: sender, to: &otherAddr, gasCost: 81},
+		"target sponsor of other sender":                   {from: otherAddr, to: &target, gasCost: 50, expectedSponsor: targetSponsor, expectSponsored: true},
+		"target sponsor deposit exceeded":                  {from: otherAddr, to: &target, gasCost: 51},
+		"unsponsored transaction":                          {from: otherAddr, to: &otherAddr, gasCost: 0},
+	} {
+		t.Run(name, func(t *testing.T) {
+			sponsor, ok := precompile.GetGasSponsor(state, test.from, test.to, big.NewInt(test.gasCost))
+			assert.Equal(t, test.expectSponsored, ok)
+			assert.Equal(t, test.expectedSponsor, sponsor)
+		})
+	}
+	assert.Equal(t, big.NewInt(80), precompile.GetGasSponsorAllowance(state, sender, &target))
+	assert.Equal(t, big.NewInt(50), precompile.GetGasSponsorAllowance(state, otherAddr, &target))
+	assert.Equal(t, new(big.Int), precompile.GetGasSponsorAllowance(state, otherAddr, &otherAddr))
+
+	// Charging the sponsor counts against the spending limit of the sender until it is refunded.
+	precompile.ChargeGasSponsor(state, senderSponsor, sender, big.NewInt(30))
+	assert.Equal(t, big.NewInt(70), precompile.GetSponsorDeposit(state, senderSponsor))
+	assert.Equal(t, big.NewInt(50), precompile.GetRemainingAllowance(state, senderSponsor, sender))
+	assert.Equal(t, big.NewInt(120), state.GetBalance(precompile.GasSponsorAddress))
+	precompile.RefundGasSponsor(state, senderSponsor, sender, big.NewInt(10))
+	assert.Equal(t, big.NewInt(80), precompile.GetSponsorDeposit(state, senderSponsor))
+	assert.Equal(t, big.NewInt(60), precompile.GetRemainingAllowance(state, senderSponsor, sender))
+	assert.Equal(t, big.NewInt(130), state.GetBalance(precompile.GasSponsorAddress))
+}
+
+func TestGasSponsorDisableRefundsDeposits(t *testing.T) {
+	sponsorAddr := common.HexToAddress("0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC")
+	otherSponsorAddr := common.HexToAddress("0xAb5801a7D398351b8bE11C439e05C5B3259aeC9B")
+
+	state, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chainConfig := *params.TestChainConfig
+	chainConfig.GenesisPrecompiles = params.Precompiles{precompile.GasSponsorConfigKey: precompile.NewGasSponsorConfig(common.Big0, nil)}
+	chainConfig.PrecompileUpgrades = []params.PrecompileUpgrade{{Config: precompile.NewDisableGasSponsorConfig(big.NewInt(10))}}
+	chainConfig.CheckConfigurePrecompiles(nil, &mockBlockContext{blockNumber: common.Big0}, state)
+
+	run := func(caller common.Address, input []byte) {
+		_, _, err := precompile.GasSponsorPrecompile.Run(&mockAccessibleState{state: state, blockContext: &mockBlockContext{blockNumber: testBlockNumber}}, caller, precompile.GasSponsorAddress, input, math.MaxUint64, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	state.SetBalance(sponsorAddr, big.NewInt(100))
+	state.SetBalance(otherSponsorAddr, big.NewInt(100))
+	run(sponsorAddr, precompile.PackSponsorDeposit(big.NewInt(30)))
+	run(sponsorAddr, precompile.PackSponsorDeposit(big.NewInt(20)))
+	run(otherSponsorAddr, precompile.PackSponsorDeposit(big.NewInt(70)))
+	run(otherSponsorAddr, precompile.PackSponsorWithdraw(big.NewInt(10)))
+	assert.Equal(t, big.NewInt(50), state.GetBalance(sponsorAddr))
+	assert.Equal(t, big.NewInt(40), state.GetBalance(otherSponsorAddr))
+	assert.Equal(t, big.NewInt(110), state.GetBalance(precompile.GasSponsorAddress))
+
+	// Disabling the gas sponsor refunds the deposits before its balance is cleared.
+	chainConfig.CheckConfigurePrecompiles(big.NewInt(5), &mockBlockContext{blockNumber: testBlockNumber, timestamp: 10}, state)
+	assert.Equal(t, big.NewInt(100), state.GetBalance(sponsorAddr))
+	assert.Equal(t, big.NewInt(100), state.GetBalance(otherSponsorAddr))
+	assert.Equal(t, new(big.Int), state.GetBalance(precompile.GasSponsorAddress))
+	assert.True(t, state.HasSuicided(precompile.GasSponsorAddress))
+}
+
+func TestPrecompileLogs(t *testing.T) {
+	adminAddr := common.HexToAddress("0x1234567890000000000000000000000000000000")
+	noRoleAddr := common.HexToAddress("0x0987654321000000000000000000000000000000")
+	mintAmount := big.NewInt(100)
+
+	type test struct {
+		precompileAddr common.Address
+		contract       precompile.StatefulPrecompiledContract
+		input          func() []byte
+		gasCost        uint64
+		// extraLogGasCost is any additional gas charged to emit the log besides the log itself.
+		extraLogGasCost uint64
+		preCondition    func(t *testing.T, state *state.StateDB)
+		expectedLog     func(state *state.StateDB) ([]common.Hash, []byte)
+	}
+
+	for name, test := range map[string]test{
+		"set role": {
+			precompileAddr: precompile.TxAllowListAddress,
+			contract:       precompile.TxAllowListPrecompile,
+			input: func() []byte {
+				input, err := precompile.PackModifyAllowList(noRoleAddr, precompile.AllowListEnabled)
+				if err != nil {
+					panic(err)
+				}
+				return input
+			},
+			gasCost: precompile.ModifyAllowListGasCost,
+			expectedLog: func(*state.StateDB) ([]common.Hash, []byte) {
+				return precompile.PackRoleSetEvent(precompile.AllowListEnabled, noRoleAddr, adminAddr)
+			},
+		},
+		"mint": {
+			precompileAddr: precompile.ContractNativeMinterAddress,
+			contract:       precompile.ContractNativeMinterPrecompile,
+			input: func() []byte {
+				input, err := precompile.PackMintInput(noRoleAddr, mintAmount)
+				if err != nil {
+					panic(err)
+				}
+				return input
+			},
+			gasCost: precompile.MintGasCost,
+			expectedLog: func(*state.StateDB) ([]common.Hash, []byte) {
+				return precompile.PackNativeCoinMintedEvent(adminAddr, noRoleAddr, mintAmount)
+			},
+		},
+		"set fee config": {
+			precompileAddr: precompile.FeeConfigManagerAddress,
+			contract:       precompile.FeeConfigManagerPrecompile,
+			input: func() []byte {
+				input, err := precompile.PackSetFeeConfig(testFeeConfig)
+				if err != nil {
+					panic(err)
+				}
+				return input
+			},
+			gasCost:         precompile.SetFeeConfigGasCost,
+			extraLogGasCost: precompile.GetFeeConfigGasCost,
+			expectedLog: func(state *state.StateDB) ([]common.Hash, []byte) {
+				return precompile.PackFeeConfigChangedEvent(adminAddr, precompile.GetStoredFeeConfig(state), testFeeConfig)
+			},
+		},
+		"pause": {
+			precompileAddr: precompile.PauserAddress,
+			contract:       precompile.PauserPrecompile,
+			input:          precompile.PackPause,
+			gasCost:        precompile.PauseGasCost,
+			expectedLog: func(*state.StateDB) ([]common.Hash, []byte) {
+				return precompile.PackPausedEvent(adminAddr)
+			},
+		},
+		"sponsor target": {
+			precompileAddr: precompile.GasSponsorAddress,
+			contract:       precompile.GasSponsorPrecompile,
+			input:          func() []byte { return precompile.PackSetSponsoredTarget(noRoleAddr, true) },
+			gasCost:        precompile.SetSponsoredGasCost,
+			preCondition: func(t *testing.T, state *state.StateDB) {
+				state.SetBalance(adminAddr, mintAmount)
+				runGasSponsor(t, state, adminAddr, precompile.PackSponsorDeposit(mintAmount))
+			},
+			expectedLog: func(*state.StateDB) ([]common.Hash, []byte) {
+				return []common.Hash{precompile.SponsoredTargetSetEventID, adminAddr.Hash(), noRoleAddr.Hash()}, common.BigToHash(common.Big1).Bytes()
+			},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			for _, precompileLogs := range []bool{false, true} {
+				db := rawdb.NewMemoryDatabase()
+				state, err := state.New(common.Hash{}, state.NewDatabase(db), nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				state.SetState(test.precompileAddr, adminAddr.Hash(), common.Hash(precompile.AllowListAdmin))
+				if test.preCondition != nil {
+					test.preCondition(t, state)
+				}
+
+				accessibleState := &mockAccessibleState{
+					state:        state,
+					blockContext: &mockBlockContext{blockNumber: testBlockNumber},
+					chainConfig:  mockChainConfig{precompileLogs: precompileLogs},
+				}
+				topics, data := test.expectedLog(state)
+				suppliedGas := test.gasCost
+				if precompileLogs {
+					suppliedGas += test.extraLogGasCost + precompile.LogGas + uint64(len(topics))*precompile.LogTopicGas + uint64(len(data))*precompile.LogDataGas
+				}
+
+				_, remainingGas, err := test.contract.Run(accessibleState, adminAddr, test.precompileAddr, test.input(), suppliedGas, false)
+				if err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, uint64(0), remainingGas)
+
+				logs := state.Logs()
+				if !precompileLogs {
+					assert.Empty(t, logs)
+					continue
+				}
+				if assert.Len(t, logs, 1) {
+					assert.Equal(t, test.precompileAddr, logs[0].Address)
+					assert.Equal(t, topics, logs[0].Topics)
+					assert.Equal(t, data, logs[0].Data)
+					assert.Equal(t, testBlockNumber.Uint64(), logs[0].BlockNumber)
+				}
+
+				// Emitting the log should fail if there is not enough gas to pay for it.
+				_, _, err = test.contract.Run(accessibleState, adminAddr, test.precompileAddr, test.input(), suppliedGas-1, false)
+				assert.ErrorIs(t, err, vmerrs.ErrOutOfGas)
+			}
+		})
+	}
+}
+
+func TestAllowListRolesRun(t *testing.T) {
+	adminAddr := common.HexToAddress("0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC")
+	managerAddr := common.HexToAddress("0x1234567890000000000000000000000000000000")
+	enabledAddr := common.HexToAddress("0x0987654321000000000000000000000000000000")
+	noRoleAddr := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	const blockTimestamp = 100
+
+	packModifyAllowList := func(address common.Address, role precompile.AllowListRole) func() []byte {
+		return func() []byte {
+			input, err := precompile.PackModifyAllowList(address, role)
+			if err != nil {
+				panic(err)
+			}
+			return input
+		}
+	}
+
+	type test struct {
+		caller         common.Address
+		input          func() []byte
+		suppliedGas    uint64
+		allowListRoles bool
+		// setup is run with a block timestamp of 0 before [input] is run
+		setup func(t *testing.T, run func(caller common.Address, input []byte))
+
+		expectedRes []byte
+		expectedErr string
+
+		// assertState is called with the allow list status of an address at a given timestamp
+		assertState func(t *testing.T, status func(address common.Address, timestamp uint64) precompile.AllowListRole)
+	}
+
+	for name, test := range map[string]test{
+		"admin set manager": {
+			caller:         adminAddr,
+			input:          packModifyAllowList(noRoleAddr, precompile.AllowListManager),
+			suppliedGas:    precompile.ModifyAllowListGasCost,
+			allowListRoles: true,
+			expectedRes:    []byte{},
+			assertState: func(t *testing.T, status func(common.Address, uint64) precompile.AllowListRole) {
+				assert.Equal(t, precompile.AllowListManager, status(noRoleAddr, blockTimestamp))
+			},
+		},
+		"set manager before upgrade": {
+			caller:      adminAddr,
+			input:       packModifyAllowList(noRoleAddr, precompile.AllowListManager),
+			suppliedGas: precompile.ModifyAllowListGasCost,
+			expectedErr: "invalid function selector",
+		},
+		"manager set enabled": {
+			caller:         managerAddr,
+			input:          packModifyAllowList(noRoleAddr, precompile.AllowListEnabled),
+			suppliedGas:    precompile.ModifyAllowListGasCost + precompile.ReadAllowListGasCost,
+			allowListRoles: true,
+			expectedRes:    []byte{},
+			assertState: func(t *testing.T, status func(common.Address, uint64) precompile.AllowListRole) {
+				assert.Equal(t, precompile.AllowListEnabled, status(noRoleAddr, blockTimestamp))
+			},
+		},
+		"manager set none": {
+			caller:         managerAddr,
+			input:          packModifyAllowList(enabledAddr, precompile.AllowListNoRole),
+			suppliedGas:    precompile.ModifyAllowListGasCost + precompile.ReadAllowListGasCost,
+			allowListRoles: true,
+			expectedRes:    []byte{},
+			assertState: func(t *testing.T, status func(common.Address, uint64) precompile.AllowListRole) {
+				assert.Equal(t, precompile.AllowListNoRole, status(enabledAddr, blockTimestamp))
+			},
+		},
+		"manager cannot set admin": {
+			caller:         managerAddr,
+			input:          packModifyAllowList(noRoleAddr, precompile.AllowListAdmin),
+			suppliedGas:    precompile.ModifyAllowListGasCost + precompile.ReadAllowListGasCost,
+			allowListRoles: true,
+			expectedErr:    precompile.ErrCannotModifyAllowList.Error(),
+		},
+		"manager cannot set manager": {
+			caller:         managerAddr,
+			input:          packModifyAllowList(noRoleAddr, precompile.AllowListManager),
+			suppliedGas:    precompile.ModifyAllowListGasCost + precompile.ReadAllowListGasCost,
+			allowListRoles: true,
+			expectedErr:    precompile.ErrCannotModifyAllowList.Error(),
+		},
+		"manager cannot remove admin": {
+			caller:         managerAddr,
+			input:          packModifyAllowList(adminAddr, precompile.AllowListNoRole),
+			suppliedGas:    precompile.ModifyAllowListGasCost + precompile.ReadAllowListGasCost,
+			allowListRoles: true,
+			expectedErr:    precompile.ErrCannotModifyAllowList.Error(),
+		},
+		"enabled cannot set enabled": {
+			caller:         enabledAddr,
+			input:          packModifyAllowList(noRoleAddr, precompile.AllowListEnabled),
+			suppliedGas:    precompile.ModifyAllowListGasCost + precompile.ReadAllowListGasCost,
+			allowListRoles: true,
+			expectedErr:    precompile.ErrCannotModifyAllowList.Error(),
+		},
+		"admin set enabled until": {
+			caller: adminAddr,
+			input: func() []byte {
+				return precompile.PackSetEnabledUntil(noRoleAddr, 200)
+			},
+			suppliedGas:    precompile.ModifyAllowListWithExpiryGasCost,
+			allowListRoles: true,
+			expectedRes:    []byte{},
+			assertState: func(t *testing.T, status func(common.Address, uint64) precompile.AllowListRole) {
+				assert.Equal(t, precompile.AllowListEnabled, status(noRoleAddr, 199))
+				assert.Equal(t, precompile.AllowListNoRole, status(noRoleAddr, 200))
+			},
+		},
+		"manager set enabled until": {
+			caller: managerAddr,
+			input: func() []byte {
+				return precompile.PackSetEnabledUntil(noRoleAddr, 200)
+			},
+			suppliedGas:    precompile.ModifyAllowListWithExpiryGasCost + precompile.ReadAllowListGasCost,
+			allowListRoles: true,
+			expectedRes:    []byte{},
+			assertState: func(t *testing.T, status func(common.Address, uint64) precompile.AllowListRole) {
+				assert.Equal(t, precompile.AllowListEnabled, status(noRoleAddr, 199))
+				assert.Equal(t, precompile.AllowListNoRole, status(noRoleAddr, 200))
+			},
+		},
+		"set enabled until expired": {
+			caller: adminAddr,
+			input: func() []byte {
+				return precompile.PackSetEnabledUntil(noRoleAddr, blockTimestamp)
+			},
+			suppliedGas:    precompile.ModifyAllowListWithExpiryGasCost,
+			allowListRoles: true,
+			expectedErr:    precompile.ErrInvalidAllowListExpiry.Error(),
+		},
+		"set enabled until before upgrade": {
+			caller: adminAddr,
+			input: func() []byte {
+				return precompile.PackSetEnabledUntil(noRoleAddr, 200)
+			},
+			suppliedGas: precompile.ModifyAllowListWithExpiryGasCost,
+			expectedErr: "invalid function selector",
+		},
+		"set enabled removes expiry": {
+			caller: adminAddr,
+			setup: func(t *testing.T, run func(common.Address, []byte)) {
+				run(adminAddr, precompile.PackSetEnabledUntil(noRoleAddr, 200))
+			},
+			input:          packModifyAllowList(noRoleAddr, precompile.AllowListEnabled),
+			suppliedGas:    precompile.ModifyAllowListGasCost,
+			allowListRoles: true,
+			expectedRes:    []byte{},
+			assertState: func(t *testing.T, status func(common.Address, uint64) precompile.AllowListRole) {
+				assert.Equal(t, precompile.AllowListEnabled, status(noRoleAddr, 200))
+			},
+		},
+		"read allow list after expiry": {
+			caller: noRoleAddr,
+			setup: func(t *testing.T, run func(common.Address, []byte)) {
+				run(adminAddr, precompile.PackSetEnabledUntil(enabledAddr, blockTimestamp))
+			},
+			input: func() []byte {
+				return precompile.PackReadAllowList(enabledAddr)
+			},
+			suppliedGas:    precompile.ReadAllowListGasCost,
+			allowListRoles: true,
+			expectedRes:    common.Hash(precompile.AllowListNoRole).Bytes(),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			for _, precompileAddr := range []common.Address{
+				precompile.ContractDeployerAllowListAddress,
+				precompile.TxAllowListAddress,
+				precompile.ContractNativeMinterAddress,
+				precompile.FeeConfigManagerAddress,
+				precompile.RewardManagerAddress,
+				precompile.PauserAddress,
+				precompile.GasSponsorAddress,
+			} {
+				db := rawdb.NewMemoryDatabase()
+				state, err := state.New(common.Hash{}, state.NewDatabase(db), nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				state.SetState(precompileAddr, adminAddr.Hash(), common.Hash(precompile.AllowListAdmin))
+				state.SetState(precompileAddr, managerAddr.Hash(), common.Hash(precompile.AllowListManager))
+				state.SetState(precompileAddr, enabledAddr.Hash(), common.Hash(precompile.AllowListEnabled))
+
+				newAccessibleState := func(timestamp uint64, allowListRoles bool) *mockAccessibleState {
+					return &mockAccessibleState{
+						state:        state,
+						blockContext: &mockBlockContext{blockNumber: testBlockNumber, timestamp: timestamp},
+						chainConfig:  mockChainConfig{allowListRoles: allowListRoles},
+					}
+				}
+				precompileContract := allowListContract(precompileAddr)
+				if test.setup != nil {
+					test.setup(t, func(caller common.Address, input []byte) {
+						_, _, err := precompileContract.Run(newAccessibleState(0, true), caller, precompileAddr, input, math.MaxUint64, false)
+						assert.NoError(t, err)
+					})
+				}
+
+				ret, remainingGas, err := precompileContract.Run(newAccessibleState(blockTimestamp, test.allowListRoles), test.caller, precompileAddr, test.input(), test.suppliedGas, false)
+				if len(test.expectedErr) != 0 {
+					assert.ErrorContains(t, err, test.expectedErr, "precompile %s", precompileAddr)
+					continue
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				assert.Equal(t, uint64(0), remainingGas)
+				assert.Equal(t, test.expectedRes, ret)
+
+				if test.assertState != nil {
+					test.assertState(t, func(address common.Address, timestamp uint64) precompile.AllowListRole {
+						ret, _, err := precompileContract.Run(newAccessibleState(timestamp, true), noRoleAddr, precompileAddr, precompile.PackReadAllowList(address), precompile.ReadAllowListGasCost, true)
+						assert.NoError(t, err)
+						return precompile.AllowListRole(common.BytesToHash(ret))
+					})
+				}
+			}
+		})
+	}
+}
+
+// allowListContract returns the stateful precompiled contract of the allow list precompile at [precompileAddr].
+func allowListContract(precompileAddr common.Address) precompile.StatefulPrecompiledContract {
+	switch precompileAddr {
+	case precompile.ContractDeployerAllowListAddress:
+		return precompile.ContractDeployerAllowListPrecompile
+	case precompile.TxAllowListAddress:
+		return precompile.TxAllowListPrecompile
+	case precompile.ContractNativeMinterAddress:
+		return precompile.ContractNativeMinterPrecompile
+	case precompile.FeeConfigManagerAddress:
+		return precompile.FeeConfigManagerPrecompile
+	case precompile.RewardManagerAddress:
+		return precompile.RewardManagerPrecompile
+	case precompile.PauserAddress:
+		return precompile.PauserPrecompile
+	case precompile.GasSponsorAddress:
+		return precompile.GasSponsorPrecompile
+	default:
+		panic(fmt.Sprintf("no allow list precompile at %s", precompileAddr))
+	}
+}
+
+// TestAllowListExpiryRevokesAccess checks that a time-bound grant stops permitting the
+// use of the precompile once it expires.
+func TestAllowListExpiryRevokesAccess(t *testing.T) {
+	adminAddr := common.HexToAddress("0x8db97C7cEcE249c2b98bDC0226Cc4C2A57BF52FC")
+	minterAddr := common.HexToAddress("0xF60C45c607D0f41687c94C314d300f483661E13a")
+	mintAmount := big.NewInt(100)
+
+	db := rawdb.NewMemoryDatabase()
+	state, err := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	precompile.SetContractNativeMinterStatus(state, adminAddr, precompile.AllowListAdmin)
+	atTimestamp := func(timestamp uint64) *mockAccessibleState {
+		return &mockAccessibleState{
+			state:        state,
+			blockContext: &mockBlockContext{blockNumber: testBlockNumber, timestamp: timestamp},
+			chainConfig:  mockChainConfig{allowListRoles: true},
+		}
+	}
+
+	_, _, err = precompile.ContractNativeMinterPrecompile.Run(atTimestamp(0), adminAddr, precompile.ContractNativeMinterAddress, precompile.PackSetEnabledUntil(minterAddr, 10), precompile.ModifyAllowListWithExpiryGasCost, false)
+	assert.NoError(t, err)
+	assert.Equal(t, precompile.AllowListEnabled, precompile.GetContractNativeMinterStatus(state, minterAddr, big.NewInt(9)))
+	assert.Equal(t, precompile.AllowListNoRole, precompile.GetContractNativeMinterStatus(state, minterAddr, big.NewInt(10)))
+
+	mintInput, err := precompile.PackMintInput(minterAddr, mintAmount)
+	assert.NoError(t, err)
+	_, _, err = precompile.ContractNativeMinterPrecompile.Run(atTimestamp(9), minterAddr, precompile.ContractNativeMinterAddress, mintInput, precompile.MintGasCost, false)
+	assert.NoError(t, err)
+	assert.Equal(t, mintAmount, state.GetBalance(minterAddr))
+
+	_, _, err = precompile.ContractNativeMinterPrecompile.Run(atTimestamp(10), minterAddr, precompile.ContractNativeMinterAddress, mintInput, precompile.MintGasCost, false)
+	assert.ErrorIs(t, err, precompile.ErrCannotMint)
+	assert.Equal(t, mintAmount, state.GetBalance(minterAddr))
+}
@@ -1546,8 +1546,10 @@ func TestStatefulPrecompiles(t *testing.T, create func(db ethdb.Database, chainC
 	genesisBalance := new(big.Int).Mul(big.NewInt(1000000), big.NewInt(params.Ether))
 	config := *params.TestChainConfig
 	// Set all of the required config parameters
-	config.ContractDeployerAllowListConfig = precompile.NewContractDeployerAllowListConfig(big.NewInt(0), []common.Address{addr1})
-	config.FeeManagerConfig = precompile.NewFeeManagerConfig(big.NewInt(0), []common.Address{addr1})
+	config.GenesisPrecompiles = params.Precompiles{
+		precompile.ContractDeployerAllowListConfigKey: precompile.NewContractDeployerAllowListConfig(big.NewInt(0), []common.Address{addr1}),
+		precompile.FeeConfigManagerConfigKey:          precompile.NewFeeManagerConfig(big.NewInt(0), []common.Address{addr1}),
+	}
 	gspec := &Genesis{
 		Config: &config,
 		Alloc:  GenesisAlloc{addr1: {Balance: genesisBalance}},
@@ -1609,22 +1611,22 @@ func TestStatefulPrecompiles(t *testing.T, create func(db ethdb.Database, chainC
 				gen.AddTx(signedTx)
 			},
 			verifyState: func(sdb *state.StateDB) error {
-				res := precompile.GetContractDeployerAllowListStatus(sdb, addr1)
+				res := precompile.GetContractDeployerAllowListStatus(sdb, addr1, common.Big0)
 				if precompile.AllowListAdmin != res {
 					return fmt.Errorf("unexpected allow list status for addr1 %s, expected %s", res, precompile.AllowListAdmin)
 				}
-				res = precompile.GetContractDeployerAllowListStatus(sdb, addr2)
+				res = precompile.GetContractDeployerAllowListStatus(sdb, addr2, common.Big0)
 				if precompile.AllowListAdmin != res {
 					return fmt.Errorf("unexpected allow list status for addr2 %s, expected %s", res, precompile.AllowListAdmin)
 				}
 				return nil
 			},
 			verifyGenesis: func(sdb *state.StateDB) {
-				res := precompile.GetContractDeployerAllowListStatus(sdb, addr1)
+				res := precompile.GetContractDeployerAllowListStatus(sdb, addr1, common.Big0)
 				if precompile.AllowListAdmin != res {
 					t.Fatalf("unexpected allow list status for addr1 %s, expected %s", res, precompile.AllowListAdmin)
 				}
-				res = precompile.GetContractDeployerAllowListStatus(sdb, addr2)
+				res = precompile.GetContractDeployerAllowListStatus(sdb, addr2, common.Big0)
 				if precompile.AllowListNoRole != res {
 					t.Fatalf("unexpected allow list status for addr2 %s, expected %s", res, precompile.AllowListNoRole)
 				}
@@ -1655,7 +1657,7 @@ func TestStatefulPrecompiles(t *testing.T, create func(db ethdb.Database, chainC
 				gen.AddTx(signedTx)
 			},
 			verifyState: func(sdb *state.StateDB) error {
-				res := precompile.GetFeeConfigManagerStatus(sdb, addr1)
+				res := precompile.GetFeeConfigManagerStatus(sdb, addr1, common.Big0)
 				assert.Equal(precompile.AllowListAdmin, res)
 
 				storedConfig := precompile.GetStoredFeeConfig(sdb)
@@ -1667,7 +1669,7 @@ func TestStatefulPrecompiles(t *testing.T, create func(db ethdb.Database, chainC
 				return nil
 			},
 			verifyGenesis: func(sdb *state.StateDB) {
-				res := precompile.GetFeeConfigManagerStatus(sdb, addr1)
+				res := precompile.GetFeeConfigManagerStatus(sdb, addr1, common.Big0)
 				assert.Equal(precompile.AllowListAdmin, res)
 
 				feeConfig, _, err := blockchain.GetFeeConfigAt(blockchain.Genesis().Header())
@@ -351,7 +351,41 @@ func (l *txList) Filter(costLimit *big.Int, gasLimit uint64) (types.Transactions
 	removed := l.txs.Filter(func(tx *types.Transaction) bool {
 		return tx.Gas() > gasLimit || tx.Cost().Cmp(costLimit) > 0
 	})
+	return l.filterInvalids(removed)
+}
+
+// FilterSponsored is like Filter, except that only the value of transactions for
+// which [sponsored] returns true is checked against [costLimit], since a sponsor
+// pays for their gas.
+//
+// Since whether a transaction is sponsored depends on the state of its sponsor
+// rather than its sender, the cached costcap cannot be used to skip the filtering.
+// Instead, the costcap is reset to the highest cost of the remaining transactions.
+func (l *txList) FilterSponsored(costLimit *big.Int, gasLimit uint64, sponsored func(*types.Transaction) bool) (types.Transactions, types.Transactions) {
+	l.gascap = gasLimit
+
+	// Filter out all the transactions above the account's funds
+	removed := l.txs.Filter(func(tx *types.Transaction) bool {
+		if tx.Gas() > gasLimit {
+			return true
+		}
+		if sponsored(tx) {
+			return tx.Value().Cmp(costLimit) > 0
+		}
+		return tx.Cost().Cmp(costLimit) > 0
+	})
+	l.costcap = new(big.Int)
+	for _, tx := range l.txs.items {
+		if cost := tx.Cost(); l.costcap.Cmp(cost) < 0 {
+			l.costcap = cost
+		}
+	}
+	return l.filterInvalids(removed)
+}
 
+// filterInvalids returns [removed] together with the transactions invalidated by
+// removing them, which are also removed from the list if it is strict.
+func (l *txList) filterInvalids(removed types.Transactions) (types.Transactions, types.Transactions) {
 	if len(removed) == 0 {
 		return nil, nil
 	}
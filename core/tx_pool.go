@@ -47,6 +47,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/prque"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
+	"golang.org/x/time/rate"
 )
 
 const (
@@ -100,6 +101,14 @@ var (
 	// than some meaningful limit a user might use. This is not a consensus error
 	// making the transaction invalid, rather a DOS protection.
 	ErrOversizedData = errors.New("oversized data")
+
+	// ErrSenderRateLimited is returned if a remote transaction is received from
+	// a sender that exceeds its admission rate.
+	ErrSenderRateLimited = errors.New("sender rate limit exceeded")
+
+	// ErrSenderSlotsExceeded is returned if a remote transaction is received
+	// from a sender that already occupies its maximum share of the pool.
+	ErrSenderSlotsExceeded = errors.New("sender exceeds its share of the txpool")
 )
 
 var (
@@ -128,6 +137,10 @@ var (
 	invalidTxMeter     = metrics.NewRegisteredMeter("txpool/invalid", nil)
 	underpricedTxMeter = metrics.NewRegisteredMeter("txpool/underpriced", nil)
 	overflowedTxMeter  = metrics.NewRegisteredMeter("txpool/overflowed", nil)
+	// senderRateLimitedTxMeter and senderOverflowedTxMeter count how many remote
+	// transactions are rejected due to the per-sender fairness limits.
+	senderRateLimitedTxMeter = metrics.NewRegisteredMeter("txpool/sender/ratelimited", nil)
+	senderOverflowedTxMeter  = metrics.NewRegisteredMeter("txpool/sender/overflowed", nil)
 	// throttleTxMeter counts how many transactions are rejected due to too-many-changes between
 	// txpool reorgs.
 	throttleTxMeter = metrics.NewRegisteredMeter("txpool/throttle", nil)
@@ -183,6 +196,10 @@ type TxPoolConfig struct {
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+
+	AccountRateLimit float64 // Maximum number of remote transactions admitted per second per account (0 = unlimited)
+	AccountRateBurst int     // Maximum number of remote transactions admitted at once per account above [AccountRateLimit]
+	AccountMaxShare  float64 // Maximum fraction of [GlobalSlots] a single remote account may occupy (0 = unlimited)
 }
 
 // DefaultTxPoolConfig contains the default configurations for the transaction
@@ -238,6 +255,18 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		log.Warn("Sanitizing invalid txpool lifetime", "provided", conf.Lifetime, "updated", DefaultTxPoolConfig.Lifetime)
 		conf.Lifetime = DefaultTxPoolConfig.Lifetime
 	}
+	if conf.AccountRateLimit < 0 {
+		log.Warn("Sanitizing invalid txpool account rate limit", "provided", conf.AccountRateLimit, "updated", 0)
+		conf.AccountRateLimit = 0
+	}
+	if conf.AccountRateLimit > 0 && conf.AccountRateBurst < 1 {
+		log.Warn("Sanitizing invalid txpool account rate burst", "provided", conf.AccountRateBurst, "updated", conf.AccountSlots)
+		conf.AccountRateBurst = int(conf.AccountSlots)
+	}
+	if conf.AccountMaxShare < 0 || conf.AccountMaxShare > 1 {
+		log.Warn("Sanitizing invalid txpool account max share", "provided", conf.AccountMaxShare, "updated", 0)
+		conf.AccountMaxShare = 0
+	}
 	return conf
 }
 
@@ -264,6 +293,7 @@ type TxPool struct {
 	istanbul bool // Fork indicator whether we are in the istanbul stage.
 	eip2718  bool // Fork indicator whether we are using EIP-2718 type transactions.
 	eip1559  bool // Fork indicator whether we are using EIP-1559 type transactions.
+	durango  bool // Fork indicator whether we are in the durango stage.
 
 	currentHead *types.Header
 	// [currentState] is the state of the blockchain head. It is reset whenever
@@ -272,6 +302,9 @@ type TxPool struct {
 	// [currentStateLock] is required to allow concurrent access to address nonces
 	// and balances during reorgs and gossip handling.
 	currentStateLock sync.Mutex
+	// [sponsorUsage] is the allowance of sponsors consumed by the pooled
+	// transactions as of the last reorg, and is protected by [currentStateLock].
+	sponsorUsage *sponsorUsage
 
 	pendingNonces *txNoncer // Pending state tracking virtual nonces
 	currentMaxGas uint64    // Current gas limit for transaction caps
@@ -285,6 +318,8 @@ type TxPool struct {
 	all     *txLookup                    // All transactions to allow lookups
 	priced  *txPricedList                // All transactions sorted by price
 
+	senderLimiters map[common.Address]*senderLimiter // Admission rate limiters of remote senders
+
 	chainHeadCh         chan ChainHeadEvent
 	chainHeadSub        event.Subscription
 	reqResetCh          chan *txpoolResetRequest
@@ -319,6 +354,8 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		pending:             make(map[common.Address]*txList),
 		queue:               make(map[common.Address]*txList),
 		beats:               make(map[common.Address]time.Time),
+		senderLimiters:      make(map[common.Address]*senderLimiter),
+		sponsorUsage:        newSponsorUsage(),
 		all:                 newTxLookup(),
 		chainHeadCh:         make(chan ChainHeadEvent, chainHeadChanSize),
 		reqResetCh:          make(chan *txpoolResetRequest),
@@ -429,6 +466,7 @@ func (pool *TxPool) loop() {
 					queuedEvictionMeter.Mark(int64(len(list)))
 				}
 			}
+			pool.pruneSenderLimiters()
 			pool.mu.Unlock()
 
 		// Handle local transaction journal rotation
@@ -514,6 +552,11 @@ func (pool *TxPool) SetMinFee(minFee *big.Int) {
 	pool.minimumFee = minFee
 }
 
+// Config returns the sanitized configuration of the transaction pool.
+func (pool *TxPool) Config() TxPoolConfig {
+	return pool.config
+}
+
 // Nonce returns the next nonce of an account, with all transactions executable
 // by the pool already applied on top.
 func (pool *TxPool) Nonce(addr common.Address) uint64 {
@@ -649,6 +692,51 @@ func (pool *TxPool) Locals() []common.Address {
 	return pool.locals.flatten()
 }
 
+// AddLocalAccounts marks [addrs] as local accounts, exempting their
+// transactions from pricing constraints and eviction. Transactions from these
+// accounts that are already in the pool are migrated to the local set.
+func (pool *TxPool) AddLocalAccounts(addrs []common.Address) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	added := false
+	for _, addr := range addrs {
+		if pool.locals.contains(addr) {
+			continue
+		}
+		log.Info("Setting new local account", "address", addr)
+		pool.locals.add(addr)
+		added = true
+	}
+	if added {
+		pool.priced.Removed(pool.all.RemoteToLocals(pool.locals))
+	}
+}
+
+// RemoveLocalAccounts stops treating [addrs] as local accounts. Transactions
+// from these accounts that are already in the pool are migrated to the remote
+// set, losing their exemption from pricing constraints and eviction.
+func (pool *TxPool) RemoveLocalAccounts(addrs []common.Address) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	removed := newAccountSet(pool.signer)
+	for _, addr := range addrs {
+		if !pool.locals.contains(addr) {
+			continue
+		}
+		log.Info("Removing local account", "address", addr)
+		pool.locals.remove(addr)
+		removed.add(addr)
+	}
+	if removed.empty() {
+		return
+	}
+	for _, tx := range pool.all.LocalToRemotes(removed) {
+		pool.priced.Put(tx, false)
+	}
+}
+
 // local retrieves all currently known local transactions, grouped by origin
 // account and sorted by nonce. The returned transaction set is a copy and can be
 // freely modified by calling code.
@@ -670,8 +758,8 @@ func (pool *TxPool) checkTxState(from common.Address, tx *types.Transaction) err
 	pool.currentStateLock.Lock()
 	defer pool.currentStateLock.Unlock()
 
-	// cost == V + GP * GL
-	if balance, cost := pool.currentState.GetBalance(from), tx.Cost(); balance.Cmp(cost) < 0 {
+	// cost == V + GP * GL, or only V if a sponsor pays for the gas
+	if balance, cost := pool.currentState.GetBalance(from), pool.senderCost(from, tx); balance.Cmp(cost) < 0 {
 		return fmt.Errorf("%w: address %s have (%d) want (%d)", ErrInsufficientFunds, from.Hex(), balance, cost)
 	}
 
@@ -684,15 +772,139 @@ func (pool *TxPool) checkTxState(from common.Address, tx *types.Transaction) err
 
 	// If the tx allow list is enabled, return an error if the from address is not allow listed.
 	headTimestamp := big.NewInt(int64(pool.currentHead.Time))
-	if pool.chainconfig.IsTxAllowList(headTimestamp) {
-		txAllowListRole := precompile.GetTxAllowListStatus(pool.currentState, from)
+	if pool.chainconfig.IsPrecompileEnabled(precompile.TxAllowListAddress, headTimestamp) {
+		txAllowListRole := precompile.GetTxAllowListStatus(pool.currentState, from, headTimestamp)
 		if !txAllowListRole.IsEnabled() {
 			return fmt.Errorf("%w: %s", precompile.ErrSenderAddressNotAllowListed, from)
 		}
 	}
+
+	// If the pauser is enabled, return an error if the chain is paused and the tx is not exempt.
+	if pool.chainconfig.IsPrecompileEnabled(precompile.PauserAddress, headTimestamp) {
+		if err := precompile.CheckPaused(pool.currentState, from, tx.To(), headTimestamp); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// sponsorSender identifies the spending of a sponsor on the gas of a sender.
+type sponsorSender struct {
+	sponsor common.Address
+	sender  common.Address
+}
+
+// sponsorUsage tracks the allowance of sponsors consumed by the gas of pooled
+// transactions, which is not charged in the current state yet. Like the balance
+// of a sender, the allowance of a sponsor must cover all of the transactions it
+// pays for.
+type sponsorUsage struct {
+	bySponsor map[common.Address]*big.Int
+	bySender  map[sponsorSender]*big.Int
+}
+
+func newSponsorUsage() *sponsorUsage {
+	return &sponsorUsage{
+		bySponsor: make(map[common.Address]*big.Int),
+		bySender:  make(map[sponsorSender]*big.Int),
+	}
+}
+
+// spent returns the amount [sponsor] pays for pooled transactions in total and
+// for pooled transactions from [sender].
+func (u *sponsorUsage) spent(sponsor common.Address, sender common.Address) (*big.Int, *big.Int) {
+	bySponsor, bySender := u.bySponsor[sponsor], u.bySender[sponsorSender{sponsor, sender}]
+	if bySponsor == nil {
+		bySponsor = common.Big0
+	}
+	if bySender == nil {
+		bySender = common.Big0
+	}
+	return bySponsor, bySender
+}
+
+// charge records that [sponsor] pays [amount] for a pooled transaction from [sender].
+func (u *sponsorUsage) charge(sponsor common.Address, sender common.Address, amount *big.Int) {
+	bySponsor, bySender := u.spent(sponsor, sender)
+	u.bySponsor[sponsor] = new(big.Int).Add(bySponsor, amount)
+	u.bySender[sponsorSender{sponsor, sender}] = new(big.Int).Add(bySender, amount)
+}
+
+// gasSponsor returns the sponsor that would pay for the gas of [tx] from [from] in the
+// current state, once the allowance in [usage] is consumed by other transactions.
+// Returns false if the sender pays for the gas of [tx].
+// Assumes that the caller holds the currentState.
+func (pool *TxPool) gasSponsor(usage *sponsorUsage, from common.Address, tx *types.Transaction) (common.Address, bool) {
+	if !pool.chainconfig.IsPrecompileEnabled(precompile.GasSponsorAddress, new(big.Int).SetUint64(pool.currentHead.Time)) {
+		return common.Address{}, false
+	}
+	gasCost := new(big.Int).Sub(tx.Cost(), tx.Value())
+	for _, sponsor := range precompile.GetSponsorCandidates(pool.currentState, from, tx.To()) {
+		spentBySponsor, spentOnSender := usage.spent(sponsor, from)
+		if precompile.GetRemainingAllowanceAfter(pool.currentState, sponsor, from, spentBySponsor, spentOnSender).Cmp(gasCost) >= 0 {
+			return sponsor, true
+		}
+	}
+	return common.Address{}, false
+}
+
+// senderCost returns the most that [tx] may cost [from] in the current state, which is only the
+// value of [tx] if a sponsor pays for its gas on top of the pooled transactions it pays for.
+// Assumes that the caller holds the currentState.
+func (pool *TxPool) senderCost(from common.Address, tx *types.Transaction) *big.Int {
+	if _, sponsored := pool.gasSponsor(pool.sponsorUsage, from, tx); sponsored {
+		return tx.Value()
+	}
+	return tx.Cost()
+}
+
+// pendingSponsorUsage returns the allowance of sponsors consumed by the pending
+// transactions in the current state.
+// Assumes that the caller holds the currentState.
+func (pool *TxPool) pendingSponsorUsage() *sponsorUsage {
+	usage := newSponsorUsage()
+	if !pool.chainconfig.IsPrecompileEnabled(precompile.GasSponsorAddress, new(big.Int).SetUint64(pool.currentHead.Time)) {
+		return usage
+	}
+	for addr, list := range pool.pending {
+		for _, tx := range list.Flatten() {
+			if sponsor, ok := pool.gasSponsor(usage, addr, tx); ok {
+				usage.charge(sponsor, addr, new(big.Int).Sub(tx.Cost(), tx.Value()))
+			}
+		}
+	}
+	return usage
+}
+
+// filterList removes all transactions from [list] of [addr] that are too costly
+// for the current balance of [addr] or exceed the current max gas, returning the
+// removed and the invalidated transactions, as in [txList.Filter].
+// The gas of the remaining transactions paid for by sponsors is charged to [usage],
+// so the transactions are only sponsored up to the allowance left by the
+// transactions charged to [usage] before.
+func (pool *TxPool) filterList(addr common.Address, list *txList, usage *sponsorUsage) (types.Transactions, types.Transactions) {
+	balance := pool.currentState.GetBalance(addr)
+	if !pool.chainconfig.IsPrecompileEnabled(precompile.GasSponsorAddress, new(big.Int).SetUint64(pool.currentHead.Time)) {
+		return list.Filter(balance, pool.currentMaxGas)
+	}
+	// Decide which transactions are sponsored in nonce order, since the
+	// allowance is consumed by the transactions executed first.
+	sponsored := make(map[common.Hash]struct{})
+	for _, tx := range list.Flatten() {
+		if tx.Gas() > pool.currentMaxGas || tx.Value().Cmp(balance) > 0 {
+			continue
+		}
+		if sponsor, ok := pool.gasSponsor(usage, addr, tx); ok {
+			usage.charge(sponsor, addr, new(big.Int).Sub(tx.Cost(), tx.Value()))
+			sponsored[tx.Hash()] = struct{}{}
+		}
+	}
+	return list.FilterSponsored(balance, pool.currentMaxGas, func(tx *types.Transaction) bool {
+		_, ok := sponsored[tx.Hash()]
+		return ok
+	})
+}
+
 // validateTx checks whether a transaction is valid according to the consensus
 // rules and adheres to some heuristic limits of the local node (price and size).
 func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
@@ -747,8 +959,13 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 		return err
 	}
 
+	// Check whether the init code size has been exceeded.
+	if pool.durango && tx.To() == nil && len(tx.Data()) > params.MaxInitCodeSize {
+		return fmt.Errorf("%w: code size %v limit %v", ErrMaxInitCodeSizeExceeded, len(tx.Data()), params.MaxInitCodeSize)
+	}
+
 	// Ensure the transaction has more gas than the basic tx fee.
-	intrGas, err := IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, true, pool.istanbul)
+	intrGas, err := IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, true, pool.istanbul, pool.durango)
 	if err != nil {
 		return err
 	}
@@ -765,7 +982,10 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 // If a newly added transaction is marked as local, its sending account will be
 // be added to the allowlist, preventing any associated transaction from being dropped
 // out of the pool due to pricing constraints.
-func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err error) {
+//
+// Transactions reinjected after a reorg are marked with [reinject] and are not
+// subject to the per-sender limits, since they were already admitted once.
+func (pool *TxPool) add(tx *types.Transaction, local bool, reinject bool) (replaced bool, err error) {
 	// If the transaction is already known, discard it
 	hash := tx.Hash()
 	if pool.all.Get(hash) != nil {
@@ -783,6 +1003,21 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 		invalidTxMeter.Mark(1)
 		return false, err
 	}
+	// Enforce the per-sender fairness limits on remote transactions. The rate
+	// token is only reserved here and is given back if the transaction ends up
+	// not being admitted.
+	if !isLocal && !reinject {
+		var release func()
+		if release, err = pool.checkSenderLimits(tx); err != nil {
+			log.Trace("Discarding transaction exceeding sender limits", "hash", hash, "err", err)
+			return false, err
+		}
+		defer func() {
+			if err != nil {
+				release()
+			}
+		}()
+	}
 	// If the transaction pool is full, discard underpriced transactions
 	if uint64(pool.all.Slots()+numSlots(tx)) > pool.config.GlobalSlots+pool.config.GlobalQueue {
 		// If the new transaction is underpriced, don't accept it
@@ -865,6 +1100,86 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 	return replaced, nil
 }
 
+// senderLimiter tracks the admission rate of a remote sender.
+type senderLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// checkSenderLimits returns an error if admitting the remote transaction [tx]
+// would exceed the admission rate or the share of the pool allowed for its
+// sender. Replacements of pooled transactions do not count towards the share
+// of the pool, since they do not take up additional slots.
+//
+// The returned function gives back the rate token spent on [tx] and must be
+// called if [tx] is not admitted to the pool.
+//
+// The caller must hold pool.mu.
+func (pool *TxPool) checkSenderLimits(tx *types.Transaction) (func(), error) {
+	from, _ := types.Sender(pool.signer, tx) // already validated
+
+	if maxShare := pool.config.AccountMaxShare; maxShare > 0 {
+		// A sender is always allowed the slots guaranteed by [AccountSlots].
+		limit := uint64(maxShare * float64(pool.config.GlobalSlots))
+		if limit < pool.config.AccountSlots {
+			limit = pool.config.AccountSlots
+		}
+		var (
+			count    int
+			replaces bool
+		)
+		if list := pool.pending[from]; list != nil {
+			count += list.Len()
+			replaces = list.Overlaps(tx)
+		}
+		if list := pool.queue[from]; list != nil {
+			count += list.Len()
+			replaces = replaces || list.Overlaps(tx)
+		}
+		if !replaces && uint64(count) >= limit {
+			senderOverflowedTxMeter.Mark(1)
+			return nil, fmt.Errorf("%w: address %s has %d transactions, limit %d", ErrSenderSlotsExceeded, from, count, limit)
+		}
+	}
+
+	if pool.config.AccountRateLimit > 0 {
+		sl := pool.senderLimiters[from]
+		if sl == nil {
+			sl = &senderLimiter{limiter: rate.NewLimiter(rate.Limit(pool.config.AccountRateLimit), pool.config.AccountRateBurst)}
+			pool.senderLimiters[from] = sl
+		}
+		now := time.Now()
+		sl.lastSeen = now
+		// Reserve the token rather than spending it outright, so it can be
+		// returned if the transaction is rejected later on.
+		reservation := sl.limiter.ReserveN(now, 1)
+		if !reservation.OK() || reservation.DelayFrom(now) > 0 {
+			reservation.CancelAt(now)
+			senderRateLimitedTxMeter.Mark(1)
+			return nil, fmt.Errorf("%w: address %s", ErrSenderRateLimited, from)
+		}
+		return func() { reservation.CancelAt(now) }, nil
+	}
+	return func() {}, nil
+}
+
+// pruneSenderLimiters removes the rate limiters of senders that have been
+// inactive for long enough that their limiter is full again, since a new
+// limiter would behave identically.
+//
+// The caller must hold pool.mu.
+func (pool *TxPool) pruneSenderLimiters() {
+	if pool.config.AccountRateLimit <= 0 {
+		return
+	}
+	refill := time.Duration(float64(pool.config.AccountRateBurst) / pool.config.AccountRateLimit * float64(time.Second))
+	for addr, sl := range pool.senderLimiters {
+		if time.Since(sl.lastSeen) > refill {
+			delete(pool.senderLimiters, addr)
+		}
+	}
+}
+
 // enqueueTx inserts a new transaction into the non-executable transaction queue.
 //
 // Note, this method assumes the pool lock is held!
@@ -1030,7 +1345,7 @@ func (pool *TxPool) addTxs(txs []*types.Transaction, local, sync bool) []error {
 
 	// Process all the new transaction and merge any errors into the original slice
 	pool.mu.Lock()
-	newErrs, dirtyAddrs := pool.addTxsLocked(news, local)
+	newErrs, dirtyAddrs := pool.addTxsLocked(news, local, false)
 	pool.mu.Unlock()
 
 	nilSlot := 0
@@ -1050,12 +1365,13 @@ func (pool *TxPool) addTxs(txs []*types.Transaction, local, sync bool) []error {
 }
 
 // addTxsLocked attempts to queue a batch of transactions if they are valid.
+// [reinject] marks transactions returned to the pool after a reorg.
 // The transaction pool lock must be held.
-func (pool *TxPool) addTxsLocked(txs []*types.Transaction, local bool) ([]error, *accountSet) {
+func (pool *TxPool) addTxsLocked(txs []*types.Transaction, local bool, reinject bool) ([]error, *accountSet) {
 	dirty := newAccountSet(pool.signer)
 	errs := make([]error, len(txs))
 	for i, tx := range txs {
-		replaced, err := pool.add(tx, local)
+		replaced, err := pool.add(tx, local, reinject)
 		errs[i] = err
 		if err == nil && !replaced {
 			dirty.addTx(tx)
@@ -1105,6 +1421,17 @@ func (pool *TxPool) HasLocal(hash common.Hash) bool {
 	return pool.all.GetLocal(hash) != nil
 }
 
+// Hashes returns the hashes of all transactions in the pool, both pending and
+// queued.
+func (pool *TxPool) Hashes() []common.Hash {
+	hashes := make([]common.Hash, 0, pool.all.Count())
+	pool.all.Range(func(hash common.Hash, _ *types.Transaction, _ bool) bool {
+		hashes = append(hashes, hash)
+		return true
+	}, true, true)
+	return hashes
+}
+
 // removeTx removes a single transaction from the queue, moving all subsequent
 // transactions back to the future queue.
 func (pool *TxPool) removeTx(hash common.Hash, outofbound bool) {
@@ -1424,7 +1751,7 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 
 	// when we reset txPool we should explicitly check if fee struct for min base fee has changed
 	// so that we can correctly drop txs with < minBaseFee from tx pool.
-	if pool.chainconfig.IsFeeConfigManager(new(big.Int).SetUint64(newHead.Time)) {
+	if pool.chainconfig.IsPrecompileEnabled(precompile.FeeConfigManagerAddress, new(big.Int).SetUint64(newHead.Time)) {
 		feeConfig, _, err := pool.chain.GetFeeConfigAt(newHead)
 		if err != nil {
 			log.Error("Failed to get fee config state", "err", err, "root", newHead.Root)
@@ -1436,7 +1763,7 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	// Inject any transactions discarded due to reorgs
 	log.Debug("Reinjecting stale transactions", "count", len(reinject))
 	pool.chain.SenderCacher().Recover(pool.signer, reinject)
-	pool.addTxsLocked(reinject, false)
+	pool.addTxsLocked(reinject, false, true)
 
 	// Update all fork indicator by next pending block number.
 	next := new(big.Int).Add(newHead.Number, big.NewInt(1))
@@ -1445,6 +1772,11 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	isSubnetEVM := pool.chainconfig.IsSubnetEVM(new(big.Int).SetUint64(newHead.Time))
 	pool.eip2718 = isSubnetEVM
 	pool.eip1559 = isSubnetEVM
+
+	// Durango is decided from the wall-clock time on purpose, not from the
+	// timestamp of [newHead]: the timestamp of the next block is not known yet,
+	// and it is no earlier than the current time.
+	pool.durango = pool.chainconfig.IsDurango(big.NewInt(time.Now().Unix()))
 }
 
 // promoteExecutables moves transactions that have become processable from the
@@ -1457,6 +1789,10 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) []*types.Trans
 	// Track the promoted transactions to broadcast them at once
 	var promoted []*types.Transaction
 
+	// The queued transactions may only be sponsored with the allowance left by
+	// the pending ones
+	usage := pool.pendingSponsorUsage()
+
 	// Iterate over all accounts and promote any executable transactions
 	for _, addr := range accounts {
 		list := pool.queue[addr]
@@ -1471,7 +1807,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) []*types.Trans
 		}
 		log.Trace("Removed old queued transactions", "count", len(forwards))
 		// Drop all transactions that are too costly (low balance or out of gas)
-		drops, _ := list.Filter(pool.currentState.GetBalance(addr), pool.currentMaxGas)
+		drops, _ := pool.filterList(addr, list, usage)
 		for _, tx := range drops {
 			hash := tx.Hash()
 			pool.all.Remove(hash)
@@ -1513,6 +1849,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) []*types.Trans
 			delete(pool.beats, addr)
 		}
 	}
+	pool.sponsorUsage = usage
 	return promoted
 }
 
@@ -1659,6 +1996,9 @@ func (pool *TxPool) demoteUnexecutables() {
 	pool.currentStateLock.Lock()
 	defer pool.currentStateLock.Unlock()
 
+	// Track the allowance of sponsors consumed by the remaining pending transactions
+	usage := newSponsorUsage()
+
 	// Iterate over all accounts and demote any non-executable transactions
 	for addr, list := range pool.pending {
 		nonce := pool.currentState.GetNonce(addr)
@@ -1671,7 +2011,7 @@ func (pool *TxPool) demoteUnexecutables() {
 			log.Trace("Removed old pending transaction", "hash", hash)
 		}
 		// Drop all transactions that are too costly (low balance or out of gas), and queue any invalids back for later
-		drops, invalids := list.Filter(pool.currentState.GetBalance(addr), pool.currentMaxGas)
+		drops, invalids := pool.filterList(addr, list, usage)
 		for _, tx := range drops {
 			hash := tx.Hash()
 			log.Trace("Removed unpayable pending transaction", "hash", hash)
@@ -1708,6 +2048,7 @@ func (pool *TxPool) demoteUnexecutables() {
 			delete(pool.pending, addr)
 		}
 	}
+	pool.sponsorUsage = usage
 }
 
 func (pool *TxPool) startPeriodicFeeUpdate() {
@@ -1829,6 +2170,12 @@ func (as *accountSet) add(addr common.Address) {
 	as.cache = nil
 }
 
+// remove deletes an address from the set.
+func (as *accountSet) remove(addr common.Address) {
+	delete(as.accounts, addr)
+	as.cache = nil
+}
+
 // addTx adds the sender of tx into the set.
 func (as *accountSet) addTx(tx *types.Transaction) {
 	if addr, err := types.Sender(as.signer, tx); err == nil {
@@ -2018,6 +2365,23 @@ func (t *txLookup) RemoteToLocals(locals *accountSet) int {
 	return migrated
 }
 
+// LocalToRemotes migrates the local transactions sent by [remotes] into the
+// remote set, returning the migrated transactions.
+func (t *txLookup) LocalToRemotes(remotes *accountSet) types.Transactions {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var migrated types.Transactions
+	for hash, tx := range t.locals {
+		if remotes.containsTx(tx) {
+			t.remotes[hash] = tx
+			delete(t.locals, hash)
+			migrated = append(migrated, tx)
+		}
+	}
+	return migrated
+}
+
 // RemotesBelowTip finds all remote transactions below the given tip threshold.
 func (t *txLookup) RemotesBelowTip(threshold *big.Int) types.Transactions {
 	found := make(types.Transactions, 0, 128)
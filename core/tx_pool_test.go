@@ -44,6 +44,7 @@ import (
 	"github.com/ava-labs/subnet-evm/core/state"
 	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
 	"github.com/ava-labs/subnet-evm/trie"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -477,14 +478,14 @@ func TestTransactionChainFork(t *testing.T) {
 	resetState()
 
 	tx := transaction(0, 100000, key)
-	if _, err := pool.add(tx, false); err != nil {
+	if _, err := pool.add(tx, false, false); err != nil {
 		t.Error("didn't expect error", err)
 	}
 	pool.removeTx(tx.Hash(), true)
 
 	// reset the pool's internal state
 	resetState()
-	if _, err := pool.add(tx, false); err != nil {
+	if _, err := pool.add(tx, false, false); err != nil {
 		t.Error("didn't expect error", err)
 	}
 }
@@ -511,10 +512,10 @@ func TestTransactionDoubleNonce(t *testing.T) {
 	tx3, _ := types.SignTx(types.NewTransaction(0, common.Address{}, big.NewInt(100), 1000000, big.NewInt(1), nil), signer, key)
 
 	// Add the first two transaction, ensure higher priced stays only
-	if replace, err := pool.add(tx1, false); err != nil || replace {
+	if replace, err := pool.add(tx1, false, false); err != nil || replace {
 		t.Errorf("first transaction insert failed (%v) or reported replacement (%v)", err, replace)
 	}
-	if replace, err := pool.add(tx2, false); err != nil || !replace {
+	if replace, err := pool.add(tx2, false, false); err != nil || !replace {
 		t.Errorf("second transaction insert failed (%v) or not reported replacement (%v)", err, replace)
 	}
 	<-pool.requestPromoteExecutables(newAccountSet(signer, addr))
@@ -526,7 +527,7 @@ func TestTransactionDoubleNonce(t *testing.T) {
 	}
 
 	// Add the third transaction and ensure it's not saved (smaller price)
-	pool.add(tx3, false)
+	pool.add(tx3, false, false)
 	<-pool.requestPromoteExecutables(newAccountSet(signer, addr))
 	if pool.pending[addr].Len() != 1 {
 		t.Error("expected 1 pending transactions, got", pool.pending[addr].Len())
@@ -549,7 +550,7 @@ func TestTransactionMissingNonce(t *testing.T) {
 	addr := crypto.PubkeyToAddress(key.PublicKey)
 	testAddBalance(pool, addr, big.NewInt(100000000000000))
 	tx := transaction(1, 100000, key)
-	if _, err := pool.add(tx, false); err != nil {
+	if _, err := pool.add(tx, false, false); err != nil {
 		t.Error("didn't expect error", err)
 	}
 	if len(pool.pending) != 0 {
@@ -1364,6 +1365,311 @@ func TestTransactionPendingMinimumAllowance(t *testing.T) {
 	}
 }
 
+// Tests that a remote sender cannot occupy more than its share of the global
+// slots, while replacements and local transactions are still accepted.
+func TestTransactionSenderMaxShare(t *testing.T) {
+	t.Parallel()
+
+	// Create the pool to test the limit enforcement with
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := newTestBlockchain(statedb, 1000000, new(event.Feed))
+
+	config := testTxPoolConfig
+	config.AccountSlots = 4
+	config.GlobalSlots = 100
+	config.AccountMaxShare = 0.1
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	key, _ := crypto.GenerateKey()
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+
+	// Fill up the share of the sender with both pending and queued transactions
+	for i := uint64(0); i < 5; i++ {
+		if err := pool.addRemoteSync(transaction(i, 100000, key)); err != nil {
+			t.Fatalf("failed to add transaction %d: %v", i, err)
+		}
+	}
+	for i := uint64(20); i < 25; i++ {
+		if err := pool.addRemoteSync(transaction(i, 100000, key)); err != nil {
+			t.Fatalf("failed to add transaction %d: %v", i, err)
+		}
+	}
+	if err := pool.addRemoteSync(transaction(5, 100000, key)); !errors.Is(err, ErrSenderSlotsExceeded) {
+		t.Fatalf("expected %v, got %v", ErrSenderSlotsExceeded, err)
+	}
+	// Replacing a pooled transaction does not need an additional slot
+	if err := pool.addRemoteSync(pricedTransaction(0, 100000, big.NewInt(2), key)); err != nil {
+		t.Fatalf("failed to replace transaction: %v", err)
+	}
+	// Local transactions are exempt from the limit
+	if err := pool.AddLocal(transaction(5, 100000, key)); err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that remote transactions exceeding the admission rate of their sender
+// are rejected, without affecting other senders.
+func TestTransactionSenderRateLimit(t *testing.T) {
+	t.Parallel()
+
+	// Create the pool to test the limit enforcement with
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := newTestBlockchain(statedb, 1000000, new(event.Feed))
+
+	config := testTxPoolConfig
+	config.AccountRateLimit = 0.001
+	config.AccountRateBurst = 3
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	key1, _ := crypto.GenerateKey()
+	key2, _ := crypto.GenerateKey()
+	testAddBalance(pool, crypto.PubkeyToAddress(key1.PublicKey), big.NewInt(1000000000))
+	testAddBalance(pool, crypto.PubkeyToAddress(key2.PublicKey), big.NewInt(1000000000))
+
+	errs := pool.AddRemotesSync([]*types.Transaction{
+		transaction(0, 100000, key1),
+		transaction(1, 100000, key1),
+		transaction(2, 100000, key1),
+		transaction(3, 100000, key1),
+		transaction(0, 100000, key2),
+	})
+	for i, err := range errs {
+		switch i {
+		case 3:
+			if !errors.Is(err, ErrSenderRateLimited) {
+				t.Errorf("transaction %d: expected %v, got %v", i, ErrSenderRateLimited, err)
+			}
+		default:
+			if err != nil {
+				t.Errorf("transaction %d: unexpected error: %v", i, err)
+			}
+		}
+	}
+	pending, queued := pool.Stats()
+	if pending != 4 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 4)
+	}
+	if queued != 0 {
+		t.Fatalf("queued transactions mismatched: have %d, want %d", queued, 0)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that remote transactions rejected after the sender limits were checked
+// do not spend rate tokens, and that transactions reinjected after a reorg are
+// not rate limited at all.
+func TestTransactionSenderRateLimitAdmission(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := newTestBlockchain(statedb, 1000000, new(event.Feed))
+
+	config := testTxPoolConfig
+	config.AccountRateLimit = 0.001
+	config.AccountRateBurst = 2
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	key, _ := crypto.GenerateKey()
+	testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+
+	if err := pool.addRemoteSync(transaction(0, 100000, key)); err != nil {
+		t.Fatalf("failed to add remote transaction: %v", err)
+	}
+	// A replacement without a price bump is rejected and must not spend a token
+	if err := pool.addRemoteSync(transaction(0, 100001, key)); !errors.Is(err, ErrReplaceUnderpriced) {
+		t.Fatalf("expected %v, got %v", ErrReplaceUnderpriced, err)
+	}
+	if err := pool.addRemoteSync(transaction(1, 100000, key)); err != nil {
+		t.Fatalf("failed to add remote transaction: %v", err)
+	}
+	if err := pool.addRemoteSync(transaction(2, 100000, key)); !errors.Is(err, ErrSenderRateLimited) {
+		t.Fatalf("expected %v, got %v", ErrSenderRateLimited, err)
+	}
+	// Reinjected transactions bypass the exhausted limiter
+	pool.mu.Lock()
+	errs, _ := pool.addTxsLocked([]*types.Transaction{transaction(2, 100000, key)}, false, true)
+	pool.mu.Unlock()
+	if errs[0] != nil {
+		t.Fatalf("failed to reinject transaction: %v", errs[0])
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that only exempt transactions are accepted while the chain is paused.
+func TestTransactionPoolPaused(t *testing.T) {
+	t.Parallel()
+
+	adminKey, _ := crypto.GenerateKey()
+	otherKey, _ := crypto.GenerateKey()
+	adminAddr := crypto.PubkeyToAddress(adminKey.PublicKey)
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	pauserConfig := precompile.NewPauserConfig(common.Big0, []common.Address{adminAddr})
+	pauserConfig.Configure(&mockChainConfig{}, statedb, &mockBlockContext{blockNumber: common.Big0})
+	if _, _, err := precompile.PauserPrecompile.Run(&mockAccessibleState{state: statedb, blockContext: &mockBlockContext{blockNumber: common.Big0}}, adminAddr, precompile.PauserAddress, precompile.PackPause(), precompile.PauseGasCost, false); err != nil {
+		t.Fatal(err)
+	}
+	blockchain := newTestBlockchain(statedb, 1000000, new(event.Feed))
+
+	chainConfig := *params.TestChainConfig
+	chainConfig.GenesisPrecompiles = params.Precompiles{precompile.PauserConfigKey: pauserConfig}
+	pool := NewTxPool(testTxPoolConfig, &chainConfig, blockchain)
+	defer pool.Stop()
+
+	testAddBalance(pool, adminAddr, big.NewInt(1000000000))
+	testAddBalance(pool, crypto.PubkeyToAddress(otherKey.PublicKey), big.NewInt(1000000000))
+
+	unpause, _ := types.SignTx(types.NewTransaction(0, precompile.PauserAddress, big.NewInt(0), 100000, big.NewInt(1), precompile.PackUnpause()), types.HomesteadSigner{}, adminKey)
+	errs := pool.AddRemotesSync([]*types.Transaction{
+		transaction(0, 100000, otherKey),
+		unpause,
+		transaction(1, 100000, adminKey),
+	})
+	for i, err := range errs {
+		switch i {
+		case 1:
+			if err != nil {
+				t.Errorf("transaction %d: unexpected error: %v", i, err)
+			}
+		default:
+			if !errors.Is(err, precompile.ErrChainPaused) {
+				t.Errorf("transaction %d: expected %v, got %v", i, precompile.ErrChainPaused, err)
+			}
+		}
+	}
+	if pending, _ := pool.Stats(); pending != 1 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 1)
+	}
+}
+
+func TestTransactionPoolGasSponsor(t *testing.T) {
+	t.Parallel()
+
+	sponsorKey, _ := crypto.GenerateKey()
+	sponsoredKey, _ := crypto.GenerateKey()
+	otherKey, _ := crypto.GenerateKey()
+	sponsorAddr := crypto.PubkeyToAddress(sponsorKey.PublicKey)
+	sponsoredAddr := crypto.PubkeyToAddress(sponsoredKey.PublicKey)
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	gasSponsorConfig := precompile.NewGasSponsorConfig(common.Big0, []common.Address{sponsorAddr})
+	gasSponsorConfig.Configure(&mockChainConfig{}, statedb, &mockBlockContext{blockNumber: common.Big0})
+	statedb.SetBalance(sponsorAddr, big.NewInt(1000000000))
+	runGasSponsor := func(statedb *state.StateDB, input []byte) {
+		if _, _, err := precompile.GasSponsorPrecompile.Run(&mockAccessibleState{state: statedb, blockContext: &mockBlockContext{blockNumber: common.Big0}}, sponsorAddr, precompile.GasSponsorAddress, input, 1000000, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runGasSponsor(statedb, precompile.PackSponsorDeposit(big.NewInt(1000000000)))
+	runGasSponsor(statedb, precompile.PackSetSponsoredSender(sponsoredAddr, true))
+	blockchain := newTestBlockchain(statedb, 1000000, new(event.Feed))
+
+	chainConfig := *params.TestChainConfig
+	chainConfig.GenesisPrecompiles = params.Precompiles{precompile.GasSponsorConfigKey: gasSponsorConfig}
+	pool := NewTxPool(testTxPoolConfig, &chainConfig, blockchain)
+	defer pool.Stop()
+
+	// Both senders can only afford the value of their transactions, so only the transaction
+	// with sponsored gas is accepted.
+	testAddBalance(pool, sponsoredAddr, big.NewInt(100))
+	testAddBalance(pool, crypto.PubkeyToAddress(otherKey.PublicKey), big.NewInt(100))
+	errs := pool.AddRemotesSync([]*types.Transaction{
+		transaction(0, 100000, sponsoredKey),
+		transaction(0, 100000, otherKey),
+	})
+	if errs[0] != nil {
+		t.Errorf("sponsored transaction: unexpected error: %v", errs[0])
+	}
+	if !errors.Is(errs[1], ErrInsufficientFunds) {
+		t.Errorf("unsponsored transaction: expected %v, got %v", ErrInsufficientFunds, errs[1])
+	}
+
+	// The sponsored transaction stays pending while the sponsor can afford it.
+	pool.mu.Lock()
+	pool.demoteUnexecutables()
+	pool.mu.Unlock()
+	if pending, _ := pool.Stats(); pending != 1 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 1)
+	}
+
+	// Once the sponsor withdraws its deposit, the sender cannot afford the transaction.
+	pool.mu.Lock()
+	runGasSponsor(pool.currentState, precompile.PackSponsorWithdraw(big.NewInt(1000000000)))
+	pool.demoteUnexecutables()
+	pool.mu.Unlock()
+	if pending, _ := pool.Stats(); pending != 0 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 0)
+	}
+}
+
+// Tests that the pooled transactions of a sender are only sponsored jointly up to the
+// allowance of their sponsor.
+func TestTransactionPoolGasSponsorAllowance(t *testing.T) {
+	t.Parallel()
+
+	sponsorKey, _ := crypto.GenerateKey()
+	sponsoredKey, _ := crypto.GenerateKey()
+	sponsorAddr := crypto.PubkeyToAddress(sponsorKey.PublicKey)
+	sponsoredAddr := crypto.PubkeyToAddress(sponsoredKey.PublicKey)
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	gasSponsorConfig := precompile.NewGasSponsorConfig(common.Big0, []common.Address{sponsorAddr})
+	gasSponsorConfig.Configure(&mockChainConfig{}, statedb, &mockBlockContext{blockNumber: common.Big0})
+	statedb.SetBalance(sponsorAddr, big.NewInt(1000000000))
+	runGasSponsor := func(statedb *state.StateDB, input []byte) {
+		if _, _, err := precompile.GasSponsorPrecompile.Run(&mockAccessibleState{state: statedb, blockContext: &mockBlockContext{blockNumber: common.Big0}}, sponsorAddr, precompile.GasSponsorAddress, input, 1000000, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The deposit covers the gas of two transactions
+	runGasSponsor(statedb, precompile.PackSponsorDeposit(big.NewInt(250000)))
+	runGasSponsor(statedb, precompile.PackSetSponsoredSender(sponsoredAddr, true))
+	blockchain := newTestBlockchain(statedb, 1000000, new(event.Feed))
+
+	chainConfig := *params.TestChainConfig
+	chainConfig.GenesisPrecompiles = params.Precompiles{precompile.GasSponsorConfigKey: gasSponsorConfig}
+	pool := NewTxPool(testTxPoolConfig, &chainConfig, blockchain)
+	defer pool.Stop()
+
+	// The sender can only afford the value of its transactions, so the transaction
+	// exceeding the allowance is dropped when the transactions are promoted.
+	testAddBalance(pool, sponsoredAddr, big.NewInt(100))
+	pool.AddRemotesSync([]*types.Transaction{
+		transaction(0, 100000, sponsoredKey),
+		transaction(1, 100000, sponsoredKey),
+		transaction(2, 100000, sponsoredKey),
+	})
+	if pending, queued := pool.Stats(); pending != 2 || queued != 0 {
+		t.Fatalf("pending/queued transactions mismatched: have %d/%d, want 2/0", pending, queued)
+	}
+	// Further transactions are rejected, since the pending ones consume the allowance
+	if err := pool.addRemoteSync(transaction(2, 100000, sponsoredKey)); !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("expected %v, got %v", ErrInsufficientFunds, err)
+	}
+	pool.mu.Lock()
+	pool.demoteUnexecutables()
+	pool.mu.Unlock()
+	if pending, queued := pool.Stats(); pending != 2 || queued != 0 {
+		t.Fatalf("pending/queued transactions mismatched: have %d/%d, want 2/0", pending, queued)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // Tests that setting the transaction pool gas price to a higher value correctly
 // discards everything cheaper than that and moves any gapped transactions back
 // from the pending pool to the queue.
@@ -2611,3 +2917,40 @@ func BenchmarkPoolMultiAccountBatchInsert(b *testing.B) {
 		pool.AddRemotesSync([]*types.Transaction{tx})
 	}
 }
+
+// Tests that removing a local account migrates its transactions to the remote
+// set, and adding it back migrates them to the local set again.
+func TestTransactionPoolRemoveLocalAccounts(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, addr, big.NewInt(1000000))
+	if err := pool.AddLocal(transaction(0, 100000, key)); err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+	if locals, remotes := pool.all.LocalCount(), pool.all.RemoteCount(); locals != 1 || remotes != 0 {
+		t.Fatalf("local/remote transaction count mismatch: have %d/%d, want 1/0", locals, remotes)
+	}
+
+	pool.RemoveLocalAccounts([]common.Address{addr})
+	if pool.locals.contains(addr) {
+		t.Fatalf("expected %s to no longer be a local account", addr)
+	}
+	if locals, remotes := pool.all.LocalCount(), pool.all.RemoteCount(); locals != 0 || remotes != 1 {
+		t.Fatalf("local/remote transaction count mismatch: have %d/%d, want 0/1", locals, remotes)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+
+	pool.AddLocalAccounts([]common.Address{addr})
+	if locals, remotes := pool.all.LocalCount(), pool.all.RemoteCount(); locals != 1 || remotes != 0 {
+		t.Fatalf("local/remote transaction count mismatch: have %d/%d, want 1/0", locals, remotes)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
@@ -159,7 +159,8 @@ func init() {
 
 	// Ensure that this package will panic during init if there is a conflict present with the declared
 	// precompile addresses.
-	for _, k := range precompile.UsedAddresses {
+	for _, module := range precompile.RegisteredModules() {
+		k := module.Address
 		if _, ok := PrecompileAllNativeAddresses[k]; ok {
 			panic(fmt.Errorf("precompile address collides with existing native address: %s", k))
 		}
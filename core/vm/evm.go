@@ -43,7 +43,7 @@ import (
 
 var (
 	_ precompile.PrecompileAccessibleState = &EVM{}
-	_ precompile.BlockContext              = &BlockContext{}
+	_ precompile.BlockContext              = &precompileBlockContext{}
 )
 
 // IsProhibited returns true if [addr] is in the prohibited list of addresses which should
@@ -122,6 +122,21 @@ func (b *BlockContext) Timestamp() *big.Int {
 	return b.Time
 }
 
+// precompileBlockContext exposes a BlockContext to stateful precompiles.
+// The Coinbase and BaseFee methods cannot be defined on BlockContext itself
+// since they would collide with its fields.
+type precompileBlockContext struct {
+	*BlockContext
+}
+
+func (b *precompileBlockContext) Coinbase() common.Address {
+	return b.BlockContext.Coinbase
+}
+
+func (b *precompileBlockContext) BaseFee() *big.Int {
+	return b.BlockContext.BaseFee
+}
+
 // TxContext provides the EVM with information about a transaction.
 // All fields can change between transactions.
 type TxContext struct {
@@ -205,9 +220,24 @@ func (evm *EVM) GetStateDB() precompile.StateDB {
 	return evm.StateDB
 }
 
+// GetChainConfig returns the evm's ChainConfig
+func (evm *EVM) GetChainConfig() precompile.ChainConfig {
+	return evm.chainConfig
+}
+
 // GetBlockContext returns the evm's BlockContext
 func (evm *EVM) GetBlockContext() precompile.BlockContext {
-	return &evm.Context
+	return &precompileBlockContext{BlockContext: &evm.Context}
+}
+
+// GetTxOrigin returns the origin of the transaction being executed
+func (evm *EVM) GetTxOrigin() common.Address {
+	return evm.TxContext.Origin
+}
+
+// GetChainID returns the chain ID of the evm's ChainConfig
+func (evm *EVM) GetChainID() *big.Int {
+	return evm.chainConfig.ChainID
 }
 
 // Interpreter returns the current interpreter
@@ -496,8 +526,8 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 		return nil, common.Address{}, 0, vmerrs.ErrContractAddressCollision
 	}
 	// If the allow list is enabled, check that [evm.TxContext.Origin] has permission to deploy a contract.
-	if evm.chainRules.IsContractDeployerAllowListEnabled {
-		allowListRole := precompile.GetContractDeployerAllowListStatus(evm.StateDB, evm.TxContext.Origin)
+	if evm.chainRules.IsPrecompileEnabled(precompile.ContractDeployerAllowListAddress) {
+		allowListRole := precompile.GetContractDeployerAllowListStatus(evm.StateDB, evm.TxContext.Origin, evm.Context.Time)
 		if !allowListRole.IsEnabled() {
 			return nil, common.Address{}, 0, fmt.Errorf("tx.origin %s is not authorized to deploy a contract", evm.TxContext.Origin)
 		}
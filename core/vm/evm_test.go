@@ -0,0 +1,55 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestEVMPrecompileAccessibleState(t *testing.T) {
+	var (
+		coinbase = common.HexToAddress("0x0100000000000000000000000000000000000001")
+		origin   = common.HexToAddress("0x0200000000000000000000000000000000000002")
+	)
+	vmctx := BlockContext{
+		Coinbase:    coinbase,
+		BlockNumber: big.NewInt(7),
+		Time:        big.NewInt(100),
+		BaseFee:     big.NewInt(25_000_000_000),
+	}
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	evm := NewEVM(vmctx, TxContext{Origin: origin}, statedb, params.TestChainConfig, Config{})
+
+	blockContext := evm.GetBlockContext()
+	if number := blockContext.Number(); number.Cmp(vmctx.BlockNumber) != 0 {
+		t.Errorf("number mismatch: have %v, want %v", number, vmctx.BlockNumber)
+	}
+	if timestamp := blockContext.Timestamp(); timestamp.Cmp(vmctx.Time) != 0 {
+		t.Errorf("timestamp mismatch: have %v, want %v", timestamp, vmctx.Time)
+	}
+	if have := blockContext.Coinbase(); have != coinbase {
+		t.Errorf("coinbase mismatch: have %v, want %v", have, coinbase)
+	}
+	if baseFee := blockContext.BaseFee(); baseFee.Cmp(vmctx.BaseFee) != 0 {
+		t.Errorf("base fee mismatch: have %v, want %v", baseFee, vmctx.BaseFee)
+	}
+	if have := evm.GetTxOrigin(); have != origin {
+		t.Errorf("origin mismatch: have %v, want %v", have, origin)
+	}
+	if chainID := evm.GetChainID(); chainID.Cmp(params.TestChainConfig.ChainID) != 0 {
+		t.Errorf("chain ID mismatch: have %v, want %v", chainID, params.TestChainConfig.ChainID)
+	}
+
+	// The tx context is updated when the EVM is reused for the next transaction.
+	evm.Reset(TxContext{Origin: coinbase}, statedb)
+	if have := evm.GetTxOrigin(); have != coinbase {
+		t.Errorf("origin mismatch after reset: have %v, want %v", have, coinbase)
+	}
+}
@@ -310,6 +310,40 @@ func gasCreate2(evm *EVM, contract *Contract, stack *Stack, mem *Memory, memoryS
 	return gas, nil
 }
 
+func gasCreateEip3860(evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	gas, err := memoryGasCost(mem, memorySize)
+	if err != nil {
+		return 0, err
+	}
+	size, overflow := stack.Back(2).Uint64WithOverflow()
+	if overflow || size > params.MaxInitCodeSize {
+		return 0, vmerrs.ErrGasUintOverflow
+	}
+	// Since size <= params.MaxInitCodeSize, this multiplication cannot overflow
+	moreGas := params.InitCodeWordGas * toWordSize(size)
+	if gas, overflow = math.SafeAdd(gas, moreGas); overflow {
+		return 0, vmerrs.ErrGasUintOverflow
+	}
+	return gas, nil
+}
+
+func gasCreate2Eip3860(evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	gas, err := memoryGasCost(mem, memorySize)
+	if err != nil {
+		return 0, err
+	}
+	size, overflow := stack.Back(2).Uint64WithOverflow()
+	if overflow || size > params.MaxInitCodeSize {
+		return 0, vmerrs.ErrGasUintOverflow
+	}
+	// Since size <= params.MaxInitCodeSize, this multiplication cannot overflow
+	moreGas := (params.InitCodeWordGas + params.Keccak256WordGas) * toWordSize(size)
+	if gas, overflow = math.SafeAdd(gas, moreGas); overflow {
+		return 0, vmerrs.ErrGasUintOverflow
+	}
+	return gas, nil
+}
+
 func gasExpFrontier(evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
 	expByteLen := uint64((stack.data[stack.len()-2].BitLen() + 7) / 8)
 
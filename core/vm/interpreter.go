@@ -83,6 +83,8 @@ func NewEVMInterpreter(evm *EVM, cfg Config) *EVMInterpreter {
 	// If jump table was not initialised we set the default one.
 	if cfg.JumpTable == nil {
 		switch {
+		case evm.chainRules.IsDurango:
+			cfg.JumpTable = &durangoInstructionSet
 		case evm.chainRules.IsSubnetEVM:
 			cfg.JumpTable = &subnetEVMInstructionSet
 		case evm.chainRules.IsIstanbul:
@@ -75,7 +75,11 @@ func setDefaults(cfg *Config) {
 			IstanbulBlock:       new(big.Int),
 			MuirGlacierBlock:    new(big.Int),
 			NetworkUpgrades: params.NetworkUpgrades{
-				SubnetEVMTimestamp: new(big.Int),
+				SubnetEVMTimestamp:          new(big.Int),
+				PrecompileLogsTimestamp:     new(big.Int),
+				DurangoTimestamp:            new(big.Int),
+				AllowListRolesTimestamp:     new(big.Int),
+				NativeMinterSupplyTimestamp: new(big.Int),
 			},
 		}
 	}
@@ -129,6 +133,10 @@ func Execute(code, input []byte, cfg *Config) ([]byte, *state.StateDB, error) {
 	)
 	if rules := cfg.ChainConfig.AvalancheRules(vmenv.Context.BlockNumber, vmenv.Context.Time); rules.IsSubnetEVM {
 		cfg.State.PrepareAccessList(cfg.Origin, &address, vm.ActivePrecompiles(rules), nil)
+		// EIP-3651: the coinbase starts out warm
+		if rules.IsDurango {
+			cfg.State.AddAddressToAccessList(vmenv.Context.Coinbase)
+		}
 	}
 	cfg.State.CreateAccount(address)
 	// set the receiver's (the executing contract) code for execution.
@@ -161,6 +169,10 @@ func Create(input []byte, cfg *Config) ([]byte, common.Address, uint64, error) {
 	)
 	if rules := cfg.ChainConfig.AvalancheRules(vmenv.Context.BlockNumber, vmenv.Context.Time); rules.IsSubnetEVM {
 		cfg.State.PrepareAccessList(cfg.Origin, nil, vm.ActivePrecompiles(rules), nil)
+		// EIP-3651: the coinbase starts out warm
+		if rules.IsDurango {
+			cfg.State.AddAddressToAccessList(vmenv.Context.Coinbase)
+		}
 	}
 	// Call the code with the given configuration.
 	code, address, leftOverGas, err := vmenv.Create(
@@ -187,6 +199,10 @@ func Call(address common.Address, input []byte, cfg *Config) ([]byte, uint64, er
 
 	if rules := cfg.ChainConfig.AvalancheRules(vmenv.Context.BlockNumber, vmenv.Context.Time); rules.IsSubnetEVM {
 		statedb.PrepareAccessList(cfg.Origin, &address, vm.ActivePrecompiles(rules), nil)
+		// EIP-3651: the coinbase starts out warm
+		if rules.IsDurango {
+			statedb.AddAddressToAccessList(vmenv.Context.Coinbase)
+		}
 	}
 	// Call the code with the given configuration.
 	ret, leftOverGas, err := vmenv.Call(
@@ -365,7 +365,7 @@ func benchmarkNonModifyingCode(gas uint64, code []byte, name string, tracerCode
 	cfg.State, _ = state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
 	cfg.GasLimit = gas
 	if len(tracerCode) > 0 {
-		tracer, err := tracers.New(tracerCode, new(tracers.Context))
+		tracer, err := tracers.New(tracerCode, new(tracers.Context), nil)
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -712,3 +712,46 @@ func TestColdAccountAccessCost(t *testing.T) {
 		}
 	}
 }
+
+func TestDurango(t *testing.T) {
+	for i, tc := range []struct {
+		code []byte
+		step int
+		want uint64
+	}{
+		{ // PUSH0
+			code: []byte{byte(vm.PUSH0), byte(vm.POP)},
+			step: 0,
+			want: 2,
+		},
+		{ // BALANCE(COINBASE), the coinbase is warm
+			code: []byte{byte(vm.COINBASE), byte(vm.BALANCE), byte(vm.POP)},
+			step: 1,
+			want: 100,
+		},
+	} {
+		tracer := logger.NewStructLogger(nil)
+		if _, _, err := Execute(tc.code, nil, &Config{
+			Coinbase: common.HexToAddress("0xff"),
+			EVMConfig: vm.Config{
+				Debug:  true,
+				Tracer: tracer,
+			},
+		}); err != nil {
+			t.Fatalf("testcase %d, unexpected error: %v", i, err)
+		}
+		have := tracer.StructLogs()[tc.step].GasCost
+		if want := tc.want; have != want {
+			for ii, op := range tracer.StructLogs() {
+				t.Logf("%d: %v %d", ii, op.OpName(), op.GasCost)
+			}
+			t.Fatalf("testcase %d, gas report wrong, step %d, have %d want %d", i, tc.step, have, want)
+		}
+	}
+
+	// PUSH0 is an invalid opcode before Durango.
+	cfg := &Config{ChainConfig: params.TestPreSubnetEVMConfig}
+	if _, _, err := Execute([]byte{byte(vm.PUSH0)}, nil, cfg); err == nil {
+		t.Fatal("expected PUSH0 to be invalid before Durango")
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"context"
 	"errors"
 	"math/big"
+	"sync"
 	"time"
 
 	"github.com/ava-labs/subnet-evm/accounts"
@@ -61,6 +62,10 @@ type EthAPIBackend struct {
 	allowUnprotectedTxs bool
 	eth                 *Ethereum
 	gpo                 *gasprice.Oracle
+
+	// rpcLock protects the RPC caps in the eth config, which may be updated
+	// while the node is running.
+	rpcLock sync.RWMutex
 }
 
 // ChainConfig returns the active chain configuration.
@@ -104,6 +109,10 @@ func (b *EthAPIBackend) GetFeeConfigAt(parent *types.Header) (commontype.FeeConf
 	return b.eth.blockchain.GetFeeConfigAt(parent)
 }
 
+func (b *EthAPIBackend) GetFeeConfigChanges(from uint64, to uint64) ([]*rawdb.FeeConfigChange, error) {
+	return b.eth.blockchain.GetFeeConfigChanges(from, to)
+}
+
 func (b *EthAPIBackend) HeaderByNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (*types.Header, error) {
 	if blockNr, ok := blockNrOrHash.Number(); ok {
 		return b.HeaderByNumber(ctx, blockNr)
@@ -330,6 +339,10 @@ func (b *EthAPIBackend) GetTransaction(ctx context.Context, txHash common.Hash)
 	return tx, blockHash, blockNumber, index, nil
 }
 
+func (b *EthAPIBackend) TxLookupError() error {
+	return b.eth.blockchain.TxLookupError()
+}
+
 func (b *EthAPIBackend) GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error) {
 	return b.eth.txPool.Nonce(addr), nil
 }
@@ -391,17 +404,37 @@ func (b *EthAPIBackend) UnprotectedAllowed() bool {
 }
 
 func (b *EthAPIBackend) RPCGasCap() uint64 {
+	b.rpcLock.RLock()
+	defer b.rpcLock.RUnlock()
+
 	return b.eth.config.RPCGasCap
 }
 
 func (b *EthAPIBackend) RPCEVMTimeout() time.Duration {
+	b.rpcLock.RLock()
+	defer b.rpcLock.RUnlock()
+
 	return b.eth.config.RPCEVMTimeout
 }
 
 func (b *EthAPIBackend) RPCTxFeeCap() float64 {
+	b.rpcLock.RLock()
+	defer b.rpcLock.RUnlock()
+
 	return b.eth.config.RPCTxFeeCap
 }
 
+// SetRPCCaps updates the gas cap, EVM timeout and tx fee cap applied to RPC
+// calls.
+func (b *EthAPIBackend) SetRPCCaps(gasCap uint64, evmTimeout time.Duration, txFeeCap float64) {
+	b.rpcLock.Lock()
+	defer b.rpcLock.Unlock()
+
+	b.eth.config.RPCGasCap = gasCap
+	b.eth.config.RPCEVMTimeout = evmTimeout
+	b.eth.config.RPCTxFeeCap = txFeeCap
+}
+
 func (b *EthAPIBackend) BloomStatus() (uint64, uint64) {
 	sections, _, _ := b.eth.bloomIndexer.Sections()
 	return params.BloomBitsBlocks, sections
@@ -425,6 +458,10 @@ func (b *EthAPIBackend) GetMaxBlocksPerRequest() int64 {
 	return b.eth.settings.MaxBlocksPerRequest
 }
 
+func (b *EthAPIBackend) GetMaxTraceFilterBlocks() int64 {
+	return b.eth.settings.MaxTraceFilterBlocks
+}
+
 func (b *EthAPIBackend) StateAtBlock(ctx context.Context, block *types.Block, reexec uint64, base *state.StateDB, checkLive bool, preferDisk bool) (*state.StateDB, error) {
 	return b.eth.StateAtBlock(block, reexec, base, checkLive, preferDisk)
 }
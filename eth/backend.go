@@ -63,10 +63,11 @@ import (
 // Deprecated: use ethconfig.Config instead.
 type Config = ethconfig.Config
 
-var DefaultSettings Settings = Settings{MaxBlocksPerRequest: 2000}
+var DefaultSettings Settings = Settings{MaxBlocksPerRequest: 2000, MaxTraceFilterBlocks: 2000}
 
 type Settings struct {
-	MaxBlocksPerRequest int64 // Maximum number of blocks to serve per getLogs request
+	MaxBlocksPerRequest  int64 // Maximum number of blocks to serve per getLogs request
+	MaxTraceFilterBlocks int64 // Maximum number of blocks to trace per trace_filter request
 }
 
 // Ethereum implements the Ethereum full node service.
@@ -217,6 +218,11 @@ func New(
 			SnapshotVerify:                  config.SnapshotVerify,
 			SkipSnapshotRebuild:             config.SkipSnapshotRebuild,
 			Preimages:                       config.Preimages,
+			OnlinePruning:                   config.OnlinePruning,
+			OnlinePruningRetention:          config.OnlinePruningRetention,
+			OnlinePruningBatchSize:          config.OnlinePruningBatchSize,
+			OnlinePruningBloomFilterSize:    config.OnlinePruningBloomFilterSize,
+			TxLookupLimit:                   config.TxLookupLimit,
 		}
 	)
 
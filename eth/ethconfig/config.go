@@ -93,6 +93,7 @@ type Config struct {
 	SnapshotAsync                   bool    // Whether to generate the initial snapshot in async mode
 	SnapshotVerify                  bool    // Whether to verify generated snapshots
 	SkipSnapshotRebuild             bool    // Whether to skip rebuilding the snapshot in favor of returning an error (only set to true for tests)
+	TxLookupLimit                   uint64  // The maximum number of blocks from head whose tx indices are reserved (0 = all blocks).
 
 	// Whitelist of required block number -> hash values to accept
 	Whitelist map[uint64]common.Hash `toml:"-"`
@@ -159,4 +160,13 @@ type Config struct {
 	OfflinePruning                bool
 	OfflinePruningBloomFilterSize uint64
 	OfflinePruningDataDirectory   string
+
+	// OnlinePruning enables online pruning while the node is running. The trie nodes
+	// unreachable from the last OnlinePruningRetention committed state roots are
+	// deleted incrementally, processing at most OnlinePruningBatchSize trie nodes or
+	// database keys per accepted block.
+	OnlinePruning                bool
+	OnlinePruningRetention       uint64
+	OnlinePruningBatchSize       int
+	OnlinePruningBloomFilterSize uint64
 }
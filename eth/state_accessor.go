@@ -187,6 +187,9 @@ func (eth *Ethereum) stateAtTransaction(block *types.Block, txIndex int, reexec
 	if err != nil {
 		return nil, vm.BlockContext{}, nil, err
 	}
+	// Configure any stateful precompiles that go into effect during this block, as
+	// the state processor does.
+	eth.blockchain.Config().CheckConfigurePrecompiles(new(big.Int).SetUint64(parent.Time()), block, statedb)
 	if txIndex == 0 && len(block.Transactions()) == 0 {
 		return nil, vm.BlockContext{}, statedb, nil
 	}
@@ -194,7 +197,10 @@ func (eth *Ethereum) stateAtTransaction(block *types.Block, txIndex int, reexec
 	signer := types.MakeSigner(eth.blockchain.Config(), block.Number(), new(big.Int).SetUint64(block.Time()))
 	for idx, tx := range block.Transactions() {
 		// Assemble the transaction call message and return if the requested offset
-		msg, _ := tx.AsMessage(signer, block.BaseFee())
+		msg, err := tx.AsMessage(signer, block.BaseFee())
+		if err != nil {
+			return nil, vm.BlockContext{}, nil, fmt.Errorf("transaction %#x failed: %v", tx.Hash(), err)
+		}
 		txContext := core.NewEVMTxContext(msg)
 		context := core.NewEVMBlockContext(block.Header(), eth.blockchain, nil)
 		if idx == txIndex {
@@ -64,6 +64,8 @@ type testBackend struct {
 	engine      consensus.Engine
 	chaindb     ethdb.Database
 	chain       *core.BlockChain
+
+	maxTraceFilterBlocks int64
 }
 
 func newTestBackend(t *testing.T, n int, gspec *core.Genesis, generator func(i int, b *core.BlockGen)) *testBackend {
@@ -72,7 +74,10 @@ func newTestBackend(t *testing.T, n int, gspec *core.Genesis, generator func(i i
 		engine:      dummy.NewETHFaker(),
 		chaindb:     rawdb.NewMemoryDatabase(),
 	}
-	// Generate blocks for testing
+	// Generate blocks for testing, with the chain config of [gspec] if any
+	if gspec.Config != nil {
+		backend.chainConfig = gspec.Config
+	}
 	gspec.Config = backend.chainConfig
 	var (
 		gendb   = rawdb.NewMemoryDatabase()
@@ -144,6 +149,10 @@ func (b *testBackend) RPCGasCap() uint64 {
 	return 25000000
 }
 
+func (b *testBackend) GetMaxTraceFilterBlocks() int64 {
+	return b.maxTraceFilterBlocks
+}
+
 func (b *testBackend) ChainConfig() *params.ChainConfig {
 	return b.chainConfig
 }
@@ -173,13 +182,19 @@ func (b *testBackend) StateAtTransaction(ctx context.Context, block *types.Block
 	if err != nil {
 		return nil, vm.BlockContext{}, nil, errStateNotFound
 	}
+	// Configure any stateful precompiles that go into effect during this block, as
+	// the state processor does.
+	b.chainConfig.CheckConfigurePrecompiles(new(big.Int).SetUint64(parent.Time()), block, statedb)
 	if txIndex == 0 && len(block.Transactions()) == 0 {
 		return nil, vm.BlockContext{}, statedb, nil
 	}
 	// Recompute transactions up to the target index.
 	signer := types.MakeSigner(b.chainConfig, block.Number(), new(big.Int).SetUint64(block.Time()))
 	for idx, tx := range block.Transactions() {
-		msg, _ := tx.AsMessage(signer, block.BaseFee())
+		msg, err := tx.AsMessage(signer, block.BaseFee())
+		if err != nil {
+			return nil, vm.BlockContext{}, nil, fmt.Errorf("transaction %#x failed: %v", tx.Hash(), err)
+		}
 		txContext := core.NewEVMTxContext(msg)
 		context := core.NewEVMBlockContext(block.Header(), b.chain, nil)
 		if idx == txIndex {
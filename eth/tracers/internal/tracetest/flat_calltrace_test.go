@@ -0,0 +1,287 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tracetest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/core/vm"
+	"github.com/ava-labs/subnet-evm/eth/tracers"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ava-labs/subnet-evm/tests"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// flatTrace is the result of a flatCallTracer run.
+type flatTrace struct {
+	Action struct {
+		CallType      string          `json:"callType"`
+		From          *common.Address `json:"from"`
+		To            *common.Address `json:"to"`
+		Address       *common.Address `json:"address"`
+		RefundAddress *common.Address `json:"refundAddress"`
+	} `json:"action"`
+	BlockHash   *common.Hash `json:"blockHash"`
+	BlockNumber *uint64      `json:"blockNumber"`
+	Error       string       `json:"error"`
+	Result      *struct {
+		Address *common.Address `json:"address"`
+	} `json:"result"`
+	Subtraces           int          `json:"subtraces"`
+	TraceAddress        []int        `json:"traceAddress"`
+	TransactionHash     *common.Hash `json:"transactionHash"`
+	TransactionPosition *uint64      `json:"transactionPosition"`
+	Type                string       `json:"type"`
+}
+
+// flatSummary is the part of a trace compared between the callTracer and flatCallTracer.
+type flatSummary struct {
+	Type         string
+	CallType     string
+	From         common.Address
+	To           common.Address
+	Failed       bool
+	Subtraces    int
+	TraceAddress []int
+}
+
+// flattenCallTrace flattens the result of a callTracer run into the summaries of the
+// traces the flatCallTracer is expected to produce.
+func flattenCallTrace(call *callTrace, traceAddress []int, summaries []flatSummary) []flatSummary {
+	summary := flatSummary{
+		From:         call.From,
+		To:           call.To,
+		Failed:       call.Error != "",
+		Subtraces:    len(call.Calls),
+		TraceAddress: traceAddress,
+	}
+	switch call.Type {
+	case "CREATE", "CREATE2":
+		summary.Type = "create"
+		if summary.Failed {
+			summary.To = common.Address{}
+		}
+	case "SELFDESTRUCT":
+		summary.Type = "suicide"
+	default:
+		summary.Type = "call"
+		summary.CallType = strings.ToLower(call.Type)
+	}
+	summaries = append(summaries, summary)
+	for i := range call.Calls {
+		summaries = flattenCallTrace(&call.Calls[i], append(append([]int{}, traceAddress...), i), summaries)
+	}
+	return summaries
+}
+
+// summarize returns the summary of [trace].
+func summarize(trace *flatTrace) flatSummary {
+	summary := flatSummary{
+		Type:         trace.Type,
+		CallType:     trace.Action.CallType,
+		Failed:       trace.Error != "",
+		Subtraces:    trace.Subtraces,
+		TraceAddress: trace.TraceAddress,
+	}
+	switch trace.Type {
+	case "create":
+		summary.From = *trace.Action.From
+		if trace.Result != nil {
+			summary.To = *trace.Result.Address
+		}
+	case "suicide":
+		summary.From = *trace.Action.Address
+		summary.To = *trace.Action.RefundAddress
+	default:
+		summary.From = *trace.Action.From
+		summary.To = *trace.Action.To
+	}
+	return summary
+}
+
+// TestFlatCallTracerNative checks that the flatCallTracer reports the same call frames
+// as the callTracer for the callTracer test suite.
+func TestFlatCallTracerNative(t *testing.T) {
+	files, err := ioutil.ReadDir(filepath.Join("testdata", "call_tracer"))
+	if err != nil {
+		t.Fatalf("failed to retrieve tracer test suite: %v", err)
+	}
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		file := file // capture range variable
+		t.Run(camel(strings.TrimSuffix(file.Name(), ".json")), func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				test = new(callTracerTest)
+				tx   = new(types.Transaction)
+			)
+			if blob, err := ioutil.ReadFile(filepath.Join("testdata", "call_tracer", file.Name())); err != nil {
+				t.Fatalf("failed to read testcase: %v", err)
+			} else if err := json.Unmarshal(blob, test); err != nil {
+				t.Fatalf("failed to parse testcase: %v", err)
+			}
+			if err := rlp.DecodeBytes(common.FromHex(test.Input), tx); err != nil {
+				t.Fatalf("failed to parse testcase input: %v", err)
+			}
+			var (
+				signer    = types.MakeSigner(test.Genesis.Config, new(big.Int).SetUint64(uint64(test.Context.Number)), new(big.Int).SetUint64(uint64(test.Context.Time)))
+				origin, _ = signer.Sender(tx)
+				txContext = vm.TxContext{
+					Origin:   origin,
+					GasPrice: tx.GasPrice(),
+				}
+				context = vm.BlockContext{
+					CanTransfer: core.CanTransfer,
+					Transfer:    core.Transfer,
+					Coinbase:    test.Context.Miner,
+					BlockNumber: new(big.Int).SetUint64(uint64(test.Context.Number)),
+					Time:        new(big.Int).SetUint64(uint64(test.Context.Time)),
+					Difficulty:  (*big.Int)(test.Context.Difficulty),
+					GasLimit:    uint64(test.Context.GasLimit),
+				}
+				_, statedb = tests.MakePreState(rawdb.NewMemoryDatabase(), test.Genesis.Alloc, false)
+			)
+			traces := runFlatCallTracer(t, tx, signer, context, txContext, statedb, test.Genesis.Config, &tracers.Context{
+				BlockHash:   common.Hash{0x01},
+				BlockNumber: context.BlockNumber,
+				TxIndex:     2,
+				TxHash:      tx.Hash(),
+			})
+
+			want := flattenCallTrace(test.Result, []int{}, nil)
+			have := make([]flatSummary, len(traces))
+			for i, trace := range traces {
+				have[i] = summarize(trace)
+				if *trace.BlockHash != (common.Hash{0x01}) || *trace.BlockNumber != uint64(test.Context.Number) ||
+					*trace.TransactionHash != tx.Hash() || *trace.TransactionPosition != 2 {
+					t.Fatalf("trace %d has the wrong transaction context", i)
+				}
+			}
+			if !reflect.DeepEqual(have, want) {
+				t.Fatalf("trace mismatch: \nhave %+v\nwant %+v", have, want)
+			}
+		})
+	}
+}
+
+// TestFlatCallTracerPrecompiles checks that the flatCallTracer reports the calls to the
+// stateful precompiles, but not to the precompiles of Ethereum.
+func TestFlatCallTracerPrecompiles(t *testing.T) {
+	to := common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+	privkey, err := crypto.HexToECDSA("0000000000000000deadbeef00000000000000000000000000000000deadbeef")
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	config := *params.TestChainConfig
+	config.GenesisPrecompiles = params.Precompiles{
+		precompile.ContractDeployerAllowListConfigKey: precompile.NewContractDeployerAllowListConfig(big.NewInt(0), nil),
+	}
+	signer := types.NewEIP155Signer(big.NewInt(1))
+	tx, err := types.SignNewTx(privkey, signer, &types.LegacyTx{
+		GasPrice: big.NewInt(0),
+		Gas:      100000,
+		To:       &to,
+	})
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	origin, _ := signer.Sender(tx)
+	txContext := vm.TxContext{
+		Origin:   origin,
+		GasPrice: big.NewInt(1),
+	}
+	context := vm.BlockContext{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		Coinbase:    common.Address{},
+		BlockNumber: new(big.Int).SetUint64(8000000),
+		Time:        new(big.Int).SetUint64(5),
+		Difficulty:  big.NewInt(0x30000),
+		GasLimit:    uint64(6000000),
+		BaseFee:     big.NewInt(0),
+	}
+	// Store the readAllowList(address) selector in memory, then staticcall the contract
+	// deployer allow list and the ecrecover precompile.
+	code := []byte{byte(vm.PUSH4)}
+	code = append(code, precompile.CalculateFunctionSelector("readAllowList(address)")...)
+	code = append(code,
+		byte(vm.PUSH1), 0xe0, byte(vm.SHL), byte(vm.PUSH1), 0x0, byte(vm.MSTORE),
+		byte(vm.PUSH1), 0x20, byte(vm.PUSH1), 0x0, byte(vm.PUSH1), 0x24, byte(vm.PUSH1), 0x0, // out 0x20, in 0x24
+		byte(vm.PUSH20),
+	)
+	code = append(code, precompile.ContractDeployerAllowListAddress.Bytes()...)
+	code = append(code,
+		byte(vm.GAS), byte(vm.STATICCALL), byte(vm.POP),
+		byte(vm.PUSH1), 0x0, byte(vm.DUP1), byte(vm.DUP1), byte(vm.DUP1), // in and outs zero
+		byte(vm.PUSH1), 0x01, byte(vm.GAS), byte(vm.STATICCALL), byte(vm.POP), // address=ecrecover, gas=GAS
+	)
+	alloc := core.GenesisAlloc{
+		to: core.GenesisAccount{
+			Nonce: 1,
+			Code:  code,
+		},
+		origin: core.GenesisAccount{
+			Nonce:   0,
+			Balance: big.NewInt(500000000000000),
+		},
+	}
+	_, statedb := tests.MakePreState(rawdb.NewMemoryDatabase(), alloc, false)
+	traces := runFlatCallTracer(t, tx, signer, context, txContext, statedb, &config, nil)
+
+	want := []flatSummary{
+		{Type: "call", CallType: "call", From: origin, To: to, Subtraces: 1, TraceAddress: []int{}},
+		{Type: "call", CallType: "staticcall", From: to, To: precompile.ContractDeployerAllowListAddress, TraceAddress: []int{0}},
+	}
+	have := make([]flatSummary, len(traces))
+	for i, trace := range traces {
+		have[i] = summarize(trace)
+		if trace.BlockHash != nil || trace.BlockNumber != nil || trace.TransactionHash != nil || trace.TransactionPosition != nil {
+			t.Fatalf("trace %d of a dangling transaction has a transaction context", i)
+		}
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Fatalf("trace mismatch: \nhave %+v\nwant %+v", have, want)
+	}
+}
+
+// runFlatCallTracer executes [tx] with the flatCallTracer and returns its traces.
+func runFlatCallTracer(t *testing.T, tx *types.Transaction, signer types.Signer, context vm.BlockContext, txContext vm.TxContext, statedb vm.StateDB, config *params.ChainConfig, ctx *tracers.Context) []*flatTrace {
+	tracer, err := tracers.New("flatCallTracer", ctx, nil)
+	if err != nil {
+		t.Fatalf("failed to create flat call tracer: %v", err)
+	}
+	evm := vm.NewEVM(context, txContext, statedb, config, vm.Config{Debug: true, Tracer: tracer})
+	msg, err := tx.AsMessage(signer, nil)
+	if err != nil {
+		t.Fatalf("failed to prepare transaction for tracing: %v", err)
+	}
+	st := core.NewStateTransition(evm, msg, new(core.GasPool).AddGas(tx.Gas()))
+	if _, err = st.TransitionDb(); err != nil {
+		t.Fatalf("failed to execute transaction: %v", err)
+	}
+	res, err := tracer.GetResult()
+	if err != nil {
+		t.Fatalf("failed to retrieve trace result: %v", err)
+	}
+	var traces []*flatTrace
+	if err := json.Unmarshal(res, &traces); err != nil {
+		t.Fatalf("failed to unmarshal trace result: %v", err)
+	}
+	return traces
+}
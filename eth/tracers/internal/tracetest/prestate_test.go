@@ -0,0 +1,160 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tracetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/core/vm"
+	"github.com/ava-labs/subnet-evm/eth/tracers"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ava-labs/subnet-evm/tests"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// TestPrestateTracerDiffMode checks that the prestateTracer in diff mode reports the
+// changes made by the stateful precompiles along with the changes made by the EVM.
+func TestPrestateTracerDiffMode(t *testing.T) {
+	privkey, err := crypto.HexToECDSA("0000000000000000deadbeef00000000000000000000000000000000deadbeef")
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	config := *params.TestChainConfig
+	config.GenesisPrecompiles = params.Precompiles{
+		precompile.ContractNativeMinterConfigKey: precompile.NewContractNativeMinterConfig(big.NewInt(0), nil),
+	}
+	var (
+		signer    = types.NewEIP155Signer(big.NewInt(1))
+		origin    = crypto.PubkeyToAddress(privkey.PublicKey)
+		recipient = common.HexToAddress("0x00000000000000000000000000000000deadbeef")
+		minter    = precompile.ContractNativeMinterAddress
+		// totalMintedKey is the storage slot of the total supply minted by the native minter
+		totalMintedKey = common.Hash{'t', 'm', 's', 'k'}
+		balance        = big.NewInt(500000000000000)
+		context        = vm.BlockContext{
+			CanTransfer: core.CanTransfer,
+			Transfer:    core.Transfer,
+			Coinbase:    common.Address{},
+			BlockNumber: new(big.Int).SetUint64(8000000),
+			Time:        new(big.Int).SetUint64(5),
+			Difficulty:  big.NewInt(0x30000),
+			GasLimit:    uint64(6000000),
+			BaseFee:     big.NewInt(0),
+		}
+	)
+	alloc := core.GenesisAlloc{
+		origin: core.GenesisAccount{
+			Balance: balance,
+		},
+		minter: core.GenesisAccount{
+			Nonce:   1,
+			Balance: big.NewInt(0),
+			Storage: map[common.Hash]common.Hash{origin.Hash(): common.Hash(precompile.AllowListAdmin)},
+		},
+	}
+	_, statedb := tests.MakePreState(rawdb.NewMemoryDatabase(), alloc, false)
+
+	mintInput, err := precompile.PackMintInput(recipient, big.NewInt(1000))
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	enableInput, err := precompile.PackModifyAllowList(recipient, precompile.AllowListEnabled)
+	if err != nil {
+		t.Fatalf("err %v", err)
+	}
+	testSuite := []struct {
+		input []byte
+		want  *tracers.StateDiff
+	}{
+		// Minting creates the recipient, which is missing from the pre state, and records
+		// the total supply minted in the storage of the native minter
+		{
+			input: mintInput,
+			want: &tracers.StateDiff{
+				Pre: map[common.Address]*tracers.StateDiffAccount{
+					origin: {Balance: (*hexutil.Big)(balance)},
+					minter: {Balance: (*hexutil.Big)(big.NewInt(0)), Nonce: 1},
+				},
+				Post: map[common.Address]*tracers.StateDiffAccount{
+					origin:    {Nonce: 1},
+					recipient: {Balance: (*hexutil.Big)(big.NewInt(1000))},
+					minter:    {Storage: map[common.Hash]common.Hash{totalMintedKey: common.BigToHash(big.NewInt(1000))}},
+				},
+			},
+		},
+		// Enabling the recipient modifies the storage of the allow list
+		{
+			input: enableInput,
+			want: &tracers.StateDiff{
+				Pre: map[common.Address]*tracers.StateDiffAccount{
+					origin: {Balance: (*hexutil.Big)(balance), Nonce: 1},
+					minter: {Balance: (*hexutil.Big)(big.NewInt(0)), Nonce: 1},
+				},
+				Post: map[common.Address]*tracers.StateDiffAccount{
+					origin: {Nonce: 2},
+					minter: {Storage: map[common.Hash]common.Hash{recipient.Hash(): common.Hash(precompile.AllowListEnabled)}},
+				},
+			},
+		},
+	}
+	for i, test := range testSuite {
+		tx, err := types.SignNewTx(privkey, signer, &types.LegacyTx{
+			Nonce:    uint64(i),
+			GasPrice: big.NewInt(0),
+			Gas:      1000000,
+			To:       &minter,
+			Data:     test.input,
+		})
+		if err != nil {
+			t.Fatalf("test %d: err %v", i, err)
+		}
+		// Compare the encoded diffs, since empty fields are omitted from the result
+		have := runPrestateDiffTracer(t, tx, signer, context, statedb, &config)
+		want, err := json.Marshal(test.want)
+		if err != nil {
+			t.Fatalf("test %d: err %v", i, err)
+		}
+		if !bytes.Equal(have, want) {
+			t.Fatalf("test %d: state diff mismatch: \nhave %s\nwant %s", i, have, want)
+		}
+	}
+}
+
+// runPrestateDiffTracer executes [tx] with the prestateTracer in diff mode, finalises the
+// resulting state and returns the encoded state diff of [tx].
+func runPrestateDiffTracer(t *testing.T, tx *types.Transaction, signer types.Signer, context vm.BlockContext, statedb *state.StateDB, config *params.ChainConfig) json.RawMessage {
+	tracer, err := tracers.New("prestateTracer", nil, json.RawMessage(`{"diffMode":true}`))
+	if err != nil {
+		t.Fatalf("failed to create prestate tracer: %v", err)
+	}
+	msg, err := tx.AsMessage(signer, nil)
+	if err != nil {
+		t.Fatalf("failed to prepare transaction for tracing: %v", err)
+	}
+	evm := vm.NewEVM(context, core.NewEVMTxContext(msg), statedb, config, vm.Config{Debug: true, Tracer: tracer})
+	st := core.NewStateTransition(evm, msg, new(core.GasPool).AddGas(tx.Gas()))
+	result, err := st.TransitionDb()
+	if err != nil {
+		t.Fatalf("failed to execute transaction: %v", err)
+	}
+	if result.Failed() {
+		t.Fatalf("transaction failed: %v", result.Err)
+	}
+	res, err := tracer.GetResult()
+	if err != nil {
+		t.Fatalf("failed to retrieve trace result: %v", err)
+	}
+	statedb.Finalise(true)
+	return res
+}
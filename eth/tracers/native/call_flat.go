@@ -0,0 +1,306 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package native
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/core/vm"
+	"github.com/ava-labs/subnet-evm/eth/tracers"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func init() {
+	register("flatCallTracer", newFlatCallTracer)
+}
+
+// parityErrorMapping maps the errors of the EVM to the errors reported by Parity.
+var parityErrorMapping = map[string]string{
+	"contract creation code storage out of gas": "Out of gas",
+	"out of gas":                      "Out of gas",
+	"gas uint64 overflow":             "Out of gas",
+	"max code size exceeded":          "Out of gas",
+	"invalid jump destination":        "Bad jump destination",
+	"execution reverted":              "Reverted",
+	"return data out of bounds":       "Out of bounds",
+	"stack limit reached 1024 (1023)": "Out of stack",
+	"precompiled failed":              "Built-in failed",
+	"invalid input length":            "Built-in failed",
+}
+
+// parityErrorMappingStartingWith maps the errors of the EVM starting with a given
+// prefix to the errors reported by Parity.
+var parityErrorMappingStartingWith = map[string]string{
+	"invalid opcode:": "Bad instruction",
+	"stack underflow": "Out of stack",
+}
+
+// flatCallFrame is a call frame of the transaction, kept in its raw form until the
+// frames are flattened into traces.
+type flatCallFrame struct {
+	typ     vm.OpCode
+	from    common.Address
+	to      common.Address
+	input   []byte
+	output  []byte
+	gas     uint64
+	gasUsed uint64
+	value   *big.Int
+	err     error
+	calls   []*flatCallFrame
+}
+
+type flatCallAction struct {
+	CallType      string `json:"callType,omitempty"`
+	From          string `json:"from,omitempty"`
+	To            string `json:"to,omitempty"`
+	Gas           string `json:"gas,omitempty"`
+	Input         string `json:"input,omitempty"`
+	Init          string `json:"init,omitempty"`
+	Value         string `json:"value,omitempty"`
+	Address       string `json:"address,omitempty"`
+	RefundAddress string `json:"refundAddress,omitempty"`
+	Balance       string `json:"balance,omitempty"`
+}
+
+type flatCallResult struct {
+	Address string `json:"address,omitempty"`
+	Code    string `json:"code,omitempty"`
+	GasUsed string `json:"gasUsed"`
+	Output  string `json:"output,omitempty"`
+}
+
+// flatCallTrace is a single trace in the format of the Parity trace module.
+type flatCallTrace struct {
+	Action              flatCallAction  `json:"action"`
+	BlockHash           *common.Hash    `json:"blockHash"`
+	BlockNumber         *uint64         `json:"blockNumber"`
+	Error               string          `json:"error,omitempty"`
+	Result              *flatCallResult `json:"result"`
+	Subtraces           int             `json:"subtraces"`
+	TraceAddress        []int           `json:"traceAddress"`
+	TransactionHash     *common.Hash    `json:"transactionHash"`
+	TransactionPosition *uint64         `json:"transactionPosition"`
+	Type                string          `json:"type"`
+}
+
+type flatCallTracer struct {
+	env         *vm.EVM
+	ctx         *tracers.Context
+	precompiles map[common.Address]struct{}
+	callstack   []*flatCallFrame
+	interrupt   uint32 // Atomic flag to signal execution interruption
+	reason      error  // Textual reason for the interruption
+}
+
+// newFlatCallTracer returns a native go tracer which tracks the call frames of a tx
+// and reports them as a flat list of Parity-style traces. Calls to the stateful
+// precompiles are reported like any other call, while calls to the precompiles of
+// Ethereum are omitted as Parity does.
+func newFlatCallTracer(ctx *tracers.Context, _ json.RawMessage) (tracers.Tracer, error) {
+	if ctx == nil {
+		ctx = new(tracers.Context)
+	}
+	// First callframe contains tx context info
+	// and is populated on start and end.
+	return &flatCallTracer{ctx: ctx, callstack: make([]*flatCallFrame, 1)}, nil
+}
+
+// CaptureStart implements the EVMLogger interface to initialize the tracing operation.
+func (t *flatCallTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.env = env
+	t.precompiles = make(map[common.Address]struct{})
+	for _, addr := range vm.ActivePrecompiles(env.ChainConfig().AvalancheRules(env.Context.BlockNumber, env.Context.Time)) {
+		t.precompiles[addr] = struct{}{}
+	}
+	t.callstack[0] = &flatCallFrame{
+		typ:   vm.CALL,
+		from:  from,
+		to:    to,
+		input: common.CopyBytes(input),
+		gas:   gas,
+		value: value,
+	}
+	if create {
+		t.callstack[0].typ = vm.CREATE
+	}
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *flatCallTracer) CaptureEnd(output []byte, gasUsed uint64, _ time.Duration, err error) {
+	t.callstack[0].finish(output, gasUsed, err)
+}
+
+// CaptureState implements the EVMLogger interface to trace a single step of VM execution.
+func (t *flatCallTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+}
+
+// CaptureFault implements the EVMLogger interface to trace an execution fault.
+func (t *flatCallTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, _ *vm.ScopeContext, depth int, err error) {
+}
+
+// CaptureEnter is called when EVM enters a new scope (via call, create or selfdestruct).
+func (t *flatCallTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	// Skip if tracing was interrupted
+	if atomic.LoadUint32(&t.interrupt) > 0 {
+		t.env.Cancel()
+		return
+	}
+
+	t.callstack = append(t.callstack, &flatCallFrame{
+		typ:   typ,
+		from:  from,
+		to:    to,
+		input: common.CopyBytes(input),
+		gas:   gas,
+		value: value,
+	})
+}
+
+// CaptureExit is called when EVM exits a scope, even if the scope didn't
+// execute any code.
+func (t *flatCallTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	size := len(t.callstack)
+	if size <= 1 {
+		return
+	}
+	// pop call
+	call := t.callstack[size-1]
+	t.callstack = t.callstack[:size-1]
+	size -= 1
+
+	call.finish(output, gasUsed, err)
+	if _, ok := t.precompiles[call.to]; ok && call.typ != vm.SELFDESTRUCT {
+		return
+	}
+	t.callstack[size-1].calls = append(t.callstack[size-1].calls, call)
+}
+
+// GetResult returns the json-encoded flat list of call traces, and any
+// error arising from the encoding or forceful termination (via `Stop`).
+func (t *flatCallTracer) GetResult() (json.RawMessage, error) {
+	if len(t.callstack) != 1 {
+		return nil, errors.New("incorrect number of top-level calls")
+	}
+	res, err := json.Marshal(t.flatten(t.callstack[0], []int{}, nil))
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(res), t.reason
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *flatCallTracer) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
+
+// flatten appends the trace of [frame], followed by the traces of its sub-calls in
+// depth-first order, to [traces].
+func (t *flatCallTracer) flatten(frame *flatCallFrame, traceAddress []int, traces []*flatCallTrace) []*flatCallTrace {
+	trace := &flatCallTrace{
+		Subtraces:    len(frame.calls),
+		TraceAddress: traceAddress,
+	}
+	if t.ctx.BlockHash != (common.Hash{}) {
+		trace.BlockHash = &t.ctx.BlockHash
+	}
+	if t.ctx.BlockNumber != nil {
+		number := t.ctx.BlockNumber.Uint64()
+		trace.BlockNumber = &number
+	}
+	if t.ctx.TxHash != (common.Hash{}) {
+		position := uint64(t.ctx.TxIndex)
+		trace.TransactionHash = &t.ctx.TxHash
+		trace.TransactionPosition = &position
+	}
+
+	switch frame.typ {
+	case vm.CREATE, vm.CREATE2:
+		trace.Type = "create"
+		trace.Action = flatCallAction{
+			From:  addrToHex(frame.from),
+			Gas:   uintToHex(frame.gas),
+			Init:  bytesToHex(frame.input),
+			Value: valueToHex(frame.value),
+		}
+		if frame.err == nil {
+			trace.Result = &flatCallResult{
+				Address: addrToHex(frame.to),
+				Code:    bytesToHex(frame.output),
+				GasUsed: uintToHex(frame.gasUsed),
+			}
+		}
+	case vm.SELFDESTRUCT:
+		trace.Type = "suicide"
+		trace.Action = flatCallAction{
+			Address:       addrToHex(frame.from),
+			RefundAddress: addrToHex(frame.to),
+			Balance:       valueToHex(frame.value),
+		}
+	default:
+		trace.Type = "call"
+		trace.Action = flatCallAction{
+			CallType: strings.ToLower(frame.typ.String()),
+			From:     addrToHex(frame.from),
+			To:       addrToHex(frame.to),
+			Gas:      uintToHex(frame.gas),
+			Input:    bytesToHex(frame.input),
+			Value:    valueToHex(frame.value),
+		}
+		if frame.err == nil {
+			trace.Result = &flatCallResult{
+				GasUsed: uintToHex(frame.gasUsed),
+				Output:  bytesToHex(frame.output),
+			}
+		}
+	}
+	if frame.err != nil {
+		trace.Error = parityError(frame.err)
+	}
+
+	traces = append(traces, trace)
+	for i, call := range frame.calls {
+		childAddress := make([]int, len(traceAddress)+1)
+		copy(childAddress, traceAddress)
+		childAddress[len(traceAddress)] = i
+		traces = t.flatten(call, childAddress, traces)
+	}
+	return traces
+}
+
+// finish records the outcome of the call in [f].
+func (f *flatCallFrame) finish(output []byte, gasUsed uint64, err error) {
+	f.output = common.CopyBytes(output)
+	f.gasUsed = gasUsed
+	f.err = err
+}
+
+// parityError returns the error reported by Parity for [err].
+func parityError(err error) string {
+	msg := err.Error()
+	if mapped, ok := parityErrorMapping[msg]; ok {
+		return mapped
+	}
+	for prefix, mapped := range parityErrorMappingStartingWith {
+		if strings.HasPrefix(msg, prefix) {
+			return mapped
+		}
+	}
+	return msg
+}
+
+// valueToHex returns the hex encoding of [n], where the calls that do not transfer
+// value report a value of zero.
+func valueToHex(n *big.Int) string {
+	if n == nil {
+		return "0x0"
+	}
+	return bigToHex(n)
+}
@@ -28,11 +28,13 @@ package native
 
 import (
 	"encoding/json"
+	"errors"
 	"math/big"
 	"sync/atomic"
 	"time"
 
 	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/state"
 	"github.com/ava-labs/subnet-evm/core/vm"
 	"github.com/ava-labs/subnet-evm/eth/tracers"
 	"github.com/ethereum/go-ethereum/common"
@@ -59,14 +61,27 @@ type prestateTracer struct {
 	prestate  prestate
 	create    bool
 	to        common.Address
+	config    prestateTracerConfig
 	interrupt uint32 // Atomic flag to signal execution interruption
 	reason    error  // Textual reason for the interruption
 }
 
-func newPrestateTracer() tracers.Tracer {
+type prestateTracerConfig struct {
+	// DiffMode makes the tracer return the pre and post state of the accounts changed
+	// by the transaction instead of the prestate of the accounts it accessed.
+	DiffMode bool `json:"diffMode"`
+}
+
+func newPrestateTracer(ctx *tracers.Context, cfg json.RawMessage) (tracers.Tracer, error) {
+	var config prestateTracerConfig
+	if cfg != nil {
+		if err := json.Unmarshal(cfg, &config); err != nil {
+			return nil, err
+		}
+	}
 	// First callframe contains tx context info
 	// and is populated on start and end.
-	return &prestateTracer{prestate: prestate{}}
+	return &prestateTracer{prestate: prestate{}, config: config}, nil
 }
 
 // CaptureStart implements the EVMLogger interface to initialize the tracing operation.
@@ -76,9 +91,8 @@ func (t *prestateTracer) CaptureStart(env *vm.EVM, from common.Address, to commo
 	t.to = to
 
 	// Compute intrinsic gas
-	isHomestead := env.ChainConfig().IsHomestead(env.Context.BlockNumber)
-	isIstanbul := env.ChainConfig().IsIstanbul(env.Context.BlockNumber)
-	intrinsicGas, err := core.IntrinsicGas(input, nil, create, isHomestead, isIstanbul)
+	rules := env.ChainConfig().AvalancheRules(env.Context.BlockNumber, env.Context.Time)
+	intrinsicGas, err := core.IntrinsicGas(input, nil, create, rules.IsHomestead, rules.IsIstanbul, rules.IsDurango)
 	if err != nil {
 		return
 	}
@@ -160,7 +174,19 @@ func (t *prestateTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
 // GetResult returns the json-encoded nested list of call traces, and any
 // error arising from the encoding or forceful termination (via `Stop`).
 func (t *prestateTracer) GetResult() (json.RawMessage, error) {
-	res, err := json.Marshal(t.prestate)
+	var (
+		res []byte
+		err error
+	)
+	if t.config.DiffMode {
+		var diff *tracers.StateDiff
+		if diff, err = t.diff(); err != nil {
+			return nil, err
+		}
+		res, err = json.Marshal(diff)
+	} else {
+		res, err = json.Marshal(t.prestate)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -173,6 +199,23 @@ func (t *prestateTracer) Stop(err error) {
 	atomic.StoreUint32(&t.interrupt, 1)
 }
 
+// diff returns the pre and post state of the accounts changed by the transaction. The
+// changes are read from the journal of the state, which also records the changes made
+// by stateful precompiles, so it must be called before the state is finalised.
+func (t *prestateTracer) diff() (*tracers.StateDiff, error) {
+	if t.env == nil {
+		return &tracers.StateDiff{Pre: map[common.Address]*tracers.StateDiffAccount{}, Post: map[common.Address]*tracers.StateDiffAccount{}}, nil
+	}
+	statedb, ok := t.env.StateDB.(*state.StateDB)
+	if !ok {
+		return nil, errors.New("diff mode is not supported by the state")
+	}
+	deleteEmptyObjects := t.env.ChainConfig().IsEIP158(t.env.Context.BlockNumber)
+	return tracers.NewStateDiff(statedb.DirtyAccounts(), statedb, func(addr common.Address) bool {
+		return statedb.HasSuicided(addr) || (deleteEmptyObjects && statedb.Empty(addr))
+	}), nil
+}
+
 // lookupAccount fetches details of an account and adds it to the prestate
 // if it doesn't exist there.
 func (t *prestateTracer) lookupAccount(addr common.Address) {
@@ -0,0 +1,212 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tracers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/core/vm"
+	"github.com/ava-labs/subnet-evm/rpc"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// StateDiffAccount is the state of an account in a StateDiff.
+type StateDiffAccount struct {
+	Balance *hexutil.Big                `json:"balance,omitempty"`
+	Nonce   uint64                      `json:"nonce,omitempty"`
+	Code    hexutil.Bytes               `json:"code,omitempty"`
+	Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+}
+
+// StateDiff holds the state of the accounts changed by a transaction or a block, before
+// and after the changes. The pre state of an account holds its balance, nonce and code
+// along with the original values of the changed storage slots, while the post state only
+// holds the changed values. Accounts missing from the pre state were created and accounts
+// missing from the post state were deleted.
+type StateDiff struct {
+	Pre  map[common.Address]*StateDiffAccount `json:"pre"`
+	Post map[common.Address]*StateDiffAccount `json:"post"`
+}
+
+// NewStateDiff returns the state diff of the [dirty] accounts, whose original values are
+// recorded in [dirty] and whose current values are read from [statedb]. [deleted] reports
+// whether an account was deleted. Accounts whose values did not change are omitted.
+func NewStateDiff(dirty map[common.Address]*state.DirtyAccount, statedb vm.StateDB, deleted func(common.Address) bool) *StateDiff {
+	diff := &StateDiff{
+		Pre:  make(map[common.Address]*StateDiffAccount),
+		Post: make(map[common.Address]*StateDiffAccount),
+	}
+	for addr, account := range dirty {
+		var (
+			isDeleted = deleted(addr)
+			balance   = statedb.GetBalance(addr)
+			nonce     = statedb.GetNonce(addr)
+			code      = statedb.GetCode(addr)
+
+			preBalance, preNonce, preCode = balance, nonce, code
+		)
+		if account.Balance != nil {
+			preBalance = account.Balance
+		}
+		if account.Nonce != nil {
+			preNonce = *account.Nonce
+		}
+		if account.CodeChanged {
+			preCode = account.Code
+		}
+		if !account.Existed {
+			preBalance, preNonce, preCode = new(big.Int), 0, nil
+		}
+
+		var (
+			pre      *StateDiffAccount
+			post     = &StateDiffAccount{Storage: make(map[common.Hash]common.Hash)}
+			modified = isDeleted && account.Existed
+		)
+		if account.Existed {
+			pre = &StateDiffAccount{
+				Balance: (*hexutil.Big)(preBalance),
+				Nonce:   preNonce,
+				Code:    preCode,
+				Storage: make(map[common.Hash]common.Hash),
+			}
+		}
+		if !isDeleted {
+			if preBalance.Cmp(balance) != 0 {
+				post.Balance, modified = (*hexutil.Big)(balance), true
+			}
+			if preNonce != nonce {
+				post.Nonce, modified = nonce, true
+			}
+			if !bytes.Equal(preCode, code) {
+				post.Code, modified = code, true
+			}
+		}
+		for key, preValue := range account.Storage {
+			var value common.Hash
+			if !account.Existed {
+				preValue = common.Hash{}
+			}
+			if !isDeleted {
+				value = statedb.GetState(addr, key)
+			}
+			if preValue == value {
+				continue
+			}
+			modified = true
+			if pre != nil && preValue != (common.Hash{}) {
+				pre.Storage[key] = preValue
+			}
+			if value != (common.Hash{}) {
+				post.Storage[key] = value
+			}
+		}
+		if !modified {
+			continue
+		}
+		if pre != nil {
+			diff.Pre[addr] = pre
+		}
+		if !isDeleted {
+			diff.Post[addr] = post
+		}
+	}
+	return diff
+}
+
+// StateDiffConfig holds extra parameters to the block state diff functions.
+type StateDiffConfig struct {
+	Reexec *uint64
+}
+
+// StateDiffBlockByNumber returns the state diff of the block [number], aggregated over
+// all its transactions.
+func (api *API) StateDiffBlockByNumber(ctx context.Context, number rpc.BlockNumber, config *StateDiffConfig) (*StateDiff, error) {
+	block, err := api.blockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	return api.stateDiffBlock(ctx, block, config)
+}
+
+// StateDiffBlockByHash returns the state diff of the block [hash], aggregated over all
+// its transactions.
+func (api *API) StateDiffBlockByHash(ctx context.Context, hash common.Hash, config *StateDiffConfig) (*StateDiff, error) {
+	block, err := api.blockByHash(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return api.stateDiffBlock(ctx, block, config)
+}
+
+// stateDiffBlock executes [block] on top of the state of its parent once and returns the
+// difference between the state of its parent and the resulting state.
+func (api *API) stateDiffBlock(ctx context.Context, block *types.Block, config *StateDiffConfig) (*StateDiff, error) {
+	if block.NumberU64() == 0 {
+		return nil, errors.New("genesis is not traceable")
+	}
+	parent, err := api.blockByNumberAndHash(ctx, rpc.BlockNumber(block.NumberU64()-1), block.ParentHash())
+	if err != nil {
+		return nil, err
+	}
+	reexec := defaultTraceReexec
+	if config != nil && config.Reexec != nil {
+		reexec = *config.Reexec
+	}
+	// Replay the block up to its last transaction, which is then applied on top. The
+	// changes are only finalised in between, so they remain pending in the state.
+	var (
+		txs   = block.Transactions()
+		index = len(txs) - 1
+	)
+	if index < 0 {
+		index = 0
+	}
+	msg, vmctx, statedb, err := api.backend.StateAtTransaction(ctx, block, index, reexec)
+	if err != nil {
+		return nil, err
+	}
+	prestate, err := state.New(parent.Root(), statedb.Database(), nil)
+	if err != nil {
+		return nil, err
+	}
+	chainConfig := api.backend.ChainConfig()
+	if msg != nil {
+		statedb.Prepare(txs[index].Hash(), index)
+		vmenv := vm.NewEVM(vmctx, core.NewEVMTxContext(msg), statedb, chainConfig, vm.Config{})
+		if _, err := core.ApplyMessage(vmenv, msg, new(core.GasPool).AddGas(msg.Gas())); err != nil {
+			return nil, fmt.Errorf("transaction %#x failed: %w", txs[index].Hash(), err)
+		}
+	}
+	statedb.Finalise(chainConfig.IsEIP158(block.Number()))
+
+	// The original values of the changed accounts are read from the state of the parent,
+	// since an account may be deleted and recreated within the block.
+	pending := statedb.PendingAccounts()
+	dirty := make(map[common.Address]*state.DirtyAccount, len(pending))
+	for addr, keys := range pending {
+		nonce := prestate.GetNonce(addr)
+		account := &state.DirtyAccount{
+			Existed:     prestate.Exist(addr),
+			Balance:     prestate.GetBalance(addr),
+			Nonce:       &nonce,
+			Code:        prestate.GetCode(addr),
+			CodeChanged: true,
+			Storage:     make(map[common.Hash]common.Hash, len(keys)),
+		}
+		for _, key := range keys {
+			account.Storage[key] = prestate.GetState(addr, key)
+		}
+		dirty[addr] = account
+	}
+	return NewStateDiff(dirty, statedb, func(addr common.Address) bool { return !statedb.Exist(addr) }), nil
+}
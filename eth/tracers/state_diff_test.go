@@ -0,0 +1,138 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tracers
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ava-labs/subnet-evm/rpc"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestStateDiffBlock(t *testing.T) {
+	t.Parallel()
+
+	// Initialize test accounts
+	accounts := newAccounts(3)
+	genesis := &core.Genesis{Alloc: core.GenesisAlloc{
+		accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+		accounts[1].addr: {Balance: big.NewInt(params.Ether)},
+	}}
+	genBlocks := 2
+	signer := types.HomesteadSigner{}
+	backend := newTestBackend(t, genBlocks, genesis, func(i int, b *core.BlockGen) {
+		// Transfer from account[0] to account[2] in every block and from account[1] to
+		// account[2] in the second block, so the diff of the second block aggregates the
+		// changes of both transactions to account[2].
+		for _, sender := range accounts[:i+1] {
+			tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(sender.addr), accounts[2].addr, big.NewInt(1000), params.TxGas, new(big.Int).Add(b.BaseFee(), big.NewInt(int64(500*params.GWei))), nil), signer, sender.key)
+			b.AddTx(tx)
+		}
+	})
+	api := NewAPI(backend)
+
+	if _, err := api.StateDiffBlockByNumber(context.Background(), rpc.BlockNumber(0), nil); err == nil {
+		t.Fatal("expected the state diff of the genesis block to fail")
+	}
+	for number := 1; number <= genBlocks; number++ {
+		block := backend.chain.GetBlockByNumber(uint64(number))
+		diff, err := api.StateDiffBlockByNumber(context.Background(), rpc.BlockNumber(number), nil)
+		if err != nil {
+			t.Fatalf("block %d: failed to get state diff: %v", number, err)
+		}
+		byHash, err := api.StateDiffBlockByHash(context.Background(), block.Hash(), nil)
+		if err != nil {
+			t.Fatalf("block %d: failed to get state diff by hash: %v", number, err)
+		}
+		if !reflect.DeepEqual(diff, byHash) {
+			t.Fatalf("block %d: state diff by hash mismatch", number)
+		}
+
+		// The diff must match the states of the block and its parent
+		parentState, err := backend.chain.StateAt(backend.chain.GetBlockByNumber(uint64(number - 1)).Root())
+		if err != nil {
+			t.Fatal(err)
+		}
+		blockState, err := backend.chain.StateAt(block.Root())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, sender := range accounts[:number] {
+			pre, post := diff.Pre[sender.addr], diff.Post[sender.addr]
+			if pre == nil || post == nil {
+				t.Fatalf("block %d: missing state diff of sender %s", number, sender.addr.Hex())
+			}
+			if pre.Nonce != parentState.GetNonce(sender.addr) || post.Nonce != blockState.GetNonce(sender.addr) {
+				t.Fatalf("block %d: nonce mismatch of sender %s: have %d -> %d", number, sender.addr.Hex(), pre.Nonce, post.Nonce)
+			}
+			if pre.Balance.ToInt().Cmp(parentState.GetBalance(sender.addr)) != 0 || post.Balance.ToInt().Cmp(blockState.GetBalance(sender.addr)) != 0 {
+				t.Fatalf("block %d: balance mismatch of sender %s", number, sender.addr.Hex())
+			}
+		}
+		recipient := accounts[2].addr
+		if pre, ok := diff.Pre[recipient]; (number == 1) == ok {
+			t.Fatalf("block %d: unexpected pre state of recipient: %+v", number, pre)
+		}
+		if post := diff.Post[recipient]; post == nil || post.Balance.ToInt().Cmp(blockState.GetBalance(recipient)) != 0 {
+			t.Fatalf("block %d: unexpected post state of recipient: %+v", number, post)
+		}
+	}
+}
+
+func TestStateDiffBlockPrecompileActivation(t *testing.T) {
+	t.Parallel()
+
+	// Activate the contract deployer allow list in the second block, which changes the
+	// state of the precompile before its transactions are executed.
+	accounts := newAccounts(2)
+	config := *params.TestChainConfig
+	config.GenesisPrecompiles = params.Precompiles{
+		precompile.ContractDeployerAllowListConfigKey: precompile.NewContractDeployerAllowListConfig(big.NewInt(20), []common.Address{accounts[0].addr}),
+	}
+	genesis := &core.Genesis{
+		Config: &config,
+		Alloc:  core.GenesisAlloc{accounts[0].addr: {Balance: big.NewInt(params.Ether)}},
+	}
+	signer := types.HomesteadSigner{}
+	backend := newTestBackend(t, 2, genesis, func(i int, b *core.BlockGen) {
+		// The second block has no transaction.
+		if i == 0 {
+			tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(accounts[0].addr), accounts[1].addr, big.NewInt(1000), params.TxGas, new(big.Int).Add(b.BaseFee(), big.NewInt(int64(500*params.GWei))), nil), signer, accounts[0].key)
+			b.AddTx(tx)
+		}
+	})
+	api := NewAPI(backend)
+
+	diff, err := api.StateDiffBlockByNumber(context.Background(), rpc.BlockNumber(1), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := diff.Post[precompile.ContractDeployerAllowListAddress]; ok {
+		t.Fatal("unexpected state diff of the precompile before its activation")
+	}
+	diff, err = api.StateDiffBlockByNumber(context.Background(), rpc.BlockNumber(2), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockState, err := backend.chain.StateAt(backend.chain.GetBlockByNumber(2).Root())
+	if err != nil {
+		t.Fatal(err)
+	}
+	post := diff.Post[precompile.ContractDeployerAllowListAddress]
+	if post == nil || post.Nonce != 1 || len(post.Storage) != 1 {
+		t.Fatalf("unexpected state diff of the activated precompile: %+v", post)
+	}
+	for key, value := range post.Storage {
+		if have := blockState.GetState(precompile.ContractDeployerAllowListAddress, key); have != value {
+			t.Fatalf("storage mismatch of the activated precompile at %s: have %s, want %s", key, have, value)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tracers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/rpc"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// flatCallTracer is the name of the native tracer producing Parity-style traces.
+const flatCallTracer = "flatCallTracer"
+
+// TraceAPI is the collection of Parity-style tracing APIs exposed over the trace endpoint.
+type TraceAPI struct {
+	api *API
+}
+
+// NewTraceAPI creates a new API definition for the Parity-style tracing methods of the
+// Ethereum service.
+func NewTraceAPI(backend Backend) *TraceAPI {
+	return &TraceAPI{api: NewAPI(backend)}
+}
+
+// TraceFilterArgs are the arguments of trace_filter. Traces are returned if they are
+// sent from one of [FromAddress] and to one of [ToAddress], where an empty list
+// matches any address.
+type TraceFilterArgs struct {
+	FromBlock   *rpc.BlockNumber `json:"fromBlock"`
+	ToBlock     *rpc.BlockNumber `json:"toBlock"`
+	FromAddress []common.Address `json:"fromAddress"`
+	ToAddress   []common.Address `json:"toAddress"`
+	After       *uint64          `json:"after"`
+	Count       *uint64          `json:"count"`
+}
+
+// TraceResults is the result of replaying a transaction with trace_replayBlockTransactions.
+type TraceResults struct {
+	Output          hexutil.Bytes     `json:"output"`
+	StateDiff       interface{}       `json:"stateDiff"`
+	Trace           []json.RawMessage `json:"trace"`
+	VMTrace         interface{}       `json:"vmTrace"`
+	TransactionHash common.Hash       `json:"transactionHash"`
+}
+
+// flatTrace is a single trace produced by the flatCallTracer. Only the fields needed
+// to filter the trace are decoded, [raw] is the trace as returned to the caller.
+type flatTrace struct {
+	raw    json.RawMessage
+	Action struct {
+		From          *common.Address `json:"from"`
+		To            *common.Address `json:"to"`
+		Address       *common.Address `json:"address"`
+		RefundAddress *common.Address `json:"refundAddress"`
+	} `json:"action"`
+	Result *struct {
+		Address *common.Address `json:"address"`
+		Code    hexutil.Bytes   `json:"code"`
+		Output  hexutil.Bytes   `json:"output"`
+	} `json:"result"`
+}
+
+// from returns the address that sent the call, created the contract or self destructed.
+func (t *flatTrace) from() common.Address {
+	switch {
+	case t.Action.From != nil:
+		return *t.Action.From
+	case t.Action.Address != nil:
+		return *t.Action.Address
+	default:
+		return common.Address{}
+	}
+}
+
+// to returns the address that received the call, was created or received the balance
+// of the self destructed contract.
+func (t *flatTrace) to() common.Address {
+	switch {
+	case t.Action.To != nil:
+		return *t.Action.To
+	case t.Action.RefundAddress != nil:
+		return *t.Action.RefundAddress
+	case t.Result != nil && t.Result.Address != nil:
+		return *t.Result.Address
+	default:
+		return common.Address{}
+	}
+}
+
+// Block returns the traces of all the transactions in the block [number].
+func (api *TraceAPI) Block(ctx context.Context, number rpc.BlockNumber) ([]json.RawMessage, error) {
+	block, err := api.api.blockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	txTraces, err := api.traceBlock(ctx, block)
+	if err != nil {
+		return nil, err
+	}
+	traces := []json.RawMessage{}
+	for _, txTrace := range txTraces {
+		for _, trace := range txTrace {
+			traces = append(traces, trace.raw)
+		}
+	}
+	return traces, nil
+}
+
+// Transaction returns the traces of the transaction [hash].
+func (api *TraceAPI) Transaction(ctx context.Context, hash common.Hash) ([]json.RawMessage, error) {
+	tracer := flatCallTracer
+	result, err := api.api.TraceTransaction(ctx, hash, &TraceConfig{Tracer: &tracer})
+	if err != nil {
+		return nil, err
+	}
+	traces, err := decodeFlatTraces(result)
+	if err != nil {
+		return nil, err
+	}
+	raw := make([]json.RawMessage, len(traces))
+	for i, trace := range traces {
+		raw[i] = trace.raw
+	}
+	return raw, nil
+}
+
+// Filter returns the traces of the transactions in the blocks [args.FromBlock, args.ToBlock]
+// that match the addresses of [args], skipping the first [args.After] matches and returning
+// at most [args.Count] traces. Both ends of the range default to the latest block. Ranges
+// spanning more than the configured maximum number of blocks are rejected.
+func (api *TraceAPI) Filter(ctx context.Context, args TraceFilterArgs) ([]json.RawMessage, error) {
+	fromNumber, toNumber := rpc.LatestBlockNumber, rpc.LatestBlockNumber
+	if args.FromBlock != nil {
+		fromNumber = *args.FromBlock
+	}
+	if args.ToBlock != nil {
+		toNumber = *args.ToBlock
+	}
+	from, err := api.api.blockByNumber(ctx, fromNumber)
+	if err != nil {
+		return nil, err
+	}
+	to, err := api.api.blockByNumber(ctx, toNumber)
+	if err != nil {
+		return nil, err
+	}
+	if from.NumberU64() > to.NumberU64() {
+		return nil, fmt.Errorf("end block (#%d) needs to come after start block (#%d)", to.NumberU64(), from.NumberU64())
+	}
+	if maxBlocks := api.api.backend.GetMaxTraceFilterBlocks(); maxBlocks > 0 && to.NumberU64()-from.NumberU64() > uint64(maxBlocks) {
+		return nil, fmt.Errorf("requested too many blocks from %d to %d, maximum is set to %d", from.NumberU64(), to.NumberU64(), maxBlocks)
+	}
+
+	var (
+		fromAddresses = addressSet(args.FromAddress)
+		toAddresses   = addressSet(args.ToAddress)
+		skip          uint64
+		traces        = []json.RawMessage{}
+	)
+	if args.After != nil {
+		skip = *args.After
+	}
+	if args.Count != nil && *args.Count == 0 {
+		return traces, nil
+	}
+	for number := from.NumberU64(); number <= to.NumberU64(); number++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		// The genesis block has no transactions to trace.
+		if number == 0 {
+			continue
+		}
+		block := to
+		if number != to.NumberU64() {
+			if block, err = api.api.blockByNumber(ctx, rpc.BlockNumber(number)); err != nil {
+				return nil, err
+			}
+		}
+		if len(block.Transactions()) == 0 {
+			continue
+		}
+		txTraces, err := api.traceBlock(ctx, block)
+		if err != nil {
+			return nil, err
+		}
+		for _, txTrace := range txTraces {
+			for _, trace := range txTrace {
+				if !matchAddress(fromAddresses, trace.from()) || !matchAddress(toAddresses, trace.to()) {
+					continue
+				}
+				if skip > 0 {
+					skip--
+					continue
+				}
+				traces = append(traces, trace.raw)
+				if args.Count != nil && uint64(len(traces)) >= *args.Count {
+					return traces, nil
+				}
+			}
+		}
+	}
+	return traces, nil
+}
+
+// ReplayBlockTransactions replays all the transactions in the block [number] and returns
+// the requested [traceTypes] of each of them. Only the "trace" type is supported.
+func (api *TraceAPI) ReplayBlockTransactions(ctx context.Context, number rpc.BlockNumber, traceTypes []string) ([]*TraceResults, error) {
+	var withTrace bool
+	for _, traceType := range traceTypes {
+		if traceType != "trace" {
+			return nil, fmt.Errorf("unsupported trace type %q", traceType)
+		}
+		withTrace = true
+	}
+	block, err := api.api.blockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	txTraces, err := api.traceBlock(ctx, block)
+	if err != nil {
+		return nil, err
+	}
+	txs := block.Transactions()
+	results := make([]*TraceResults, len(txTraces))
+	for i, txTrace := range txTraces {
+		result := &TraceResults{TransactionHash: txs[i].Hash()}
+		// The first trace is the one of the transaction itself.
+		if len(txTrace) > 0 && txTrace[0].Result != nil {
+			result.Output = txTrace[0].Result.Output
+			if result.Output == nil {
+				result.Output = txTrace[0].Result.Code
+			}
+		}
+		if result.Output == nil {
+			result.Output = hexutil.Bytes{}
+		}
+		if withTrace {
+			result.Trace = make([]json.RawMessage, len(txTrace))
+			for j, trace := range txTrace {
+				result.Trace[j] = trace.raw
+			}
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+// traceBlock returns the traces of each transaction in [block].
+func (api *TraceAPI) traceBlock(ctx context.Context, block *types.Block) ([][]*flatTrace, error) {
+	tracer := flatCallTracer
+	results, err := api.api.traceBlock(ctx, block, &TraceConfig{Tracer: &tracer})
+	if err != nil {
+		return nil, err
+	}
+	traces := make([][]*flatTrace, len(results))
+	for i, result := range results {
+		if result.Error != "" {
+			return nil, fmt.Errorf("tracing transaction %d of block #%d failed: %s", i, block.NumberU64(), result.Error)
+		}
+		if traces[i], err = decodeFlatTraces(result.Result); err != nil {
+			return nil, err
+		}
+	}
+	return traces, nil
+}
+
+// decodeFlatTraces decodes the [result] of the flatCallTracer.
+func decodeFlatTraces(result interface{}) ([]*flatTrace, error) {
+	encoded, ok := result.(json.RawMessage)
+	if !ok {
+		return nil, errors.New("unexpected result of the flat call tracer")
+	}
+	var raws []json.RawMessage
+	if err := json.Unmarshal(encoded, &raws); err != nil {
+		return nil, err
+	}
+	traces := make([]*flatTrace, len(raws))
+	for i, raw := range raws {
+		trace := &flatTrace{raw: raw}
+		if err := json.Unmarshal(raw, trace); err != nil {
+			return nil, err
+		}
+		traces[i] = trace
+	}
+	return traces, nil
+}
+
+func addressSet(addresses []common.Address) map[common.Address]struct{} {
+	set := make(map[common.Address]struct{}, len(addresses))
+	for _, addr := range addresses {
+		set[addr] = struct{}{}
+	}
+	return set
+}
+
+// matchAddress returns true if [addr] is in [set] or [set] is empty.
+func matchAddress(set map[common.Address]struct{}, addr common.Address) bool {
+	if len(set) == 0 {
+		return true
+	}
+	_, ok := set[addr]
+	return ok
+}
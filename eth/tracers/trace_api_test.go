@@ -0,0 +1,236 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package tracers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/core/vm"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/rpc"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func init() {
+	RegisterLookup(false, func(name string, ctx *Context, cfg json.RawMessage) (Tracer, error) {
+		if name != flatCallTracer {
+			return nil, errors.New("no tracer found")
+		}
+		return &testFlatCallTracer{ctx: ctx}, nil
+	})
+}
+
+// testFlatCallTracer stands in for the native flatCallTracer, which cannot be imported
+// by the tests of this package, and reports the transaction as a single trace.
+type testFlatCallTracer struct {
+	ctx      *Context
+	from, to common.Address
+}
+
+type testFlatCallTrace struct {
+	Action struct {
+		From common.Address `json:"from"`
+		To   common.Address `json:"to"`
+	} `json:"action"`
+	BlockNumber     uint64      `json:"blockNumber"`
+	Result          *testResult `json:"result"`
+	TraceAddress    []int       `json:"traceAddress"`
+	TransactionHash common.Hash `json:"transactionHash"`
+	Type            string      `json:"type"`
+}
+
+type testResult struct {
+	Output hexutil.Bytes `json:"output"`
+}
+
+func (t *testFlatCallTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.from, t.to = from, to
+}
+
+func (t *testFlatCallTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+}
+
+func (t *testFlatCallTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+}
+
+func (t *testFlatCallTracer) CaptureExit(output []byte, gasUsed uint64, err error) {}
+
+func (t *testFlatCallTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+func (t *testFlatCallTracer) CaptureEnd(output []byte, gasUsed uint64, _ time.Duration, err error) {}
+
+func (t *testFlatCallTracer) GetResult() (json.RawMessage, error) {
+	trace := testFlatCallTrace{
+		BlockNumber:     t.ctx.BlockNumber.Uint64(),
+		Result:          &testResult{Output: hexutil.Bytes{}},
+		TraceAddress:    []int{},
+		TransactionHash: t.ctx.TxHash,
+		Type:            "call",
+	}
+	trace.Action.From, trace.Action.To = t.from, t.to
+	return json.Marshal([]testFlatCallTrace{trace})
+}
+
+func (t *testFlatCallTracer) Stop(err error) {}
+
+func TestTraceAPI(t *testing.T) {
+	t.Parallel()
+
+	// Initialize test accounts
+	accounts := newAccounts(3)
+	genesis := &core.Genesis{Alloc: core.GenesisAlloc{
+		accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+		accounts[1].addr: {Balance: big.NewInt(params.Ether)},
+	}}
+	genBlocks := 5
+	signer := types.HomesteadSigner{}
+	var txHashes []common.Hash
+	backend := newTestBackend(t, genBlocks, genesis, func(i int, b *core.BlockGen) {
+		// The last block is empty
+		if i == genBlocks-1 {
+			return
+		}
+		// Transfer from account[0] in odd blocks and account[1] in even blocks to account[2]
+		sender := accounts[i%2]
+		tx, _ := types.SignTx(types.NewTransaction(b.TxNonce(sender.addr), accounts[2].addr, big.NewInt(1000), params.TxGas, new(big.Int).Add(b.BaseFee(), big.NewInt(int64(500*params.GWei))), nil), signer, sender.key)
+		b.AddTx(tx)
+		txHashes = append(txHashes, tx.Hash())
+	})
+	backend.maxTraceFilterBlocks = 4
+	api := NewTraceAPI(backend)
+	decode := func(raw []json.RawMessage) []*testFlatCallTrace {
+		traces := make([]*testFlatCallTrace, len(raw))
+		for i, r := range raw {
+			traces[i] = new(testFlatCallTrace)
+			if err := json.Unmarshal(r, traces[i]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return traces
+	}
+	blockNumbers := func(traces []*testFlatCallTrace) []uint64 {
+		numbers := []uint64{}
+		for _, trace := range traces {
+			numbers = append(numbers, trace.BlockNumber)
+		}
+		return numbers
+	}
+	blockNumber := func(n rpc.BlockNumber) *rpc.BlockNumber { return &n }
+	uint64Ptr := func(n uint64) *uint64 { return &n }
+
+	// trace_block and trace_transaction
+	raw, err := api.Block(context.Background(), rpc.BlockNumber(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	traces := decode(raw)
+	if len(traces) != 1 || traces[0].TransactionHash != txHashes[2] || traces[0].Action.From != accounts[0].addr || traces[0].Action.To != accounts[2].addr {
+		t.Fatalf("unexpected traces of block 3: %+v", traces)
+	}
+	raw, err = api.Transaction(context.Background(), txHashes[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if traces := decode(raw); len(traces) != 1 || traces[0].BlockNumber != 2 || traces[0].Action.From != accounts[1].addr {
+		t.Fatalf("unexpected traces of transaction %s: %+v", txHashes[1].Hex(), traces)
+	}
+	if _, err := api.Block(context.Background(), rpc.BlockNumber(0)); err == nil {
+		t.Fatal("expected tracing the genesis block to fail")
+	}
+	raw, err = api.Block(context.Background(), rpc.BlockNumber(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw == nil || len(raw) != 0 {
+		t.Fatalf("expected no traces of empty block 5, got %v", raw)
+	}
+
+	// trace_filter
+	testSuite := []struct {
+		args      TraceFilterArgs
+		expect    []uint64
+		expectErr bool
+	}{
+		{
+			args:   TraceFilterArgs{FromBlock: blockNumber(0), ToBlock: blockNumber(4)},
+			expect: []uint64{1, 2, 3, 4},
+		},
+		// Both ends of the range default to the latest block
+		{
+			args:   TraceFilterArgs{},
+			expect: []uint64{},
+		},
+		{
+			args:   TraceFilterArgs{FromBlock: blockNumber(4)},
+			expect: []uint64{4},
+		},
+		{
+			args:   TraceFilterArgs{FromBlock: blockNumber(1), ToBlock: blockNumber(4), FromAddress: []common.Address{accounts[0].addr}},
+			expect: []uint64{1, 3},
+		},
+		{
+			args:   TraceFilterArgs{FromBlock: blockNumber(1), ToBlock: blockNumber(4), FromAddress: []common.Address{accounts[0].addr, accounts[1].addr}, ToAddress: []common.Address{accounts[2].addr}},
+			expect: []uint64{1, 2, 3, 4},
+		},
+		{
+			args:   TraceFilterArgs{FromBlock: blockNumber(1), ToBlock: blockNumber(4), ToAddress: []common.Address{accounts[0].addr}},
+			expect: []uint64{},
+		},
+		{
+			args:   TraceFilterArgs{FromBlock: blockNumber(1), ToBlock: blockNumber(4), After: uint64Ptr(1), Count: uint64Ptr(2)},
+			expect: []uint64{2, 3},
+		},
+		{
+			args:   TraceFilterArgs{FromBlock: blockNumber(1), ToBlock: blockNumber(4), Count: uint64Ptr(0)},
+			expect: []uint64{},
+		},
+		// The range spans more than the maximum number of blocks
+		{
+			args:      TraceFilterArgs{FromBlock: blockNumber(0), ToBlock: blockNumber(5)},
+			expectErr: true,
+		},
+		{
+			args:      TraceFilterArgs{FromBlock: blockNumber(3), ToBlock: blockNumber(2)},
+			expectErr: true,
+		},
+	}
+	for i, testspec := range testSuite {
+		raw, err := api.Filter(context.Background(), testspec.args)
+		if testspec.expectErr {
+			if err == nil {
+				t.Errorf("test %d: expected error, got nothing", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test %d: expected no error, got %v", i, err)
+			continue
+		}
+		if have := blockNumbers(decode(raw)); !reflect.DeepEqual(have, testspec.expect) {
+			t.Errorf("test %d: block numbers mismatch, want %v, got %v", i, testspec.expect, have)
+		}
+	}
+
+	// trace_replayBlockTransactions
+	results, err := api.ReplayBlockTransactions(context.Background(), rpc.BlockNumber(2), []string{"trace"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].TransactionHash != txHashes[1] || len(results[0].Trace) != 1 || results[0].StateDiff != nil || results[0].VMTrace != nil {
+		t.Fatalf("unexpected replay results of block 2: %+v", results)
+	}
+	if _, err := api.ReplayBlockTransactions(context.Background(), rpc.BlockNumber(2), []string{"trace", "vmTrace"}); err == nil {
+		t.Fatal("expected the vmTrace trace type to be unsupported")
+	}
+}
@@ -44,6 +44,7 @@ import (
 	"github.com/ava-labs/subnet-evm/core/vm"
 	"github.com/ava-labs/subnet-evm/eth/tracers/logger"
 	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
 	"github.com/ava-labs/subnet-evm/rpc"
 	"github.com/ava-labs/subnet-evm/vmerrs"
 	"github.com/davecgh/go-spew/spew"
@@ -635,6 +636,84 @@ func (s *PublicBlockChainAPI) FeeConfig(ctx context.Context, blockNrOrHash *rpc.
 	return &FeeConfigResult{FeeConfig: feeConfig, LastChangedAt: lastChangedAt}, nil
 }
 
+type FeeConfigChangeResult struct {
+	BlockNumber hexutil.Uint64       `json:"blockNumber"`
+	BlockHash   common.Hash          `json:"blockHash"`
+	Timestamp   hexutil.Uint64       `json:"timestamp"`
+	FeeConfig   commontype.FeeConfig `json:"feeConfig"`
+	TxHash      *common.Hash         `json:"transactionHash"`
+	Sender      *common.Address      `json:"sender"`
+}
+
+// FeeConfigHistory returns every change of the fee config by the fee config manager precompile
+// in the accepted blocks from [fromBlock] to [toBlock] inclusive, ordered by block number.
+// The transaction hash and sender are null for changes that were not made by a transaction,
+// such as the initial fee config set when the fee config manager activates. Returns an error
+// if [fromBlock] is below the first block of the fee config history indexed by this node.
+func (s *PublicBlockChainAPI) FeeConfigHistory(ctx context.Context, fromBlock rpc.BlockNumber, toBlock rpc.BlockNumber) ([]*FeeConfigChangeResult, error) {
+	from, err := s.b.HeaderByNumber(ctx, fromBlock)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.b.HeaderByNumber(ctx, toBlock)
+	if err != nil {
+		return nil, err
+	}
+	if from == nil || to == nil {
+		return nil, errors.New("block not found")
+	}
+	if from.Number.Cmp(to.Number) > 0 {
+		return nil, fmt.Errorf("invalid block range: fromBlock %d is after toBlock %d", from.Number, to.Number)
+	}
+
+	changes, err := s.b.GetFeeConfigChanges(from.Number.Uint64(), to.Number.Uint64())
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*FeeConfigChangeResult, 0, len(changes))
+	for _, change := range changes {
+		result := &FeeConfigChangeResult{
+			BlockNumber: hexutil.Uint64(change.BlockNumber),
+			BlockHash:   change.BlockHash,
+			Timestamp:   hexutil.Uint64(change.Timestamp),
+			FeeConfig:   change.FeeConfig,
+		}
+		if change.TxHash != (common.Hash{}) {
+			txHash, sender := change.TxHash, change.Sender
+			result.TxHash = &txHash
+			result.Sender = &sender
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+type PauseStatusResult struct {
+	Enabled bool `json:"enabled"`
+	Paused  bool `json:"paused"`
+}
+
+// PauseStatus returns whether the pauser precompile is enabled and whether the chain is paused
+// in the state of the given block. If [blockNrOrHash] is nil, the latest block is used.
+func (s *PublicBlockChainAPI) PauseStatus(ctx context.Context, blockNrOrHash *rpc.BlockNumberOrHash) (*PauseStatusResult, error) {
+	if blockNrOrHash == nil {
+		latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+		blockNrOrHash = &latest
+	}
+	state, header, err := s.b.StateAndHeaderByNumberOrHash(ctx, *blockNrOrHash)
+	if state == nil || err != nil {
+		return nil, err
+	}
+
+	result := &PauseStatusResult{
+		Enabled: s.b.ChainConfig().IsPrecompileEnabled(precompile.PauserAddress, new(big.Int).SetUint64(header.Time)),
+	}
+	if result.Enabled {
+		result.Paused = precompile.IsPaused(state)
+	}
+	return result, state.Error()
+}
+
 // BlockNumber returns the block number of the chain head.
 func (s *PublicBlockChainAPI) BlockNumber() hexutil.Uint64 {
 	header, _ := s.b.HeaderByNumber(context.Background(), rpc.LatestBlockNumber) // latest header should always be available
@@ -1059,18 +1138,28 @@ func DoEstimateGas(ctx context.Context, b Backend, args TransactionArgs, blockNr
 	}
 	// Recap the highest gas limit with account's available balance.
 	if feeCap.BitLen() != 0 {
-		state, _, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+		state, header, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
 		if err != nil {
 			return 0, err
 		}
 		balance := state.GetBalance(*args.From) // from can't be nil
 		available := new(big.Int).Set(balance)
+		// If the gas of the call is sponsored, the sender only needs to afford the
+		// transfer and the gas is capped by the allowance of the sponsor instead.
+		sponsorAvailable := new(big.Int)
+		if b.ChainConfig().IsPrecompileEnabled(precompile.GasSponsorAddress, new(big.Int).SetUint64(header.Time)) &&
+			(args.Value == nil || args.Value.ToInt().Cmp(balance) <= 0) {
+			sponsorAvailable = precompile.GetGasSponsorAllowance(state, *args.From, args.To)
+		}
 		if args.Value != nil {
-			if args.Value.ToInt().Cmp(available) >= 0 {
+			if args.Value.ToInt().Cmp(available) >= 0 && sponsorAvailable.Sign() == 0 {
 				return 0, errors.New("insufficient funds for transfer")
 			}
 			available.Sub(available, args.Value.ToInt())
 		}
+		if sponsorAvailable.Cmp(available) > 0 {
+			available = sponsorAvailable
+		}
 		allowance := new(big.Int).Div(available, feeCap)
 
 		// If the allowance is larger than maximum uint64, skip checking
@@ -1653,6 +1742,16 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, ha
 	if err != nil {
 		return nil, nil
 	}
+	if tx == nil {
+		// Pending transactions, transactions in blocks that are not yet
+		// accepted and unknown transactions have no receipt. Only report an
+		// error if some accepted blocks are not indexed, since the
+		// transaction may then be in one of them.
+		if s.b.GetPoolTransaction(hash) != nil {
+			return nil, nil
+		}
+		return nil, s.b.TxLookupError()
+	}
 	header, err := s.b.HeaderByHash(ctx, blockHash)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,86 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package ethapi
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// receiptTestBackend serves transaction lookups for the receipt tests. Calling
+// any other method of [Backend] panics.
+type receiptTestBackend struct {
+	Backend
+
+	pool        map[common.Hash]*types.Transaction
+	txLookupErr error
+}
+
+func (b *receiptTestBackend) GetTransaction(context.Context, common.Hash) (*types.Transaction, common.Hash, uint64, uint64, error) {
+	return nil, common.Hash{}, 0, 0, nil
+}
+
+func (b *receiptTestBackend) GetPoolTransaction(hash common.Hash) *types.Transaction {
+	return b.pool[hash]
+}
+
+func (b *receiptTestBackend) TxLookupError() error { return b.txLookupErr }
+
+func (b *receiptTestBackend) ChainConfig() *params.ChainConfig { return params.TestChainConfig }
+
+func TestGetTransactionReceiptNotFound(t *testing.T) {
+	var (
+		pending = types.NewTransaction(0, common.Address{1}, big.NewInt(1), 21000, big.NewInt(1), nil)
+		unknown = common.Hash{2}
+	)
+	tests := map[string]struct {
+		txLookupErr error
+		hash        common.Hash
+		expectedErr error
+	}{
+		"pending, all indexed": {
+			hash: pending.Hash(),
+		},
+		"unknown, all indexed": {
+			hash: unknown,
+		},
+		"pending, lookup limit": {
+			txLookupErr: fmt.Errorf("%w: only the transactions of the last 10 accepted blocks are indexed", core.ErrTxNotIndexed),
+			hash:        pending.Hash(),
+		},
+		"unknown, lookup limit": {
+			txLookupErr: fmt.Errorf("%w: only the transactions of the last 10 accepted blocks are indexed", core.ErrTxNotIndexed),
+			hash:        unknown,
+			expectedErr: core.ErrTxNotIndexed,
+		},
+		"pending, indexing": {
+			txLookupErr: fmt.Errorf("%w: the transactions of blocks below 5 are not indexed yet", core.ErrTxIndexing),
+			hash:        pending.Hash(),
+		},
+		"unknown, indexing": {
+			txLookupErr: fmt.Errorf("%w: the transactions of blocks below 5 are not indexed yet", core.ErrTxIndexing),
+			hash:        unknown,
+			expectedErr: core.ErrTxIndexing,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			api := NewPublicTransactionPoolAPI(&receiptTestBackend{
+				pool:        map[common.Hash]*types.Transaction{pending.Hash(): pending},
+				txLookupErr: test.txLookupErr,
+			}, nil)
+			receipt, err := api.GetTransactionReceipt(context.Background(), test.hash)
+			assert.ErrorIs(t, err, test.expectedErr)
+			assert.Nil(t, receipt)
+		})
+	}
+}
@@ -44,6 +44,7 @@ import (
 	"github.com/ava-labs/subnet-evm/core/state"
 	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
@@ -163,6 +164,15 @@ func (w *worker) commitNewWork() (*types.Block, error) {
 		return nil, errors.New("cannot mine without etherbase")
 	}
 	header.Coinbase = w.coinbase
+	// If the RewardManager precompile (or the chain config) does not allow block producers
+	// to choose their own fee recipient, fees must be paid to the configured coinbase.
+	configuredCoinbase, allowFeeRecipients, err := w.chain.GetCoinbaseAt(parent.Header(), header.Time)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get configured coinbase: %w", err)
+	}
+	if !allowFeeRecipients {
+		header.Coinbase = configuredCoinbase
+	}
 	if err := w.engine.Prepare(w.chain, header); err != nil {
 		return nil, fmt.Errorf("failed to prepare header for mining: %w", err)
 	}
@@ -188,11 +198,11 @@ func (w *worker) commitNewWork() (*types.Block, error) {
 	}
 	if len(localTxs) > 0 {
 		txs := types.NewTransactionsByPriceAndNonce(env.signer, localTxs, header.BaseFee)
-		w.commitTransactions(env, txs, w.coinbase)
+		w.commitTransactions(env, txs, header.Coinbase)
 	}
 	if len(remoteTxs) > 0 {
 		txs := types.NewTransactionsByPriceAndNonce(env.signer, remoteTxs, header.BaseFee)
-		w.commitTransactions(env, txs, w.coinbase)
+		w.commitTransactions(env, txs, header.Coinbase)
 	}
 
 	return w.commit(env)
@@ -291,6 +301,12 @@ func (w *worker) commitTransactions(env *environment, txs *types.TransactionsByP
 			log.Trace("Skipping unsupported transaction type", "sender", from, "type", tx.Type())
 			txs.Pop()
 
+		case errors.Is(err, precompile.ErrChainPaused):
+			// The chain was paused, possibly by a transaction earlier in this block. Leave the
+			// transactions of the account in the pool until the chain is unpaused.
+			log.Trace("Skipping transaction while the chain is paused", "sender", from, "hash", tx.Hash())
+			txs.Pop()
+
 		default:
 			// Strange error, discard the transaction and get the next in line (note, the
 			// nonce-too-high clause will prevent us from executing in vain).
@@ -39,7 +39,8 @@ import (
 )
 
 var (
-	errNonGenesisForkByHeight = errors.New("subnet-evm only supports forking by height at the genesis block")
+	errNonGenesisForkByHeight        = errors.New("subnet-evm only supports forking by height at the genesis block")
+	errAllowListManagersNotActivated = errors.New("allow list managers cannot be assigned before the AllowListRoles upgrade")
 
 	SubnetEVMChainID = big.NewInt(43214)
 
@@ -88,8 +89,8 @@ var (
 		},
 	}
 
-	TestChainConfig        = &ChainConfig{big.NewInt(1), DefaultFeeConfig, false, big.NewInt(0), big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), NetworkUpgrades{big.NewInt(0)}, PrecompileUpgrade{}, UpgradeConfig{}}
-	TestPreSubnetEVMConfig = &ChainConfig{big.NewInt(1), DefaultFeeConfig, false, big.NewInt(0), big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), NetworkUpgrades{}, PrecompileUpgrade{}, UpgradeConfig{}}
+	TestChainConfig        = &ChainConfig{big.NewInt(1), DefaultFeeConfig, false, big.NewInt(0), big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), NetworkUpgrades{big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0)}, Precompiles{}, UpgradeConfig{}}
+	TestPreSubnetEVMConfig = &ChainConfig{big.NewInt(1), DefaultFeeConfig, false, big.NewInt(0), big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), NetworkUpgrades{}, Precompiles{}, UpgradeConfig{}}
 )
 
 // ChainConfig is the core config which determines the blockchain settings.
@@ -117,9 +118,45 @@ type ChainConfig struct {
 	IstanbulBlock       *big.Int `json:"istanbulBlock,omitempty"`       // Istanbul switch block (nil = no fork, 0 = already on istanbul)
 	MuirGlacierBlock    *big.Int `json:"muirGlacierBlock,omitempty"`    // Eip-2384 (bomb delay) switch block (nil = no fork, 0 = already activated)
 
-	NetworkUpgrades              // Config for timestamps that enable avalanche network upgrades
-	PrecompileUpgrade            // Config for enabling precompiles from genesis
-	UpgradeConfig     `json:"-"` // Config specified in upgradeBytes (avalanche network upgrades or enable/disabling precompiles). Skip encoding/decoding directly into ChainConfig.
+	NetworkUpgrades                // Config for timestamps that enable avalanche network upgrades
+	GenesisPrecompiles Precompiles `json:"-"` // Config for enabling precompiles from genesis. Encoded as top level keys of the ChainConfig JSON by [MarshalJSON].
+	UpgradeConfig      `json:"-"`  // Config specified in upgradeBytes (avalanche network upgrades or enable/disabling precompiles). Skip encoding/decoding directly into ChainConfig.
+}
+
+// UnmarshalJSON parses the JSON-encoded data into [c]. The config of each
+// precompile enabled from genesis is read from the top level key registered
+// for it in the precompile registry.
+func (c *ChainConfig) UnmarshalJSON(data []byte) error {
+	// chainConfigJSON does not have the methods of ChainConfig, which avoids
+	// recursing into this function.
+	type chainConfigJSON ChainConfig
+	if err := json.Unmarshal(data, (*chainConfigJSON)(c)); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &c.GenesisPrecompiles)
+}
+
+// MarshalJSON returns the JSON encoding of [c], including the config of each
+// precompile enabled from genesis as a top level key.
+func (c ChainConfig) MarshalJSON() ([]byte, error) {
+	type chainConfigJSON ChainConfig
+	data, err := json.Marshal(chainConfigJSON(c))
+	if err != nil || len(c.GenesisPrecompiles) == 0 {
+		return data, err
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for key, config := range c.GenesisPrecompiles {
+		configBytes, err := json.Marshal(config)
+		if err != nil {
+			return nil, err
+		}
+		fields[key] = configBytes
+	}
+	return json.Marshal(fields)
 }
 
 // UpgradeConfig includes the following configs that may be specified in upgradeBytes:
@@ -146,16 +183,16 @@ func (c *ChainConfig) String() string {
 	if err != nil {
 		networkUpgradesBytes = []byte("cannot marshal NetworkUpgrades")
 	}
-	precompileUpgradeBytes, err := json.Marshal(c.PrecompileUpgrade)
+	genesisPrecompilesBytes, err := json.Marshal(c.GenesisPrecompiles)
 	if err != nil {
-		precompileUpgradeBytes = []byte("cannot marshal PrecompileUpgrade")
+		genesisPrecompilesBytes = []byte("cannot marshal GenesisPrecompiles")
 	}
 	upgradeConfigBytes, err := json.Marshal(c.UpgradeConfig)
 	if err != nil {
 		upgradeConfigBytes = []byte("cannot marshal UpgradeConfig")
 	}
 
-	return fmt.Sprintf("{ChainID: %v Homestead: %v EIP150: %v EIP155: %v EIP158: %v Byzantium: %v Constantinople: %v Petersburg: %v Istanbul: %v, Muir Glacier: %v, Subnet EVM: %v, FeeConfig: %v, AllowFeeRecipients: %v, NetworkUpgrades: %v, PrecompileUpgrade: %v, UpgradeConfig: %v, Engine: Dummy Consensus Engine}",
+	return fmt.Sprintf("{ChainID: %v Homestead: %v EIP150: %v EIP155: %v EIP158: %v Byzantium: %v Constantinople: %v Petersburg: %v Istanbul: %v, Muir Glacier: %v, Subnet EVM: %v, FeeConfig: %v, AllowFeeRecipients: %v, NetworkUpgrades: %v, GenesisPrecompiles: %v, UpgradeConfig: %v, Engine: Dummy Consensus Engine}",
 		c.ChainID,
 		c.HomesteadBlock,
 		c.EIP150Block,
@@ -170,7 +207,7 @@ func (c *ChainConfig) String() string {
 		string(feeBytes),
 		c.AllowFeeRecipients,
 		string(networkUpgradesBytes),
-		string(precompileUpgradeBytes),
+		string(genesisPrecompilesBytes),
 		string(upgradeConfigBytes),
 	)
 }
@@ -227,28 +264,29 @@ func (c *ChainConfig) IsSubnetEVM(blockTimestamp *big.Int) bool {
 	return utils.IsForked(c.getNetworkUpgrades().SubnetEVMTimestamp, blockTimestamp)
 }
 
-// IsContractDeployerAllowList returns whether [blockTimestamp] is either equal to the ContractDeployerAllowList fork block timestamp or greater.
-func (c *ChainConfig) IsContractDeployerAllowList(blockTimestamp *big.Int) bool {
-	config := c.GetContractDeployerAllowListConfig(blockTimestamp)
-	return config != nil && !config.Disable
+// IsPrecompileLogs returns whether [blockTimestamp] is either equal to the PrecompileLogs fork block timestamp or greater.
+// Once activated, stateful precompiles emit EVM logs for the state changes they make.
+func (c *ChainConfig) IsPrecompileLogs(blockTimestamp *big.Int) bool {
+	return utils.IsForked(c.getNetworkUpgrades().PrecompileLogsTimestamp, blockTimestamp)
 }
 
-// IsContractNativeMinter returns whether [blockTimestamp] is either equal to the NativeMinter fork block timestamp or greater.
-func (c *ChainConfig) IsContractNativeMinter(blockTimestamp *big.Int) bool {
-	config := c.GetContractNativeMinterConfig(blockTimestamp)
-	return config != nil && !config.Disable
+// IsDurango returns whether [blockTimestamp] is either equal to the Durango fork block timestamp or greater.
+// Durango activates the Shanghai EIPs that apply to Avalanche: EIP-3651 (warm COINBASE),
+// EIP-3855 (PUSH0) and EIP-3860 (limit and meter initcode).
+func (c *ChainConfig) IsDurango(blockTimestamp *big.Int) bool {
+	return utils.IsForked(c.getNetworkUpgrades().DurangoTimestamp, blockTimestamp)
 }
 
-// IsTxAllowList returns whether [blockTimestamp] is either equal to the TxAllowList fork block timestamp or greater.
-func (c *ChainConfig) IsTxAllowList(blockTimestamp *big.Int) bool {
-	config := c.GetTxAllowListConfig(blockTimestamp)
-	return config != nil && !config.Disable
+// IsAllowListRoles returns whether [blockTimestamp] is either equal to the AllowListRoles fork block timestamp or greater.
+// Once activated, the allow lists of stateful precompiles support the manager role and time-bound grants.
+func (c *ChainConfig) IsAllowListRoles(blockTimestamp *big.Int) bool {
+	return utils.IsForked(c.getNetworkUpgrades().AllowListRolesTimestamp, blockTimestamp)
 }
 
-// IsFeeConfigManager returns whether [blockTimestamp] is either equal to the FeeConfigManager fork block timestamp or greater.
-func (c *ChainConfig) IsFeeConfigManager(blockTimestamp *big.Int) bool {
-	config := c.GetFeeConfigManagerConfig(blockTimestamp)
-	return config != nil && !config.Disable
+// IsNativeMinterSupply returns whether [blockTimestamp] is either equal to the NativeMinterSupply fork block timestamp or greater.
+// Once activated, the native minter tracks the minted supply, enforces its supply cap and minter quotas and supports burning.
+func (c *ChainConfig) IsNativeMinterSupply(blockTimestamp *big.Int) bool {
+	return utils.IsForked(c.getNetworkUpgrades().NativeMinterSupplyTimestamp, blockTimestamp)
 }
 
 // CheckCompatible checks whether scheduled fork transitions have been imported
@@ -333,10 +371,13 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 	// Instead, we check only that Phases are enabled in order.
 	// Note: we do not add the optional stateful precompile configs in here because they are optional
 	// and independent, such that the ordering they are enabled does not impact the correctness of the
-	// chain config.
+	// chain config. The same holds for upgrades that only change the behavior of a precompile, such as
+	// [AllowListRolesTimestamp] and [NativeMinterSupplyTimestamp].
 	lastFork = fork{}
 	for _, cur := range []fork{
 		{name: "subnetEVMTimestamp", block: c.SubnetEVMTimestamp},
+		{name: "precompileLogsTimestamp", block: c.PrecompileLogsTimestamp, optional: true},
+		{name: "durangoTimestamp", block: c.DurangoTimestamp, optional: true},
 	} {
 		if lastFork.name != "" {
 			// Next one must be higher number
@@ -476,13 +517,11 @@ type Rules struct {
 	IsByzantium, IsConstantinople, IsPetersburg, IsIstanbul bool
 
 	// Rules for Avalanche releases
-	IsSubnetEVM bool
-
-	// Optional stateful precompile rules
-	IsContractDeployerAllowListEnabled bool
-	IsContractNativeMinterEnabled      bool
-	IsTxAllowListEnabled               bool
-	IsFeeConfigManagerEnabled          bool
+	IsSubnetEVM          bool
+	IsPrecompileLogs     bool
+	IsDurango            bool
+	IsAllowListRoles     bool
+	IsNativeMinterSupply bool
 
 	// Precompiles maps addresses to stateful precompiled contracts that are enabled
 	// for this rule set.
@@ -491,6 +530,13 @@ type Rules struct {
 	Precompiles map[common.Address]precompile.StatefulPrecompiledContract
 }
 
+// IsPrecompileEnabled returns whether the stateful precompile at [address] is enabled
+// for this rule set.
+func (r *Rules) IsPrecompileEnabled(address common.Address) bool {
+	_, ok := r.Precompiles[address]
+	return ok
+}
+
 // Rules ensures c's ChainID is not nil.
 func (c *ChainConfig) rules(num *big.Int) Rules {
 	chainID := c.ChainID
@@ -516,10 +562,10 @@ func (c *ChainConfig) AvalancheRules(blockNum, blockTimestamp *big.Int) Rules {
 	rules := c.rules(blockNum)
 
 	rules.IsSubnetEVM = c.IsSubnetEVM(blockTimestamp)
-	rules.IsContractDeployerAllowListEnabled = c.IsContractDeployerAllowList(blockTimestamp)
-	rules.IsContractNativeMinterEnabled = c.IsContractNativeMinter(blockTimestamp)
-	rules.IsTxAllowListEnabled = c.IsTxAllowList(blockTimestamp)
-	rules.IsFeeConfigManagerEnabled = c.IsFeeConfigManager(blockTimestamp)
+	rules.IsPrecompileLogs = c.IsPrecompileLogs(blockTimestamp)
+	rules.IsDurango = c.IsDurango(blockTimestamp)
+	rules.IsAllowListRoles = c.IsAllowListRoles(blockTimestamp)
+	rules.IsNativeMinterSupply = c.IsNativeMinterSupply(blockTimestamp)
 
 	// Initialize the stateful precompiles that should be enabled at [blockTimestamp].
 	rules.Precompiles = make(map[common.Address]precompile.StatefulPrecompiledContract)
@@ -537,3 +583,8 @@ func (c *ChainConfig) AvalancheRules(blockNum, blockTimestamp *big.Int) Rules {
 func (c *ChainConfig) GetFeeConfig() commontype.FeeConfig {
 	return c.FeeConfig
 }
+
+// AllowedFeeRecipients returns true if fee recipients are allowed in the genesis.
+func (c *ChainConfig) AllowedFeeRecipients() bool {
+	return c.AllowFeeRecipients
+}
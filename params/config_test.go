@@ -127,6 +127,25 @@ func TestCheckCompatible(t *testing.T) {
 				RewindTo:     0,
 			},
 		},
+		{
+			stored:        &ChainConfig{NetworkUpgrades: NetworkUpgrades{DurangoTimestamp: big.NewInt(100)}},
+			new:           &ChainConfig{NetworkUpgrades: NetworkUpgrades{DurangoTimestamp: big.NewInt(200)}},
+			headHeight:    10,
+			headTimestamp: 50,
+			wantErr:       nil,
+		},
+		{
+			stored:        &ChainConfig{NetworkUpgrades: NetworkUpgrades{DurangoTimestamp: big.NewInt(100)}},
+			new:           &ChainConfig{NetworkUpgrades: NetworkUpgrades{DurangoTimestamp: big.NewInt(200)}},
+			headHeight:    10,
+			headTimestamp: 150,
+			wantErr: &ConfigCompatError{
+				What:         "Durango fork block timestamp",
+				StoredConfig: big.NewInt(100),
+				NewConfig:    big.NewInt(200),
+				RewindTo:     99,
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -136,3 +155,49 @@ func TestCheckCompatible(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckConfigForkOrderUpgrades(t *testing.T) {
+	tests := map[string]struct {
+		upgrades NetworkUpgrades
+		wantErr  bool
+	}{
+		"only subnet evm": {
+			upgrades: NetworkUpgrades{SubnetEVMTimestamp: big.NewInt(0)},
+		},
+		"precompile logs after subnet evm": {
+			upgrades: NetworkUpgrades{SubnetEVMTimestamp: big.NewInt(0), PrecompileLogsTimestamp: big.NewInt(10)},
+		},
+		"precompile logs before subnet evm": {
+			upgrades: NetworkUpgrades{SubnetEVMTimestamp: big.NewInt(10), PrecompileLogsTimestamp: big.NewInt(5)},
+			wantErr:  true,
+		},
+		"precompile logs without subnet evm": {
+			upgrades: NetworkUpgrades{PrecompileLogsTimestamp: big.NewInt(10)},
+			wantErr:  true,
+		},
+		"durango without precompile logs": {
+			upgrades: NetworkUpgrades{SubnetEVMTimestamp: big.NewInt(0), DurangoTimestamp: big.NewInt(10)},
+		},
+		"feature upgrades without network upgrades": {
+			upgrades: NetworkUpgrades{SubnetEVMTimestamp: big.NewInt(0), AllowListRolesTimestamp: big.NewInt(5), NativeMinterSupplyTimestamp: big.NewInt(5)},
+		},
+		"durango before precompile logs": {
+			upgrades: NetworkUpgrades{SubnetEVMTimestamp: big.NewInt(0), PrecompileLogsTimestamp: big.NewInt(10), DurangoTimestamp: big.NewInt(5)},
+			wantErr:  true,
+		},
+		"durango without subnet evm": {
+			upgrades: NetworkUpgrades{DurangoTimestamp: big.NewInt(10)},
+			wantErr:  true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := *TestChainConfig
+			config.NetworkUpgrades = test.upgrades
+			err := config.CheckConfigForkOrder()
+			if test.wantErr != (err != nil) {
+				t.Fatalf("expected error %v, got %v", test.wantErr, err)
+			}
+		})
+	}
+}
@@ -9,7 +9,11 @@ import (
 
 // NetworkUpgrades contains timestamps that enable avalanche network upgrades.
 type NetworkUpgrades struct {
-	SubnetEVMTimestamp *big.Int `json:"subnetEVMTimestamp,omitempty"` // A placeholder for the latest avalanche forks (nil = no fork, 0 = already activated)
+	SubnetEVMTimestamp          *big.Int `json:"subnetEVMTimestamp,omitempty"`          // A placeholder for the latest avalanche forks (nil = no fork, 0 = already activated)
+	PrecompileLogsTimestamp     *big.Int `json:"precompileLogsTimestamp,omitempty"`     // Stateful precompiles emit EVM logs (nil = no fork, 0 = already activated)
+	DurangoTimestamp            *big.Int `json:"durangoTimestamp,omitempty"`            // Shanghai equivalent EIPs: PUSH0, initcode limits and warm coinbase (nil = no fork, 0 = already activated)
+	AllowListRolesTimestamp     *big.Int `json:"allowListRolesTimestamp,omitempty"`     // Allow list manager role and time-bound grants (nil = no fork, 0 = already activated)
+	NativeMinterSupplyTimestamp *big.Int `json:"nativeMinterSupplyTimestamp,omitempty"` // Native minter supply cap, minter quotas and burning (nil = no fork, 0 = already activated)
 }
 
 func (n *NetworkUpgrades) CheckCompatible(newcfg *NetworkUpgrades, headTimestamp *big.Int) *ConfigCompatError {
@@ -17,6 +21,18 @@ func (n *NetworkUpgrades) CheckCompatible(newcfg *NetworkUpgrades, headTimestamp
 	if isForkIncompatible(n.SubnetEVMTimestamp, newcfg.SubnetEVMTimestamp, headTimestamp) {
 		return newCompatError("SubnetEVM fork block timestamp", n.SubnetEVMTimestamp, newcfg.SubnetEVMTimestamp)
 	}
+	if isForkIncompatible(n.PrecompileLogsTimestamp, newcfg.PrecompileLogsTimestamp, headTimestamp) {
+		return newCompatError("PrecompileLogs fork block timestamp", n.PrecompileLogsTimestamp, newcfg.PrecompileLogsTimestamp)
+	}
+	if isForkIncompatible(n.DurangoTimestamp, newcfg.DurangoTimestamp, headTimestamp) {
+		return newCompatError("Durango fork block timestamp", n.DurangoTimestamp, newcfg.DurangoTimestamp)
+	}
+	if isForkIncompatible(n.AllowListRolesTimestamp, newcfg.AllowListRolesTimestamp, headTimestamp) {
+		return newCompatError("AllowListRoles fork block timestamp", n.AllowListRolesTimestamp, newcfg.AllowListRolesTimestamp)
+	}
+	if isForkIncompatible(n.NativeMinterSupplyTimestamp, newcfg.NativeMinterSupplyTimestamp, headTimestamp) {
+		return newCompatError("NativeMinterSupply fork block timestamp", n.NativeMinterSupplyTimestamp, newcfg.NativeMinterSupplyTimestamp)
+	}
 
 	return nil
 }
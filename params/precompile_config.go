@@ -4,97 +4,144 @@
 package params
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"math/big"
 
 	"github.com/ava-labs/subnet-evm/precompile"
 	"github.com/ava-labs/subnet-evm/utils"
+	"github.com/ethereum/go-ethereum/common"
 )
 
-// precompileKey is a helper type used to reference each of the
-// possible stateful precompile types that can be activated
-// as a network upgrade.
-type precompileKey int
+// Precompiles maps the config key of each registered stateful precompile to
+// its config. Keys are the [ConfigKey] of the modules registered in the
+// precompile registry.
+type Precompiles map[string]precompile.StatefulPrecompileConfig
 
-const (
-	contractDeployerAllowListKey precompileKey = iota + 1
-	contractNativeMinterKey
-	txAllowListKey
-	feeManagerKey
-)
-
-var (
-	precompileKeys = []precompileKey{contractDeployerAllowListKey, contractNativeMinterKey, txAllowListKey, feeManagerKey}
-)
+// UnmarshalJSON parses the JSON-encoded data into [p], using the precompile
+// registry to construct the config of each registered precompile key.
+// Keys that do not belong to a registered precompile are ignored.
+func (p *Precompiles) UnmarshalJSON(data []byte) error {
+	raw := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	var precompiles Precompiles
+	for _, module := range precompile.RegisteredModules() {
+		value, ok := raw[module.ConfigKey]
+		if !ok || bytes.Equal(value, []byte("null")) {
+			continue
+		}
+		config := module.NewConfig()
+		if err := json.Unmarshal(value, config); err != nil {
+			return fmt.Errorf("failed to unmarshal %s: %w", module.ConfigKey, err)
+		}
+		if precompiles == nil {
+			precompiles = make(Precompiles)
+		}
+		precompiles[module.ConfigKey] = config
+	}
+	*p = precompiles
+	return nil
+}
 
 // PrecompileUpgrade is a helper struct embedded in UpgradeConfig, representing
-// each of the possible stateful precompile types that can be activated
-// as a network upgrade.
+// a stateful precompile being enabled or disabled as a network upgrade.
+// It is encoded in JSON as a single key-value pair, mapping the config key of
+// the precompile to its config.
 type PrecompileUpgrade struct {
-	ContractDeployerAllowListConfig *precompile.ContractDeployerAllowListConfig `json:"contractDeployerAllowListConfig,omitempty"` // Config for the contract deployer allow list precompile
-	ContractNativeMinterConfig      *precompile.ContractNativeMinterConfig      `json:"contractNativeMinterConfig,omitempty"`      // Config for the native minter precompile
-	TxAllowListConfig               *precompile.TxAllowListConfig               `json:"txAllowListConfig,omitempty"`               // Config for the tx allow list precompile
-	FeeManagerConfig                *precompile.FeeConfigManagerConfig          `json:"feeManagerConfig,omitempty"`                // Config for the fee manager precompile
+	Config precompile.StatefulPrecompileConfig
 }
 
-func (p *PrecompileUpgrade) getByKey(key precompileKey) (precompile.StatefulPrecompileConfig, bool) {
-	switch key {
-	case contractDeployerAllowListKey:
-		return p.ContractDeployerAllowListConfig, p.ContractDeployerAllowListConfig != nil
-	case contractNativeMinterKey:
-		return p.ContractNativeMinterConfig, p.ContractNativeMinterConfig != nil
-	case txAllowListKey:
-		return p.TxAllowListConfig, p.TxAllowListConfig != nil
-	case feeManagerKey:
-		return p.FeeManagerConfig, p.FeeManagerConfig != nil
-	default:
-		panic(fmt.Sprintf("unknown upgrade key: %v", key))
+// UnmarshalJSON parses the JSON-encoded data into [u]. Returns an error if the
+// data sets more than one registered precompile key.
+func (u *PrecompileUpgrade) UnmarshalJSON(data []byte) error {
+	var precompiles Precompiles
+	if err := json.Unmarshal(data, &precompiles); err != nil {
+		return err
+	}
+	if len(precompiles) > 1 {
+		return fmt.Errorf("PrecompileUpgrade has more than one key set")
+	}
+	u.Config = nil
+	for _, config := range precompiles {
+		u.Config = config
 	}
+	return nil
 }
 
-// VerifyPrecompileUpgrades checks [c.PrecompileUpgrades] is well formed:
-// - [upgrades] must specify exactly one key per PrecompileUpgrade
+// MarshalJSON returns the JSON encoding of [u].
+func (u PrecompileUpgrade) MarshalJSON() ([]byte, error) {
+	if u.Config == nil {
+		return []byte("{}"), nil
+	}
+	module, ok := precompile.GetModuleByAddress(u.Config.Address())
+	if !ok {
+		return nil, fmt.Errorf("no precompile registered at address %s", u.Config.Address())
+	}
+	return json.Marshal(map[string]precompile.StatefulPrecompileConfig{module.ConfigKey: u.Config})
+}
+
+// VerifyPrecompileUpgrades checks [c.GenesisPrecompiles] and [c.PrecompileUpgrades] are well formed:
+// - each genesis precompile must be registered under its config key
+// - [upgrades] must specify exactly one registered precompile per PrecompileUpgrade
 // - the specified blockTimestamps must monotonically increase
 // - the specified blockTimestamps must be compatible with those
 //   specified in the chainConfig by genesis.
 // - check a precompile is disabled before it is re-enabled
+// - each precompile config must pass its own verification
+// - allow list managers may only be assigned once the AllowListRoles upgrade is activated
 func (c *ChainConfig) VerifyPrecompileUpgrades() error {
+	for key, config := range c.GenesisPrecompiles {
+		module, ok := precompile.GetModule(key)
+		if !ok {
+			return fmt.Errorf("unknown precompile config key %s", key)
+		}
+		if config == nil || config.Address() != module.Address {
+			return fmt.Errorf("invalid config for precompile %s", key)
+		}
+		if err := config.Verify(); err != nil {
+			return fmt.Errorf("invalid config for precompile %s: %w", key, err)
+		}
+		if err := c.verifyAllowListManagers(config); err != nil {
+			return fmt.Errorf("invalid config for precompile %s: %w", key, err)
+		}
+	}
+
 	var lastBlockTimestamp *big.Int
 	for i, upgrade := range c.PrecompileUpgrades {
-		hasKey := false // used to verify if there is only one key per Upgrade
-
-		for _, key := range precompileKeys {
-			config, ok := upgrade.getByKey(key)
-			if !ok {
-				continue
-			}
-			if hasKey {
-				return fmt.Errorf("PrecompileUpgrades[%d] has more than one key set", i)
-			}
-			configTimestamp := config.Timestamp()
-			if configTimestamp == nil {
-				return fmt.Errorf("PrecompileUpgrades[%d] cannot have a nil timestamp", i)
-			}
-			// Verify specified timestamps are monotonically increasing across all precompile keys.
-			// Note: It is OK for multiple configs of different keys to specify the same timestamp.
-			if lastBlockTimestamp != nil && configTimestamp.Cmp(lastBlockTimestamp) < 0 {
-				return fmt.Errorf("PrecompileUpgrades[%d] config timestamp (%v) < previous timestamp (%v)", i, configTimestamp, lastBlockTimestamp)
-			}
-			lastBlockTimestamp = configTimestamp
-			hasKey = true
-		}
-		if !hasKey {
+		if upgrade.Config == nil {
 			return fmt.Errorf("empty precompile upgrade at index %d", i)
 		}
+		if _, ok := precompile.GetModuleByAddress(upgrade.Config.Address()); !ok {
+			return fmt.Errorf("PrecompileUpgrades[%d] configures unregistered precompile at %s", i, upgrade.Config.Address())
+		}
+		if err := upgrade.Config.Verify(); err != nil {
+			return fmt.Errorf("PrecompileUpgrades[%d] has invalid config: %w", i, err)
+		}
+		if err := c.verifyAllowListManagers(upgrade.Config); err != nil {
+			return fmt.Errorf("PrecompileUpgrades[%d] has invalid config: %w", i, err)
+		}
+		configTimestamp := upgrade.Config.Timestamp()
+		if configTimestamp == nil {
+			return fmt.Errorf("PrecompileUpgrades[%d] cannot have a nil timestamp", i)
+		}
+		// Verify specified timestamps are monotonically increasing across all precompile keys.
+		// Note: It is OK for multiple configs of different keys to specify the same timestamp.
+		if lastBlockTimestamp != nil && configTimestamp.Cmp(lastBlockTimestamp) < 0 {
+			return fmt.Errorf("PrecompileUpgrades[%d] config timestamp (%v) < previous timestamp (%v)", i, configTimestamp, lastBlockTimestamp)
+		}
+		lastBlockTimestamp = configTimestamp
 	}
 
-	for _, key := range precompileKeys {
+	for _, module := range precompile.RegisteredModules() {
 		var (
 			lastUpgraded *big.Int
 			disabled     bool
 		)
 		// check the genesis chain config for any enabled upgrade
-		if config, ok := c.PrecompileUpgrade.getByKey(key); ok {
+		if config, ok := c.GenesisPrecompiles[module.ConfigKey]; ok {
 			disabled = false
 			lastUpgraded = config.Timestamp()
 		} else {
@@ -102,9 +149,9 @@ func (c *ChainConfig) VerifyPrecompileUpgrades() error {
 		}
 		// next range over upgrades to verify correct use of disabled and blockTimestamps.
 		for i, upgrade := range c.PrecompileUpgrades {
-			config, ok := upgrade.getByKey(key)
-			// Skip the upgrade if it's not relevant to [key].
-			if !ok {
+			config := upgrade.Config
+			// Skip the upgrade if it's not relevant to [module].
+			if config.Address() != module.Address {
 				continue
 			}
 
@@ -123,10 +170,53 @@ func (c *ChainConfig) VerifyPrecompileUpgrades() error {
 	return nil
 }
 
-// getActivePrecompileConfig returns the most recent precompile config corresponding to [key].
+// verifyAllowListManagers returns an error if [config] assigns allow list managers before the
+// AllowListRoles upgrade is activated, since the manager role does not exist before then.
+func (c *ChainConfig) verifyAllowListManagers(config precompile.StatefulPrecompileConfig) error {
+	allowListConfig, ok := config.(interface {
+		GetAllowListConfig() *precompile.AllowListConfig
+	})
+	if !ok || len(allowListConfig.GetAllowListConfig().ManagerAddresses) == 0 {
+		return nil
+	}
+	if !c.IsAllowListRoles(config.Timestamp()) {
+		return fmt.Errorf("%w: config timestamp (%v) is before the AllowListRoles upgrade", errAllowListManagersNotActivated, config.Timestamp())
+	}
+	return nil
+}
+
+// GetActivePrecompileConfig returns the most recent config of the precompile at [address]
+// activated at or before [blockTimestamp]. If none have occurred, returns nil.
+func (c *ChainConfig) GetActivePrecompileConfig(address common.Address, blockTimestamp *big.Int) precompile.StatefulPrecompileConfig {
+	return c.getActivePrecompileConfig(blockTimestamp, address, c.PrecompileUpgrades)
+}
+
+// IsPrecompileEnabled returns whether the precompile at [address] is enabled at [blockTimestamp].
+func (c *ChainConfig) IsPrecompileEnabled(address common.Address, blockTimestamp *big.Int) bool {
+	config := c.GetActivePrecompileConfig(address, blockTimestamp)
+	return config != nil && !config.IsDisabled()
+}
+
+// IsPrecompileConfigured returns whether the precompile at [address] is enabled at any
+// timestamp by the genesis precompiles or the precompile upgrades of [c].
+func (c *ChainConfig) IsPrecompileConfigured(address common.Address) bool {
+	if module, ok := precompile.GetModuleByAddress(address); ok {
+		if config, ok := c.GenesisPrecompiles[module.ConfigKey]; ok && !config.IsDisabled() {
+			return true
+		}
+	}
+	for _, upgrade := range c.PrecompileUpgrades {
+		if upgrade.Config != nil && upgrade.Config.Address() == address && !upgrade.Config.IsDisabled() {
+			return true
+		}
+	}
+	return false
+}
+
+// getActivePrecompileConfig returns the most recent precompile config corresponding to [address].
 // If none have occurred, returns nil.
-func (c *ChainConfig) getActivePrecompileConfig(blockTimestamp *big.Int, key precompileKey, upgrades []PrecompileUpgrade) precompile.StatefulPrecompileConfig {
-	configs := c.getActivatingPrecompileConfigs(nil, blockTimestamp, key, upgrades)
+func (c *ChainConfig) getActivePrecompileConfig(blockTimestamp *big.Int, address common.Address, upgrades []PrecompileUpgrade) precompile.StatefulPrecompileConfig {
+	configs := c.getActivatingPrecompileConfigs(nil, blockTimestamp, address, upgrades)
 	if len(configs) == 0 {
 		return nil
 	}
@@ -135,70 +225,36 @@ func (c *ChainConfig) getActivePrecompileConfig(blockTimestamp *big.Int, key pre
 
 // getActivatingPrecompileConfigs returns all forks configured to activate during the state transition from a block with timestamp [from]
 // to a block with timestamp [to].
-func (c *ChainConfig) getActivatingPrecompileConfigs(from *big.Int, to *big.Int, key precompileKey, upgrades []PrecompileUpgrade) []precompile.StatefulPrecompileConfig {
+func (c *ChainConfig) getActivatingPrecompileConfigs(from *big.Int, to *big.Int, address common.Address, upgrades []PrecompileUpgrade) []precompile.StatefulPrecompileConfig {
 	configs := make([]precompile.StatefulPrecompileConfig, 0)
-	// First check the embedded [upgrade] for precompiles configured
-	// in the genesis chain config.
-	if config, ok := c.PrecompileUpgrade.getByKey(key); ok {
-		if utils.IsForkTransition(config.Timestamp(), from, to) {
-			configs = append(configs, config)
+	// First check the genesis chain config for precompiles configured
+	// at [address].
+	if module, ok := precompile.GetModuleByAddress(address); ok {
+		if config, ok := c.GenesisPrecompiles[module.ConfigKey]; ok {
+			if utils.IsForkTransition(config.Timestamp(), from, to) {
+				configs = append(configs, config)
+			}
 		}
 	}
 	// Loop over all upgrades checking for the requested precompile config.
 	for _, upgrade := range upgrades {
-		if config, ok := upgrade.getByKey(key); ok {
+		if upgrade.Config != nil && upgrade.Config.Address() == address {
 			// Check if the precompile activates in the specified range.
-			if utils.IsForkTransition(config.Timestamp(), from, to) {
-				configs = append(configs, config)
+			if utils.IsForkTransition(upgrade.Config.Timestamp(), from, to) {
+				configs = append(configs, upgrade.Config)
 			}
 		}
 	}
 	return configs
 }
 
-// GetContractDeployerAllowListConfig returns the latest forked ContractDeployerAllowListConfig
-// specified by [c] or nil if it was never enabled.
-func (c *ChainConfig) GetContractDeployerAllowListConfig(blockTimestamp *big.Int) *precompile.ContractDeployerAllowListConfig {
-	if val := c.getActivePrecompileConfig(blockTimestamp, contractDeployerAllowListKey, c.PrecompileUpgrades); val != nil {
-		return val.(*precompile.ContractDeployerAllowListConfig)
-	}
-	return nil
-}
-
-// GetContractNativeMinterConfig returns the latest forked ContractNativeMinterConfig
-// specified by [c] or nil if it was never enabled.
-func (c *ChainConfig) GetContractNativeMinterConfig(blockTimestamp *big.Int) *precompile.ContractNativeMinterConfig {
-	if val := c.getActivePrecompileConfig(blockTimestamp, contractNativeMinterKey, c.PrecompileUpgrades); val != nil {
-		return val.(*precompile.ContractNativeMinterConfig)
-	}
-	return nil
-}
-
-// GetTxAllowListConfig returns the latest forked TxAllowListConfig
-// specified by [c] or nil if it was never enabled.
-func (c *ChainConfig) GetTxAllowListConfig(blockTimestamp *big.Int) *precompile.TxAllowListConfig {
-	if val := c.getActivePrecompileConfig(blockTimestamp, txAllowListKey, c.PrecompileUpgrades); val != nil {
-		return val.(*precompile.TxAllowListConfig)
-	}
-	return nil
-}
-
-// GetFeeConfigManagerConfig returns the latest forked FeeManagerConfig
-// specified by [c] or nil if it was never enabled.
-func (c *ChainConfig) GetFeeConfigManagerConfig(blockTimestamp *big.Int) *precompile.FeeConfigManagerConfig {
-	if val := c.getActivePrecompileConfig(blockTimestamp, feeManagerKey, c.PrecompileUpgrades); val != nil {
-		return val.(*precompile.FeeConfigManagerConfig)
-	}
-	return nil
-}
-
 // CheckPrecompilesCompatible checks if [precompileUpgrades] are compatible with [c] at [headTimestamp].
 // Returns a ConfigCompatError if upgrades already forked at [headTimestamp] are missing from
 // [precompileUpgrades]. Upgrades not already forked may be modified or absent from [precompileUpgrades].
 // Returns nil if [precompileUpgrades] is compatible with [c].
 func (c *ChainConfig) CheckPrecompilesCompatible(precompileUpgrades []PrecompileUpgrade, headTimestamp *big.Int) *ConfigCompatError {
-	for _, key := range precompileKeys {
-		if err := c.checkPrecompileCompatible(key, precompileUpgrades, headTimestamp); err != nil {
+	for _, module := range precompile.RegisteredModules() {
+		if err := c.checkPrecompileCompatible(module.Address, precompileUpgrades, headTimestamp); err != nil {
 			return err
 		}
 	}
@@ -206,13 +262,13 @@ func (c *ChainConfig) CheckPrecompilesCompatible(precompileUpgrades []Precompile
 	return nil
 }
 
-// checkPrecompileCompatible verifies that the precompile specified by [key] is compatible between [c] and [precompileUpgrades] at [headTimestamp].
+// checkPrecompileCompatible verifies that the precompile at [address] is compatible between [c] and [precompileUpgrades] at [headTimestamp].
 // Returns an error if upgrades already forked at [headTimestamp] are missing from [precompileUpgrades].
 // Upgrades that have already gone into effect cannot be modified or absent from [precompileUpgrades].
-func (c *ChainConfig) checkPrecompileCompatible(key precompileKey, precompileUpgrades []PrecompileUpgrade, headTimestamp *big.Int) *ConfigCompatError {
+func (c *ChainConfig) checkPrecompileCompatible(address common.Address, precompileUpgrades []PrecompileUpgrade, headTimestamp *big.Int) *ConfigCompatError {
 	// all active upgrades must match
-	activeUpgrades := c.getActivatingPrecompileConfigs(nil, headTimestamp, key, c.PrecompileUpgrades)
-	newUpgrades := c.getActivatingPrecompileConfigs(nil, headTimestamp, key, precompileUpgrades)
+	activeUpgrades := c.getActivatingPrecompileConfigs(nil, headTimestamp, address, c.PrecompileUpgrades)
+	newUpgrades := c.getActivatingPrecompileConfigs(nil, headTimestamp, address, precompileUpgrades)
 
 	// first, check existing upgrades are there
 	for i, upgrade := range activeUpgrades {
@@ -250,8 +306,8 @@ func (c *ChainConfig) checkPrecompileCompatible(key precompileKey, precompileUpg
 // have been activated through an upgrade.
 func (c *ChainConfig) EnabledStatefulPrecompiles(blockTimestamp *big.Int) []precompile.StatefulPrecompileConfig {
 	statefulPrecompileConfigs := make([]precompile.StatefulPrecompileConfig, 0)
-	for _, key := range precompileKeys {
-		if config := c.getActivePrecompileConfig(blockTimestamp, key, c.PrecompileUpgrades); config != nil {
+	for _, module := range precompile.RegisteredModules() {
+		if config := c.getActivePrecompileConfig(blockTimestamp, module.Address, c.PrecompileUpgrades); config != nil {
 			statefulPrecompileConfigs = append(statefulPrecompileConfigs, config)
 		}
 	}
@@ -267,12 +323,12 @@ func (c *ChainConfig) EnabledStatefulPrecompiles(blockTimestamp *big.Int) []prec
 // - during block processing to update the state before processing the given block.
 func (c *ChainConfig) CheckConfigurePrecompiles(parentTimestamp *big.Int, blockContext precompile.BlockContext, statedb precompile.StateDB) {
 	blockTimestamp := blockContext.Timestamp()
-	for _, key := range precompileKeys { // Note: configure precompiles in a deterministic order.
-		for _, config := range c.getActivatingPrecompileConfigs(parentTimestamp, blockTimestamp, key, c.PrecompileUpgrades) {
+	for _, module := range precompile.RegisteredModules() { // Note: configure precompiles in the deterministic order of the registry.
+		for _, config := range c.getActivatingPrecompileConfigs(parentTimestamp, blockTimestamp, module.Address, c.PrecompileUpgrades) {
 			// If this transition activates the upgrade, configure the stateful precompile.
 			// (or deconfigure it if it is being disabled.)
 			if config.IsDisabled() {
-				statedb.Suicide(config.Address())
+				precompile.Deconfigure(c, blockContext, config, statedb)
 			} else {
 				precompile.Configure(c, blockContext, config, statedb)
 			}
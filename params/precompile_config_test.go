@@ -4,9 +4,11 @@
 package params
 
 import (
+	"encoding/json"
 	"math/big"
 	"testing"
 
+	"github.com/ava-labs/subnet-evm/commontype"
 	"github.com/ava-labs/subnet-evm/precompile"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
@@ -15,8 +17,8 @@ import (
 func TestValidateWithChainConfig(t *testing.T) {
 	admins := []common.Address{{1}}
 	config := &ChainConfig{
-		PrecompileUpgrade: PrecompileUpgrade{
-			TxAllowListConfig: &precompile.TxAllowListConfig{
+		GenesisPrecompiles: Precompiles{
+			precompile.TxAllowListConfigKey: &precompile.TxAllowListConfig{
 				UpgradeableConfig: precompile.UpgradeableConfig{
 					BlockTimestamp: big.NewInt(2),
 				},
@@ -26,11 +28,11 @@ func TestValidateWithChainConfig(t *testing.T) {
 	config.PrecompileUpgrades = []PrecompileUpgrade{
 		{
 			// disable TxAllowList at timestamp 4
-			TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(4)),
+			Config: precompile.NewDisableTxAllowListConfig(big.NewInt(4)),
 		},
 		{
 			// re-enable TxAllowList at timestamp 5
-			TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(5), admins),
+			Config: precompile.NewTxAllowListConfig(big.NewInt(5), admins),
 		},
 	}
 
@@ -43,7 +45,7 @@ func TestValidateWithChainConfig(t *testing.T) {
 	badConfig.PrecompileUpgrades = append(
 		badConfig.PrecompileUpgrades,
 		PrecompileUpgrade{
-			TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(5)),
+			Config: precompile.NewDisableTxAllowListConfig(big.NewInt(5)),
 		},
 	)
 	err = badConfig.VerifyPrecompileUpgrades()
@@ -54,7 +56,7 @@ func TestValidateWithChainConfig(t *testing.T) {
 	badConfig.PrecompileUpgrades = append(
 		badConfig.PrecompileUpgrades,
 		PrecompileUpgrade{
-			TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(5), admins),
+			Config: precompile.NewTxAllowListConfig(big.NewInt(5), admins),
 		},
 	)
 	err = badConfig.VerifyPrecompileUpgrades()
@@ -66,10 +68,10 @@ func TestValidate(t *testing.T) {
 	config := &ChainConfig{}
 	config.PrecompileUpgrades = []PrecompileUpgrade{
 		{
-			TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(1), admins),
+			Config: precompile.NewTxAllowListConfig(big.NewInt(1), admins),
 		},
 		{
-			TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(2)),
+			Config: precompile.NewDisableTxAllowListConfig(big.NewInt(2)),
 		},
 	}
 
@@ -83,10 +85,10 @@ func TestValidateRequiresSortedTimestamps(t *testing.T) {
 	config := &ChainConfig{}
 	config.PrecompileUpgrades = []PrecompileUpgrade{
 		{
-			TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(2), admins),
+			Config: precompile.NewTxAllowListConfig(big.NewInt(2), admins),
 		},
 		{
-			ContractDeployerAllowListConfig: precompile.NewContractDeployerAllowListConfig(big.NewInt(1), admins),
+			Config: precompile.NewContractDeployerAllowListConfig(big.NewInt(1), admins),
 		},
 	}
 
@@ -95,23 +97,211 @@ func TestValidateRequiresSortedTimestamps(t *testing.T) {
 	assert.ErrorContains(t, err, "config timestamp (1) < previous timestamp (2)")
 }
 
+func TestValidateInvalidConfig(t *testing.T) {
+	invalidRewardConfig := &precompile.InitialRewardConfig{
+		AllowFeeRecipients: true,
+		RewardAddress:      common.HexToAddress("0x0123456789abcdef0123456789abcdef01234567"),
+	}
+
+	// invalid genesis precompile configs are rejected
+	config := &ChainConfig{
+		GenesisPrecompiles: Precompiles{
+			precompile.RewardManagerConfigKey: precompile.NewRewardManagerConfig(big.NewInt(0), nil, invalidRewardConfig),
+		},
+	}
+	err := config.VerifyPrecompileUpgrades()
+	assert.ErrorIs(t, err, precompile.ErrCannotEnableBothRewards)
+
+	// invalid precompile upgrades are rejected
+	config = &ChainConfig{}
+	config.PrecompileUpgrades = []PrecompileUpgrade{
+		{
+			Config: precompile.NewRewardManagerConfig(big.NewInt(1), nil, invalidRewardConfig),
+		},
+	}
+	err = config.VerifyPrecompileUpgrades()
+	assert.ErrorIs(t, err, precompile.ErrCannotEnableBothRewards)
+}
+
+func TestValidateAllowListMembers(t *testing.T) {
+	admins := []common.Address{{1}}
+	enabled := []common.Address{{2}}
+	managers := []common.Address{{3}}
+
+	// an address cannot be assigned two roles
+	config := &ChainConfig{}
+	config.PrecompileUpgrades = []PrecompileUpgrade{
+		{
+			Config: &precompile.TxAllowListConfig{
+				AllowListConfig:   precompile.AllowListConfig{AllowListAdmins: admins, EnabledAddresses: admins},
+				UpgradeableConfig: precompile.UpgradeableConfig{BlockTimestamp: big.NewInt(1)},
+			},
+		},
+	}
+	err := config.VerifyPrecompileUpgrades()
+	assert.ErrorIs(t, err, precompile.ErrDuplicateAllowListAddress)
+
+	// an enabled address cannot be repeated
+	config.PrecompileUpgrades[0].Config = &precompile.TxAllowListConfig{
+		AllowListConfig:   precompile.AllowListConfig{AllowListAdmins: admins, EnabledAddresses: append(enabled, enabled...)},
+		UpgradeableConfig: precompile.UpgradeableConfig{BlockTimestamp: big.NewInt(1)},
+	}
+	err = config.VerifyPrecompileUpgrades()
+	assert.ErrorIs(t, err, precompile.ErrDuplicateAllowListAddress)
+
+	// an admin can be repeated, including in the genesis config
+	repeatedAdminsConfig := &precompile.TxAllowListConfig{
+		AllowListConfig:   precompile.AllowListConfig{AllowListAdmins: append(admins, admins...), EnabledAddresses: enabled},
+		UpgradeableConfig: precompile.UpgradeableConfig{BlockTimestamp: big.NewInt(1)},
+	}
+	config.PrecompileUpgrades[0].Config = repeatedAdminsConfig
+	assert.NoError(t, config.VerifyPrecompileUpgrades())
+	config = &ChainConfig{GenesisPrecompiles: Precompiles{precompile.TxAllowListConfigKey: repeatedAdminsConfig}}
+	assert.NoError(t, config.VerifyPrecompileUpgrades())
+
+	// managers cannot be assigned before the AllowListRoles upgrade
+	managerConfig := &precompile.TxAllowListConfig{
+		AllowListConfig:   precompile.AllowListConfig{AllowListAdmins: admins, ManagerAddresses: managers, EnabledAddresses: enabled},
+		UpgradeableConfig: precompile.UpgradeableConfig{BlockTimestamp: big.NewInt(1)},
+	}
+	config = &ChainConfig{NetworkUpgrades: NetworkUpgrades{AllowListRolesTimestamp: big.NewInt(2)}}
+	config.PrecompileUpgrades = []PrecompileUpgrade{{Config: managerConfig}}
+	err = config.VerifyPrecompileUpgrades()
+	assert.ErrorIs(t, err, errAllowListManagersNotActivated)
+
+	config = &ChainConfig{
+		NetworkUpgrades:    NetworkUpgrades{AllowListRolesTimestamp: big.NewInt(2)},
+		GenesisPrecompiles: Precompiles{precompile.TxAllowListConfigKey: managerConfig},
+	}
+	err = config.VerifyPrecompileUpgrades()
+	assert.ErrorIs(t, err, errAllowListManagersNotActivated)
+
+	config = &ChainConfig{NetworkUpgrades: NetworkUpgrades{AllowListRolesTimestamp: big.NewInt(1)}}
+	config.PrecompileUpgrades = []PrecompileUpgrade{{Config: managerConfig}}
+	assert.NoError(t, config.VerifyPrecompileUpgrades())
+}
+
+func TestValidateInitialFeeConfig(t *testing.T) {
+	feeManagerConfig := precompile.NewFeeManagerConfig(big.NewInt(1), nil)
+	feeManagerConfig.InitialFeeConfig = &commontype.FeeConfig{}
+	config := &ChainConfig{}
+	config.PrecompileUpgrades = []PrecompileUpgrade{{Config: feeManagerConfig}}
+	err := config.VerifyPrecompileUpgrades()
+	assert.ErrorContains(t, err, "invalid initial fee config")
+
+	initialFeeConfig := DefaultFeeConfig
+	feeManagerConfig.InitialFeeConfig = &initialFeeConfig
+	assert.NoError(t, config.VerifyPrecompileUpgrades())
+}
+
+func TestPrecompileUpgradeMembersJSON(t *testing.T) {
+	upgradeBytes := []byte(`{"precompileUpgrades":[{"feeManagerConfig":{"blockTimestamp":1,"adminAddresses":["0x0100000000000000000000000000000000000000"],"enabledAddresses":["0x0200000000000000000000000000000000000000"],"initialFeeConfig":{"gasLimit":20000000,"targetBlockRate":2,"minBaseFee":25000000000,"targetGas":15000000,"baseFeeChangeDenominator":36,"minBlockGasCost":0,"maxBlockGasCost":1000000,"blockGasCostStep":200000}}}]}`)
+	var upgradeConfig UpgradeConfig
+	assert.NoError(t, json.Unmarshal(upgradeBytes, &upgradeConfig))
+	assert.Len(t, upgradeConfig.PrecompileUpgrades, 1)
+
+	expectedFeeConfig := DefaultFeeConfig
+	expectedFeeConfig.GasLimit = big.NewInt(20_000_000)
+	expected := precompile.NewFeeManagerConfig(big.NewInt(1), []common.Address{{1}})
+	expected.EnabledAddresses = []common.Address{{2}}
+	expected.InitialFeeConfig = &expectedFeeConfig
+	assert.True(t, expected.Equal(upgradeConfig.PrecompileUpgrades[0].Config))
+
+	// configs that differ in their members or initial fee config are not equal
+	otherMembers := *expected
+	otherMembers.AllowListConfig = precompile.AllowListConfig{AllowListAdmins: []common.Address{{1}}, EnabledAddresses: []common.Address{{3}}}
+	assert.False(t, otherMembers.Equal(upgradeConfig.PrecompileUpgrades[0].Config))
+	otherFeeConfig := *expected
+	otherFeeConfig.InitialFeeConfig = nil
+	assert.False(t, otherFeeConfig.Equal(upgradeConfig.PrecompileUpgrades[0].Config))
+}
+
 func TestGetPrecompileConfig(t *testing.T) {
 	assert := assert.New(t)
 	baseConfig := *SubnetEVMDefaultChainConfig
 	config := &baseConfig
-	config.PrecompileUpgrade = PrecompileUpgrade{
-		ContractDeployerAllowListConfig: precompile.NewContractDeployerAllowListConfig(big.NewInt(10), []common.Address{}),
+	config.GenesisPrecompiles = Precompiles{
+		precompile.ContractDeployerAllowListConfigKey: precompile.NewContractDeployerAllowListConfig(big.NewInt(10), []common.Address{}),
 	}
 
-	deployerConfig := config.GetContractDeployerAllowListConfig(big.NewInt(0))
+	deployerConfig := config.GetActivePrecompileConfig(precompile.ContractDeployerAllowListAddress, big.NewInt(0))
 	assert.Nil(deployerConfig)
 
-	deployerConfig = config.GetContractDeployerAllowListConfig(big.NewInt(10))
+	deployerConfig = config.GetActivePrecompileConfig(precompile.ContractDeployerAllowListAddress, big.NewInt(10))
 	assert.NotNil(deployerConfig)
 
-	deployerConfig = config.GetContractDeployerAllowListConfig(big.NewInt(11))
+	deployerConfig = config.GetActivePrecompileConfig(precompile.ContractDeployerAllowListAddress, big.NewInt(11))
 	assert.NotNil(deployerConfig)
 
-	txAllowListConfig := config.GetTxAllowListConfig(big.NewInt(0))
+	txAllowListConfig := config.GetActivePrecompileConfig(precompile.TxAllowListAddress, big.NewInt(0))
 	assert.Nil(txAllowListConfig)
 }
+
+func TestIsPrecompileConfigured(t *testing.T) {
+	assert := assert.New(t)
+	baseConfig := *SubnetEVMDefaultChainConfig
+	config := &baseConfig
+	assert.False(config.IsPrecompileConfigured(precompile.RewardManagerAddress))
+
+	// A precompile upgrade that only disables the precompile does not configure it.
+	config.PrecompileUpgrades = []PrecompileUpgrade{
+		{Config: precompile.NewDisableRewardManagerConfig(big.NewInt(5))},
+	}
+	assert.False(config.IsPrecompileConfigured(precompile.RewardManagerAddress))
+
+	config.PrecompileUpgrades = []PrecompileUpgrade{
+		{Config: precompile.NewRewardManagerConfig(big.NewInt(5), []common.Address{{1}}, nil)},
+	}
+	assert.True(config.IsPrecompileConfigured(precompile.RewardManagerAddress))
+	assert.False(config.IsPrecompileConfigured(precompile.TxAllowListAddress))
+
+	config.PrecompileUpgrades = nil
+	config.GenesisPrecompiles = Precompiles{
+		precompile.RewardManagerConfigKey: precompile.NewRewardManagerConfig(big.NewInt(10), []common.Address{{1}}, nil),
+	}
+	assert.True(config.IsPrecompileConfigured(precompile.RewardManagerAddress))
+}
+
+func TestPrecompilesJSON(t *testing.T) {
+	admins := []common.Address{{1}}
+	config := *SubnetEVMDefaultChainConfig
+	config.GenesisPrecompiles = Precompiles{
+		precompile.TxAllowListConfigKey:      precompile.NewTxAllowListConfig(big.NewInt(1), admins),
+		precompile.FeeConfigManagerConfigKey: precompile.NewFeeManagerConfig(big.NewInt(2), admins),
+	}
+
+	// genesis precompiles are encoded as top level keys of the chain config
+	configBytes, err := json.Marshal(&config)
+	assert.NoError(t, err)
+	var fields map[string]json.RawMessage
+	assert.NoError(t, json.Unmarshal(configBytes, &fields))
+	assert.Contains(t, fields, precompile.TxAllowListConfigKey)
+	assert.Contains(t, fields, precompile.FeeConfigManagerConfigKey)
+	assert.NotContains(t, fields, precompile.ContractNativeMinterConfigKey)
+
+	var parsed ChainConfig
+	assert.NoError(t, json.Unmarshal(configBytes, &parsed))
+	assert.Len(t, parsed.GenesisPrecompiles, 2)
+	for key, expected := range config.GenesisPrecompiles {
+		assert.True(t, expected.Equal(parsed.GenesisPrecompiles[key]), key)
+	}
+
+	// precompile upgrades are encoded as a single key
+	upgradeConfig := UpgradeConfig{
+		PrecompileUpgrades: []PrecompileUpgrade{
+			{Config: precompile.NewDisableTxAllowListConfig(big.NewInt(3))},
+		},
+	}
+	upgradeBytes, err := json.Marshal(upgradeConfig)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"precompileUpgrades":[{"txAllowListConfig":{"adminAddresses":null,"blockTimestamp":3,"disable":true}}]}`, string(upgradeBytes))
+
+	var parsedUpgradeConfig UpgradeConfig
+	assert.NoError(t, json.Unmarshal(upgradeBytes, &parsedUpgradeConfig))
+	assert.Len(t, parsedUpgradeConfig.PrecompileUpgrades, 1)
+	assert.True(t, upgradeConfig.PrecompileUpgrades[0].Config.Equal(parsedUpgradeConfig.PrecompileUpgrades[0].Config))
+
+	// an upgrade cannot set more than one precompile
+	err = json.Unmarshal([]byte(`{"precompileUpgrades":[{"txAllowListConfig":{"blockTimestamp":3,"disable":true},"feeManagerConfig":{"blockTimestamp":3,"disable":true}}]}`), &parsedUpgradeConfig)
+	assert.ErrorContains(t, err, "more than one key set")
+}
@@ -15,7 +15,7 @@ import (
 func TestVerifyUpgradeConfig(t *testing.T) {
 	admins := []common.Address{{1}}
 	chainConfig := *TestChainConfig
-	chainConfig.TxAllowListConfig = precompile.NewTxAllowListConfig(big.NewInt(1), admins)
+	chainConfig.GenesisPrecompiles = Precompiles{precompile.TxAllowListConfigKey: precompile.NewTxAllowListConfig(big.NewInt(1), admins)}
 
 	type test struct {
 		upgrades            []PrecompileUpgrade
@@ -27,7 +27,7 @@ func TestVerifyUpgradeConfig(t *testing.T) {
 			expectedErrorString: "disable should be [true]",
 			upgrades: []PrecompileUpgrade{
 				{
-					TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(2), admins),
+					Config: precompile.NewTxAllowListConfig(big.NewInt(2), admins),
 				},
 			},
 		},
@@ -35,7 +35,7 @@ func TestVerifyUpgradeConfig(t *testing.T) {
 			expectedErrorString: "config timestamp (0) <= previous timestamp (1)",
 			upgrades: []PrecompileUpgrade{
 				{
-					TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(0)),
+					Config: precompile.NewDisableTxAllowListConfig(big.NewInt(0)),
 				},
 			},
 		},
@@ -43,7 +43,7 @@ func TestVerifyUpgradeConfig(t *testing.T) {
 			expectedErrorString: "config timestamp (1) <= previous timestamp (1)",
 			upgrades: []PrecompileUpgrade{
 				{
-					TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(1)),
+					Config: precompile.NewDisableTxAllowListConfig(big.NewInt(1)),
 				},
 			},
 		},
@@ -70,8 +70,10 @@ func TestVerifyUpgradeConfig(t *testing.T) {
 func TestCheckCompatibleUpgradeConfigs(t *testing.T) {
 	admins := []common.Address{{1}}
 	chainConfig := *TestChainConfig
-	chainConfig.TxAllowListConfig = precompile.NewTxAllowListConfig(big.NewInt(1), admins)
-	chainConfig.ContractDeployerAllowListConfig = precompile.NewContractDeployerAllowListConfig(big.NewInt(10), admins)
+	chainConfig.GenesisPrecompiles = Precompiles{
+		precompile.TxAllowListConfigKey:               precompile.NewTxAllowListConfig(big.NewInt(1), admins),
+		precompile.ContractDeployerAllowListConfigKey: precompile.NewContractDeployerAllowListConfig(big.NewInt(10), admins),
+	}
 
 	type test struct {
 		configs             []*UpgradeConfig
@@ -86,10 +88,10 @@ func TestCheckCompatibleUpgradeConfigs(t *testing.T) {
 				{
 					PrecompileUpgrades: []PrecompileUpgrade{
 						{
-							TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
+							Config: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
 						},
 						{
-							TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(7), admins),
+							Config: precompile.NewTxAllowListConfig(big.NewInt(7), admins),
 						},
 					},
 				},
@@ -101,20 +103,20 @@ func TestCheckCompatibleUpgradeConfigs(t *testing.T) {
 				{
 					PrecompileUpgrades: []PrecompileUpgrade{
 						{
-							TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
+							Config: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
 						},
 						{
-							TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(7), admins),
+							Config: precompile.NewTxAllowListConfig(big.NewInt(7), admins),
 						},
 					},
 				},
 				{
 					PrecompileUpgrades: []PrecompileUpgrade{
 						{
-							TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
+							Config: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
 						},
 						{
-							TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(8), admins),
+							Config: precompile.NewTxAllowListConfig(big.NewInt(8), admins),
 						},
 					},
 				},
@@ -127,20 +129,20 @@ func TestCheckCompatibleUpgradeConfigs(t *testing.T) {
 				{
 					PrecompileUpgrades: []PrecompileUpgrade{
 						{
-							TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
+							Config: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
 						},
 						{
-							TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(7), admins),
+							Config: precompile.NewTxAllowListConfig(big.NewInt(7), admins),
 						},
 					},
 				},
 				{
 					PrecompileUpgrades: []PrecompileUpgrade{
 						{
-							TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
+							Config: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
 						},
 						{
-							TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(8), admins),
+							Config: precompile.NewTxAllowListConfig(big.NewInt(8), admins),
 						},
 					},
 				},
@@ -152,17 +154,17 @@ func TestCheckCompatibleUpgradeConfigs(t *testing.T) {
 				{
 					PrecompileUpgrades: []PrecompileUpgrade{
 						{
-							TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
+							Config: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
 						},
 						{
-							TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(7), admins),
+							Config: precompile.NewTxAllowListConfig(big.NewInt(7), admins),
 						},
 					},
 				},
 				{
 					PrecompileUpgrades: []PrecompileUpgrade{
 						{
-							TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
+							Config: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
 						},
 					},
 				},
@@ -175,17 +177,17 @@ func TestCheckCompatibleUpgradeConfigs(t *testing.T) {
 				{
 					PrecompileUpgrades: []PrecompileUpgrade{
 						{
-							TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
+							Config: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
 						},
 						{
-							TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(7), admins),
+							Config: precompile.NewTxAllowListConfig(big.NewInt(7), admins),
 						},
 					},
 				},
 				{
 					PrecompileUpgrades: []PrecompileUpgrade{
 						{
-							TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
+							Config: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
 						},
 					},
 				},
@@ -198,21 +200,21 @@ func TestCheckCompatibleUpgradeConfigs(t *testing.T) {
 				{
 					PrecompileUpgrades: []PrecompileUpgrade{
 						{
-							TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
+							Config: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
 						},
 						{
-							TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(7), admins),
+							Config: precompile.NewTxAllowListConfig(big.NewInt(7), admins),
 						},
 					},
 				},
 				{
 					PrecompileUpgrades: []PrecompileUpgrade{
 						{
-							TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
+							Config: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
 						},
 						{
 							// uses a different (empty) admin list, not allowed
-							TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(7), []common.Address{}),
+							Config: precompile.NewTxAllowListConfig(big.NewInt(7), []common.Address{}),
 						},
 					},
 				},
@@ -224,20 +226,20 @@ func TestCheckCompatibleUpgradeConfigs(t *testing.T) {
 				{
 					PrecompileUpgrades: []PrecompileUpgrade{
 						{
-							TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
+							Config: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
 						},
 						{
-							TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(7), admins),
+							Config: precompile.NewTxAllowListConfig(big.NewInt(7), admins),
 						},
 					},
 				},
 				{
 					PrecompileUpgrades: []PrecompileUpgrade{
 						{
-							TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
+							Config: precompile.NewDisableTxAllowListConfig(big.NewInt(6)),
 						},
 						{
-							TxAllowListConfig: precompile.NewTxAllowListConfig(big.NewInt(7), admins),
+							Config: precompile.NewTxAllowListConfig(big.NewInt(7), admins),
 						},
 					},
 				},
@@ -4,35 +4,46 @@
 package peer
 
 import (
-	"errors"
+	"context"
 
 	"github.com/ava-labs/avalanchego/ids"
 
 	"github.com/ava-labs/avalanchego/version"
 )
 
-var (
-	_ Client = &client{}
-
-	errRequestFailed = errors.New("request failed")
-)
+var _ Client = &client{}
 
 // Client defines ability to send request / response through the Network
 type Client interface {
 	// RequestAny synchronously sends request to the first connected peer that matches the specified minVersion in
 	// random order.
 	// A peer is considered a match if its version is greater than or equal to the specified minVersion
-	// Returns errNoPeersMatchingVersion if no peer could be found matching specified version
-	// and errRequestFailed if the request should be retried.
-	RequestAny(minVersion *version.Application, request []byte) ([]byte, error)
+	// Returns ErrNoPeersMatchingVersion if no peer could be found matching specified version
+	// and ErrRequestFailed if the request should be retried.
+	// Returns the nodeID of the peer the request was sent to, also on failure once a peer was selected.
+	RequestAny(minVersion *version.Application, request []byte) ([]byte, ids.NodeID, error)
+
+	// RequestAnyWithContext is RequestAny, except that it returns once [ctx] is done.
+	// Returns ErrRequestTimeout if the deadline of [ctx] is exceeded and the error of [ctx]
+	// if it is cancelled before a response is received.
+	RequestAnyWithContext(ctx context.Context, minVersion *version.Application, request []byte) ([]byte, ids.NodeID, error)
 
 	// Request synchronously sends request to the selected nodeID
 	// Returns response bytes
-	// Returns errRequestFailed if request should be retried
+	// Returns ErrRequestFailed if request should be retried
 	Request(nodeID ids.NodeID, request []byte) ([]byte, error)
 
+	// RequestWithContext is Request, except that it returns once [ctx] is done.
+	// Returns ErrRequestTimeout if the deadline of [ctx] is exceeded and the error of [ctx]
+	// if it is cancelled before a response is received.
+	RequestWithContext(ctx context.Context, nodeID ids.NodeID, request []byte) ([]byte, error)
+
 	// Gossip sends given gossip message to peers
 	Gossip(gossip []byte) error
+
+	// ReportInvalidResponse reports that the response received from [nodeID]
+	// failed to parse or verify, so that the peer is charged with a failure.
+	ReportInvalidResponse(nodeID ids.NodeID)
 }
 
 // client implements Client interface
@@ -43,37 +54,48 @@ type client struct {
 
 // RequestAny synchronously sends request to the first connected peer that matches the specified minVersion in
 // random order and blocks until it receives a response or the request could not be sent or times out.
-// Returns the response bytes from the peer.
-func (c *client) RequestAny(minVersion *version.Application, request []byte) ([]byte, error) {
+// Returns the response bytes and the nodeID of the peer.
+func (c *client) RequestAny(minVersion *version.Application, request []byte) ([]byte, ids.NodeID, error) {
+	return c.RequestAnyWithContext(context.Background(), minVersion, request)
+}
+
+// RequestAnyWithContext synchronously sends request to the first connected peer that matches the specified
+// minVersion and blocks until it receives a response, the request fails or [ctx] is done.
+// Returns the response bytes and the nodeID of the peer.
+func (c *client) RequestAnyWithContext(ctx context.Context, minVersion *version.Application, request []byte) ([]byte, ids.NodeID, error) {
 	waitingHandler := newWaitingResponseHandler()
-	if err := c.network.RequestAny(minVersion, request, waitingHandler); err != nil {
-		return nil, err
-	}
-	response := <-waitingHandler.responseChan
-	if waitingHandler.failed {
-		return nil, errRequestFailed
+	nodeID, err := c.network.RequestAnyWithContext(ctx, minVersion, request, waitingHandler)
+	if err != nil {
+		return nil, nodeID, err
 	}
-	return response, nil
+	response, err := waitingHandler.wait(ctx)
+	return response, nodeID, err
 }
 
 // Request synchronously sends [request] message to specified [nodeID]
 // This function blocks until a response is received from the peer
 func (c *client) Request(nodeID ids.NodeID, request []byte) ([]byte, error) {
+	return c.RequestWithContext(context.Background(), nodeID, request)
+}
+
+// RequestWithContext synchronously sends [request] message to specified [nodeID]
+// This function blocks until a response is received from the peer, the request fails or [ctx] is done.
+func (c *client) RequestWithContext(ctx context.Context, nodeID ids.NodeID, request []byte) ([]byte, error) {
 	waitingHandler := newWaitingResponseHandler()
-	if err := c.network.Request(nodeID, request, waitingHandler); err != nil {
+	if err := c.network.RequestWithContext(ctx, nodeID, request, waitingHandler); err != nil {
 		return nil, err
 	}
-	response := <-waitingHandler.responseChan
-	if waitingHandler.failed {
-		return nil, errRequestFailed
-	}
-	return response, nil
+	return waitingHandler.wait(ctx)
 }
 
 func (c *client) Gossip(gossip []byte) error {
 	return c.network.Gossip(gossip)
 }
 
+func (c *client) ReportInvalidResponse(nodeID ids.NodeID) {
+	c.network.ReportInvalidResponse(nodeID)
+}
+
 // NewClient returns Client for a given network
 func NewClient(network Network) Client {
 	return &client{
@@ -27,10 +27,19 @@ import (
 const minRequestHandlingDuration = 100 * time.Millisecond
 
 var (
-	errAcquiringSemaphore                      = errors.New("error acquiring semaphore")
-	_                     Network              = &network{}
-	_                     validators.Connector = &network{}
-	_                     common.AppHandler    = &network{}
+	_ Network              = &network{}
+	_ validators.Connector = &network{}
+	_ common.AppHandler    = &network{}
+
+	// ErrNoPeersMatchingVersion is returned when no connected peer matches the
+	// version required by a request.
+	ErrNoPeersMatchingVersion = errors.New("no peers found matching version")
+	// ErrRequestTimeout is returned when the deadline of the context of a
+	// request expires before a response is received.
+	ErrRequestTimeout = errors.New("request timed out")
+	// ErrRequestFailed is returned when the request failed to be answered by
+	// the peer and should be retried.
+	ErrRequestFailed = errors.New("request failed")
 )
 
 type Network interface {
@@ -40,15 +49,27 @@ type Network interface {
 	// RequestAny synchronously sends request to the first connected peer that matches the specified minVersion in
 	// random order.
 	// A peer is considered a match if its version is greater than or equal to the specified minVersion
-	// Returns an error if the request could not be sent to a peer with the desired [minVersion].
-	RequestAny(minVersion *version.Application, message []byte, handler message.ResponseHandler) error
+	// Returns the nodeID of the peer the request was sent to, or an error if the request could not be sent
+	// to a peer with the desired [minVersion].
+	RequestAny(minVersion *version.Application, message []byte, handler message.ResponseHandler) (ids.NodeID, error)
+
+	// RequestAnyWithContext is RequestAny, except that waiting for an active request slot
+	// is aborted and the outstanding request is failed when [ctx] is done.
+	RequestAnyWithContext(ctx context.Context, minVersion *version.Application, message []byte, handler message.ResponseHandler) (ids.NodeID, error)
 
 	// Request sends message to given nodeID, notifying handler when there's a response or timeout
 	Request(nodeID ids.NodeID, message []byte, handler message.ResponseHandler) error
 
+	// RequestWithContext is Request, except that waiting for an active request slot
+	// is aborted and the outstanding request is failed when [ctx] is done.
+	RequestWithContext(ctx context.Context, nodeID ids.NodeID, message []byte, handler message.ResponseHandler) error
+
 	// Gossip sends given gossip message to peers
 	Gossip(gossip []byte) error
 
+	// GossipSpecific sends given gossip message to [nodeIDs]
+	GossipSpecific(nodeIDs ids.NodeIDSet, gossip []byte) error
+
 	// Shutdown stops all peer channel listeners and marks the node to have stopped
 	// n.Start() can be called again but the peers will have to be reconnected
 	// by calling OnPeerConnected for each peer
@@ -62,22 +83,29 @@ type Network interface {
 
 	// Size returns the size of the network in number of connected peers
 	Size() uint32
+
+	// PeerStats returns the performance measured for each connected peer
+	PeerStats() []PeerStats
+
+	// ReportInvalidResponse records that the last response received from [nodeID]
+	// failed to parse or verify, so that it counts as a failed request.
+	ReportInvalidResponse(nodeID ids.NodeID)
 }
 
 // network is an implementation of Network that processes message requests for
 // each peer in linear fashion
 type network struct {
-	lock                          sync.RWMutex                        // lock for mutating state of this Network struct
-	self                          ids.NodeID                          // NodeID of this node
-	requestIDGen                  uint32                              // requestID counter used to track outbound requests
-	outstandingResponseHandlerMap map[uint32]message.ResponseHandler  // maps avalanchego requestID => response handler
-	activeRequests                *semaphore.Weighted                 // controls maximum number of active outbound requests
-	appSender                     common.AppSender                    // avalanchego AppSender for sending messages
-	codec                         codec.Manager                       // Codec used for parsing messages
-	requestHandler                message.RequestHandler              // maps request type => handler
-	gossipHandler                 message.GossipHandler               // maps gossip type => handler
-	peers                         map[ids.NodeID]*version.Application // maps nodeID => version.Version
-	stats                         stats.RequestHandlerStats           // Provide request handler metrics
+	lock                          sync.RWMutex                       // lock for mutating state of this Network struct
+	self                          ids.NodeID                         // NodeID of this node
+	requestIDGen                  uint32                             // requestID counter used to track outbound requests
+	outstandingResponseHandlerMap map[uint32]*trackedResponseHandler // maps avalanchego requestID => response handler
+	activeRequests                *semaphore.Weighted                // controls maximum number of active outbound requests
+	appSender                     common.AppSender                   // avalanchego AppSender for sending messages
+	codec                         codec.Manager                      // Codec used for parsing messages
+	requestHandler                message.RequestHandler             // maps request type => handler
+	gossipHandler                 message.GossipHandler              // maps gossip type => handler
+	peers                         *peerTracker                       // tracks the version and performance of each peer
+	stats                         stats.RequestHandlerStats          // Provide request handler metrics
 }
 
 func NewNetwork(appSender common.AppSender, codec codec.Manager, self ids.NodeID, maxActiveRequests int64) Network {
@@ -85,10 +113,11 @@ func NewNetwork(appSender common.AppSender, codec codec.Manager, self ids.NodeID
 		appSender:                     appSender,
 		codec:                         codec,
 		self:                          self,
-		outstandingResponseHandlerMap: make(map[uint32]message.ResponseHandler),
-		peers:                         make(map[ids.NodeID]*version.Application),
+		outstandingResponseHandlerMap: make(map[uint32]*trackedResponseHandler),
+		peers:                         newPeerTracker(),
 		activeRequests:                semaphore.NewWeighted(maxActiveRequests),
 		gossipHandler:                 message.NoopMempoolGossipHandler{},
+		requestHandler:                message.NoopRequestHandler{},
 		stats:                         stats.NewRequestHandlerStats(),
 	}
 }
@@ -98,43 +127,55 @@ func NewNetwork(appSender common.AppSender, codec codec.Manager, self ids.NodeID
 // If minVersion is nil, then the request will be sent to any peer regardless of their version
 // Returns a non-nil error if we were not able to send a request to a peer with >= [minVersion]
 // or we fail to send a request to the selected peer.
-func (n *network) RequestAny(minVersion *version.Application, request []byte, handler message.ResponseHandler) error {
+// Returns the nodeID of the peer the request was sent to.
+func (n *network) RequestAny(minVersion *version.Application, request []byte, handler message.ResponseHandler) (ids.NodeID, error) {
+	return n.RequestAnyWithContext(context.Background(), minVersion, request, handler)
+}
+
+// RequestAnyWithContext sends given request to the first connected peer that matches the specified minVersion
+// Returns ErrNoPeersMatchingVersion if there is no such peer, and the error of [ctx] if it is done
+// before an active request slot becomes available.
+// If [ctx] is done before the peer responds, the request is failed and its slot released.
+func (n *network) RequestAnyWithContext(ctx context.Context, minVersion *version.Application, request []byte, handler message.ResponseHandler) (ids.NodeID, error) {
 	// Take a slot from total [activeRequests] and block until a slot becomes available.
-	if err := n.activeRequests.Acquire(context.Background(), 1); err != nil {
-		return errAcquiringSemaphore
+	if err := n.activeRequests.Acquire(ctx, 1); err != nil {
+		return ids.EmptyNodeID, contextError(ctx)
 	}
 
 	n.lock.Lock()
 	defer n.lock.Unlock()
 
-	for nodeID, nodeVersion := range n.peers {
-		// map iteration is sufficiently random to avoid hitting same peer so here
-		// we get a random peerID key that we compare minimum version that
-		// we have
-		if minVersion == nil || nodeVersion.Compare(minVersion) >= 0 {
-			return n.request(nodeID, request, handler)
-		}
+	// The peer tracker prefers well performing peers, skipping benched peers.
+	if nodeID, ok := n.peers.getAnyPeer(minVersion, time.Now()); ok {
+		return nodeID, n.request(ctx, nodeID, request, handler)
 	}
 
 	n.activeRequests.Release(1)
-	return fmt.Errorf("no peers found matching version %s out of %d peers", minVersion, len(n.peers))
+	return ids.EmptyNodeID, fmt.Errorf("%w %s out of %d peers", ErrNoPeersMatchingVersion, minVersion, n.peers.size())
 }
 
 // Request sends request message bytes to specified nodeID, notifying the responseHandler on response or failure
 func (n *network) Request(nodeID ids.NodeID, request []byte, responseHandler message.ResponseHandler) error {
+	return n.RequestWithContext(context.Background(), nodeID, request, responseHandler)
+}
+
+// RequestWithContext sends request message bytes to specified nodeID, notifying the responseHandler on response or failure
+// Returns the error of [ctx] if it is done before an active request slot becomes available.
+// If [ctx] is done before the peer responds, the request is failed and its slot released.
+func (n *network) RequestWithContext(ctx context.Context, nodeID ids.NodeID, request []byte, responseHandler message.ResponseHandler) error {
 	if nodeID == ids.EmptyNodeID {
 		return fmt.Errorf("cannot send request to empty nodeID, nodeID=%s, requestLen=%d", nodeID, len(request))
 	}
 
 	// Take a slot from total [activeRequests] and block until a slot becomes available.
-	if err := n.activeRequests.Acquire(context.Background(), 1); err != nil {
-		return errAcquiringSemaphore
+	if err := n.activeRequests.Acquire(ctx, 1); err != nil {
+		return contextError(ctx)
 	}
 
 	n.lock.Lock()
 	defer n.lock.Unlock()
 
-	return n.request(nodeID, request, responseHandler)
+	return n.request(ctx, nodeID, request, responseHandler)
 }
 
 // request sends request message bytes to specified nodeID and adds [responseHandler] to [outstandingRequestHandlers]
@@ -142,15 +183,22 @@ func (n *network) Request(nodeID ids.NodeID, request []byte, responseHandler mes
 // Assumes [nodeID] is never [self] since we guarantee [self] will not be added to the [peers] map.
 // Releases active requests semaphore if there was an error in sending the request
 // Returns an error if [appSender] is unable to make the request.
+// If [ctx] is done before the request completes, the request is cancelled with cancelRequest.
 // Assumes write lock is held
-func (n *network) request(nodeID ids.NodeID, request []byte, responseHandler message.ResponseHandler) error {
+func (n *network) request(ctx context.Context, nodeID ids.NodeID, request []byte, responseHandler message.ResponseHandler) error {
 	log.Debug("sending request to peer", "nodeID", nodeID, "requestLen", len(request))
 
 	// generate requestID
 	requestID := n.requestIDGen
 	n.requestIDGen++
 
-	n.outstandingResponseHandlerMap[requestID] = responseHandler
+	handler := &trackedResponseHandler{
+		handler: responseHandler,
+		peers:   n.peers,
+		sent:    time.Now(),
+		done:    make(chan struct{}),
+	}
+	n.outstandingResponseHandlerMap[requestID] = handler
 
 	nodeIDs := ids.NewNodeIDSet(1)
 	nodeIDs.Add(nodeID)
@@ -166,9 +214,45 @@ func (n *network) request(nodeID ids.NodeID, request []byte, responseHandler mes
 	}
 
 	log.Debug("sent request message to peer", "nodeID", nodeID, "requestID", requestID)
+
+	// [ctx.Done()] is nil if [ctx] can never be cancelled.
+	if ctxDone := ctx.Done(); ctxDone != nil {
+		go func() {
+			select {
+			case <-ctxDone:
+				n.cancelRequest(nodeID, requestID, ctx.Err())
+			case <-handler.done:
+			}
+		}()
+	}
 	return nil
 }
 
+// cancelRequest fails the outstanding request [requestID] sent to [nodeID] after its context is done
+// with [ctxErr], releasing its activeRequests slot. Does nothing if the request has already completed.
+// If the deadline of the context was exceeded, the failure is attributed to the peer since it did not
+// respond in time. Otherwise the request was abandoned by the caller and the peer is not penalized.
+func (n *network) cancelRequest(nodeID ids.NodeID, requestID uint32, ctxErr error) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	handler, exists := n.getRequestHandler(requestID)
+	if !exists {
+		return
+	}
+	log.Debug("cancelling request to peer", "nodeID", nodeID, "requestID", requestID, "err", ctxErr)
+	if errors.Is(ctxErr, context.DeadlineExceeded) {
+		if err := handler.OnFailure(nodeID, requestID); err != nil {
+			log.Warn("failed to cancel request", "nodeID", nodeID, "requestID", requestID, "err", err)
+		}
+		return
+	}
+	close(handler.done)
+	if err := handler.handler.OnFailure(nodeID, requestID); err != nil {
+		log.Warn("failed to cancel request", "nodeID", nodeID, "requestID", requestID, "err", err)
+	}
+}
+
 // AppRequest is called by avalanchego -> VM when there is an incoming AppRequest from a peer
 // error returned by this function is expected to be treated as fatal by the engine
 // returns error if the requestHandler returns an error
@@ -230,8 +314,8 @@ func (n *network) AppResponse(nodeID ids.NodeID, requestID uint32, response []by
 
 	handler, exists := n.getRequestHandler(requestID)
 	if !exists {
-		// Should never happen since the engine should be managing outstanding requests
-		log.Error("received response to unknown request", "nodeID", nodeID, "requestID", requestID, "responseLen", len(response))
+		// Happens when the request was cancelled before the response was received
+		log.Debug("received response to unknown request", "nodeID", nodeID, "requestID", requestID, "responseLen", len(response))
 		return nil
 	}
 
@@ -251,8 +335,8 @@ func (n *network) AppRequestFailed(nodeID ids.NodeID, requestID uint32) error {
 
 	handler, exists := n.getRequestHandler(requestID)
 	if !exists {
-		// Should never happen since the engine should be managing outstanding requests
-		log.Error("received request failed to unknown request", "nodeID", nodeID, "requestID", requestID)
+		// Happens when the request was cancelled before it failed
+		log.Debug("received request failed to unknown request", "nodeID", nodeID, "requestID", requestID)
 		return nil
 	}
 
@@ -262,7 +346,7 @@ func (n *network) AppRequestFailed(nodeID ids.NodeID, requestID uint32) error {
 // getRequestHandler fetches the handler for [requestID] and marks the request with [requestID] as having been fulfilled.
 // This is called by either [AppResponse] or [AppRequestFailed].
 // assumes that the write lock is held.
-func (n *network) getRequestHandler(requestID uint32) (message.ResponseHandler, bool) {
+func (n *network) getRequestHandler(requestID uint32) (*trackedResponseHandler, bool) {
 	handler, exists := n.outstandingResponseHandlerMap[requestID]
 	if !exists {
 		return nil, false
@@ -278,6 +362,11 @@ func (n *network) Gossip(gossip []byte) error {
 	return n.appSender.SendAppGossip(gossip)
 }
 
+// GossipSpecific sends given gossip message to [nodeIDs]
+func (n *network) GossipSpecific(nodeIDs ids.NodeIDSet, gossip []byte) error {
+	return n.appSender.SendAppGossipSpecific(nodeIDs, gossip)
+}
+
 // AppGossip is called by avalanchego -> VM when there is an incoming AppGossip from a peer
 // error returned by this function is expected to be treated as fatal by the engine
 // returns error if request could not be parsed as message.Request or when the requestHandler returns an error
@@ -304,12 +393,12 @@ func (n *network) Connected(nodeID ids.NodeID, nodeVersion *version.Application)
 		return nil
 	}
 
-	if storedVersion, exists := n.peers[nodeID]; exists {
+	if storedVersion, exists := n.peers.version(nodeID); exists {
 		// Peer is already connected, update the version if it has changed.
 		// Log a warning message since the consensus engine should never call Connected on a peer
 		// that we have already marked as Connected.
 		if nodeVersion.Compare(storedVersion) != 0 {
-			n.peers[nodeID] = nodeVersion
+			n.peers.connected(nodeID, nodeVersion)
 			log.Warn("received Connected message for already connected peer, updating node version", "nodeID", nodeID, "storedVersion", storedVersion, "nodeVersion", nodeVersion)
 		} else {
 			log.Warn("ignoring peer connected event for already connected peer with identical version", "nodeID", nodeID)
@@ -317,7 +406,7 @@ func (n *network) Connected(nodeID ids.NodeID, nodeVersion *version.Application)
 		return nil
 	}
 
-	n.peers[nodeID] = nodeVersion
+	n.peers.connected(nodeID, nodeVersion)
 	return nil
 }
 
@@ -328,13 +417,13 @@ func (n *network) Disconnected(nodeID ids.NodeID) error {
 	defer n.lock.Unlock()
 
 	// if this peer already exists, log a warning and ignore the request
-	if _, exists := n.peers[nodeID]; !exists {
+	if _, exists := n.peers.version(nodeID); !exists {
 		// we're not connected to this peer, nothing to do here
 		log.Warn("received peer disconnect request to unconnected peer", "nodeID", nodeID)
 		return nil
 	}
 
-	delete(n.peers, nodeID)
+	n.peers.disconnected(nodeID)
 	return nil
 }
 
@@ -344,7 +433,7 @@ func (n *network) Shutdown() {
 	defer n.lock.Unlock()
 
 	// reset peers map
-	n.peers = make(map[ids.NodeID]*version.Application)
+	n.peers = newPeerTracker()
 }
 
 func (n *network) SetGossipHandler(handler message.GossipHandler) {
@@ -365,5 +454,51 @@ func (n *network) Size() uint32 {
 	n.lock.RLock()
 	defer n.lock.RUnlock()
 
-	return uint32(len(n.peers))
+	return uint32(n.peers.size())
+}
+
+func (n *network) PeerStats() []PeerStats {
+	n.lock.RLock()
+	defer n.lock.RUnlock()
+
+	return n.peers.stats(time.Now())
+}
+
+func (n *network) ReportInvalidResponse(nodeID ids.NodeID) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	n.peers.trackInvalidResponse(nodeID, time.Now())
+}
+
+// trackedResponseHandler records the outcome of a request in the peer tracker
+// before passing it on to [handler].
+// The callbacks are invoked while holding the lock of the network.
+type trackedResponseHandler struct {
+	handler message.ResponseHandler
+	peers   *peerTracker
+	sent    time.Time
+	done    chan struct{} // closed once the request completed or was cancelled
+}
+
+func (h *trackedResponseHandler) OnResponse(nodeID ids.NodeID, requestID uint32, response []byte) error {
+	close(h.done)
+	h.peers.trackResponse(nodeID, h.sent, len(response), time.Now())
+	return h.handler.OnResponse(nodeID, requestID, response)
+}
+
+func (h *trackedResponseHandler) OnFailure(nodeID ids.NodeID, requestID uint32) error {
+	close(h.done)
+	h.peers.trackFailure(nodeID, time.Now())
+	return h.handler.OnFailure(nodeID, requestID)
+}
+
+// contextError returns the error of [ctx], wrapped in ErrRequestTimeout if
+// the deadline of [ctx] was exceeded.
+func contextError(ctx context.Context) error {
+	err := ctx.Err()
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %s", ErrRequestTimeout, err)
+	}
+	return err
 }
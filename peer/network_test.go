@@ -103,7 +103,7 @@ func TestRequestAnyRequestsRoutingAndResponse(t *testing.T) {
 			defer wg.Done()
 			requestBytes, err := message.RequestToBytes(codecManager, requestMessage)
 			assert.NoError(t, err)
-			responseBytes, err := client.RequestAny(defaultPeerVersion, requestBytes)
+			responseBytes, _, err := client.RequestAny(defaultPeerVersion, requestBytes)
 			assert.NoError(t, err)
 			assert.NotNil(t, responseBytes)
 
@@ -118,6 +118,13 @@ func TestRequestAnyRequestsRoutingAndResponse(t *testing.T) {
 	requestWg.Wait()
 	senderWg.Wait()
 	assert.Equal(t, totalCalls, int(atomic.LoadUint32(&callNum)))
+
+	// all responses are tracked for the peer
+	peerStats := net.PeerStats()
+	assert.Len(t, peerStats, 1)
+	assert.Equal(t, nodeID, peerStats[0].NodeID)
+	assert.Equal(t, uint64(totalCalls), peerStats[0].Requests)
+	assert.Zero(t, peerStats[0].Failures)
 }
 
 func TestRequestRequestsRoutingAndResponse(t *testing.T) {
@@ -253,16 +260,17 @@ func TestRequestMinVersion(t *testing.T) {
 	}))
 
 	// ensure version does not match
-	responseBytes, err := client.RequestAny(&version.Application{
+	responseBytes, _, err := client.RequestAny(&version.Application{
 		Major: 2,
 		Minor: 0,
 		Patch: 0,
 	}, requestBytes)
 	assert.Equal(t, err.Error(), "no peers found matching version avalanche/2.0.0 out of 1 peers")
+	assert.ErrorIs(t, err, ErrNoPeersMatchingVersion)
 	assert.Nil(t, responseBytes)
 
 	// ensure version matches and the request goes through
-	responseBytes, err = client.RequestAny(&version.Application{
+	responseBytes, _, err = client.RequestAny(&version.Application{
 		Major: 1,
 		Minor: 0,
 		Patch: 0,
@@ -276,6 +284,142 @@ func TestRequestMinVersion(t *testing.T) {
 	assert.Equal(t, "this is a response", response.Message)
 }
 
+func TestRequestWithContext(t *testing.T) {
+	nodeID := ids.GenerateTestNodeID()
+	codecManager := buildCodec(t, TestMessage{})
+	requestBytes, err := message.RequestToBytes(codecManager, TestMessage{Message: "this is a request"})
+	assert.NoError(t, err)
+
+	// The peer never responds, unless it fails the request.
+	var (
+		net        Network
+		failNext   uint32
+		requestIDs = make(chan uint32, 4)
+	)
+	sender := testAppSender{
+		sendAppRequestFn: func(_ ids.NodeIDSet, requestID uint32, _ []byte) error {
+			requestIDs <- requestID
+			if atomic.LoadUint32(&failNext) == 1 {
+				go func() {
+					assert.NoError(t, net.AppRequestFailed(nodeID, requestID))
+				}()
+			}
+			return nil
+		},
+	}
+	net = NewNetwork(sender, codecManager, ids.EmptyNodeID, 1)
+	client := NewClient(net)
+	assert.NoError(t, net.Connected(nodeID, defaultPeerVersion))
+
+	// the deadline expires while waiting for the response
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = client.RequestWithContext(ctx, nodeID, requestBytes)
+	assert.ErrorIs(t, err, ErrRequestTimeout)
+	requestID := <-requestIDs
+
+	// the active request slot is released once the request is cancelled, so
+	// the next request does not block on it
+	cancelledCtx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-requestIDs
+		cancel()
+	}()
+	_, _, err = client.RequestAnyWithContext(cancelledCtx, nil, requestBytes)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// a response to a cancelled request is ignored
+	assert.NoError(t, net.AppResponse(nodeID, requestID, []byte("late response")))
+
+	// failed requests are reported as such
+	atomic.StoreUint32(&failNext, 1)
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = client.RequestWithContext(ctx, nodeID, requestBytes)
+	assert.ErrorIs(t, err, ErrRequestFailed)
+	<-requestIDs
+}
+
+func TestRequestWithContextWaitingForSlot(t *testing.T) {
+	nodeID := ids.GenerateTestNodeID()
+	codecManager := buildCodec(t, TestMessage{})
+	requestBytes, err := message.RequestToBytes(codecManager, TestMessage{Message: "this is a request"})
+	assert.NoError(t, err)
+
+	sent := uint32(0)
+	sender := testAppSender{
+		sendAppRequestFn: func(ids.NodeIDSet, uint32, []byte) error {
+			atomic.AddUint32(&sent, 1)
+			return nil
+		},
+	}
+	net := NewNetwork(sender, codecManager, ids.EmptyNodeID, 1)
+	assert.NoError(t, net.Connected(nodeID, defaultPeerVersion))
+
+	// take the only active request slot with a request that is never answered
+	assert.NoError(t, net.Request(nodeID, requestBytes, newWaitingResponseHandler()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = net.RequestAnyWithContext(ctx, nil, requestBytes, newWaitingResponseHandler())
+	assert.ErrorIs(t, err, ErrRequestTimeout)
+	assert.EqualValues(t, 1, atomic.LoadUint32(&sent))
+}
+
+func TestRequestWithContextBenchesPeerOnDeadline(t *testing.T) {
+	nodeID := ids.GenerateTestNodeID()
+	codecManager := buildCodec(t, TestMessage{})
+	requestBytes, err := message.RequestToBytes(codecManager, TestMessage{Message: "this is a request"})
+	assert.NoError(t, err)
+
+	// The peer never responds.
+	sender := testAppSender{
+		sendAppRequestFn: func(ids.NodeIDSet, uint32, []byte) error {
+			return nil
+		},
+	}
+	net := NewNetwork(sender, codecManager, ids.EmptyNodeID, 2*benchFailureThreshold)
+	client := NewClient(net)
+	assert.NoError(t, net.Connected(nodeID, defaultPeerVersion))
+
+	// requests abandoned by the caller are not attributed to the peer
+	for i := 0; i < benchFailureThreshold; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			cancel()
+		}()
+		_, _, err = client.RequestAnyWithContext(ctx, nil, requestBytes)
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+	stats := net.PeerStats()
+	assert.Len(t, stats, 1)
+	assert.Zero(t, stats[0].Failures)
+	assert.False(t, stats[0].Benched)
+
+	// requests timing out under the deadline of the caller are attributed to the
+	// peer, which is benched after enough of them
+	for i := 0; i < benchFailureThreshold; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		_, _, err = client.RequestAnyWithContext(ctx, nil, requestBytes)
+		cancel()
+		assert.ErrorIs(t, err, ErrRequestTimeout)
+	}
+	// the requests are failed asynchronously once their deadline expires, and
+	// only those count as failures
+	assert.Eventually(t, func() bool {
+		stats := net.PeerStats()
+		return len(stats) == 1 && stats[0].Benched
+	}, 5*time.Second, 10*time.Millisecond)
+	stats = net.PeerStats()
+	assert.EqualValues(t, benchFailureThreshold, stats[0].Failures)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, _, err = client.RequestAnyWithContext(ctx, nil, requestBytes)
+	assert.ErrorIs(t, err, ErrNoPeersMatchingVersion)
+}
+
 func TestOnRequestHonoursDeadline(t *testing.T) {
 	var net Network
 	responded := false
@@ -355,6 +499,30 @@ func TestGossip(t *testing.T) {
 	assert.True(t, gossipHandler.received)
 }
 
+func TestGossipSpecific(t *testing.T) {
+	codecManager := buildCodec(t, HelloGossip{})
+
+	nodeID := ids.GenerateTestNodeID()
+	var sentTo ids.NodeIDSet
+	sender := testAppSender{
+		sendAppGossipSpecificFn: func(nodeIDs ids.NodeIDSet, msg []byte) error {
+			sentTo = nodeIDs
+			return nil
+		},
+	}
+
+	clientNetwork := NewNetwork(sender, codecManager, ids.EmptyNodeID, 1)
+	defer clientNetwork.Shutdown()
+
+	b, err := buildGossip(codecManager, HelloGossip{Msg: "hello there!"})
+	assert.NoError(t, err)
+
+	nodeIDs := ids.NewNodeIDSet(1)
+	nodeIDs.Add(nodeID)
+	assert.NoError(t, clientNetwork.GossipSpecific(nodeIDs, b))
+	assert.True(t, sentTo.Equals(nodeIDs))
+}
+
 func TestHandleInvalidMessages(t *testing.T) {
 	codecManager := buildCodec(t, HelloGossip{}, TestMessage{})
 
@@ -448,13 +616,14 @@ func buildGossip(codec codec.Manager, msg message.GossipMessage) ([]byte, error)
 }
 
 type testAppSender struct {
-	sendAppRequestFn  func(ids.NodeIDSet, uint32, []byte) error
-	sendAppResponseFn func(ids.NodeID, uint32, []byte) error
-	sendAppGossipFn   func([]byte) error
+	sendAppRequestFn        func(ids.NodeIDSet, uint32, []byte) error
+	sendAppResponseFn       func(ids.NodeID, uint32, []byte) error
+	sendAppGossipFn         func([]byte) error
+	sendAppGossipSpecificFn func(ids.NodeIDSet, []byte) error
 }
 
-func (t testAppSender) SendAppGossipSpecific(ids.NodeIDSet, []byte) error {
-	panic("not implemented")
+func (t testAppSender) SendAppGossipSpecific(nodeIDs ids.NodeIDSet, message []byte) error {
+	return t.sendAppGossipSpecificFn(nodeIDs, message)
 }
 
 func (t testAppSender) SendAppRequest(nodeIDs ids.NodeIDSet, requestID uint32, message []byte) error {
@@ -566,6 +735,10 @@ func (t *testGossipHandler) HandleTxs(nodeID ids.NodeID, msg message.TxsGossip)
 	return nil
 }
 
+func (t *testGossipHandler) HandleTxsPullAnnouncement(nodeID ids.NodeID, msg message.TxsPullAnnouncement) error {
+	return nil
+}
+
 type testRequestHandler struct {
 	message.RequestHandler
 	calls              uint32
@@ -0,0 +1,267 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package peer
+
+import (
+	"bytes"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	safemath "github.com/ava-labs/avalanchego/utils/math"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// peerStatsHalflife is the halflife of the moving averages of the
+	// bandwidth, latency and failure rate of each peer.
+	peerStatsHalflife = 5 * time.Minute
+
+	// explorationProbability is the probability of sending a request to a
+	// random peer instead of the best performing one, so that the performance
+	// of every peer keeps being measured.
+	explorationProbability = 0.2
+
+	// benchFailureThreshold is the number of consecutive failed requests after
+	// which a peer is benched.
+	benchFailureThreshold = 5
+
+	// minBenchDuration is how long a peer is benched the first time. Each
+	// consecutive benching doubles the duration, up to [maxBenchDuration].
+	minBenchDuration = 30 * time.Second
+	maxBenchDuration = 10 * time.Minute
+)
+
+// PeerStats contains the performance of a peer measured from the requests it
+// answered.
+type PeerStats struct {
+	NodeID       ids.NodeID    `json:"nodeID"`
+	Version      string        `json:"version"`
+	Requests     uint64        `json:"requests"`
+	Failures     uint64        `json:"failures"`
+	Bandwidth    float64       `json:"bandwidth"` // Moving average of the response bytes per second
+	Latency      time.Duration `json:"latency"`   // Moving average of the response latency
+	FailureRate  float64       `json:"failureRate"`
+	Benched      bool          `json:"benched"`
+	BenchedUntil time.Time     `json:"benchedUntil,omitempty"`
+}
+
+// peerInfo contains the version and the measured performance of a peer.
+type peerInfo struct {
+	version *version.Application
+
+	requests    uint64
+	failures    uint64
+	bandwidth   safemath.Averager
+	latency     safemath.Averager
+	failureRate safemath.Averager
+
+	// [consecutiveFailures] counts the failures since the last response and
+	// [benchings] the consecutive times the peer was benched.
+	consecutiveFailures int
+	benchings           int
+	benchedUntil        time.Time
+
+	// [prevConsecutiveFailures] and [prevBenchings] hold the counters reset
+	// by the last response, so they can be restored if it turns out invalid.
+	prevConsecutiveFailures int
+	prevBenchings           int
+}
+
+// observed returns true if a response or failure was recorded for the peer.
+func (p *peerInfo) observed() bool {
+	return p.requests > 0
+}
+
+// score returns the expected useful bandwidth of the peer.
+func (p *peerInfo) score() float64 {
+	return p.bandwidth.Read() * (1 - p.failureRate.Read())
+}
+
+// peerTracker tracks the connected peers and the performance of the requests
+// they answer, in order to select the peer to send requests to.
+// peerTracker is not thread safe, and is protected by the lock of [network].
+type peerTracker struct {
+	peers map[ids.NodeID]*peerInfo
+
+	// explorationProbability is the probability that getAnyPeer returns a
+	// random peer instead of the best performing one.
+	explorationProbability float64
+}
+
+func newPeerTracker() *peerTracker {
+	return &peerTracker{
+		peers:                  make(map[ids.NodeID]*peerInfo),
+		explorationProbability: explorationProbability,
+	}
+}
+
+// connected adds [nodeID] to the tracked peers, or updates its version if
+// it is already tracked.
+func (t *peerTracker) connected(nodeID ids.NodeID, nodeVersion *version.Application) {
+	if peer, ok := t.peers[nodeID]; ok {
+		peer.version = nodeVersion
+		return
+	}
+	t.peers[nodeID] = &peerInfo{
+		version:     nodeVersion,
+		bandwidth:   safemath.NewUninitializedAverager(peerStatsHalflife),
+		latency:     safemath.NewUninitializedAverager(peerStatsHalflife),
+		failureRate: safemath.NewUninitializedAverager(peerStatsHalflife),
+	}
+}
+
+// disconnected stops tracking [nodeID].
+func (t *peerTracker) disconnected(nodeID ids.NodeID) {
+	delete(t.peers, nodeID)
+}
+
+// version returns the version of [nodeID] and whether it is connected.
+func (t *peerTracker) version(nodeID ids.NodeID) (*version.Application, bool) {
+	peer, ok := t.peers[nodeID]
+	if !ok {
+		return nil, false
+	}
+	return peer.version, true
+}
+
+func (t *peerTracker) size() int {
+	return len(t.peers)
+}
+
+// getAnyPeer returns a peer that is not benched at [now] and has a version
+// of at least [minVersion], or any version if [minVersion] is nil.
+// Peers that were not measured yet are preferred, then the best performing
+// peer is returned, except with probability [explorationProbability] where
+// a random peer is returned instead.
+func (t *peerTracker) getAnyPeer(minVersion *version.Application, now time.Time) (ids.NodeID, bool) {
+	var (
+		candidates []ids.NodeID
+		unobserved []ids.NodeID
+		best       ids.NodeID
+		bestScore  = -1.0
+	)
+	for nodeID, peer := range t.peers {
+		if minVersion != nil && peer.version.Compare(minVersion) < 0 {
+			continue
+		}
+		if now.Before(peer.benchedUntil) {
+			continue
+		}
+		candidates = append(candidates, nodeID)
+		if !peer.observed() {
+			unobserved = append(unobserved, nodeID)
+			continue
+		}
+		if score := peer.score(); score > bestScore {
+			best, bestScore = nodeID, score
+		}
+	}
+
+	switch {
+	case len(candidates) == 0:
+		return ids.EmptyNodeID, false
+	case len(unobserved) > 0:
+		return unobserved[rand.Intn(len(unobserved))], true
+	case rand.Float64() < t.explorationProbability:
+		return candidates[rand.Intn(len(candidates))], true
+	default:
+		return best, true
+	}
+}
+
+// trackResponse records a response of [size] bytes received from [nodeID]
+// at [now] to a request sent at [sent].
+func (t *peerTracker) trackResponse(nodeID ids.NodeID, sent time.Time, size int, now time.Time) {
+	peer, ok := t.peers[nodeID]
+	if !ok {
+		return
+	}
+	latency := now.Sub(sent)
+	if latency <= 0 {
+		latency = time.Nanosecond
+	}
+	peer.requests++
+	peer.bandwidth.Observe(float64(size)/latency.Seconds(), now)
+	peer.latency.Observe(float64(latency), now)
+	peer.failureRate.Observe(0, now)
+	peer.prevConsecutiveFailures = peer.consecutiveFailures
+	peer.prevBenchings = peer.benchings
+	peer.consecutiveFailures = 0
+	peer.benchings = 0
+}
+
+// trackFailure records a failed request to [nodeID] at [now], and benches
+// the peer after [benchFailureThreshold] consecutive failures.
+func (t *peerTracker) trackFailure(nodeID ids.NodeID, now time.Time) {
+	peer, ok := t.peers[nodeID]
+	if !ok {
+		return
+	}
+	peer.requests++
+	t.fail(nodeID, peer, now)
+}
+
+// trackInvalidResponse records that the last response received from [nodeID]
+// was invalid at [now]. The response no longer counts as useful bandwidth,
+// and the request counts as a failure towards benching the peer.
+func (t *peerTracker) trackInvalidResponse(nodeID ids.NodeID, now time.Time) {
+	peer, ok := t.peers[nodeID]
+	if !ok {
+		return
+	}
+	peer.bandwidth.Observe(0, now)
+	peer.consecutiveFailures = peer.prevConsecutiveFailures
+	peer.benchings = peer.prevBenchings
+	t.fail(nodeID, peer, now)
+}
+
+// fail records a failed request to [peer] at [now], and benches the peer
+// after [benchFailureThreshold] consecutive failures.
+func (t *peerTracker) fail(nodeID ids.NodeID, peer *peerInfo, now time.Time) {
+	peer.failures++
+	peer.failureRate.Observe(1, now)
+	peer.consecutiveFailures++
+	if peer.consecutiveFailures < benchFailureThreshold {
+		return
+	}
+
+	benchDuration := maxBenchDuration
+	if peer.benchings < 16 && minBenchDuration<<peer.benchings < maxBenchDuration {
+		benchDuration = minBenchDuration << peer.benchings
+	}
+	peer.benchedUntil = now.Add(benchDuration)
+	peer.benchings++
+	peer.consecutiveFailures = 0
+	log.Debug("benching peer", "nodeID", nodeID, "duration", benchDuration)
+}
+
+// stats returns the stats of all tracked peers at [now], sorted by node ID.
+func (t *peerTracker) stats(now time.Time) []PeerStats {
+	stats := make([]PeerStats, 0, len(t.peers))
+	for nodeID, peer := range t.peers {
+		peerStats := PeerStats{
+			NodeID:   nodeID,
+			Version:  peer.version.String(),
+			Requests: peer.requests,
+			Failures: peer.failures,
+		}
+		if peer.observed() {
+			peerStats.Bandwidth = peer.bandwidth.Read()
+			peerStats.Latency = time.Duration(peer.latency.Read())
+			peerStats.FailureRate = peer.failureRate.Read()
+		}
+		if now.Before(peer.benchedUntil) {
+			peerStats.Benched = true
+			peerStats.BenchedUntil = peer.benchedUntil
+		}
+		stats = append(stats, peerStats)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return bytes.Compare(stats[i].NodeID[:], stats[j].NodeID[:]) < 0
+	})
+	return stats
+}
@@ -0,0 +1,140 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package peer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/version"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPeerTrackerPrefersBestPeer(t *testing.T) {
+	tracker := newPeerTracker()
+	tracker.explorationProbability = 0
+
+	slowPeer, fastPeer := ids.GenerateTestNodeID(), ids.GenerateTestNodeID()
+	tracker.connected(slowPeer, defaultPeerVersion)
+	tracker.connected(fastPeer, defaultPeerVersion)
+
+	now := time.Unix(1_000_000, 0)
+	tracker.trackResponse(slowPeer, now, 1000, now.Add(time.Second))
+	tracker.trackResponse(fastPeer, now, 1000, now.Add(10*time.Millisecond))
+
+	for i := 0; i < 10; i++ {
+		nodeID, ok := tracker.getAnyPeer(nil, now)
+		assert.True(t, ok)
+		assert.Equal(t, fastPeer, nodeID)
+	}
+
+	// Unmeasured peers are tried first.
+	newPeer := ids.GenerateTestNodeID()
+	tracker.connected(newPeer, defaultPeerVersion)
+	nodeID, ok := tracker.getAnyPeer(nil, now)
+	assert.True(t, ok)
+	assert.Equal(t, newPeer, nodeID)
+
+	// Peers below the minimum version are skipped.
+	newerVersion := &version.Application{Major: 2}
+	_, ok = tracker.getAnyPeer(newerVersion, now)
+	assert.False(t, ok)
+	tracker.connected(slowPeer, newerVersion)
+	nodeID, ok = tracker.getAnyPeer(newerVersion, now)
+	assert.True(t, ok)
+	assert.Equal(t, slowPeer, nodeID)
+}
+
+func TestPeerTrackerBenching(t *testing.T) {
+	tracker := newPeerTracker()
+
+	nodeID := ids.GenerateTestNodeID()
+	tracker.connected(nodeID, defaultPeerVersion)
+
+	now := time.Unix(1_000_000, 0)
+	for i := 0; i < benchFailureThreshold-1; i++ {
+		tracker.trackFailure(nodeID, now)
+	}
+	_, ok := tracker.getAnyPeer(nil, now)
+	assert.True(t, ok)
+
+	tracker.trackFailure(nodeID, now)
+	_, ok = tracker.getAnyPeer(nil, now)
+	assert.False(t, ok, "benched peer should not be selected")
+
+	stats := tracker.stats(now)
+	assert.Len(t, stats, 1)
+	assert.True(t, stats[0].Benched)
+	assert.Equal(t, uint64(benchFailureThreshold), stats[0].Failures)
+	assert.Equal(t, now.Add(minBenchDuration), stats[0].BenchedUntil)
+
+	// The peer can be selected once the bench expires.
+	now = now.Add(minBenchDuration)
+	_, ok = tracker.getAnyPeer(nil, now)
+	assert.True(t, ok)
+
+	// Benching again doubles the duration.
+	for i := 0; i < benchFailureThreshold; i++ {
+		tracker.trackFailure(nodeID, now)
+	}
+	assert.Equal(t, now.Add(2*minBenchDuration), tracker.stats(now)[0].BenchedUntil)
+
+	// A response resets the backoff.
+	now = now.Add(2 * minBenchDuration)
+	tracker.trackResponse(nodeID, now, 100, now.Add(time.Millisecond))
+	for i := 0; i < benchFailureThreshold; i++ {
+		tracker.trackFailure(nodeID, now)
+	}
+	assert.Equal(t, now.Add(minBenchDuration), tracker.stats(now)[0].BenchedUntil)
+
+	tracker.disconnected(nodeID)
+	assert.Empty(t, tracker.stats(now))
+}
+
+func TestPeerTrackerInvalidResponses(t *testing.T) {
+	tracker := newPeerTracker()
+	tracker.explorationProbability = 0
+
+	slowPeer, junkPeer := ids.GenerateTestNodeID(), ids.GenerateTestNodeID()
+	tracker.connected(slowPeer, defaultPeerVersion)
+	tracker.connected(junkPeer, defaultPeerVersion)
+
+	now := time.Unix(1_000_000, 0)
+	tracker.trackResponse(slowPeer, now, 1000, now.Add(time.Second))
+
+	// A peer quickly answering with invalid responses is charged with a failure
+	// for each of them, and benched as if it did not respond.
+	for i := 0; i < benchFailureThreshold; i++ {
+		tracker.trackResponse(junkPeer, now, 1000, now.Add(time.Millisecond))
+		tracker.trackInvalidResponse(junkPeer, now)
+	}
+	stats := tracker.stats(now)
+	assert.Len(t, stats, 2)
+	for _, peerStats := range stats {
+		if peerStats.NodeID != junkPeer {
+			continue
+		}
+		assert.Equal(t, uint64(benchFailureThreshold), peerStats.Requests)
+		assert.Equal(t, uint64(benchFailureThreshold), peerStats.Failures)
+		assert.True(t, peerStats.Benched)
+	}
+	for i := 0; i < 10; i++ {
+		nodeID, ok := tracker.getAnyPeer(nil, now)
+		assert.True(t, ok)
+		assert.Equal(t, slowPeer, nodeID)
+	}
+
+	// Invalid responses do not reset the backoff.
+	now = now.Add(minBenchDuration)
+	for i := 0; i < benchFailureThreshold; i++ {
+		tracker.trackResponse(junkPeer, now, 1000, now.Add(time.Millisecond))
+		tracker.trackInvalidResponse(junkPeer, now)
+	}
+	for _, peerStats := range tracker.stats(now) {
+		if peerStats.NodeID == junkPeer {
+			assert.Equal(t, now.Add(2*minBenchDuration), peerStats.BenchedUntil)
+		}
+	}
+}
@@ -4,6 +4,8 @@
 package peer
 
 import (
+	"context"
+
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/subnet-evm/plugin/evm/message"
 )
@@ -14,8 +16,9 @@ var _ message.ResponseHandler = &waitingResponseHandler{}
 // Internally used to wait for response after making a request synchronously
 // responseChan may contain response bytes if the original request has not failed
 // responseChan is closed in either fail or success scenario
+// responseChan is buffered so that the network is not blocked if the caller stopped waiting
 type waitingResponseHandler struct {
-	responseChan chan []byte // blocking channel with response bytes
+	responseChan chan []byte // buffered channel with response bytes
 	failed       bool        // whether the original request is failed
 }
 
@@ -33,7 +36,25 @@ func (w *waitingResponseHandler) OnFailure(ids.NodeID, uint32) error {
 	return nil
 }
 
+// wait blocks until the request completes or [ctx] is done, and returns the
+// response bytes or the error the request completed with.
+func (w *waitingResponseHandler) wait(ctx context.Context) ([]byte, error) {
+	select {
+	case response := <-w.responseChan:
+		if !w.failed {
+			return response, nil
+		}
+		// The network fails the request when [ctx] is done, report the context error instead.
+		if ctx.Err() != nil {
+			return nil, contextError(ctx)
+		}
+		return nil, ErrRequestFailed
+	case <-ctx.Done():
+		return nil, contextError(ctx)
+	}
+}
+
 // newWaitingResponseHandler returns new instance of the waitingResponseHandler
 func newWaitingResponseHandler() *waitingResponseHandler {
-	return &waitingResponseHandler{responseChan: make(chan []byte)}
+	return &waitingResponseHandler{responseChan: make(chan []byte, 1)}
 }
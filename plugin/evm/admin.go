@@ -4,11 +4,13 @@
 package evm
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 
 	"github.com/ava-labs/avalanchego/api"
 	"github.com/ava-labs/avalanchego/utils/profiler"
+	"github.com/ava-labs/subnet-evm/peer"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -67,7 +69,11 @@ func (p *Admin) SetLogLevel(r *http.Request, args *SetLogLevelArgs, reply *api.E
 	if err != nil {
 		return fmt.Errorf("failed to parse log level: %w ", err)
 	}
+	p.vm.configLock.Lock()
+	defer p.vm.configLock.Unlock()
+
 	p.vm.setLogLevel(logLevel)
+	p.vm.config.LogLevel = args.Level
 	return nil
 }
 
@@ -75,7 +81,44 @@ type ConfigReply struct {
 	Config *Config `json:"config"`
 }
 
+// GetVMConfig returns the config the VM is currently running with, including
+// any updates applied through UpdateVMConfig.
 func (p *Admin) GetVMConfig(r *http.Request, args *struct{}, reply *ConfigReply) error {
-	reply.Config = &p.vm.config
+	config := p.vm.currentConfig()
+	reply.Config = &config
+	return nil
+}
+
+type UpdateVMConfigArgs struct {
+	// Config is a JSON object containing the config fields to update, keyed
+	// like the VM config file.
+	Config json.RawMessage `json:"config"`
+}
+
+// UpdateVMConfig applies the fields in [args.Config] to the config of the
+// running VM and returns the resulting config. Returns an error without
+// changing the config if any field cannot be updated without a restart or if
+// the resulting config is invalid.
+func (p *Admin) UpdateVMConfig(r *http.Request, args *UpdateVMConfigArgs, reply *ConfigReply) error {
+	log.Info("Admin: UpdateVMConfig called", "config", string(args.Config))
+
+	config, err := p.vm.updateConfig(args.Config)
+	if err != nil {
+		return fmt.Errorf("failed to update VM config: %w", err)
+	}
+	reply.Config = &config
+	return nil
+}
+
+type PeerStatsReply struct {
+	Peers []peer.PeerStats `json:"peers"`
+}
+
+// GetPeerStats returns the measured performance of the connected peers used
+// to select the peers requests are sent to.
+func (p *Admin) GetPeerStats(r *http.Request, args *struct{}, reply *PeerStatsReply) error {
+	log.Info("Admin: GetPeerStats called")
+
+	reply.Peers = p.vm.Network.PeerStats()
 	return nil
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/ava-labs/subnet-evm/constants"
 	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/precompile"
 	"github.com/ava-labs/subnet-evm/trie"
 )
 
@@ -187,7 +188,10 @@ func (v blockValidatorSubnetEVM) SyntacticVerify(b *Block) error {
 		return errUncleHashMismatch
 	}
 	// Coinbase must be zero, if AllowFeeRecipients is not enabled
-	if !b.vm.chainConfig.AllowFeeRecipients && b.ethBlock.Coinbase() != constants.BlackholeAddr {
+	// Note: if the RewardManager precompile is enabled, the coinbase is verified by the consensus
+	// engine against the reward address stored in the parent state instead.
+	rewardManagerEnabled := b.vm.chainConfig.IsPrecompileEnabled(precompile.RewardManagerAddress, new(big.Int).SetUint64(b.ethBlock.Time()))
+	if !b.vm.chainConfig.AllowFeeRecipients && !rewardManagerEnabled && b.ethBlock.Coinbase() != constants.BlackholeAddr {
 		return errInvalidBlock
 	}
 	// Block must not have any uncles
@@ -5,10 +5,12 @@ package evm
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/ava-labs/avalanchego/api"
 	"github.com/ava-labs/avalanchego/utils/rpc"
+	"github.com/ava-labs/subnet-evm/peer"
 	"github.com/ethereum/go-ethereum/log"
 )
 
@@ -23,6 +25,8 @@ type Client interface {
 	LockProfile(ctx context.Context) error
 	SetLogLevel(ctx context.Context, level log.Lvl) error
 	GetVMConfig(ctx context.Context) (*Config, error)
+	UpdateVMConfig(ctx context.Context, config map[string]interface{}) (*Config, error)
+	GetPeerStats(ctx context.Context) ([]peer.PeerStats, error)
 }
 
 // Client implementation for interacting with EVM [chain]
@@ -79,3 +83,24 @@ func (c *client) GetVMConfig(ctx context.Context) (*Config, error) {
 	err := c.adminRequester.SendRequest(ctx, "getVMConfig", struct{}{}, res)
 	return res.Config, err
 }
+
+// UpdateVMConfig applies [config] to the config of the running VM and returns
+// the resulting config
+func (c *client) UpdateVMConfig(ctx context.Context, config map[string]interface{}) (*Config, error) {
+	configBytes, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	res := &ConfigReply{}
+	err = c.adminRequester.SendRequest(ctx, "updateVMConfig", &UpdateVMConfigArgs{
+		Config: configBytes,
+	}, res)
+	return res.Config, err
+}
+
+// GetPeerStats returns the measured performance of the peers of the node
+func (c *client) GetPeerStats(ctx context.Context) ([]peer.PeerStats, error) {
+	res := &PeerStatsReply{}
+	err := c.adminRequester.SendRequest(ctx, "getPeerStats", struct{}{}, res)
+	return res.Peers, err
+}
@@ -6,10 +6,17 @@ package evm
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/ava-labs/subnet-evm/core"
 	"github.com/ava-labs/subnet-evm/eth"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/plugin/evm/message"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/spf13/cast"
 )
 
@@ -25,6 +32,7 @@ const (
 	defaultWsCpuRefillRate                        = 0 // Default to no maximum WS CPU usage
 	defaultWsCpuMaxStored                         = 0 // Default to no maximum WS CPU usage
 	defaultMaxBlocksPerRequest                    = 0 // Default to no maximum on the number of blocks per getLogs request
+	defaultMaxTraceFilterBlocks                   = 2000
 	defaultContinuousProfilerFrequency            = 15 * time.Minute
 	defaultContinuousProfilerMaxFiles             = 5
 	defaultRegossipFrequency                      = 1 * time.Minute
@@ -33,10 +41,23 @@ const (
 	defaultPriorityRegossipFrequency              = 1 * time.Second
 	defaultPriorityRegossipMaxTxs                 = 32
 	defaultPriorityRegossipTxsPerAddress          = 16
+	defaultTxPullGossipFrequency                  = 10 * time.Second
+	defaultTxPullGossipFilterSize                 = 8 * 1024
 	defaultOfflinePruningBloomFilterSize   uint64 = 512 // Default size (MB) for the offline pruner to use
+	defaultOnlinePruningRetention                 = 4   // Number of committed state roots kept by the online pruner
+	defaultOnlinePruningBatchSize                 = 1024
+	defaultOnlinePruningBloomFilterSize    uint64 = 256 // Default size (MB) of each bloom filter of the online pruner, which keeps two or three of them in memory
 	defaultLogLevel                               = "info"
 	defaultMaxOutboundActiveRequests              = 8
 	defaultPopulateMissingTriesParallelism        = 1024
+	defaultSyncableCommitInterval                 = defaultCommitInterval * 4
+	defaultHealthCheckAcceptorQueueRatio          = 0.9 // Unhealthy once the acceptor queue is 90% full
+
+	// defaultStateSyncMinBlocks is the minimum number of blocks the blockchain
+	// should be ahead of local last accepted to perform state sync.
+	// This constant is chosen so normal bootstrapping is preferred when it would
+	// be faster than state sync.
+	defaultStateSyncMinBlocks = 300_000
 )
 
 var defaultEnabledAPIs = []string{
@@ -49,6 +70,30 @@ var defaultEnabledAPIs = []string{
 	"internal-public-transaction-pool",
 }
 
+// liveConfigFields is the set of config fields, by JSON key, that can be
+// updated while the VM is running. All other fields are read once during
+// initialization and require a restart to change.
+var liveConfigFields = map[string]struct{}{
+	"rpc-gas-cap":                           {},
+	"rpc-tx-fee-cap":                        {},
+	"api-max-duration":                      {},
+	"remote-gossip-only-enabled":            {},
+	"regossip-frequency":                    {},
+	"regossip-max-txs":                      {},
+	"regossip-txs-per-address":              {},
+	"priority-regossip-frequency":           {},
+	"priority-regossip-max-txs":             {},
+	"priority-regossip-txs-per-address":     {},
+	"priority-regossip-addresses":           {},
+	"tx-pull-gossip-frequency":              {},
+	"tx-pull-gossip-filter-size":            {},
+	"log-level":                             {},
+	"health-check-max-block-age-multiplier": {},
+	"health-check-max-acceptor-queue-ratio": {},
+	"health-check-min-peers":                {},
+	"health-check-max-tx-pool-ratio":        {},
+}
+
 type Duration struct {
 	time.Duration
 }
@@ -78,6 +123,12 @@ type Config struct {
 	SnapshotAsync  bool `json:"snapshot-async"`
 	SnapshotVerify bool `json:"snapshot-verification-enabled"`
 
+	// TxLookupLimit is the maximum number of blocks from head whose tx indices
+	// are reserved:
+	//  * 0:   means no limit
+	//  * N:   means N block limit [HEAD-N+1, HEAD] and delete extra indexes
+	TxLookupLimit uint64 `json:"tx-lookup-limit"`
+
 	// Pruning Settings
 	Pruning                         bool    `json:"pruning-enabled"`                    // If enabled, trie roots are only persisted every 4096 blocks
 	AcceptorQueueLimit              int     `json:"accepted-queue-limit"`               // Maximum blocks to queue before blocking during acceptance
@@ -95,9 +146,22 @@ type Config struct {
 	WSCPURefillRate         Duration `json:"ws-cpu-refill-rate"`
 	WSCPUMaxStored          Duration `json:"ws-cpu-max-stored"`
 	MaxBlocksPerRequest     int64    `json:"api-max-blocks-per-request"`
+	MaxTraceFilterBlocks    int64    `json:"api-max-trace-filter-blocks"` // Maximum number of blocks to trace per trace_filter request, 0 for no maximum
 	AllowUnfinalizedQueries bool     `json:"allow-unfinalized-queries"`
 	AllowUnprotectedTxs     bool     `json:"allow-unprotected-txs"`
 
+	// Tx Pool Settings
+	TxPoolPriceLimit       uint64   `json:"tx-pool-price-limit"`        // Minimum gas price to enforce for acceptance into the pool
+	TxPoolPriceBump        uint64   `json:"tx-pool-price-bump"`         // Minimum price bump percentage to replace an already existing transaction (nonce)
+	TxPoolAccountSlots     uint64   `json:"tx-pool-account-slots"`      // Number of executable transaction slots guaranteed per account
+	TxPoolGlobalSlots      uint64   `json:"tx-pool-global-slots"`       // Maximum number of executable transaction slots for all accounts
+	TxPoolAccountQueue     uint64   `json:"tx-pool-account-queue"`      // Maximum number of non-executable transaction slots permitted per account
+	TxPoolGlobalQueue      uint64   `json:"tx-pool-global-queue"`       // Maximum number of non-executable transaction slots for all accounts
+	TxPoolLifetime         Duration `json:"tx-pool-lifetime"`           // Maximum amount of time non-executable transaction are queued
+	TxPoolAccountRateLimit float64  `json:"tx-pool-account-rate-limit"` // Maximum number of remote transactions admitted per second per account. Disabled if 0.
+	TxPoolAccountRateBurst int      `json:"tx-pool-account-rate-burst"` // Maximum number of remote transactions admitted at once per account above the rate limit
+	TxPoolAccountMaxShare  float64  `json:"tx-pool-account-max-share"`  // Maximum fraction of the global slots a single remote account may occupy. Disabled if 0.
+
 	// Keystore Settings
 	KeystoreDirectory             string `json:"keystore-directory"` // both absolute and relative supported
 	KeystoreExternalSigner        string `json:"keystore-external-signer"`
@@ -112,6 +176,8 @@ type Config struct {
 	PriorityRegossipMaxTxs        int              `json:"priority-regossip-max-txs"`
 	PriorityRegossipTxsPerAddress int              `json:"priority-regossip-txs-per-address"`
 	PriorityRegossipAddresses     []common.Address `json:"priority-regossip-addresses"`
+	TxPullGossipFrequency         Duration         `json:"tx-pull-gossip-frequency"`   // Frequency to pull missing mempool txs from a random peer. Disabled if 0.
+	TxPullGossipFilterSize        int              `json:"tx-pull-gossip-filter-size"` // Size in bytes of the bloom filter of known txs sent when pulling txs
 
 	// Log level
 	LogLevel string `json:"log-level"`
@@ -119,13 +185,37 @@ type Config struct {
 	// Address for Tx Fees (must be empty if not supported by blockchain)
 	FeeRecipient string `json:"feeRecipient"`
 
+	// Path of a file containing the airdrop allocations of the genesis,
+	// overriding the airdrop allocations given in the genesis
+	AirdropPath string `json:"airdrop-path"`
+
 	// Offline Pruning Settings
 	OfflinePruning                bool   `json:"offline-pruning-enabled"`
 	OfflinePruningBloomFilterSize uint64 `json:"offline-pruning-bloom-filter-size"`
 	OfflinePruningDataDirectory   string `json:"offline-pruning-data-directory"`
 
+	// Online Pruning Settings
+	OnlinePruning                bool   `json:"online-pruning-enabled"`           // If enabled, trie nodes unreachable from the most recently committed roots are deleted while running
+	OnlinePruningRetention       uint64 `json:"online-pruning-retention"`         // Number of most recently committed state roots kept by online pruning
+	OnlinePruningBatchSize       int    `json:"online-pruning-batch-size"`        // Maximum number of trie nodes and database keys processed by online pruning per accepted block
+	OnlinePruningBloomFilterSize uint64 `json:"online-pruning-bloom-filter-size"` // Size (MB) of each bloom filter used by online pruning, which keeps up to three of them in memory
+
 	// VM2VM network
 	MaxOutboundActiveRequests int64 `json:"max-outbound-active-requests"`
+
+	// Sync settings
+	StateSyncEnabled        bool   `json:"state-sync-enabled"`
+	StateSyncSkipResume     bool   `json:"state-sync-skip-resume"` // Forces state sync to use the highest available summary block
+	StateSyncIDs            string `json:"state-sync-ids"`         // Comma separated list of node IDs to sync from. If empty, any peer is used.
+	StateSyncCommitInterval uint64 `json:"state-sync-commit-interval"`
+	StateSyncMinBlocks      uint64 `json:"state-sync-min-blocks"`
+	StateSyncServerEnabled  bool   `json:"state-sync-server-enabled"` // Serves leafs, blocks and code requests from peers performing state sync
+
+	// Health Check Settings
+	HealthCheckMaxBlockAgeMultiplier uint64  `json:"health-check-max-block-age-multiplier"` // Unhealthy if the last accepted block is older than this multiple of the target block rate. Disabled if 0.
+	HealthCheckMaxAcceptorQueueRatio float64 `json:"health-check-max-acceptor-queue-ratio"` // Unhealthy if the acceptor queue is filled beyond this fraction of [AcceptorQueueLimit]. Disabled if 0.
+	HealthCheckMinPeers              uint32  `json:"health-check-min-peers"`                // Unhealthy if fewer peers than this are connected. Disabled if 0.
+	HealthCheckMaxTxPoolRatio        float64 `json:"health-check-max-tx-pool-ratio"`        // Unhealthy if the tx pool is filled beyond this fraction of its capacity. Disabled if 0.
 }
 
 // EthAPIs returns an array of strings representing the Eth APIs that should be enabled
@@ -134,7 +224,7 @@ func (c Config) EthAPIs() []string {
 }
 
 func (c Config) EthBackendSettings() eth.Settings {
-	return eth.Settings{MaxBlocksPerRequest: c.MaxBlocksPerRequest}
+	return eth.Settings{MaxBlocksPerRequest: c.MaxBlocksPerRequest, MaxTraceFilterBlocks: c.MaxTraceFilterBlocks}
 }
 
 func (c *Config) SetDefaults() {
@@ -146,6 +236,7 @@ func (c *Config) SetDefaults() {
 	c.WSCPURefillRate.Duration = defaultWsCpuRefillRate
 	c.WSCPUMaxStored.Duration = defaultWsCpuMaxStored
 	c.MaxBlocksPerRequest = defaultMaxBlocksPerRequest
+	c.MaxTraceFilterBlocks = defaultMaxTraceFilterBlocks
 	c.ContinuousProfilerFrequency.Duration = defaultContinuousProfilerFrequency
 	c.ContinuousProfilerMaxFiles = defaultContinuousProfilerMaxFiles
 	c.Pruning = defaultPruningEnabled
@@ -158,10 +249,25 @@ func (c *Config) SetDefaults() {
 	c.PriorityRegossipFrequency.Duration = defaultPriorityRegossipFrequency
 	c.PriorityRegossipMaxTxs = defaultPriorityRegossipMaxTxs
 	c.PriorityRegossipTxsPerAddress = defaultPriorityRegossipTxsPerAddress
+	c.TxPullGossipFrequency.Duration = defaultTxPullGossipFrequency
+	c.TxPullGossipFilterSize = defaultTxPullGossipFilterSize
 	c.OfflinePruningBloomFilterSize = defaultOfflinePruningBloomFilterSize
+	c.OnlinePruningRetention = defaultOnlinePruningRetention
+	c.OnlinePruningBatchSize = defaultOnlinePruningBatchSize
+	c.OnlinePruningBloomFilterSize = defaultOnlinePruningBloomFilterSize
 	c.LogLevel = defaultLogLevel
 	c.MaxOutboundActiveRequests = defaultMaxOutboundActiveRequests
 	c.PopulateMissingTriesParallelism = defaultPopulateMissingTriesParallelism
+	c.StateSyncCommitInterval = defaultSyncableCommitInterval
+	c.StateSyncMinBlocks = defaultStateSyncMinBlocks
+	c.HealthCheckMaxAcceptorQueueRatio = defaultHealthCheckAcceptorQueueRatio
+	c.TxPoolPriceLimit = core.DefaultTxPoolConfig.PriceLimit
+	c.TxPoolPriceBump = core.DefaultTxPoolConfig.PriceBump
+	c.TxPoolAccountSlots = core.DefaultTxPoolConfig.AccountSlots
+	c.TxPoolGlobalSlots = core.DefaultTxPoolConfig.GlobalSlots
+	c.TxPoolAccountQueue = core.DefaultTxPoolConfig.AccountQueue
+	c.TxPoolGlobalQueue = core.DefaultTxPoolConfig.GlobalQueue
+	c.TxPoolLifetime.Duration = core.DefaultTxPoolConfig.Lifetime
 }
 
 func (d *Duration) UnmarshalJSON(data []byte) (err error) {
@@ -173,6 +279,58 @@ func (d *Duration) UnmarshalJSON(data []byte) (err error) {
 	return err
 }
 
+// Update returns a copy of the config with the fields in [partial], a JSON
+// object keyed like the VM config, applied on top of it. Returns an error if
+// [partial] contains a field that cannot be updated while the VM is running,
+// or if the resulting config is invalid.
+func (c Config) Update(partial []byte) (Config, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(partial, &fields); err != nil {
+		return Config{}, fmt.Errorf("failed to parse config update: %w", err)
+	}
+	knownFields := configFields()
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, ok := knownFields[key]; !ok {
+			return Config{}, fmt.Errorf("unknown config field %q", key)
+		}
+		if _, ok := liveConfigFields[key]; !ok {
+			return Config{}, fmt.Errorf("config field %q cannot be updated while the VM is running, restart the node to change it", key)
+		}
+	}
+
+	// Copy the priority regossip addresses so that unmarshalling does not
+	// write into the backing array of the current config.
+	c.PriorityRegossipAddresses = append([]common.Address(nil), c.PriorityRegossipAddresses...)
+	if err := json.Unmarshal(partial, &c); err != nil {
+		return Config{}, fmt.Errorf("failed to parse config update: %w", err)
+	}
+	if _, err := log.LvlFromString(c.LogLevel); err != nil {
+		return Config{}, fmt.Errorf("failed to parse log level: %w", err)
+	}
+	if err := c.Validate(); err != nil {
+		return Config{}, err
+	}
+	return c, nil
+}
+
+// configFields returns the set of JSON keys of the config fields.
+func configFields() map[string]struct{} {
+	configType := reflect.TypeOf(Config{})
+	fields := make(map[string]struct{}, configType.NumField())
+	for i := 0; i < configType.NumField(); i++ {
+		name := strings.Split(configType.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			fields[name] = struct{}{}
+		}
+	}
+	return fields
+}
+
 // Validate returns an error if this is an invalid config.
 func (c *Config) Validate() error {
 	if c.PopulateMissingTries != nil && (c.OfflinePruning || c.Pruning) {
@@ -185,10 +343,60 @@ func (c *Config) Validate() error {
 	if !c.Pruning && c.OfflinePruning {
 		return fmt.Errorf("cannot run offline pruning while pruning is disabled")
 	}
+	if !c.Pruning && c.OnlinePruning {
+		return fmt.Errorf("cannot run online pruning while pruning is disabled")
+	}
+	if c.OnlinePruning {
+		if c.OnlinePruningRetention == 0 {
+			return fmt.Errorf("online pruning retention must be positive (retention: %d)", c.OnlinePruningRetention)
+		}
+		if c.OnlinePruningBatchSize <= 0 {
+			return fmt.Errorf("online pruning batch size must be positive (size: %d)", c.OnlinePruningBatchSize)
+		}
+		if c.OnlinePruningBloomFilterSize == 0 {
+			return fmt.Errorf("online pruning bloom filter size must be positive (size: %d)", c.OnlinePruningBloomFilterSize)
+		}
+	}
 
 	// If pruning is enabled, the commit interval must be non-zero so the node commits state tries every CommitInterval blocks.
 	if c.Pruning && c.CommitInterval == 0 {
 		return fmt.Errorf("cannot use commit interval of 0 with pruning enabled")
 	}
+
+	if c.StateSyncCommitInterval == 0 {
+		return fmt.Errorf("cannot use state sync commit interval of 0")
+	}
+	// The bloom indexer is checkpointed at the parent of the block synced to, which
+	// requires the synced block to start a bloom bits section.
+	if c.StateSyncCommitInterval%params.BloomBitsBlocks != 0 {
+		return fmt.Errorf("state sync commit interval must be a multiple of %d (interval: %d)", params.BloomBitsBlocks, c.StateSyncCommitInterval)
+	}
+
+	if c.RegossipFrequency.Duration <= 0 {
+		return fmt.Errorf("regossip frequency must be positive (frequency: %s)", c.RegossipFrequency.Duration)
+	}
+	if c.PriorityRegossipFrequency.Duration <= 0 {
+		return fmt.Errorf("priority regossip frequency must be positive (frequency: %s)", c.PriorityRegossipFrequency.Duration)
+	}
+	if c.TxPullGossipFrequency.Duration < 0 {
+		return fmt.Errorf("tx pull gossip frequency cannot be negative (frequency: %s)", c.TxPullGossipFrequency.Duration)
+	}
+	if c.TxPullGossipFrequency.Duration > 0 && (c.TxPullGossipFilterSize <= 0 || c.TxPullGossipFilterSize > message.MaxTxsPullFilterSize) {
+		return fmt.Errorf("tx pull gossip filter size must be in [1, %d] (size: %d)", message.MaxTxsPullFilterSize, c.TxPullGossipFilterSize)
+	}
+
+	if c.HealthCheckMaxAcceptorQueueRatio < 0 || c.HealthCheckMaxAcceptorQueueRatio > 1 {
+		return fmt.Errorf("health check max acceptor queue ratio must be in [0, 1] (ratio: %f)", c.HealthCheckMaxAcceptorQueueRatio)
+	}
+	if c.HealthCheckMaxTxPoolRatio < 0 || c.HealthCheckMaxTxPoolRatio > 1 {
+		return fmt.Errorf("health check max tx pool ratio must be in [0, 1] (ratio: %f)", c.HealthCheckMaxTxPoolRatio)
+	}
+
+	if c.TxPoolAccountRateLimit < 0 {
+		return fmt.Errorf("tx pool account rate limit cannot be negative (limit: %f)", c.TxPoolAccountRateLimit)
+	}
+	if c.TxPoolAccountMaxShare < 0 || c.TxPoolAccountMaxShare > 1 {
+		return fmt.Errorf("tx pool account max share must be in [0, 1] (share: %f)", c.TxPoolAccountMaxShare)
+	}
 	return nil
 }
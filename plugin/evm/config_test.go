@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -43,6 +44,12 @@ func TestUnmarshalConfig(t *testing.T) {
 			Config{},
 			true,
 		},
+		{
+			"tx pool settings parsed",
+			[]byte(`{"tx-pool-global-slots": 1024, "tx-pool-lifetime": "10m", "tx-pool-account-rate-limit": 2.5, "tx-pool-account-rate-burst": 8, "tx-pool-account-max-share": 0.1}`),
+			Config{TxPoolGlobalSlots: 1024, TxPoolLifetime: Duration{10 * time.Minute}, TxPoolAccountRateLimit: 2.5, TxPoolAccountRateBurst: 8, TxPoolAccountMaxShare: 0.1},
+			false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -58,3 +65,68 @@ func TestUnmarshalConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateConfig(t *testing.T) {
+	addr := common.HexToAddress("0x71562b71999873DB5b286dF957af199Ec94617F7")
+	tests := []struct {
+		name        string
+		givenJSON   []byte
+		expected    func(*Config)
+		expectedErr string
+	}{
+		{
+			name:      "live fields updated",
+			givenJSON: []byte(`{"rpc-gas-cap": 1000, "regossip-frequency": "10s", "priority-regossip-addresses": ["0x71562b71999873DB5b286dF957af199Ec94617F7"]}`),
+			expected: func(c *Config) {
+				c.RPCGasCap = 1000
+				c.RegossipFrequency.Duration = 10 * time.Second
+				c.PriorityRegossipAddresses = []common.Address{addr}
+			},
+		},
+		{
+			name:      "pull gossip fields updated",
+			givenJSON: []byte(`{"tx-pull-gossip-frequency": "1s", "tx-pull-gossip-filter-size": 1024}`),
+			expected: func(c *Config) {
+				c.TxPullGossipFrequency.Duration = time.Second
+				c.TxPullGossipFilterSize = 1024
+			},
+		},
+		{
+			name:        "restart only field",
+			givenJSON:   []byte(`{"rpc-gas-cap": 1000, "pruning-enabled": false}`),
+			expectedErr: `config field "pruning-enabled" cannot be updated while the VM is running`,
+		},
+		{
+			name:        "unknown field",
+			givenJSON:   []byte(`{"not-a-field": 1}`),
+			expectedErr: `unknown config field "not-a-field"`,
+		},
+		{
+			name:        "invalid result",
+			givenJSON:   []byte(`{"regossip-frequency": 0}`),
+			expectedErr: "regossip frequency must be positive",
+		},
+		{
+			name:        "invalid log level",
+			givenJSON:   []byte(`{"log-level": "loud"}`),
+			expectedErr: "failed to parse log level",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var config Config
+			config.SetDefaults()
+			original := config
+
+			updated, err := config.Update(tt.givenJSON)
+			if len(tt.expectedErr) > 0 {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			tt.expected(&original)
+			assert.Equal(t, original, updated)
+		})
+	}
+}
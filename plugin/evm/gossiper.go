@@ -4,7 +4,10 @@
 package evm
 
 import (
+	"context"
+	"errors"
 	"math/big"
+	"math/rand"
 	"sync"
 	"time"
 
@@ -15,6 +18,7 @@ import (
 	"github.com/ava-labs/avalanchego/cache"
 	"github.com/ava-labs/avalanchego/ids"
 	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/avalanchego/utils"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
@@ -34,8 +38,16 @@ const (
 	// [txsGossipInterval] is how often we attempt to gossip newly seen
 	// transactions to other nodes.
 	txsGossipInterval = 500 * time.Millisecond
+
+	// [minTxsPullInterval] is the minimum time between two TxsPullRequests of
+	// the same peer that are served. Requests arriving sooner are dropped.
+	minTxsPullInterval = time.Second
 )
 
+// errNoTxsPullPeers is returned when no connected peer announced that it
+// serves TxsPullRequests.
+var errNoTxsPullPeers = errors.New("no peers serving TxsPullRequests")
+
 // Gossiper handles outgoing gossip of transactions
 type Gossiper interface {
 	// GossipTxs sends AppGossip message containing the given [txs]
@@ -57,9 +69,14 @@ type pushGossiper struct {
 	txsToGossipChan chan []*types.Transaction
 	txsToGossip     map[common.Hash]*types.Transaction
 	lastGossiped    time.Time
+	configChan      chan Config
 	shutdownChan    chan struct{}
 	shutdownWg      *sync.WaitGroup
 
+	// [puller] pulls the transactions missing from the mempool, and follows
+	// the config updates of [pushGossiper].
+	puller *pullGossiper
+
 	// [recentTxs] prevent us from over-gossiping the
 	// same transaction in a short period of time.
 	recentTxs *cache.LRU
@@ -83,6 +100,7 @@ func (vm *VM) createGossipper() Gossiper {
 		txPool:               vm.chain.GetTxPool(),
 		txsToGossipChan:      make(chan []*types.Transaction),
 		txsToGossip:          make(map[common.Hash]*types.Transaction),
+		configChan:           make(chan Config),
 		shutdownChan:         vm.shutdownChan,
 		shutdownWg:           &vm.shutdownWg,
 		recentTxs:            &cache.LRU{Size: recentCacheSize},
@@ -90,6 +108,22 @@ func (vm *VM) createGossipper() Gossiper {
 		signer:               types.LatestSigner(vm.chain.BlockChain().Config()),
 	}
 	net.awaitEthTxGossip()
+
+	net.puller = &pullGossiper{
+		ctx:                  vm.ctx,
+		gossipActivationTime: net.gossipActivationTime,
+		frequency:            vm.config.TxPullGossipFrequency.Duration,
+		filterSize:           vm.config.TxPullGossipFilterSize,
+		bootstrapped:         &vm.bootstrapped,
+		client:               vm.client,
+		peers:                vm.txsPullPeers,
+		txPool:               vm.chain.GetTxPool(),
+		configChan:           make(chan Config),
+		shutdownChan:         vm.shutdownChan,
+		shutdownWg:           &vm.shutdownWg,
+		codec:                vm.networkCodec,
+	}
+	net.puller.awaitTxsPull()
 	return net
 }
 
@@ -277,6 +311,10 @@ func (n *pushGossiper) awaitEthTxGossip() {
 						"err", err,
 					)
 				}
+			case config := <-n.configChan:
+				n.config = config
+				regossipTicker.Reset(config.RegossipFrequency.Duration)
+				priorityRegossipTicker.Reset(config.PriorityRegossipFrequency.Duration)
 			case <-n.shutdownChan:
 				return
 			}
@@ -284,6 +322,18 @@ func (n *pushGossiper) awaitEthTxGossip() {
 	})
 }
 
+// updateConfig replaces the config used by the gossip loop and the pull loop
+// with [config].
+func (n *pushGossiper) updateConfig(config Config) {
+	select {
+	case n.configChan <- config:
+	case <-n.shutdownChan:
+	}
+	if n.puller != nil {
+		n.puller.updateConfig(config)
+	}
+}
+
 func (n *pushGossiper) sendTxs(txs []*types.Transaction) error {
 	if len(txs) == 0 {
 		return nil
@@ -389,6 +439,225 @@ func (n *pushGossiper) GossipTxs(txs []*types.Transaction) error {
 	return nil
 }
 
+// pullGossiper periodically requests the mempool transactions of a random peer
+// that are missing from the local mempool, so that transactions gossiped
+// before this node was connected are still learned.
+type pullGossiper struct {
+	ctx                  *snow.Context
+	gossipActivationTime time.Time
+	bootstrapped         *utils.AtomicBool
+
+	// [frequency] and [filterSize] are only accessed by the pull loop once it
+	// is started, and are updated through [configChan]. Pulling is disabled
+	// while [frequency] is 0.
+	frequency  time.Duration
+	filterSize int
+
+	client       peer.Client
+	peers        *txsPullPeers
+	txPool       *core.TxPool
+	configChan   chan Config
+	shutdownChan chan struct{}
+	shutdownWg   *sync.WaitGroup
+
+	codec codec.Manager
+}
+
+// awaitTxsPull pulls transactions from a peer every [frequency] once the VM
+// is bootstrapped.
+func (n *pullGossiper) awaitTxsPull() {
+	n.shutdownWg.Add(1)
+	go n.ctx.Log.RecoverAndPanic(func() {
+		defer n.shutdownWg.Done()
+
+		// [pullTicker] is stopped while pulling is disabled.
+		pullTicker := time.NewTicker(time.Second)
+		defer pullTicker.Stop()
+		n.resetTicker(pullTicker)
+
+		for {
+			select {
+			case <-pullTicker.C:
+				if !n.bootstrapped.GetValue() || time.Now().Before(n.gossipActivationTime) {
+					continue
+				}
+				if added, err := n.pullTxs(); err != nil {
+					log.Debug(
+						"failed to pull eth transactions",
+						"err", err,
+					)
+				} else if added > 0 {
+					log.Debug(
+						"pulled eth transactions",
+						"len(txs)", added,
+					)
+				}
+			case config := <-n.configChan:
+				n.frequency = config.TxPullGossipFrequency.Duration
+				n.filterSize = config.TxPullGossipFilterSize
+				n.resetTicker(pullTicker)
+			case <-n.shutdownChan:
+				return
+			}
+		}
+	})
+}
+
+// resetTicker makes [pullTicker] tick every [frequency], or stops it if
+// pulling is disabled.
+func (n *pullGossiper) resetTicker(pullTicker *time.Ticker) {
+	if n.frequency > 0 {
+		pullTicker.Reset(n.frequency)
+	} else {
+		pullTicker.Stop()
+	}
+}
+
+// updateConfig replaces the frequency and filter size used by the pull loop
+// with those of [config].
+func (n *pullGossiper) updateConfig(config Config) {
+	select {
+	case n.configChan <- config:
+	case <-n.shutdownChan:
+	}
+}
+
+// pullTxs requests the transactions missing from the mempool from a random
+// peer serving TxsPullRequests and adds them to the mempool. Returns the number
+// of transactions that were added. The request is abandoned if it takes longer
+// than [frequency].
+func (n *pullGossiper) pullTxs() (int, error) {
+	nodeID, ok := n.peers.sample()
+	if !ok {
+		return 0, errNoTxsPullPeers
+	}
+	request, err := message.NewTxsPullRequest(n.txPool.Hashes(), n.filterSize)
+	if err != nil {
+		return 0, err
+	}
+	requestBytes, err := message.RequestToBytes(n.codec, request)
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), n.frequency)
+	defer cancel()
+	responseBytes, err := n.client.RequestWithContext(ctx, nodeID, requestBytes)
+	if err != nil {
+		return 0, err
+	}
+
+	var response message.TxsPullResponse
+	if _, err := n.codec.Unmarshal(responseBytes, &response); err != nil {
+		return 0, err
+	}
+	if len(response.Txs) == 0 {
+		return 0, nil
+	}
+	txs := make([]*types.Transaction, 0)
+	if err := rlp.DecodeBytes(response.Txs, &txs); err != nil {
+		return 0, err
+	}
+	added := 0
+	for i, err := range n.txPool.AddRemotes(txs) {
+		if err != nil {
+			log.Trace(
+				"failed to add pulled tx to mempool",
+				"err", err,
+				"tx", txs[i].Hash(),
+			)
+			continue
+		}
+		added++
+	}
+	return added, nil
+}
+
+// txsPullPeers tracks the connected peers that announced that they serve
+// TxsPullRequests. Peers running a release without pull gossip drop the
+// requests and let them time out, so pull requests are only sent to the peers
+// tracked here.
+type txsPullPeers struct {
+	lock  sync.Mutex
+	peers ids.NodeIDSet
+}
+
+func newTxsPullPeers() *txsPullPeers {
+	return &txsPullPeers{peers: ids.NewNodeIDSet(0)}
+}
+
+// add marks [nodeID] as serving TxsPullRequests and returns whether it was not
+// marked before.
+func (p *txsPullPeers) add(nodeID ids.NodeID) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.peers.Contains(nodeID) {
+		return false
+	}
+	p.peers.Add(nodeID)
+	return true
+}
+
+// remove forgets [nodeID] once it disconnects.
+func (p *txsPullPeers) remove(nodeID ids.NodeID) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.peers.Remove(nodeID)
+}
+
+// sample returns a peer serving TxsPullRequests chosen uniformly at random,
+// or false if there is none.
+func (p *txsPullPeers) sample() (ids.NodeID, bool) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.peers.Len() == 0 {
+		return ids.EmptyNodeID, false
+	}
+	peers := p.peers.List()
+	return peers[rand.Intn(len(peers))], true
+}
+
+// announceTxsPull announces to [nodeID] that this node serves TxsPullRequests.
+func (vm *VM) announceTxsPull(nodeID ids.NodeID) error {
+	msgBytes, err := message.BuildGossipMessage(vm.networkCodec, message.TxsPullAnnouncement{})
+	if err != nil {
+		return err
+	}
+	nodeIDs := ids.NewNodeIDSet(1)
+	nodeIDs.Add(nodeID)
+	return vm.Network.GossipSpecific(nodeIDs, msgBytes)
+}
+
+// onTxsPullAnnouncement marks [nodeID] as serving TxsPullRequests. Peers
+// marked for the first time are answered with an announcement of this node,
+// in case they missed the one sent when they connected.
+func (vm *VM) onTxsPullAnnouncement(nodeID ids.NodeID) error {
+	if !vm.txsPullPeers.add(nodeID) {
+		return nil
+	}
+	if err := vm.announceTxsPull(nodeID); err != nil {
+		log.Debug(
+			"failed to answer TxsPullAnnouncement",
+			"peerID", nodeID,
+			"err", err,
+		)
+	}
+	return nil
+}
+
+// txsPullAnnouncementHandler handles the TxsPullAnnouncements of peers until
+// the VM is bootstrapped and drops all other gossip.
+type txsPullAnnouncementHandler struct {
+	message.NoopMempoolGossipHandler
+	vm *VM
+}
+
+func (h *txsPullAnnouncementHandler) HandleTxsPullAnnouncement(nodeID ids.NodeID, _ message.TxsPullAnnouncement) error {
+	return h.vm.onTxsPullAnnouncement(nodeID)
+}
+
 // GossipHandler handles incoming gossip messages
 type GossipHandler struct {
 	vm     *VM
@@ -440,6 +709,153 @@ func (h *GossipHandler) HandleTxs(nodeID ids.NodeID, msg message.TxsGossip) erro
 	return nil
 }
 
+func (h *GossipHandler) HandleTxsPullAnnouncement(nodeID ids.NodeID, _ message.TxsPullAnnouncement) error {
+	log.Trace(
+		"AppGossip called with TxsPullAnnouncement",
+		"peerID", nodeID,
+	)
+	return h.vm.onTxsPullAnnouncement(nodeID)
+}
+
+// TxsPullHandler serves the mempool transactions requested by peers with a
+// TxsPullRequest.
+type TxsPullHandler struct {
+	// [configLock] protects [config], which is updated along with the config of
+	// the VM while requests are served.
+	configLock sync.RWMutex
+	config     Config
+
+	// [lastServed] holds when the last request of each peer was served, and is
+	// protected by [lastServedLock].
+	lastServedLock sync.Mutex
+	lastServed     map[ids.NodeID]time.Time
+
+	blockchain *core.BlockChain
+	txPool     *core.TxPool
+	codec      codec.Manager
+	signer     types.Signer
+}
+
+func NewTxsPullHandler(vm *VM) *TxsPullHandler {
+	return &TxsPullHandler{
+		config:     vm.config,
+		lastServed: make(map[ids.NodeID]time.Time),
+		blockchain: vm.chain.BlockChain(),
+		txPool:     vm.chain.GetTxPool(),
+		codec:      vm.networkCodec,
+		signer:     types.LatestSigner(vm.chain.BlockChain().Config()),
+	}
+}
+
+// updateConfig replaces the config used to serve requests with [config].
+func (h *TxsPullHandler) updateConfig(config Config) {
+	h.configLock.Lock()
+	defer h.configLock.Unlock()
+
+	h.config = config
+}
+
+// remoteGossipOnly returns whether local transactions are withheld from peers.
+func (h *TxsPullHandler) remoteGossipOnly() bool {
+	h.configLock.RLock()
+	defer h.configLock.RUnlock()
+
+	return h.config.RemoteGossipOnlyEnabled
+}
+
+// allowRequest returns whether a request of [nodeID] may be served, given that
+// at most one request per peer is served every [minTxsPullInterval].
+func (h *TxsPullHandler) allowRequest(nodeID ids.NodeID) bool {
+	h.lastServedLock.Lock()
+	defer h.lastServedLock.Unlock()
+
+	now := time.Now()
+	if last, ok := h.lastServed[nodeID]; ok && now.Sub(last) < minTxsPullInterval {
+		return false
+	}
+	// Forget the peers that may be served again, so the map does not grow with
+	// every peer ever seen.
+	for peerID, last := range h.lastServed {
+		if now.Sub(last) >= minTxsPullInterval {
+			delete(h.lastServed, peerID)
+		}
+	}
+	h.lastServed[nodeID] = now
+	return true
+}
+
+// OnTxsPullRequest responds with the executable transactions in the mempool
+// missing from the filter of [request], ordered by price, up to
+// [message.TxMsgSoftCapSize].
+// Returns nil to drop malformed requests, and requests of peers that were
+// served less than [minTxsPullInterval] ago, without a response.
+func (h *TxsPullHandler) OnTxsPullRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, request message.TxsPullRequest) ([]byte, error) {
+	if err := request.Verify(); err != nil {
+		log.Debug(
+			"dropping invalid TxsPullRequest",
+			"peerID", nodeID,
+			"requestID", requestID,
+			"err", err,
+		)
+		return nil, nil
+	}
+	if !h.allowRequest(nodeID) {
+		log.Debug(
+			"dropping throttled TxsPullRequest",
+			"peerID", nodeID,
+			"requestID", requestID,
+		)
+		return nil, nil
+	}
+
+	var (
+		remoteGossipOnly = h.remoteGossipOnly()
+		pending          = h.txPool.Pending(true)
+		stxs             = types.NewTransactionsByPriceAndNonce(h.signer, pending, h.blockchain.CurrentBlock().BaseFee())
+		txs              = make([]*types.Transaction, 0)
+		size             = common.StorageSize(0)
+	)
+	for tx := stxs.Peek(); tx != nil; tx = stxs.Peek() {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		txHash := tx.Hash()
+		// Transactions of the requester do not prevent sending the transactions
+		// of the same account with higher nonces.
+		if request.Contains(txHash) || (remoteGossipOnly && h.txPool.HasLocal(txHash)) {
+			stxs.Shift()
+			continue
+		}
+		if size+tx.Size() > message.TxMsgSoftCapSize {
+			break
+		}
+		txs = append(txs, tx)
+		size += tx.Size()
+		stxs.Shift()
+	}
+
+	response := message.TxsPullResponse{}
+	if len(txs) > 0 {
+		txBytes, err := rlp.EncodeToBytes(txs)
+		if err != nil {
+			return nil, err
+		}
+		response.Txs = txBytes
+	}
+	return h.codec.Marshal(message.Version, response)
+}
+
+// requestHandler serves the requests of peers, delegating all requests other
+// than TxsPullRequest to the embedded RequestHandler.
+type requestHandler struct {
+	message.RequestHandler
+	txsPullHandler *TxsPullHandler
+}
+
+func (h *requestHandler) HandleTxsPullRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, request message.TxsPullRequest) ([]byte, error) {
+	return h.txsPullHandler.OnTxsPullRequest(ctx, nodeID, requestID, request)
+}
+
 // noopGossiper should be used when gossip communication is not supported
 type noopGossiper struct{}
 
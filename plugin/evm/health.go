@@ -3,10 +3,103 @@
 
 package evm
 
-// Health returns nil if this chain is healthy.
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var errNotInitialized = errors.New("vm is not initialized")
+
+// Health contains the details reported by the VM health check.
+type Health struct {
+	Bootstrapped bool `json:"bootstrapped"`
+
+	LastAcceptedHeight uint64        `json:"lastAcceptedHeight"`
+	LastAcceptedAge    time.Duration `json:"lastAcceptedAge"`
+	MaxBlockAge        time.Duration `json:"maxBlockAge,omitempty"`
+
+	AcceptorQueueSize  int `json:"acceptorQueueSize"`
+	AcceptorQueueLimit int `json:"acceptorQueueLimit"`
+
+	ConnectedPeers uint32 `json:"connectedPeers"`
+	MinPeers       uint32 `json:"minPeers,omitempty"`
+
+	TxPoolPending  int    `json:"txPoolPending"`
+	TxPoolQueued   int    `json:"txPoolQueued"`
+	TxPoolCapacity uint64 `json:"txPoolCapacity"`
+
+	SnapshotEnabled    bool `json:"snapshotEnabled"`
+	SnapshotGenerating bool `json:"snapshotGenerating"`
+}
+
+// HealthCheck returns nil if this chain is healthy.
 // Also returns details, which should be one of:
 // string, []byte, map[string]string
 func (vm *VM) HealthCheck() (interface{}, error) {
-	// TODO perform actual health check
-	return nil, nil
+	if vm.chain == nil {
+		return nil, errNotInitialized
+	}
+	var (
+		config   = vm.currentConfig()
+		details  = &Health{Bootstrapped: vm.bootstrapped.GetValue()}
+		failures []string
+	)
+	if !details.Bootstrapped {
+		failures = append(failures, "vm is not bootstrapped")
+	}
+
+	blockchain := vm.chain.BlockChain()
+	lastAccepted := blockchain.LastAcceptedBlock()
+	details.LastAcceptedHeight = lastAccepted.NumberU64()
+	details.LastAcceptedAge = vm.clock.Time().Sub(time.Unix(int64(lastAccepted.Time()), 0))
+	if multiplier := config.HealthCheckMaxBlockAgeMultiplier; multiplier > 0 {
+		feeConfig, _, err := blockchain.GetFeeConfigAt(lastAccepted.Header())
+		if err != nil {
+			return details, fmt.Errorf("failed to get fee config at last accepted block %s: %w", lastAccepted.Hash(), err)
+		}
+		details.MaxBlockAge = time.Duration(multiplier*feeConfig.TargetBlockRate) * time.Second
+		if details.LastAcceptedAge > details.MaxBlockAge {
+			failures = append(failures, fmt.Sprintf("last accepted block is %s old, exceeding %s", details.LastAcceptedAge, details.MaxBlockAge))
+		}
+	}
+
+	details.AcceptorQueueSize = blockchain.AcceptorQueueSize()
+	details.AcceptorQueueLimit = config.AcceptorQueueLimit
+	if ratio := config.HealthCheckMaxAcceptorQueueRatio; ratio > 0 && details.AcceptorQueueLimit > 0 {
+		if float64(details.AcceptorQueueSize) > ratio*float64(details.AcceptorQueueLimit) {
+			failures = append(failures, fmt.Sprintf("acceptor queue has %d of %d blocks queued", details.AcceptorQueueSize, details.AcceptorQueueLimit))
+		}
+	}
+
+	details.ConnectedPeers = vm.Network.Size()
+	details.MinPeers = config.HealthCheckMinPeers
+	if details.ConnectedPeers < details.MinPeers {
+		failures = append(failures, fmt.Sprintf("connected to %d peers, fewer than %d", details.ConnectedPeers, details.MinPeers))
+	}
+
+	txPool := vm.chain.GetTxPool()
+	txPoolConfig := txPool.Config()
+	details.TxPoolPending, details.TxPoolQueued = txPool.Stats()
+	details.TxPoolCapacity = txPoolConfig.GlobalSlots + txPoolConfig.GlobalQueue
+	if ratio := config.HealthCheckMaxTxPoolRatio; ratio > 0 {
+		if size := details.TxPoolPending + details.TxPoolQueued; float64(size) > ratio*float64(details.TxPoolCapacity) {
+			failures = append(failures, fmt.Sprintf("tx pool has %d of %d transactions", size, details.TxPoolCapacity))
+		}
+	}
+
+	if snaps := blockchain.Snapshots(); snaps != nil {
+		details.SnapshotEnabled = true
+		generating, err := snaps.Generating()
+		if err != nil {
+			return details, fmt.Errorf("failed to check snapshot generation: %w", err)
+		}
+		details.SnapshotGenerating = generating
+	}
+
+	if len(failures) > 0 {
+		return details, fmt.Errorf("vm is unhealthy: %s", strings.Join(failures, "; "))
+	}
+	return details, nil
 }
@@ -0,0 +1,57 @@
+// (c) 2019-2020, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthCheck(t *testing.T) {
+	configJSON := `{"health-check-max-block-age-multiplier": 5}`
+	_, vm, _, _ := GenesisVM(t, false, genesisJSONSubnetEVM, configJSON, "")
+	defer func() {
+		assert.NoError(t, vm.Shutdown())
+	}()
+
+	// The genesis block has a timestamp of 0, so it is 2 seconds old here.
+	vm.clock.Set(time.Unix(2, 0))
+
+	details, err := vm.HealthCheck()
+	assert.ErrorContains(t, err, "not bootstrapped")
+	assert.False(t, details.(*Health).Bootstrapped)
+
+	assert.NoError(t, vm.SetState(snow.Bootstrapping))
+	assert.NoError(t, vm.SetState(snow.NormalOp))
+
+	details, err = vm.HealthCheck()
+	assert.NoError(t, err)
+	health := details.(*Health)
+	assert.True(t, health.Bootstrapped)
+	assert.Equal(t, uint64(0), health.LastAcceptedHeight)
+	assert.Equal(t, 2*time.Second, health.LastAcceptedAge)
+	assert.Equal(t, 10*time.Second, health.MaxBlockAge)
+	assert.Equal(t, vm.config.AcceptorQueueLimit, health.AcceptorQueueLimit)
+	assert.True(t, health.SnapshotEnabled)
+
+	// Exceed the maximum block age of 5 * TargetBlockRate.
+	vm.clock.Set(time.Unix(11, 0))
+	_, err = vm.HealthCheck()
+	assert.ErrorContains(t, err, "last accepted block is 11s old")
+
+	// Failed checks are all reported.
+	vm.config.HealthCheckMinPeers = 1
+	_, err = vm.HealthCheck()
+	assert.ErrorContains(t, err, "last accepted block is 11s old")
+	assert.ErrorContains(t, err, "connected to 0 peers, fewer than 1")
+}
+
+func TestHealthCheckNotInitialized(t *testing.T) {
+	vm := &VM{}
+	_, err := vm.HealthCheck()
+	assert.ErrorIs(t, err, errNotInitialized)
+}
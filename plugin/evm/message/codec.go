@@ -34,6 +34,11 @@ func BuildCodec() (codec.Manager, error) {
 		c.RegisterType(CodeRequest{}),
 		c.RegisterType(CodeResponse{}),
 
+		// Pull gossip types
+		c.RegisterType(TxsPullRequest{}),
+		c.RegisterType(TxsPullResponse{}),
+		c.RegisterType(TxsPullAnnouncement{}),
+
 		codecManager.RegisterCodec(Version, c),
 	)
 	return codecManager, errs.Err
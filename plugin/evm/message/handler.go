@@ -11,11 +11,15 @@ import (
 	"github.com/ava-labs/avalanchego/ids"
 )
 
-var _ GossipHandler = NoopMempoolGossipHandler{}
+var (
+	_ GossipHandler  = NoopMempoolGossipHandler{}
+	_ RequestHandler = NoopRequestHandler{}
+)
 
 // GossipHandler handles incoming gossip messages
 type GossipHandler interface {
 	HandleTxs(nodeID ids.NodeID, msg TxsGossip) error
+	HandleTxsPullAnnouncement(nodeID ids.NodeID, msg TxsPullAnnouncement) error
 }
 
 type NoopMempoolGossipHandler struct{}
@@ -25,6 +29,11 @@ func (NoopMempoolGossipHandler) HandleTxs(nodeID ids.NodeID, _ TxsGossip) error
 	return nil
 }
 
+func (NoopMempoolGossipHandler) HandleTxsPullAnnouncement(nodeID ids.NodeID, _ TxsPullAnnouncement) error {
+	log.Debug("dropping unexpected TxsPullAnnouncement message", "peerID", nodeID)
+	return nil
+}
+
 // RequestHandler interface handles incoming requests from peers
 // Must have methods in format of handleType(context.Context, ids.NodeID, uint32, request Type) error
 // so that the Request object of relevant Type can invoke its respective handle method
@@ -34,6 +43,26 @@ type RequestHandler interface {
 	HandleTrieLeafsRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, leafsRequest LeafsRequest) ([]byte, error)
 	HandleBlockRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, request BlockRequest) ([]byte, error)
 	HandleCodeRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, codeRequest CodeRequest) ([]byte, error)
+	HandleTxsPullRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, request TxsPullRequest) ([]byte, error)
+}
+
+// NoopRequestHandler drops all incoming requests without a response
+type NoopRequestHandler struct{}
+
+func (NoopRequestHandler) HandleTrieLeafsRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, leafsRequest LeafsRequest) ([]byte, error) {
+	return nil, nil
+}
+
+func (NoopRequestHandler) HandleBlockRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, request BlockRequest) ([]byte, error) {
+	return nil, nil
+}
+
+func (NoopRequestHandler) HandleCodeRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, codeRequest CodeRequest) ([]byte, error) {
+	return nil, nil
+}
+
+func (NoopRequestHandler) HandleTxsPullRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, request TxsPullRequest) ([]byte, error) {
+	return nil, nil
 }
 
 // ResponseHandler handles response for a sent request
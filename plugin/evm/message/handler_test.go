@@ -12,7 +12,7 @@ import (
 )
 
 type CounterHandler struct {
-	Txs int
+	Txs, TxsPullAnnouncements int
 }
 
 func (h *CounterHandler) HandleTxs(ids.NodeID, TxsGossip) error {
@@ -20,6 +20,11 @@ func (h *CounterHandler) HandleTxs(ids.NodeID, TxsGossip) error {
 	return nil
 }
 
+func (h *CounterHandler) HandleTxsPullAnnouncement(ids.NodeID, TxsPullAnnouncement) error {
+	h.TxsPullAnnouncements++
+	return nil
+}
+
 func TestHandleTxs(t *testing.T) {
 	assert := assert.New(t)
 
@@ -31,6 +36,18 @@ func TestHandleTxs(t *testing.T) {
 	assert.Equal(1, handler.Txs)
 }
 
+func TestHandleTxsPullAnnouncement(t *testing.T) {
+	assert := assert.New(t)
+
+	handler := CounterHandler{}
+	msg := TxsPullAnnouncement{}
+
+	err := msg.Handle(&handler, ids.EmptyNodeID)
+	assert.NoError(err)
+	assert.Equal(1, handler.TxsPullAnnouncements)
+	assert.Zero(handler.Txs)
+}
+
 func TestNoopHandler(t *testing.T) {
 	assert := assert.New(t)
 
@@ -38,4 +55,7 @@ func TestNoopHandler(t *testing.T) {
 
 	err := handler.HandleTxs(ids.EmptyNodeID, TxsGossip{})
 	assert.NoError(err)
+
+	err = handler.HandleTxsPullAnnouncement(ids.EmptyNodeID, TxsPullAnnouncement{})
+	assert.NoError(err)
 }
@@ -25,6 +25,7 @@ const (
 
 var (
 	_ GossipMessage = TxsGossip{}
+	_ GossipMessage = TxsPullAnnouncement{}
 
 	errUnexpectedCodecVersion = errors.New("unexpected codec version")
 )
@@ -49,6 +50,19 @@ func (msg TxsGossip) String() string {
 	return fmt.Sprintf("TxsGossip(Len=%d)", len(msg.Txs))
 }
 
+// TxsPullAnnouncement is gossiped to a peer to announce that the sender serves
+// TxsPullRequests. Peers running a release without pull gossip cannot parse it
+// and drop it.
+type TxsPullAnnouncement struct{}
+
+func (msg TxsPullAnnouncement) Handle(handler GossipHandler, nodeID ids.NodeID) error {
+	return handler.HandleTxsPullAnnouncement(nodeID, msg)
+}
+
+func (msg TxsPullAnnouncement) String() string {
+	return "TxsPullAnnouncement"
+}
+
 func ParseGossipMessage(codec codec.Manager, bytes []byte) (GossipMessage, error) {
 	var msg GossipMessage
 	version, err := codec.Unmarshal(bytes, &msg)
@@ -38,6 +38,25 @@ func TestMarshalTxs(t *testing.T) {
 	assert.Equal(msg, parsedMsg.Txs)
 }
 
+// TestMarshalTxsPullAnnouncement asserts that the serialization of TxsPullAnnouncement hasn't
+// changed, to ensure compatibility with the network.
+func TestMarshalTxsPullAnnouncement(t *testing.T) {
+	assert := assert.New(t)
+
+	base64TxsPullAnnouncement := "AAAAAAAK"
+	codec, err := BuildCodec()
+	assert.NoError(err)
+	builtMsgBytes, err := BuildGossipMessage(codec, TxsPullAnnouncement{})
+	assert.NoError(err)
+	assert.Equal(base64TxsPullAnnouncement, base64.StdEncoding.EncodeToString(builtMsgBytes))
+
+	parsedMsgIntf, err := ParseGossipMessage(codec, builtMsgBytes)
+	assert.NoError(err)
+
+	_, ok := parsedMsgIntf.(TxsPullAnnouncement)
+	assert.True(ok)
+}
+
 func TestTxsTooLarge(t *testing.T) {
 	assert := assert.New(t)
 
@@ -6,18 +6,88 @@ package message
 import (
 	"fmt"
 
+	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
+var _ block.StateSummary = SyncableBlock{}
+
 // SyncableBlock provides the information necessary to sync a node starting
 // at the given block.
 type SyncableBlock struct {
 	BlockNumber uint64      `serialize:"true"`
 	BlockRoot   common.Hash `serialize:"true"`
-	AtomicRoot  common.Hash `serialize:"true"`
 	BlockHash   common.Hash `serialize:"true"`
+
+	summaryID  ids.ID
+	bytes      []byte
+	acceptImpl func(SyncableBlock) (bool, error)
+}
+
+// NewSyncableBlockFromBytes parses [summaryBytes] into a SyncableBlock.
+// [acceptImpl] is invoked when the engine accepts the summary.
+func NewSyncableBlockFromBytes(codec codec.Manager, summaryBytes []byte, acceptImpl func(SyncableBlock) (bool, error)) (SyncableBlock, error) {
+	summary := SyncableBlock{}
+	if codecVersion, err := codec.Unmarshal(summaryBytes, &summary); err != nil {
+		return SyncableBlock{}, err
+	} else if codecVersion != Version {
+		return SyncableBlock{}, fmt.Errorf("failed to parse syncable summary due to unexpected codec version (%d != %d)", codecVersion, Version)
+	}
+
+	summary.bytes = summaryBytes
+	summaryID, err := ids.ToID(crypto.Keccak256(summaryBytes))
+	if err != nil {
+		return SyncableBlock{}, err
+	}
+	summary.summaryID = summaryID
+	summary.acceptImpl = acceptImpl
+	return summary, nil
+}
+
+// NewSyncableBlock returns a SyncableBlock for the block with [blockHash],
+// [blockNumber] and state [blockRoot].
+func NewSyncableBlock(codec codec.Manager, blockHash common.Hash, blockNumber uint64, blockRoot common.Hash) (SyncableBlock, error) {
+	summary := SyncableBlock{
+		BlockNumber: blockNumber,
+		BlockRoot:   blockRoot,
+		BlockHash:   blockHash,
+	}
+	bytes, err := codec.Marshal(Version, &summary)
+	if err != nil {
+		return SyncableBlock{}, err
+	}
+
+	summary.bytes = bytes
+	summaryID, err := ids.ToID(crypto.Keccak256(bytes))
+	if err != nil {
+		return SyncableBlock{}, err
+	}
+	summary.summaryID = summaryID
+	return summary, nil
+}
+
+func (s SyncableBlock) Bytes() []byte {
+	return s.bytes
+}
+
+func (s SyncableBlock) Height() uint64 {
+	return s.BlockNumber
+}
+
+func (s SyncableBlock) ID() ids.ID {
+	return s.summaryID
 }
 
 func (s SyncableBlock) String() string {
-	return fmt.Sprintf("SyncableBlock(BlockHash=%s, BlockNumber=%d, BlockRoot=%s, AtomicRoot=%s)", s.BlockHash, s.BlockNumber, s.BlockRoot, s.AtomicRoot)
+	return fmt.Sprintf("SyncableBlock(BlockHash=%s, BlockNumber=%d, BlockRoot=%s)", s.BlockHash, s.BlockNumber, s.BlockRoot)
+}
+
+func (s SyncableBlock) Accept() (bool, error) {
+	if s.acceptImpl == nil {
+		return false, fmt.Errorf("accept implementation not specified for summary: %s", s)
+	}
+	return s.acceptImpl(s)
 }
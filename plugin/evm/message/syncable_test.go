@@ -0,0 +1,44 @@
+// (c) 2021-2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package message
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncableBlock(t *testing.T) {
+	assert := assert.New(t)
+
+	codec, err := BuildCodec()
+	assert.NoError(err)
+
+	summary, err := NewSyncableBlock(codec, common.Hash{1}, 2, common.Hash{3})
+	assert.NoError(err)
+	assert.Equal(uint64(2), summary.Height())
+
+	accepted := false
+	parsed, err := NewSyncableBlockFromBytes(codec, summary.Bytes(), func(s SyncableBlock) (bool, error) {
+		accepted = true
+		assert.Equal(summary.ID(), s.ID())
+		return true, nil
+	})
+	assert.NoError(err)
+	assert.Equal(summary.ID(), parsed.ID())
+	assert.Equal(summary.Bytes(), parsed.Bytes())
+	assert.Equal(common.Hash{1}, parsed.BlockHash)
+	assert.Equal(uint64(2), parsed.BlockNumber)
+	assert.Equal(common.Hash{3}, parsed.BlockRoot)
+
+	ok, err := parsed.Accept()
+	assert.NoError(err)
+	assert.True(ok)
+	assert.True(accepted)
+
+	// a summary without an accept implementation cannot be accepted
+	_, err = summary.Accept()
+	assert.Error(err)
+}
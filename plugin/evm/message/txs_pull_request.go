@@ -0,0 +1,145 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package message
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/units"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// MaxTxsPullFilterSize is the maximum size in bytes of the bloom filter
+	// of a [TxsPullRequest].
+	MaxTxsPullFilterSize = 64 * units.KiB
+
+	// maxTxsPullNumHashes is the maximum number of hash functions of the bloom
+	// filter of a [TxsPullRequest].
+	maxTxsPullNumHashes = 16
+)
+
+var (
+	_ Request = TxsPullRequest{}
+
+	errInvalidTxsPullFilterSize = errors.New("invalid txs pull filter size")
+	errInvalidTxsPullNumHashes  = errors.New("invalid txs pull filter number of hashes")
+)
+
+// TxsPullRequest is a request for the transactions in the mempool of a peer
+// that the requester does not have yet. The transactions the requester already
+// has are given as a bloom filter of their hashes.
+type TxsPullRequest struct {
+	// Filter holds the bits of the bloom filter
+	Filter []byte `serialize:"true"`
+	// NumHashes is the number of bits set in [Filter] for each hash
+	NumHashes uint8 `serialize:"true"`
+	// Salt is mixed into the bit indices of each hash, so that false positives
+	// differ between requests
+	Salt uint64 `serialize:"true"`
+}
+
+// NewTxsPullRequest returns a TxsPullRequest with a [filterSize] byte bloom
+// filter containing [hashes].
+func NewTxsPullRequest(hashes []common.Hash, filterSize int) (TxsPullRequest, error) {
+	if filterSize <= 0 || filterSize > MaxTxsPullFilterSize {
+		return TxsPullRequest{}, fmt.Errorf("%w: %d", errInvalidTxsPullFilterSize, filterSize)
+	}
+	var salt [8]byte
+	if _, err := rand.Read(salt[:]); err != nil {
+		return TxsPullRequest{}, err
+	}
+	request := TxsPullRequest{
+		Filter:    make([]byte, filterSize),
+		NumHashes: optimalNumHashes(uint64(filterSize)*8, uint64(len(hashes))),
+		Salt:      binary.BigEndian.Uint64(salt[:]),
+	}
+	for _, hash := range hashes {
+		request.add(hash)
+	}
+	return request, nil
+}
+
+// optimalNumHashes returns the number of hash functions that minimizes the
+// false positive rate of a bloom filter of [m] bits containing [n] items.
+func optimalNumHashes(m, n uint64) uint8 {
+	if n == 0 {
+		return 1
+	}
+	k := math.Round(float64(m) / float64(n) * math.Ln2)
+	switch {
+	case k < 1:
+		return 1
+	case k > maxTxsPullNumHashes:
+		return maxTxsPullNumHashes
+	default:
+		return uint8(k)
+	}
+}
+
+// indices calls [f] with the index of each of the bits of [hash] in the
+// filter, stopping early if [f] returns false.
+func (r TxsPullRequest) indices(hash common.Hash, f func(uint64) bool) {
+	var (
+		m = uint64(len(r.Filter)) * 8
+		a = binary.BigEndian.Uint64(hash[0:8]) ^ r.Salt
+		b = binary.BigEndian.Uint64(hash[8:16]) ^ bits.RotateLeft64(r.Salt, 32) | 1
+	)
+	for i := uint64(0); i < uint64(r.NumHashes); i++ {
+		if !f((a + i*b) % m) {
+			return
+		}
+	}
+}
+
+func (r TxsPullRequest) add(hash common.Hash) {
+	r.indices(hash, func(i uint64) bool {
+		r.Filter[i/8] |= 1 << (i % 8)
+		return true
+	})
+}
+
+// Contains returns true if [hash] may be in the filter of the request. It may
+// return true for hashes that were never added.
+func (r TxsPullRequest) Contains(hash common.Hash) bool {
+	contains := true
+	r.indices(hash, func(i uint64) bool {
+		contains = r.Filter[i/8]&(1<<(i%8)) != 0
+		return contains
+	})
+	return contains
+}
+
+// Verify returns an error if the filter of the request is malformed.
+func (r TxsPullRequest) Verify() error {
+	if len(r.Filter) == 0 || len(r.Filter) > MaxTxsPullFilterSize {
+		return fmt.Errorf("%w: %d", errInvalidTxsPullFilterSize, len(r.Filter))
+	}
+	if r.NumHashes == 0 || r.NumHashes > maxTxsPullNumHashes {
+		return fmt.Errorf("%w: %d", errInvalidTxsPullNumHashes, r.NumHashes)
+	}
+	return nil
+}
+
+func (r TxsPullRequest) String() string {
+	return fmt.Sprintf("TxsPullRequest(FilterSize=%d, NumHashes=%d)", len(r.Filter), r.NumHashes)
+}
+
+func (r TxsPullRequest) Handle(ctx context.Context, nodeID ids.NodeID, requestID uint32, handler RequestHandler) ([]byte, error) {
+	return handler.HandleTxsPullRequest(ctx, nodeID, requestID, r)
+}
+
+// TxsPullResponse is a response to a TxsPullRequest
+// Txs holds the RLP encoded transactions missing from the filter of the request
+// handler: evm.TxsPullHandler
+type TxsPullResponse struct {
+	Txs []byte `serialize:"true"`
+}
@@ -0,0 +1,70 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package message
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMarshalTxsPullRequest asserts that the structure or serialization logic hasn't changed, primarily to
+// ensure compatibility with the network.
+func TestMarshalTxsPullRequest(t *testing.T) {
+	txsPullRequest := TxsPullRequest{
+		Filter:    []byte{0x01, 0x02, 0x03, 0x04},
+		NumHashes: 3,
+		Salt:      5,
+	}
+
+	base64TxsPullRequest := "AAAAAAAEAQIDBAMAAAAAAAAABQ=="
+
+	codec, err := BuildCodec()
+	assert.NoError(t, err)
+
+	txsPullRequestBytes, err := codec.Marshal(Version, txsPullRequest)
+	assert.NoError(t, err)
+	assert.Equal(t, base64TxsPullRequest, base64.StdEncoding.EncodeToString(txsPullRequestBytes))
+
+	var r TxsPullRequest
+	_, err = codec.Unmarshal(txsPullRequestBytes, &r)
+	assert.NoError(t, err)
+	assert.Equal(t, txsPullRequest, r)
+}
+
+func TestTxsPullRequestFilter(t *testing.T) {
+	hashes := make([]common.Hash, 1000)
+	for i := range hashes {
+		hashes[i] = crypto.Keccak256Hash([]byte{byte(i), byte(i >> 8)})
+	}
+
+	request, err := NewTxsPullRequest(hashes[:500], 1024)
+	assert.NoError(t, err)
+	assert.NoError(t, request.Verify())
+	assert.Equal(t, uint8(11), request.NumHashes)
+
+	// There are no false negatives.
+	for _, hash := range hashes[:500] {
+		assert.True(t, request.Contains(hash))
+	}
+	// With ~16 bits per hash, false positives are rare.
+	falsePositives := 0
+	for _, hash := range hashes[500:] {
+		if request.Contains(hash) {
+			falsePositives++
+		}
+	}
+	assert.Less(t, falsePositives, 10)
+
+	_, err = NewTxsPullRequest(hashes, MaxTxsPullFilterSize+1)
+	assert.ErrorIs(t, err, errInvalidTxsPullFilterSize)
+
+	request.NumHashes = maxTxsPullNumHashes + 1
+	assert.ErrorIs(t, request.Verify(), errInvalidTxsPullNumHashes)
+	request.Filter = nil
+	assert.ErrorIs(t, request.Verify(), errInvalidTxsPullFilterSize)
+}
@@ -5,6 +5,7 @@ package evm
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/ava-labs/avalanchego/utils/formatting"
@@ -18,6 +19,8 @@ var (
 	errNoChainID      = errors.New("no chain ID provided in genesis config")
 	errInvalidChainID = errors.New("chainID must be greater than 0")
 	errNoAlloc        = errors.New("no alloc table provided in genesis")
+
+	errAirdropHashMismatch = errors.New("airdrop hash does not match airdrop allocations")
 )
 
 // StaticService defines the static API services exposed by the evm
@@ -55,6 +58,22 @@ func (ss *StaticService) BuildGenesis(_ *http.Request, args *BuildGenesisArgs, r
 		return errNoAlloc
 	}
 
+	// Compute the airdrop hash for the given airdrop allocations, or verify it
+	// if it was provided.
+	if len(args.GenesisData.Airdrop) > 0 || len(args.GenesisData.AirdropPath) > 0 {
+		airdropHash, err := args.GenesisData.ComputeAirdropHash()
+		if err != nil {
+			return fmt.Errorf("failed to compute airdrop hash: %w", err)
+		}
+		switch args.GenesisData.AirdropHash {
+		case common.Hash{}:
+			args.GenesisData.AirdropHash = airdropHash
+		case airdropHash:
+		default:
+			return fmt.Errorf("%w: expected %s but computed %s", errAirdropHashMismatch, args.GenesisData.AirdropHash, airdropHash)
+		}
+	}
+
 	bytes, err := args.GenesisData.MarshalJSON()
 	if err != nil {
 		return err
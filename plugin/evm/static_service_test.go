@@ -11,6 +11,7 @@ import (
 	"github.com/ava-labs/avalanchego/utils/formatting"
 	"github.com/ava-labs/subnet-evm/core"
 	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -54,6 +55,33 @@ func TestBuildGenesis(t *testing.T) {
 	assert.Equal(t, testAlloc, decodedGenesis.Alloc)
 }
 
+func TestBuildGenesisAirdropHash(t *testing.T) {
+	ss := CreateStaticService()
+
+	genesis := &core.Genesis{}
+	if err := json.Unmarshal([]byte(testGenesisJSON), genesis); err != nil {
+		t.Fatalf("Problem unmarshaling genesis JSON: %s", err)
+	}
+	genesis.AirdropAmount = genesisBalance
+	genesis.Airdrop = []core.Airdrop{{Address: testEthAddrs[0]}, {Address: testEthAddrs[1]}}
+	expectedHash, err := genesis.ComputeAirdropHash()
+	assert.NoError(t, err)
+
+	reply := &BuildGenesisReply{}
+	assert.NoError(t, ss.BuildGenesis(nil, &BuildGenesisArgs{GenesisData: genesis}, reply))
+	genesisBytes, err := formatting.Decode(reply.Encoding, reply.GenesisBytes)
+	assert.NoError(t, err)
+	decodedGenesis := &core.Genesis{}
+	assert.NoError(t, decodedGenesis.UnmarshalJSON(genesisBytes))
+	assert.Equal(t, expectedHash, decodedGenesis.AirdropHash)
+	assert.Equal(t, genesis.Airdrop, decodedGenesis.Airdrop)
+
+	// A hash that does not match the allocations is rejected.
+	genesis.AirdropHash = common.Hash{1}
+	err = ss.BuildGenesis(nil, &BuildGenesisArgs{GenesisData: genesis}, &BuildGenesisReply{})
+	assert.ErrorIs(t, err, errAirdropHashMismatch)
+}
+
 func TestDecodeGenesis(t *testing.T) {
 	ss := CreateStaticService()
 
@@ -0,0 +1,141 @@
+// (c) 2021-2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package evm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/database"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/snow"
+	"github.com/ava-labs/subnet-evm/plugin/evm/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateSyncSummaries(t *testing.T) {
+	_, vm, _, _ := GenesisVM(t, false, genesisJSONSubnetEVM, `{"state-sync-enabled":true}`, "")
+	defer func() {
+		if err := vm.Shutdown(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	enabled, err := vm.StateSyncEnabled()
+	assert.NoError(t, err)
+	assert.True(t, enabled)
+
+	// No sync is in progress
+	_, err = vm.GetOngoingSyncStateSummary()
+	assert.ErrorIs(t, err, database.ErrNotFound)
+
+	// The genesis block is the latest block at a multiple of the syncable interval
+	summary, err := vm.GetLastStateSummary()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), summary.Height())
+
+	summaryAtHeight, err := vm.GetStateSummary(0)
+	assert.NoError(t, err)
+	assert.Equal(t, summary.ID(), summaryAtHeight.ID())
+
+	// Blocks that are not accepted or not at the syncable interval are not served
+	_, err = vm.GetStateSummary(1)
+	assert.ErrorIs(t, err, database.ErrNotFound)
+	_, err = vm.GetStateSummary(vm.config.StateSyncCommitInterval)
+	assert.ErrorIs(t, err, database.ErrNotFound)
+
+	parsed, err := vm.ParseStateSummary(summary.Bytes())
+	assert.NoError(t, err)
+	assert.Equal(t, summary.ID(), parsed.ID())
+
+	// The summary is not far enough ahead of the last accepted block, so
+	// the VM should skip state sync.
+	syncing, err := parsed.Accept()
+	assert.NoError(t, err)
+	assert.False(t, syncing)
+	_, err = vm.GetOngoingSyncStateSummary()
+	assert.ErrorIs(t, err, database.ErrNotFound)
+}
+
+func TestStateSyncSkippedInitializesSnapshots(t *testing.T) {
+	_, vm, _, _ := GenesisVM(t, false, genesisJSONSubnetEVM, `{"state-sync-enabled":true}`, "")
+	defer func() {
+		if err := vm.Shutdown(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Snapshots are not initialized until a summary is accepted or
+	// bootstrapping starts.
+	assert.Nil(t, vm.chain.BlockChain().Snapshots())
+
+	// The engine moves on to bootstrapping without accepting any summary.
+	assert.NoError(t, vm.SetState(snow.Bootstrapping))
+	assert.NotNil(t, vm.chain.BlockChain().Snapshots())
+	_, err := vm.GetOngoingSyncStateSummary()
+	assert.ErrorIs(t, err, database.ErrNotFound)
+}
+
+func TestStateSyncDisabled(t *testing.T) {
+	_, vm, _, _ := GenesisVM(t, true, genesisJSONSubnetEVM, "", "")
+	defer func() {
+		if err := vm.Shutdown(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	enabled, err := vm.StateSyncEnabled()
+	assert.NoError(t, err)
+	assert.False(t, enabled)
+}
+
+func TestStateSyncServerHandlers(t *testing.T) {
+	for name, test := range map[string]struct {
+		configJSON       string
+		expectedResponse bool
+	}{
+		"server enabled": {
+			configJSON:       `{"state-sync-server-enabled":true}`,
+			expectedResponse: true,
+		},
+		"server disabled": {
+			configJSON:       "",
+			expectedResponse: false,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, vm, _, appSender := GenesisVM(t, true, genesisJSONSubnetEVM, test.configJSON, "")
+			defer func() {
+				if err := vm.Shutdown(); err != nil {
+					t.Fatal(err)
+				}
+			}()
+
+			var response []byte
+			appSender.SendAppResponseF = func(_ ids.NodeID, _ uint32, responseBytes []byte) error {
+				response = responseBytes
+				return nil
+			}
+
+			genesis := vm.chain.GetBlockByNumber(0)
+			var request message.Request = message.BlockRequest{
+				Hash:    genesis.Hash(),
+				Height:  0,
+				Parents: 1,
+			}
+			requestBytes, err := message.RequestToBytes(vm.networkCodec, request)
+			assert.NoError(t, err)
+			assert.NoError(t, vm.AppRequest(ids.GenerateTestNodeID(), 1, time.Now().Add(time.Minute), requestBytes))
+
+			if !test.expectedResponse {
+				assert.Nil(t, response)
+				return
+			}
+			var blockResponse message.BlockResponse
+			_, err = vm.networkCodec.Unmarshal(response, &blockResponse)
+			assert.NoError(t, err)
+			assert.Len(t, blockResponse.Blocks, 1)
+		})
+	}
+}
@@ -4,6 +4,7 @@
 package evm
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"encoding/json"
 	"fmt"
@@ -13,7 +14,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ava-labs/avalanchego/codec"
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/version"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -24,6 +27,7 @@ import (
 	"github.com/ava-labs/subnet-evm/core"
 	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/peer"
 	"github.com/ava-labs/subnet-evm/plugin/evm/message"
 )
 
@@ -415,3 +419,319 @@ func TestMempoolTxsPriorityRegossip(t *testing.T) {
 	assert.Len(queued, 10, "unexpected length of queued txs")
 	assert.ElementsMatch(txs, queued)
 }
+
+// handlerClient is a peer.Client that sends requests directly to [handler].
+type handlerClient struct {
+	peer.Client
+	codec   codec.Manager
+	handler message.RequestHandler
+	nodeID  ids.NodeID
+}
+
+func (c *handlerClient) Request(nodeID ids.NodeID, requestBytes []byte) ([]byte, error) {
+	return c.RequestWithContext(context.Background(), nodeID, requestBytes)
+}
+
+func (c *handlerClient) RequestWithContext(ctx context.Context, nodeID ids.NodeID, requestBytes []byte) ([]byte, error) {
+	c.nodeID = nodeID
+	request, err := message.BytesToRequest(c.codec, requestBytes)
+	if err != nil {
+		return nil, err
+	}
+	return request.Handle(ctx, ids.GenerateTestNodeID(), 0, c.handler)
+}
+
+func TestMempoolTxsPullHandling(t *testing.T) {
+	assert := assert.New(t)
+
+	key, err := crypto.GenerateKey()
+	assert.NoError(err)
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+
+	cfgJson, err := fundAddressByGenesis([]common.Address{addr})
+	assert.NoError(err)
+
+	_, vm, _, sender := GenesisVM(t, true, cfgJson, "", "")
+	defer func() {
+		err := vm.Shutdown()
+		assert.NoError(err)
+	}()
+	vm.chain.GetTxPool().SetGasPrice(common.Big1)
+	vm.chain.GetTxPool().SetMinFee(common.Big0)
+
+	// create eth txes
+	txs := getValidTxs(key, 10, big.NewInt(226*params.GWei))
+	for _, err := range vm.chain.GetTxPool().AddRemotesSync(txs) {
+		assert.NoError(err, "failed adding subnet-evm tx to remote mempool")
+	}
+
+	// Pull the transactions, already knowing the first 3 of them
+	request, err := message.NewTxsPullRequest([]common.Hash{txs[0].Hash(), txs[1].Hash(), txs[2].Hash()}, 1024)
+	assert.NoError(err)
+	requestBytes, err := message.RequestToBytes(vm.networkCodec, request)
+	assert.NoError(err)
+
+	var responseBytes []byte
+	sender.SendAppResponseF = func(_ ids.NodeID, _ uint32, response []byte) error {
+		responseBytes = response
+		return nil
+	}
+	nodeID := ids.GenerateTestNodeID()
+	assert.NoError(vm.AppRequest(nodeID, 1, time.Now().Add(time.Minute), requestBytes))
+
+	var response message.TxsPullResponse
+	_, err = vm.networkCodec.Unmarshal(responseBytes, &response)
+	assert.NoError(err)
+	pulled := make([]*types.Transaction, 0)
+	assert.NoError(rlp.DecodeBytes(response.Txs, &pulled))
+	assert.Len(pulled, 7)
+	for i, tx := range pulled {
+		assert.Equal(txs[i+3].Hash(), tx.Hash())
+	}
+
+	// Another request of the same peer right away is dropped
+	responseBytes = nil
+	assert.NoError(vm.AppRequest(nodeID, 2, time.Now().Add(time.Minute), requestBytes))
+	assert.Nil(responseBytes)
+
+	// A node missing the transactions pulls them from [vm]
+	_, vm2, _, _ := GenesisVM(t, true, cfgJson, "", "")
+	defer func() {
+		err := vm2.Shutdown()
+		assert.NoError(err)
+	}()
+	vm2.chain.GetTxPool().SetGasPrice(common.Big1)
+	vm2.chain.GetTxPool().SetMinFee(common.Big0)
+	for _, err := range vm2.chain.GetTxPool().AddRemotesSync(txs[:3]) {
+		assert.NoError(err, "failed adding subnet-evm tx to remote mempool")
+	}
+
+	client := &handlerClient{
+		codec:   vm2.networkCodec,
+		handler: &requestHandler{RequestHandler: message.NoopRequestHandler{}, txsPullHandler: NewTxsPullHandler(vm)},
+	}
+	puller := &pullGossiper{
+		frequency:  vm2.config.TxPullGossipFrequency.Duration,
+		filterSize: vm2.config.TxPullGossipFilterSize,
+		client:     client,
+		peers:      newTxsPullPeers(),
+		txPool:     vm2.chain.GetTxPool(),
+		codec:      vm2.networkCodec,
+	}
+
+	// Nothing is pulled until a peer announced serving TxsPullRequests
+	_, err = puller.pullTxs()
+	assert.ErrorIs(err, errNoTxsPullPeers)
+
+	puller.peers.add(nodeID)
+	added, err := puller.pullTxs()
+	assert.NoError(err)
+	assert.Equal(7, added)
+	assert.Equal(nodeID, client.nodeID)
+	pending, queued := vm2.chain.GetTxPool().Stats()
+	assert.Equal(10, pending+queued)
+}
+
+func TestMempoolTxsPullBetweenPeers(t *testing.T) {
+	assert := assert.New(t)
+
+	key, err := crypto.GenerateKey()
+	assert.NoError(err)
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+
+	cfgJson, err := fundAddressByGenesis([]common.Address{addr})
+	assert.NoError(err)
+
+	_, vm, _, sender := GenesisVM(t, true, cfgJson, "", "")
+	defer func() {
+		err := vm.Shutdown()
+		assert.NoError(err)
+	}()
+	vm.chain.GetTxPool().SetGasPrice(common.Big1)
+	vm.chain.GetTxPool().SetMinFee(common.Big0)
+
+	_, vm2, _, sender2 := GenesisVM(t, true, cfgJson, "", "")
+	defer func() {
+		err := vm2.Shutdown()
+		assert.NoError(err)
+	}()
+	vm2.chain.GetTxPool().SetGasPrice(common.Big1)
+	vm2.chain.GetTxPool().SetMinFee(common.Big0)
+
+	txs := getValidTxs(key, 10, big.NewInt(226*params.GWei))
+	for _, err := range vm.chain.GetTxPool().AddRemotesSync(txs) {
+		assert.NoError(err, "failed adding subnet-evm tx to remote mempool")
+	}
+	for _, err := range vm2.chain.GetTxPool().AddRemotesSync(txs[:3]) {
+		assert.NoError(err, "failed adding subnet-evm tx to remote mempool")
+	}
+
+	// Route the requests of [vm2] to [vm] and the responses back
+	nodeID, nodeID2 := ids.GenerateTestNodeID(), ids.GenerateTestNodeID()
+	requested := ids.NewNodeIDSet(0)
+	sender2.SendAppRequestF = func(nodeIDs ids.NodeIDSet, requestID uint32, request []byte) error {
+		requested.Union(nodeIDs)
+		go func() {
+			assert.NoError(vm.AppRequest(nodeID2, requestID, time.Now().Add(time.Minute), request))
+		}()
+		return nil
+	}
+	sender.SendAppResponseF = func(_ ids.NodeID, requestID uint32, response []byte) error {
+		go func() {
+			assert.NoError(vm2.AppResponse(nodeID, requestID, response))
+		}()
+		return nil
+	}
+
+	// Announcements of [vm] and [vm2] are delivered to each other, while a peer
+	// running a release without pull gossip drops them.
+	oldNodeID := ids.GenerateTestNodeID()
+	sender2.SendAppGossipSpecificF = func(nodeIDs ids.NodeIDSet, gossip []byte) error {
+		if nodeIDs.Contains(nodeID) {
+			return vm.AppGossip(nodeID2, gossip)
+		}
+		return nil
+	}
+	sender.SendAppGossipSpecificF = func(_ ids.NodeIDSet, gossip []byte) error {
+		return vm2.AppGossip(nodeID, gossip)
+	}
+
+	puller := &pullGossiper{
+		frequency:  time.Minute,
+		filterSize: vm2.config.TxPullGossipFilterSize,
+		client:     vm2.client,
+		peers:      vm2.txsPullPeers,
+		txPool:     vm2.chain.GetTxPool(),
+		codec:      vm2.networkCodec,
+	}
+
+	// Both peers run the avalanchego release pinned in go.mod, but only [vm]
+	// serves TxsPullRequests.
+	assert.NoError(vm2.Connected(oldNodeID, version.CurrentApp))
+	_, err = puller.pullTxs()
+	assert.ErrorIs(err, errNoTxsPullPeers)
+
+	// [vm] answers the announcement [vm2] sends once connected with its own.
+	assert.NoError(vm2.Connected(nodeID, version.CurrentApp))
+	added, err := puller.pullTxs()
+	assert.NoError(err)
+	assert.Equal(7, added)
+	assert.False(requested.Contains(oldNodeID))
+	pending, queued := vm2.chain.GetTxPool().Stats()
+	assert.Equal(10, pending+queued)
+
+	// Disconnected peers are no longer pulled from
+	assert.NoError(vm2.Disconnected(nodeID))
+	_, err = puller.pullTxs()
+	assert.ErrorIs(err, errNoTxsPullPeers)
+}
+
+func TestMempoolTxsPullGossiperTicks(t *testing.T) {
+	assert := assert.New(t)
+
+	_, vm, _, sender := GenesisVM(t, true, genesisJSONSubnetEVM, `{"tx-pull-gossip-frequency": "10ms"}`, "")
+	defer func() {
+		err := vm.Shutdown()
+		assert.NoError(err)
+	}()
+
+	// The pull gossiper ticks while no peer serves TxsPullRequests
+	time.Sleep(50 * time.Millisecond)
+
+	requests := make(chan ids.NodeIDSet, 1)
+	sender.SendAppRequestF = func(nodeIDs ids.NodeIDSet, _ uint32, _ []byte) error {
+		select {
+		case requests <- nodeIDs:
+		default:
+		}
+		return nil
+	}
+	sender.SendAppGossipSpecificF = func(ids.NodeIDSet, []byte) error {
+		return nil
+	}
+
+	// Once a peer announces serving TxsPullRequests, the next tick pulls from it
+	nodeID := ids.GenerateTestNodeID()
+	assert.NoError(vm.Connected(nodeID, version.CurrentApp))
+	announcement, err := message.BuildGossipMessage(vm.networkCodec, message.TxsPullAnnouncement{})
+	assert.NoError(err)
+	assert.NoError(vm.AppGossip(nodeID, announcement))
+
+	select {
+	case nodeIDs := <-requests:
+		assert.True(nodeIDs.Contains(nodeID))
+	case <-time.After(5 * time.Second):
+		t.Fatal("pull gossiper did not send a TxsPullRequest")
+	}
+}
+
+func TestTxsPullPeersSample(t *testing.T) {
+	assert := assert.New(t)
+
+	peers := newTxsPullPeers()
+	_, ok := peers.sample()
+	assert.False(ok)
+
+	nodeIDs := ids.NewNodeIDSet(3)
+	for i := 0; i < 3; i++ {
+		nodeID := ids.GenerateTestNodeID()
+		nodeIDs.Add(nodeID)
+		assert.True(peers.add(nodeID))
+	}
+
+	// Every peer is eventually sampled
+	sampled := ids.NewNodeIDSet(3)
+	for i := 0; i < 1000 && sampled.Len() < nodeIDs.Len(); i++ {
+		nodeID, ok := peers.sample()
+		assert.True(ok)
+		assert.True(nodeIDs.Contains(nodeID))
+		sampled.Add(nodeID)
+	}
+	assert.True(nodeIDs.Equals(sampled))
+}
+
+func TestMempoolTxsPullGossiperUpdateConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	_, vm, _, sender := GenesisVM(t, true, genesisJSONSubnetEVM, `{"tx-pull-gossip-frequency": 0}`, "")
+	defer func() {
+		err := vm.Shutdown()
+		assert.NoError(err)
+	}()
+
+	requests := make(chan ids.NodeIDSet, 1)
+	sender.SendAppRequestF = func(nodeIDs ids.NodeIDSet, _ uint32, _ []byte) error {
+		select {
+		case requests <- nodeIDs:
+		default:
+		}
+		return nil
+	}
+	sender.SendAppGossipSpecificF = func(ids.NodeIDSet, []byte) error {
+		return nil
+	}
+	nodeID := ids.GenerateTestNodeID()
+	assert.NoError(vm.Connected(nodeID, version.CurrentApp))
+	announcement, err := message.BuildGossipMessage(vm.networkCodec, message.TxsPullAnnouncement{})
+	assert.NoError(err)
+	assert.NoError(vm.AppGossip(nodeID, announcement))
+
+	// Nothing is pulled while pull gossip is disabled
+	select {
+	case <-requests:
+		t.Fatal("pull gossiper sent a TxsPullRequest while disabled")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Enabling pull gossip while the VM is running starts pulling
+	admin := NewAdminService(vm, "")
+	assert.NoError(admin.UpdateVMConfig(nil, &UpdateVMConfigArgs{
+		Config: []byte(`{"tx-pull-gossip-frequency": "10ms"}`),
+	}, &ConfigReply{}))
+	select {
+	case nodeIDs := <-requests:
+		assert.True(nodeIDs.Contains(nodeID))
+	case <-time.After(5 * time.Second):
+		t.Fatal("pull gossiper did not send a TxsPullRequest")
+	}
+}
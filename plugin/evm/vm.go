@@ -20,6 +20,7 @@ import (
 	"github.com/ava-labs/subnet-evm/commontype"
 	"github.com/ava-labs/subnet-evm/constants"
 	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/rawdb"
 	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/eth/ethconfig"
 	"github.com/ava-labs/subnet-evm/metrics"
@@ -28,6 +29,12 @@ import (
 	"github.com/ava-labs/subnet-evm/params"
 	"github.com/ava-labs/subnet-evm/peer"
 	"github.com/ava-labs/subnet-evm/plugin/evm/message"
+	"github.com/ava-labs/subnet-evm/precompile"
+	"github.com/ava-labs/subnet-evm/rpc"
+	statesyncclient "github.com/ava-labs/subnet-evm/statesync/client"
+	"github.com/ava-labs/subnet-evm/statesync/client/stats"
+	"github.com/ava-labs/subnet-evm/statesync/handlers"
+	handlerstats "github.com/ava-labs/subnet-evm/statesync/handlers/stats"
 
 	"github.com/prometheus/client_golang/prometheus"
 
@@ -54,10 +61,12 @@ import (
 	"github.com/ava-labs/avalanchego/snow/choices"
 	"github.com/ava-labs/avalanchego/snow/consensus/snowman"
 	"github.com/ava-labs/avalanchego/snow/engine/snowman/block"
+	"github.com/ava-labs/avalanchego/utils"
 	cjson "github.com/ava-labs/avalanchego/utils/json"
 	"github.com/ava-labs/avalanchego/utils/perms"
 	"github.com/ava-labs/avalanchego/utils/profiler"
 	"github.com/ava-labs/avalanchego/utils/timer/mockable"
+	"github.com/ava-labs/avalanchego/version"
 	"github.com/ava-labs/avalanchego/vms/components/chain"
 
 	commonEng "github.com/ava-labs/avalanchego/snow/engine/common"
@@ -66,8 +75,10 @@ import (
 )
 
 var (
-	_ block.ChainVM              = &VM{}
-	_ block.HeightIndexedChainVM = &VM{}
+	_ block.ChainVM                  = &VM{}
+	_ block.HeightIndexedChainVM     = &VM{}
+	_ block.StateSyncableVM          = &VM{}
+	_ statesyncclient.EthBlockParser = &VM{}
 )
 
 const (
@@ -96,6 +107,7 @@ var (
 	lastAcceptedKey = []byte("last_accepted_key")
 	acceptedPrefix  = []byte("snowman_accepted")
 	ethDBPrefix     = []byte("ethdb")
+	metadataPrefix  = []byte("metadata")
 )
 
 var (
@@ -131,6 +143,12 @@ type VM struct {
 	*chain.State
 
 	config Config
+	// [configLock] protects the fields of [config] that can be updated while
+	// the VM is running.
+	configLock sync.RWMutex
+	// [configUpdateLock] serializes config updates, so that the gossip loops
+	// receive them in order without [configLock] being held while they do.
+	configUpdateLock sync.Mutex
 
 	networkID   uint64
 	genesisHash common.Hash
@@ -144,12 +162,20 @@ type VM struct {
 	// [acceptedBlockDB] is the database to store the last accepted
 	// block.
 	acceptedBlockDB database.Database
+	// [metadataDB] is used to store one off keys.
+	metadataDB database.Database
 
 	toEngine chan<- commonEng.Message
 
 	builder *blockBuilder
 
 	gossiper Gossiper
+	// [txsPullHandler] serves the mempool transactions pulled by peers, nil
+	// before the Subnet EVM upgrade is scheduled.
+	txsPullHandler *TxsPullHandler
+	// [txsPullPeers] tracks the peers that announced serving TxsPullRequests,
+	// nil before the Subnet EVM upgrade is scheduled.
+	txsPullPeers *txsPullPeers
 
 	clock mockable.Clock
 
@@ -163,10 +189,16 @@ type VM struct {
 	client       peer.Client
 	networkCodec codec.Manager
 
+	StateSyncServer
+	StateSyncClient
+
 	// Metrics
 	multiGatherer avalanchegoMetrics.MultiGatherer
 
-	bootstrapped bool
+	bootstrapped utils.AtomicBool
+
+	// [rpcHandler] serves the eth RPC APIs. Set in CreateHandlers.
+	rpcHandler *rpc.Server
 }
 
 // setLogLevel initializes logger and sets the log level with the original [os.StdErr] interface
@@ -265,6 +297,7 @@ func (vm *VM) Initialize(
 	vm.chaindb = Database{prefixdb.NewNested(ethDBPrefix, baseDB)}
 	vm.db = versiondb.New(baseDB)
 	vm.acceptedBlockDB = prefixdb.New(acceptedPrefix, vm.db)
+	vm.metadataDB = prefixdb.New(metadataPrefix, vm.db)
 	g := new(core.Genesis)
 	if err := json.Unmarshal(genesisBytes, g); err != nil {
 		return err
@@ -279,6 +312,15 @@ func (vm *VM) Initialize(
 		g.Config.FeeConfig = params.DefaultFeeConfig
 	}
 
+	// Read the airdrop allocations from the file given in the VM config, if
+	// any, in place of those in the genesis. These are still verified against
+	// the airdrop hash in the genesis, and only read when the genesis block is
+	// first committed.
+	if len(vm.config.AirdropPath) > 0 {
+		g.Airdrop = nil
+		g.AirdropPath = vm.config.AirdropPath
+	}
+
 	ethConfig := ethconfig.NewDefaultConfig()
 	ethConfig.Genesis = g
 	ethConfig.NetworkId = g.Config.ChainID.Uint64()
@@ -290,6 +332,16 @@ func (vm *VM) Initialize(
 	ethConfig.RPCTxFeeCap = vm.config.RPCTxFeeCap
 	ethConfig.TxPool.NoLocals = !vm.config.LocalTxsEnabled
 	ethConfig.TxPool.Locals = vm.config.PriorityRegossipAddresses
+	ethConfig.TxPool.PriceLimit = vm.config.TxPoolPriceLimit
+	ethConfig.TxPool.PriceBump = vm.config.TxPoolPriceBump
+	ethConfig.TxPool.AccountSlots = vm.config.TxPoolAccountSlots
+	ethConfig.TxPool.GlobalSlots = vm.config.TxPoolGlobalSlots
+	ethConfig.TxPool.AccountQueue = vm.config.TxPoolAccountQueue
+	ethConfig.TxPool.GlobalQueue = vm.config.TxPoolGlobalQueue
+	ethConfig.TxPool.Lifetime = vm.config.TxPoolLifetime.Duration
+	ethConfig.TxPool.AccountRateLimit = vm.config.TxPoolAccountRateLimit
+	ethConfig.TxPool.AccountRateBurst = vm.config.TxPoolAccountRateBurst
+	ethConfig.TxPool.AccountMaxShare = vm.config.TxPoolAccountMaxShare
 	ethConfig.AllowUnfinalizedQueries = vm.config.AllowUnfinalizedQueries
 	ethConfig.AllowUnprotectedTxs = vm.config.AllowUnprotectedTxs
 	ethConfig.Preimages = vm.config.Preimages
@@ -298,13 +350,20 @@ func (vm *VM) Initialize(
 	ethConfig.PopulateMissingTries = vm.config.PopulateMissingTries
 	ethConfig.PopulateMissingTriesParallelism = vm.config.PopulateMissingTriesParallelism
 	ethConfig.AllowMissingTries = vm.config.AllowMissingTries
-	ethConfig.SnapshotDelayInit = false // state sync enabled
+	// If state sync is enabled, snapshots are initialized once the sync completes
+	// or is skipped.
+	ethConfig.SnapshotDelayInit = vm.config.StateSyncEnabled
 	ethConfig.SnapshotAsync = vm.config.SnapshotAsync
 	ethConfig.SnapshotVerify = vm.config.SnapshotVerify
 	ethConfig.OfflinePruning = vm.config.OfflinePruning
 	ethConfig.OfflinePruningBloomFilterSize = vm.config.OfflinePruningBloomFilterSize
 	ethConfig.OfflinePruningDataDirectory = vm.config.OfflinePruningDataDirectory
+	ethConfig.OnlinePruning = vm.config.OnlinePruning
+	ethConfig.OnlinePruningRetention = vm.config.OnlinePruningRetention
+	ethConfig.OnlinePruningBatchSize = vm.config.OnlinePruningBatchSize
+	ethConfig.OnlinePruningBloomFilterSize = vm.config.OnlinePruningBloomFilterSize
 	ethConfig.CommitInterval = vm.config.CommitInterval
+	ethConfig.TxLookupLimit = vm.config.TxLookupLimit
 
 	// Create directory for offline pruning
 	if len(ethConfig.OfflinePruningDataDirectory) != 0 {
@@ -314,21 +373,6 @@ func (vm *VM) Initialize(
 		}
 	}
 
-	// Handle custom fee recipient
-	ethConfig.Miner.Etherbase = constants.BlackholeAddr
-	switch {
-	case common.IsHexAddress(vm.config.FeeRecipient):
-		if g.Config.AllowFeeRecipients {
-			address := common.HexToAddress(vm.config.FeeRecipient)
-			log.Info("Setting fee recipient", "address", address)
-			ethConfig.Miner.Etherbase = address
-			break
-		}
-		return errors.New("cannot specify a custom fee recipient on this blockchain")
-	case g.Config.AllowFeeRecipients:
-		log.Warn("Chain enabled `AllowFeeRecipients`, but chain config has not specified any coinbase address. Defaulting to the blackhole address.")
-	}
-
 	vm.chainConfig = g.Config
 	vm.networkID = ethConfig.NetworkId
 
@@ -342,9 +386,31 @@ func (vm *VM) Initialize(
 		vm.chainConfig.UpgradeConfig = upgradeConfig
 	}
 
+	// Handle custom fee recipient
+	ethConfig.Miner.Etherbase = constants.BlackholeAddr
+	switch {
+	case common.IsHexAddress(vm.config.FeeRecipient):
+		address := common.HexToAddress(vm.config.FeeRecipient)
+		if !g.Config.AllowFeeRecipients {
+			// The RewardManager precompile may allow fee recipients on chains that do not
+			// enable AllowFeeRecipients. Otherwise the fee recipient can never be used.
+			if !vm.chainConfig.IsPrecompileConfigured(precompile.RewardManagerAddress) {
+				return errors.New("cannot specify a custom fee recipient on this blockchain")
+			}
+			log.Warn("Chain has not enabled `AllowFeeRecipients`. The fee recipient will only be used if allowed by the RewardManager precompile.", "address", address)
+		}
+		log.Info("Setting fee recipient", "address", address)
+		ethConfig.Miner.Etherbase = address
+	case g.Config.AllowFeeRecipients:
+		log.Warn("Chain enabled `AllowFeeRecipients`, but chain config has not specified any coinbase address. Defaulting to the blackhole address.")
+	}
+
 	// create genesisHash after applying upgradeBytes in case
 	// upgradeBytes modifies genesis.
-	vm.genesisHash = ethConfig.Genesis.ToBlock(nil).Hash()
+	vm.genesisHash, err = vm.readGenesisHash(ethConfig.Genesis)
+	if err != nil {
+		return err
+	}
 
 	lastAcceptedHash, err := vm.readLastAccepted()
 	if err != nil {
@@ -364,11 +430,21 @@ func (vm *VM) Initialize(
 	// initialize peer network
 	vm.Network = peer.NewNetwork(appSender, vm.networkCodec, ctx.NodeID, vm.config.MaxOutboundActiveRequests)
 	vm.client = peer.NewClient(vm.Network)
+	// The pull gossiper created in initializeChain samples [txsPullPeers].
+	if vm.chainConfig.SubnetEVMTimestamp != nil {
+		vm.txsPullPeers = newTxsPullPeers()
+	}
 
 	if err := vm.initializeChain(lastAcceptedHash, ethConfig); err != nil {
 		return err
 	}
 
+	vm.initializeStateSyncServer()
+	vm.setAppRequestHandlers()
+	if err := vm.initializeStateSyncClient(); err != nil {
+		return err
+	}
+
 	go vm.ctx.Log.RecoverAndPanic(vm.startContinuousProfiler)
 
 	return nil
@@ -447,32 +523,172 @@ func (vm *VM) initChainState(lastAcceptedBlock *types.Block) error {
 	return vm.multiGatherer.Register(chainStateMetricsPrefix, chainStateRegisterer)
 }
 
+// initializeStateSyncClient initializes the client for performing state sync.
+// If state sync is disabled, this function will wipe any ongoing summary from
+// disk to ensure that we do not continue syncing from an invalid snapshot.
+func (vm *VM) initializeStateSyncClient() error {
+	// parse nodeIDs from state sync IDs in vm config
+	var stateSyncIDs []ids.NodeID
+	if vm.config.StateSyncEnabled && len(vm.config.StateSyncIDs) > 0 {
+		nodeIDs := strings.Split(vm.config.StateSyncIDs, ",")
+		stateSyncIDs = make([]ids.NodeID, len(nodeIDs))
+		for i, nodeIDString := range nodeIDs {
+			nodeID, err := ids.NodeIDFromString(nodeIDString)
+			if err != nil {
+				return fmt.Errorf("failed to parse %s as NodeID: %w", nodeIDString, err)
+			}
+			stateSyncIDs[i] = nodeID
+		}
+	}
+
+	vm.StateSyncClient = NewStateSyncClient(&stateSyncClientConfig{
+		chain: vm.chain,
+		state: vm.State,
+		client: statesyncclient.NewClient(
+			&statesyncclient.ClientConfig{
+				NetworkClient:    vm.client,
+				Codec:            vm.networkCodec,
+				Stats:            stats.NewClientSyncerStats(),
+				StateSyncNodeIDs: stateSyncIDs,
+				BlockParser:      vm,
+			},
+		),
+		enabled:            vm.config.StateSyncEnabled,
+		skipResume:         vm.config.StateSyncSkipResume,
+		stateSyncMinBlocks: vm.config.StateSyncMinBlocks,
+		lastAcceptedHeight: vm.chain.LastAcceptedBlock().NumberU64(),
+		chaindb:            vm.chaindb,
+		metadataDB:         vm.metadataDB,
+		acceptedBlockDB:    vm.acceptedBlockDB,
+		db:                 vm.db,
+		codec:              vm.networkCodec,
+		toEngine:           vm.toEngine,
+	})
+
+	// If StateSync is disabled, clear any ongoing summary so that we will not attempt to resume
+	// sync using a snapshot that has been modified by the node running normal operations.
+	if !vm.config.StateSyncEnabled {
+		return vm.StateSyncClient.StateSyncClearOngoingSummary()
+	}
+
+	return nil
+}
+
+// initializeStateSyncServer should be called after [vm.chain] is initialized.
+func (vm *VM) initializeStateSyncServer() {
+	vm.StateSyncServer = NewStateSyncServer(&stateSyncServerConfig{
+		Chain:            vm.chain.BlockChain(),
+		Codec:            vm.networkCodec,
+		SyncableInterval: vm.config.StateSyncCommitInterval,
+	})
+}
+
+// setAppRequestHandlers sets the request handlers for the VM to serve state sync
+// requests if [StateSyncServerEnabled] is set in the config, and to serve mempool
+// transactions to peers pulling them once Subnet EVM is scheduled.
+// Once Subnet EVM is scheduled, the TxsPullAnnouncements of peers are handled
+// until the gossip handler is replaced in [initGossipHandling].
+func (vm *VM) setAppRequestHandlers() {
+	var syncRequestHandler message.RequestHandler = message.NoopRequestHandler{}
+	if vm.config.StateSyncServerEnabled {
+		// Handler stats are registered on the default metrics registry, which is
+		// gathered by [multiGatherer] when metrics are enabled.
+		var handlerStats handlerstats.HandlerStats
+		if metrics.Enabled {
+			handlerStats = handlerstats.NewHandlerStats()
+		} else {
+			handlerStats = handlerstats.NewNoopHandlerStats()
+		}
+
+		blockChain := vm.chain.BlockChain()
+		syncRequestHandler = handlers.NewSyncHandler(
+			handlers.NewLeafsRequestHandler(blockChain.StateCache().TrieDB(), blockChain, handlerStats, vm.networkCodec),
+			handlers.NewBlockRequestHandler(blockChain.GetBlock, vm.networkCodec, handlerStats),
+			handlers.NewCodeRequestHandler(vm.chaindb, handlerStats, vm.networkCodec),
+		)
+	}
+	if vm.chainConfig.SubnetEVMTimestamp == nil {
+		vm.Network.SetRequestHandler(syncRequestHandler)
+		return
+	}
+	vm.txsPullHandler = NewTxsPullHandler(vm)
+	vm.Network.SetRequestHandler(&requestHandler{
+		RequestHandler: syncRequestHandler,
+		txsPullHandler: vm.txsPullHandler,
+	})
+	vm.Network.SetGossipHandler(&txsPullAnnouncementHandler{vm: vm})
+}
+
 func (vm *VM) initGossipHandling() {
 	if vm.chainConfig.SubnetEVMTimestamp != nil {
 		vm.Network.SetGossipHandler(NewGossipHandler(vm))
 	}
 }
 
+// Connected adds [nodeID] to the peers of the network and, once Subnet EVM is
+// scheduled, announces to it that this node serves TxsPullRequests.
+func (vm *VM) Connected(nodeID ids.NodeID, nodeVersion *version.Application) error {
+	if err := vm.Network.Connected(nodeID, nodeVersion); err != nil {
+		return err
+	}
+	if vm.txsPullPeers == nil || nodeID == vm.ctx.NodeID {
+		return nil
+	}
+	if err := vm.announceTxsPull(nodeID); err != nil {
+		log.Debug("failed to send TxsPullAnnouncement", "peerID", nodeID, "err", err)
+	}
+	return nil
+}
+
+// Disconnected removes [nodeID] from the peers of the network.
+func (vm *VM) Disconnected(nodeID ids.NodeID) error {
+	if vm.txsPullPeers != nil {
+		vm.txsPullPeers.remove(nodeID)
+	}
+	return vm.Network.Disconnected(nodeID)
+}
+
 func (vm *VM) SetState(state snow.State) error {
 	switch state {
-	case snow.Bootstrapping:
-		vm.bootstrapped = false
+	case snow.StateSyncing:
+		vm.bootstrapped.SetValue(false)
 		return nil
+	case snow.Bootstrapping:
+		return vm.onBootstrapStarted()
 	case snow.NormalOp:
 		vm.initGossipHandling()
-		vm.bootstrapped = true
+		vm.bootstrapped.SetValue(true)
 		return nil
 	default:
 		return snow.ErrUnknownState
 	}
 }
 
+// onBootstrapStarted marks this VM as bootstrapping
+func (vm *VM) onBootstrapStarted() error {
+	vm.bootstrapped.SetValue(false)
+	if err := vm.StateSyncClient.Error(); err != nil {
+		return err
+	}
+	// After starting bootstrapping, do not attempt to resume a previous state sync.
+	if err := vm.StateSyncClient.StateSyncClearOngoingSummary(); err != nil {
+		return err
+	}
+	// Ensure snapshots are initialized before bootstrapping (i.e., if state sync is skipped).
+	// Note calling this function has no effect if snapshots are already initialized.
+	vm.chain.BlockChain().InitializeSnapshots()
+	return nil
+}
+
 // Shutdown implements the snowman.ChainVM interface
 func (vm *VM) Shutdown() error {
 	if vm.ctx == nil {
 		return nil
 	}
 
+	if err := vm.StateSyncClient.Shutdown(); err != nil {
+		log.Error("error stopping state syncer", "err", err)
+	}
 	close(vm.shutdownChan)
 	vm.chain.Stop()
 	vm.shutdownWg.Wait()
@@ -537,6 +753,17 @@ func (vm *VM) parseBlock(b []byte) (snowman.Block, error) {
 	return block, nil
 }
 
+// ParseEthBlock parses [b] into a types.Block.
+// Implements statesyncclient.EthBlockParser.
+func (vm *VM) ParseEthBlock(b []byte) (*types.Block, error) {
+	block, err := vm.parseBlock(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return block.(*Block).ethBlock, nil
+}
+
 // getBlock attempts to retrieve block [id] from the VM to be wrapped
 // by ChainState.
 func (vm *VM) getBlock(id ids.ID) (snowman.Block, error) {
@@ -614,6 +841,7 @@ func newHandler(name string, service interface{}, lockOption ...commonEng.LockOp
 // CreateHandlers makes new http handlers that can handle API calls
 func (vm *VM) CreateHandlers() (map[string]*commonEng.HTTPHandler, error) {
 	handler := vm.chain.NewRPCHandler(vm.config.APIMaxDuration.Duration)
+	vm.rpcHandler = handler
 	enabledAPIs := vm.config.EthAPIs()
 	if err := vm.chain.AttachEthService(handler, enabledAPIs); err != nil {
 		return nil, err
@@ -658,6 +886,78 @@ func (vm *VM) CreateHandlers() (map[string]*commonEng.HTTPHandler, error) {
 	return apis, nil
 }
 
+// currentConfig returns a copy of the config the VM is running with.
+func (vm *VM) currentConfig() Config {
+	vm.configLock.RLock()
+	defer vm.configLock.RUnlock()
+
+	return vm.config
+}
+
+// updateConfig applies the fields in [partial] to the config of the running
+// VM and returns the resulting config. Only fields in [liveConfigFields] may
+// be updated. Changes to [APIMaxDuration] do not apply to websocket
+// connections that are already open.
+func (vm *VM) updateConfig(partial []byte) (Config, error) {
+	vm.configUpdateLock.Lock()
+	defer vm.configUpdateLock.Unlock()
+
+	config, removedPriorityAddresses, err := vm.swapConfig(partial)
+	if err != nil {
+		return Config{}, err
+	}
+
+	// The gossip loops may be busy, so they are updated after releasing
+	// [configLock] to not block the readers of the config meanwhile.
+	if gossiper, ok := vm.gossiper.(*pushGossiper); ok {
+		gossiper.updateConfig(config)
+	}
+	if vm.txsPullHandler != nil {
+		vm.txsPullHandler.updateConfig(config)
+	}
+	vm.chain.APIBackend().SetRPCCaps(config.RPCGasCap, config.APIMaxDuration.Duration, config.RPCTxFeeCap)
+	if vm.rpcHandler != nil {
+		vm.rpcHandler.SetMaximumDuration(config.APIMaxDuration.Duration)
+	}
+	vm.chain.GetTxPool().RemoveLocalAccounts(removedPriorityAddresses)
+	vm.chain.GetTxPool().AddLocalAccounts(config.PriorityRegossipAddresses)
+	log.Info("Updated VM config", "update", string(partial))
+	return config, nil
+}
+
+// swapConfig replaces the config of the VM with the fields in [partial]
+// applied, and applies the new log level. Returns the new config and the
+// priority regossip addresses it removed.
+func (vm *VM) swapConfig(partial []byte) (Config, []common.Address, error) {
+	vm.configLock.Lock()
+	defer vm.configLock.Unlock()
+
+	config, err := vm.config.Update(partial)
+	if err != nil {
+		return Config{}, nil, err
+	}
+	if config.LogLevel != vm.config.LogLevel {
+		logLevel, err := log.LvlFromString(config.LogLevel)
+		if err != nil {
+			return Config{}, nil, fmt.Errorf("failed to parse log level: %w", err)
+		}
+		vm.setLogLevel(logLevel)
+	}
+	// Accounts that are no longer priority addresses lose their local privileges.
+	priorityAddresses := make(map[common.Address]struct{}, len(config.PriorityRegossipAddresses))
+	for _, addr := range config.PriorityRegossipAddresses {
+		priorityAddresses[addr] = struct{}{}
+	}
+	var removedPriorityAddresses []common.Address
+	for _, addr := range vm.config.PriorityRegossipAddresses {
+		if _, ok := priorityAddresses[addr]; !ok {
+			removedPriorityAddresses = append(removedPriorityAddresses, addr)
+		}
+	}
+	vm.config = config
+	return config, removedPriorityAddresses, nil
+}
+
 // CreateStaticHandlers makes new http handlers that can handle API calls
 func (vm *VM) CreateStaticHandlers() (map[string]*commonEng.HTTPHandler, error) {
 	server := avalancheRPC.NewServer()
@@ -701,29 +1001,31 @@ func (vm *VM) currentRules() params.Rules {
 // follows the ruleset defined by [rules]
 func (vm *VM) getBlockValidator(rules params.Rules) BlockValidator {
 	if rules.IsSubnetEVM {
-		return blockValidatorSubnetEVM{feeConfigManagerEnabled: rules.IsFeeConfigManagerEnabled}
+		return blockValidatorSubnetEVM{feeConfigManagerEnabled: rules.IsPrecompileEnabled(precompile.FeeConfigManagerAddress)}
 	}
 
 	return legacyBlockValidator
 }
 
 func (vm *VM) startContinuousProfiler() {
+	// The config may be updated concurrently through the admin API.
+	config := vm.currentConfig()
 	// If the profiler directory is empty, return immediately
 	// without creating or starting a continuous profiler.
-	if vm.config.ContinuousProfilerDir == "" {
+	if config.ContinuousProfilerDir == "" {
 		return
 	}
 	vm.profiler = profiler.NewContinuous(
-		filepath.Join(vm.config.ContinuousProfilerDir),
-		vm.config.ContinuousProfilerFrequency.Duration,
-		vm.config.ContinuousProfilerMaxFiles,
+		filepath.Join(config.ContinuousProfilerDir),
+		config.ContinuousProfilerFrequency.Duration,
+		config.ContinuousProfilerMaxFiles,
 	)
 	defer vm.profiler.Shutdown()
 
 	vm.shutdownWg.Add(1)
 	go func() {
 		defer vm.shutdownWg.Done()
-		log.Info("Dispatching continuous profiler", "dir", vm.config.ContinuousProfilerDir, "freq", vm.config.ContinuousProfilerFrequency, "maxFiles", vm.config.ContinuousProfilerMaxFiles)
+		log.Info("Dispatching continuous profiler", "dir", config.ContinuousProfilerDir, "freq", config.ContinuousProfilerFrequency, "maxFiles", config.ContinuousProfilerMaxFiles)
 		err := vm.profiler.Dispatch()
 		if err != nil {
 			log.Error("continuous profiler failed", "err", err)
@@ -733,6 +1035,21 @@ func (vm *VM) startContinuousProfiler() {
 	<-vm.shutdownChan
 }
 
+// readGenesisHash returns the hash of the genesis block committed to
+// [vm.chaindb], or the hash of the genesis block built from [genesis] if none
+// is committed yet. The committed genesis is verified against [genesis] when
+// the chain is initialized, without reading the airdrop allocations again.
+func (vm *VM) readGenesisHash(genesis *core.Genesis) (common.Hash, error) {
+	if stored := rawdb.ReadCanonicalHash(vm.chaindb, 0); stored != (common.Hash{}) {
+		return stored, nil
+	}
+	block, err := genesis.ToBlock(nil)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to build genesis block: %w", err)
+	}
+	return block.Hash(), nil
+}
+
 // readLastAccepted reads the last accepted hash from [acceptedBlockDB] and returns the
 // last accepted block hash and height by reading directly from [vm.chaindb] instead of relying
 // on [chain].
@@ -41,6 +41,7 @@ import (
 	engCommon "github.com/ava-labs/avalanchego/snow/engine/common"
 
 	"github.com/ava-labs/subnet-evm/consensus/dummy"
+	subnetEVMConstants "github.com/ava-labs/subnet-evm/constants"
 	"github.com/ava-labs/subnet-evm/core"
 	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/eth"
@@ -219,6 +220,132 @@ func TestVMConfig(t *testing.T) {
 	assert.NoError(t, vm.Shutdown())
 }
 
+func TestVMTxPoolConfig(t *testing.T) {
+	configJSON := `{"tx-pool-global-slots": 1024, "tx-pool-account-slots": 8, "tx-pool-lifetime": "10m", "tx-pool-account-rate-limit": 2, "tx-pool-account-max-share": 0.1}`
+	_, vm, _, _ := GenesisVM(t, false, genesisJSONSubnetEVM, configJSON, "")
+	defer func() {
+		assert.NoError(t, vm.Shutdown())
+	}()
+
+	txPoolConfig := vm.chain.GetTxPool().Config()
+	assert.Equal(t, uint64(1024), txPoolConfig.GlobalSlots)
+	assert.Equal(t, uint64(8), txPoolConfig.AccountSlots)
+	assert.Equal(t, core.DefaultTxPoolConfig.GlobalQueue, txPoolConfig.GlobalQueue)
+	assert.Equal(t, 10*time.Minute, txPoolConfig.Lifetime)
+	assert.Equal(t, float64(2), txPoolConfig.AccountRateLimit)
+	assert.Equal(t, 8, txPoolConfig.AccountRateBurst) // Defaults to the account slots
+	assert.Equal(t, 0.1, txPoolConfig.AccountMaxShare)
+}
+
+func TestVMUpdateConfig(t *testing.T) {
+	_, vm, _, _ := GenesisVM(t, true, genesisJSONSubnetEVM, "", "")
+	defer func() {
+		assert.NoError(t, vm.Shutdown())
+	}()
+	admin := NewAdminService(vm, "")
+	priorityAddr := common.HexToAddress("0x0100000000000000000000000000000000000000")
+
+	reply := &ConfigReply{}
+	assert.NoError(t, admin.UpdateVMConfig(nil, &UpdateVMConfigArgs{
+		Config: []byte(fmt.Sprintf(`{"rpc-gas-cap": 1000, "rpc-tx-fee-cap": 2, "api-max-duration": "5s", "regossip-frequency": "2s", "priority-regossip-addresses": [%q]}`, priorityAddr)),
+	}, reply))
+	assert.Equal(t, uint64(1000), reply.Config.RPCGasCap)
+
+	backend := vm.chain.APIBackend()
+	assert.Equal(t, uint64(1000), backend.RPCGasCap())
+	assert.Equal(t, float64(2), backend.RPCTxFeeCap())
+	assert.Equal(t, 5*time.Second, backend.RPCEVMTimeout())
+	assert.Contains(t, vm.chain.GetTxPool().Locals(), priorityAddr)
+
+	// Restart only fields are rejected without applying the other fields.
+	err := admin.UpdateVMConfig(nil, &UpdateVMConfigArgs{
+		Config: []byte(`{"rpc-gas-cap": 2000, "commit-interval": 1}`),
+	}, &ConfigReply{})
+	assert.ErrorContains(t, err, `config field "commit-interval" cannot be updated while the VM is running`)
+
+	getReply := &ConfigReply{}
+	assert.NoError(t, admin.GetVMConfig(nil, nil, getReply))
+	assert.Equal(t, reply.Config, getReply.Config)
+	assert.Equal(t, uint64(1000), backend.RPCGasCap())
+
+	// Removed priority addresses are no longer local, and the pull handler
+	// follows the updated config.
+	assert.NoError(t, admin.UpdateVMConfig(nil, &UpdateVMConfigArgs{
+		Config: []byte(`{"priority-regossip-addresses": [], "remote-gossip-only-enabled": true}`),
+	}, &ConfigReply{}))
+	assert.NotContains(t, vm.chain.GetTxPool().Locals(), priorityAddr)
+	assert.True(t, vm.txsPullHandler.remoteGossipOnly())
+}
+
+func TestVMUpdateConfigWhileGossiping(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+	cfgJson, err := fundAddressByGenesis([]common.Address{crypto.PubkeyToAddress(key.PublicKey)})
+	assert.NoError(t, err)
+
+	_, vm, _, sender := GenesisVM(t, true, cfgJson, "", "")
+	defer func() {
+		assert.NoError(t, vm.Shutdown())
+	}()
+	vm.chain.GetTxPool().SetGasPrice(common.Big1)
+	vm.chain.GetTxPool().SetMinFee(common.Big0)
+	admin := NewAdminService(vm, "")
+
+	// Block the gossip loop while it sends transactions
+	gossiping := make(chan struct{}, 1)
+	unblock := make(chan struct{})
+	sender.SendAppGossipF = func([]byte) error {
+		select {
+		case gossiping <- struct{}{}:
+		default:
+		}
+		<-unblock
+		return nil
+	}
+	txs := getValidTxs(key, 1, big.NewInt(226*params.GWei))
+	for _, err := range vm.chain.GetTxPool().AddRemotesSync(txs) {
+		assert.NoError(t, err)
+	}
+	go func() {
+		assert.NoError(t, vm.gossiper.GossipTxs(txs))
+	}()
+	<-gossiping
+
+	// The updated config can be read while the update waits for the gossip loop
+	updated := make(chan error, 1)
+	go func() {
+		updated <- admin.UpdateVMConfig(nil, &UpdateVMConfigArgs{
+			Config: []byte(`{"regossip-frequency": "2s"}`),
+		}, &ConfigReply{})
+	}()
+	assert.Eventually(t, func() bool {
+		return vm.currentConfig().RegossipFrequency.Duration == 2*time.Second
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Empty(t, updated)
+
+	close(unblock)
+	assert.NoError(t, <-updated)
+}
+
+func TestVMGetPeerStats(t *testing.T) {
+	_, vm, _, _ := GenesisVM(t, true, genesisJSONSubnetEVM, "", "")
+	defer func() {
+		assert.NoError(t, vm.Shutdown())
+	}()
+	admin := NewAdminService(vm, "")
+
+	nodeID := ids.GenerateTestNodeID()
+	assert.NoError(t, vm.Network.Connected(nodeID, version.CurrentApp))
+
+	reply := &PeerStatsReply{}
+	assert.NoError(t, admin.GetPeerStats(nil, nil, reply))
+	assert.Len(t, reply.Peers, 1)
+	assert.Equal(t, nodeID, reply.Peers[0].NodeID)
+	assert.Equal(t, version.CurrentApp.String(), reply.Peers[0].Version)
+	assert.Zero(t, reply.Peers[0].Requests)
+	assert.False(t, reply.Peers[0].Benched)
+}
+
 func TestVMConfigDefaults(t *testing.T) {
 	txFeeCap := float64(11)
 	enabledEthAPIs := []string{"internal-private-debug"}
@@ -2108,7 +2235,7 @@ func TestBuildAllowListActivationBlock(t *testing.T) {
 	if err := genesis.UnmarshalJSON([]byte(genesisJSONSubnetEVM)); err != nil {
 		t.Fatal(err)
 	}
-	genesis.Config.ContractDeployerAllowListConfig = precompile.NewContractDeployerAllowListConfig(big.NewInt(time.Now().Unix()), testEthAddrs)
+	genesis.Config.GenesisPrecompiles = params.Precompiles{precompile.ContractDeployerAllowListConfigKey: precompile.NewContractDeployerAllowListConfig(big.NewInt(time.Now().Unix()), testEthAddrs)}
 
 	genesisJSON, err := genesis.MarshalJSON()
 	if err != nil {
@@ -2129,7 +2256,7 @@ func TestBuildAllowListActivationBlock(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	role := precompile.GetContractDeployerAllowListStatus(genesisState, testEthAddrs[0])
+	role := precompile.GetContractDeployerAllowListStatus(genesisState, testEthAddrs[0], common.Big0)
 	if role != precompile.AllowListNoRole {
 		t.Fatalf("Expected allow list status to be set to no role: %s, but found: %s", precompile.AllowListNoRole, role)
 	}
@@ -2159,7 +2286,7 @@ func TestBuildAllowListActivationBlock(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	role = precompile.GetContractDeployerAllowListStatus(blkState, testEthAddrs[0])
+	role = precompile.GetContractDeployerAllowListStatus(blkState, testEthAddrs[0], common.Big0)
 	if role != precompile.AllowListAdmin {
 		t.Fatalf("Expected allow list status to be set to Admin: %s, but found: %s", precompile.AllowListAdmin, role)
 	}
@@ -2172,7 +2299,7 @@ func TestTxAllowListSuccessfulTx(t *testing.T) {
 	if err := genesis.UnmarshalJSON([]byte(genesisJSONSubnetEVM)); err != nil {
 		t.Fatal(err)
 	}
-	genesis.Config.TxAllowListConfig = precompile.NewTxAllowListConfig(big.NewInt(0), testEthAddrs[0:1])
+	genesis.Config.GenesisPrecompiles = params.Precompiles{precompile.TxAllowListConfigKey: precompile.NewTxAllowListConfig(big.NewInt(0), testEthAddrs[0:1])}
 	genesisJSON, err := genesis.MarshalJSON()
 	if err != nil {
 		t.Fatal(err)
@@ -2194,11 +2321,11 @@ func TestTxAllowListSuccessfulTx(t *testing.T) {
 	}
 
 	// Check that address 0 is whitelisted and address 1 is not
-	role := precompile.GetTxAllowListStatus(genesisState, testEthAddrs[0])
+	role := precompile.GetTxAllowListStatus(genesisState, testEthAddrs[0], common.Big0)
 	if role != precompile.AllowListAdmin {
 		t.Fatalf("Expected allow list status to be set to admin: %s, but found: %s", precompile.AllowListAdmin, role)
 	}
-	role = precompile.GetTxAllowListStatus(genesisState, testEthAddrs[1])
+	role = precompile.GetTxAllowListStatus(genesisState, testEthAddrs[1], common.Big0)
 	if role != precompile.AllowListNoRole {
 		t.Fatalf("Expected allow list status to be set to no role: %s, but found: %s", precompile.AllowListNoRole, role)
 	}
@@ -2248,7 +2375,7 @@ func TestTxAllowListDisablePrecompile(t *testing.T) {
 		t.Fatal(err)
 	}
 	enableAllowListTimestamp := time.Unix(0, 0) // enable at genesis
-	genesis.Config.TxAllowListConfig = precompile.NewTxAllowListConfig(big.NewInt(enableAllowListTimestamp.Unix()), testEthAddrs[0:1])
+	genesis.Config.GenesisPrecompiles = params.Precompiles{precompile.TxAllowListConfigKey: precompile.NewTxAllowListConfig(big.NewInt(enableAllowListTimestamp.Unix()), testEthAddrs[0:1])}
 	genesisJSON, err := genesis.MarshalJSON()
 	if err != nil {
 		t.Fatal(err)
@@ -2263,7 +2390,7 @@ func TestTxAllowListDisablePrecompile(t *testing.T) {
 	precompileConfigs.PrecompileUpgrades = append(
 		precompileConfigs.PrecompileUpgrades,
 		params.PrecompileUpgrade{
-			TxAllowListConfig: precompile.NewDisableTxAllowListConfig(big.NewInt(disableAllowListTimestamp.Unix())),
+			Config: precompile.NewDisableTxAllowListConfig(big.NewInt(disableAllowListTimestamp.Unix())),
 		},
 	)
 
@@ -2284,11 +2411,11 @@ func TestTxAllowListDisablePrecompile(t *testing.T) {
 	}
 
 	// Check that address 0 is whitelisted and address 1 is not
-	role := precompile.GetTxAllowListStatus(genesisState, testEthAddrs[0])
+	role := precompile.GetTxAllowListStatus(genesisState, testEthAddrs[0], common.Big0)
 	if role != precompile.AllowListAdmin {
 		t.Fatalf("Expected allow list status to be set to admin: %s, but found: %s", precompile.AllowListAdmin, role)
 	}
-	role = precompile.GetTxAllowListStatus(genesisState, testEthAddrs[1])
+	role = precompile.GetTxAllowListStatus(genesisState, testEthAddrs[1], common.Big0)
 	if role != precompile.AllowListNoRole {
 		t.Fatalf("Expected allow list status to be set to no role: %s, but found: %s", precompile.AllowListNoRole, role)
 	}
@@ -2348,6 +2475,100 @@ func TestTxAllowListDisablePrecompile(t *testing.T) {
 	assert.Equal(t, signedTx1.Hash(), txs[0].Hash())
 }
 
+// Test that blocks pay fees to the reward address while the reward manager is enabled and to the
+// blackhole address once an upgrade disables it, and that the VM verifies the blocks it builds.
+func TestRewardManagerDisablePrecompile(t *testing.T) {
+	// Setup chain params
+	genesis := &core.Genesis{}
+	if err := genesis.UnmarshalJSON([]byte(genesisJSONSubnetEVM)); err != nil {
+		t.Fatal(err)
+	}
+	rewardAddress := common.Address{0x11}
+	genesis.Config.GenesisPrecompiles = params.Precompiles{
+		precompile.RewardManagerConfigKey: precompile.NewRewardManagerConfig(common.Big0, testEthAddrs[0:1], &precompile.InitialRewardConfig{RewardAddress: rewardAddress}),
+	}
+	genesisJSON, err := genesis.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer, vm, _, _ := GenesisVM(t, true, string(genesisJSON), "", "")
+
+	defer func() {
+		if err := vm.Shutdown(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// configure a network upgrade to disable the reward manager
+	disableRewardManagerTimestamp := vm.clock.Time().Add(10 * time.Hour)
+	precompileConfigs := &vm.chain.BlockChain().Config().UpgradeConfig
+	precompileConfigs.PrecompileUpgrades = append(
+		precompileConfigs.PrecompileUpgrades,
+		params.PrecompileUpgrade{
+			Config: precompile.NewDisableRewardManagerConfig(big.NewInt(disableRewardManagerTimestamp.Unix())),
+		},
+	)
+
+	newTxPoolHeadChan := make(chan core.NewTxPoolReorgEvent, 1)
+	vm.chain.GetTxPool().SubscribeNewReorgEvent(newTxPoolHeadChan)
+
+	genesisState, err := vm.chain.BlockChain().StateAt(vm.chain.GetGenesisBlock().Root())
+	if err != nil {
+		t.Fatal(err)
+	}
+	storedRewardAddress, allowFeeRecipients := precompile.GetStoredRewardAddress(genesisState)
+	assert.Equal(t, rewardAddress, storedRewardAddress)
+	assert.False(t, allowFeeRecipients)
+
+	tx0 := types.NewTransaction(uint64(0), testEthAddrs[0], big.NewInt(1), 21000, big.NewInt(testMinGasPrice), nil)
+	signedTx0, err := types.SignTx(tx0, types.NewEIP155Signer(vm.chainConfig.ChainID), testKeys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs := vm.chain.GetTxPool().AddRemotesSync([]*types.Transaction{signedTx0})
+	if err := errs[0]; err != nil {
+		t.Fatalf("Failed to add tx at index: %s", err)
+	}
+
+	// the block before the upgrade pays its fees to the stored reward address
+	blk := issueAndAccept(t, issuer, vm)
+	block := blk.(*chain.BlockWrapper).Block.(*Block).ethBlock
+	assert.Equal(t, rewardAddress, block.Coinbase())
+
+	<-newTxPoolHeadChan // wait for new head in tx pool
+
+	tx1 := types.NewTransaction(uint64(1), testEthAddrs[0], big.NewInt(1), 21000, big.NewInt(testMinGasPrice), nil)
+	signedTx1, err := types.SignTx(tx1, types.NewEIP155Signer(vm.chainConfig.ChainID), testKeys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs = vm.chain.GetTxPool().AddRemotesSync([]*types.Transaction{signedTx1})
+	if err := errs[0]; err != nil {
+		t.Fatalf("Failed to add tx at index: %s", err)
+	}
+
+	// the first block after the upgrade is built on a parent that still has the reward manager
+	// enabled, but must follow AllowFeeRecipients in the chain config.
+	vm.clock.Set(disableRewardManagerTimestamp)
+	blk = issueAndAccept(t, issuer, vm)
+	block = blk.(*chain.BlockWrapper).Block.(*Block).ethBlock
+	assert.True(t, block.Timestamp().Cmp(big.NewInt(disableRewardManagerTimestamp.Unix())) >= 0)
+	assert.Equal(t, subnetEVMConstants.BlackholeAddr, block.Coinbase())
+
+	// a block paying the reward address after the upgrade is rejected
+	header := types.CopyHeader(block.Header())
+	header.Coinbase = rewardAddress
+	modifiedEthBlock := types.NewBlock(header, block.Transactions(), nil, nil, new(trie.Trie))
+	modifiedBlock := &Block{
+		vm:       vm,
+		ethBlock: modifiedEthBlock,
+		id:       ids.ID(modifiedEthBlock.Hash()),
+	}
+	if err := modifiedBlock.syntacticVerify(); !errors.Is(err, errInvalidBlock) {
+		t.Fatalf("expected %s verifying a block paying the reward address, got: %v", errInvalidBlock, err)
+	}
+}
+
 // Test that the fee manager changes fee configuration
 func TestFeeManagerChangeFee(t *testing.T) {
 	// Setup chain params
@@ -2355,7 +2576,7 @@ func TestFeeManagerChangeFee(t *testing.T) {
 	if err := genesis.UnmarshalJSON([]byte(genesisJSONSubnetEVM)); err != nil {
 		t.Fatal(err)
 	}
-	genesis.Config.FeeManagerConfig = precompile.NewFeeManagerConfig(big.NewInt(0), testEthAddrs[0:1])
+	genesis.Config.GenesisPrecompiles = params.Precompiles{precompile.FeeConfigManagerConfigKey: precompile.NewFeeManagerConfig(big.NewInt(0), testEthAddrs[0:1])}
 
 	// set a lower fee config now
 	testLowFeeConfig := commontype.FeeConfig{
@@ -2393,11 +2614,11 @@ func TestFeeManagerChangeFee(t *testing.T) {
 	}
 
 	// Check that address 0 is whitelisted and address 1 is not
-	role := precompile.GetFeeConfigManagerStatus(genesisState, testEthAddrs[0])
+	role := precompile.GetFeeConfigManagerStatus(genesisState, testEthAddrs[0], common.Big0)
 	if role != precompile.AllowListAdmin {
 		t.Fatalf("Expected fee manager list status to be set to admin: %s, but found: %s", precompile.FeeConfigManagerAddress, role)
 	}
-	role = precompile.GetFeeConfigManagerStatus(genesisState, testEthAddrs[1])
+	role = precompile.GetFeeConfigManagerStatus(genesisState, testEthAddrs[1], common.Big0)
 	if role != precompile.AllowListNoRole {
 		t.Fatalf("Expected fee manager list status to be set to no role: %s, but found: %s", precompile.FeeConfigManagerAddress, role)
 	}
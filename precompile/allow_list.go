@@ -10,6 +10,7 @@ import (
 
 	"github.com/ava-labs/subnet-evm/vmerrs"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 // Enum constants for valid AllowListRole
@@ -19,41 +20,97 @@ var (
 	AllowListNoRole  AllowListRole = AllowListRole(common.BigToHash(big.NewInt(0))) // No role assigned - this is equivalent to common.Hash{} and deletes the key from the DB when set
 	AllowListEnabled AllowListRole = AllowListRole(common.BigToHash(big.NewInt(1))) // Deployers are allowed to create new contracts
 	AllowListAdmin   AllowListRole = AllowListRole(common.BigToHash(big.NewInt(2))) // Admin - allowed to modify both the admin and deployer list as well as deploy contracts
+	AllowListManager AllowListRole = AllowListRole(common.BigToHash(big.NewInt(3))) // Manager - allowed to add and remove enabled addresses, but not admins or managers. Only valid after the AllowListRoles upgrade.
 
 	// AllowList function signatures
 	setAdminSignature      = CalculateFunctionSelector("setAdmin(address)")
 	setEnabledSignature    = CalculateFunctionSelector("setEnabled(address)")
 	setNoneSignature       = CalculateFunctionSelector("setNone(address)")
 	readAllowListSignature = CalculateFunctionSelector("readAllowList(address)")
+	// AllowList function signatures added by the AllowListRoles upgrade
+	setManagerSignature      = CalculateFunctionSelector("setManager(address)")
+	setEnabledUntilSignature = CalculateFunctionSelector("setEnabledUntil(address,uint64)")
 	// Error returned when an invalid write is attempted
 	ErrCannotModifyAllowList = errors.New("non-admin cannot modify allow list")
-
-	allowListInputLen = common.HashLength
+	// Error returned when a time-bound grant would already be expired
+	ErrInvalidAllowListExpiry = errors.New("allow list expiry must be after the current block timestamp")
+	// Error returned when an allow list config assigns more than one role to an address
+	ErrDuplicateAllowListAddress = errors.New("address is repeated or assigned more than one role in allow list config")
+
+	allowListInputLen             = common.HashLength
+	allowListEnabledUntilInputLen = 2 * common.HashLength
+
+	// allowListExpiryKeyPrefix is hashed together with an address to derive the storage
+	// key of the expiry of a time-bound grant of the address, so that it cannot collide
+	// with the role key of any address.
+	allowListExpiryKeyPrefix = []byte("allowListExpiry")
 )
 
-// AllowListConfig specifies the initial set of allow list admins.
+// AllowListConfig specifies the initial set of allow list admins, managers and enabled addresses.
+// Managers can only be assigned once the AllowListRoles upgrade is activated.
 type AllowListConfig struct {
-	AllowListAdmins []common.Address `json:"adminAddresses"`
+	AllowListAdmins  []common.Address `json:"adminAddresses"`
+	ManagerAddresses []common.Address `json:"managerAddresses,omitempty"`
+	EnabledAddresses []common.Address `json:"enabledAddresses,omitempty"`
+}
+
+// GetAllowListConfig returns [c]. This allows the allow list of any config embedding
+// [AllowListConfig] to be inspected through the StatefulPrecompileConfig interface.
+func (c *AllowListConfig) GetAllowListConfig() *AllowListConfig {
+	return c
 }
 
 // Configure initializes the address space of [precompileAddr] by initializing the role of each of
-// the addresses in [AllowListAdmins].
+// the addresses in [AllowListAdmins], [ManagerAddresses] and [EnabledAddresses].
 func (c *AllowListConfig) Configure(state StateDB, precompileAddr common.Address) {
 	for _, adminAddr := range c.AllowListAdmins {
 		setAllowListRole(state, precompileAddr, adminAddr, AllowListAdmin)
 	}
+	for _, managerAddr := range c.ManagerAddresses {
+		setAllowListRole(state, precompileAddr, managerAddr, AllowListManager)
+	}
+	for _, enabledAddr := range c.EnabledAddresses {
+		setAllowListRole(state, precompileAddr, enabledAddr, AllowListEnabled)
+	}
 }
 
-// Equal returns true iff [other] has the same admins in the same order in its allow list.
+// Equal returns true iff [other] has the same admins, managers and enabled addresses in the same
+// order in its allow list.
 func (c *AllowListConfig) Equal(other *AllowListConfig) bool {
 	if other == nil {
 		return false
 	}
-	if len(c.AllowListAdmins) != len(other.AllowListAdmins) {
+	return addressesEqual(c.AllowListAdmins, other.AllowListAdmins) &&
+		addressesEqual(c.ManagerAddresses, other.ManagerAddresses) &&
+		addressesEqual(c.EnabledAddresses, other.EnabledAddresses)
+}
+
+// Verify returns an error if [c] assigns more than one role to the same address, or repeats an
+// address in [ManagerAddresses] or [EnabledAddresses]. Repeated admins are allowed, since existing
+// configs may list the same admin more than once.
+func (c *AllowListConfig) Verify() error {
+	seen := make(map[common.Address]struct{}, len(c.AllowListAdmins)+len(c.ManagerAddresses)+len(c.EnabledAddresses))
+	for _, adminAddr := range c.AllowListAdmins {
+		seen[adminAddr] = struct{}{}
+	}
+	for _, addresses := range [][]common.Address{c.ManagerAddresses, c.EnabledAddresses} {
+		for _, address := range addresses {
+			if _, ok := seen[address]; ok {
+				return fmt.Errorf("%w: %s", ErrDuplicateAllowListAddress, address)
+			}
+			seen[address] = struct{}{}
+		}
+	}
+	return nil
+}
+
+// addressesEqual returns true iff [a] and [b] contain the same addresses in the same order.
+func addressesEqual(a, b []common.Address) bool {
+	if len(a) != len(b) {
 		return false
 	}
-	for i, admin := range c.AllowListAdmins {
-		if admin != other.AllowListAdmins[i] {
+	for i, address := range a {
+		if address != b[i] {
 			return false
 		}
 	}
@@ -63,7 +120,7 @@ func (c *AllowListConfig) Equal(other *AllowListConfig) bool {
 // Valid returns true iff [s] represents a valid role.
 func (s AllowListRole) Valid() bool {
 	switch s {
-	case AllowListNoRole, AllowListEnabled, AllowListAdmin:
+	case AllowListNoRole, AllowListEnabled, AllowListAdmin, AllowListManager:
 		return true
 	default:
 		return false
@@ -90,32 +147,84 @@ func (s AllowListRole) IsAdmin() bool {
 	}
 }
 
+// IsManager returns true if [s] indicates the permission to add and remove enabled addresses.
+func (s AllowListRole) IsManager() bool {
+	switch s {
+	case AllowListManager:
+		return true
+	default:
+		return false
+	}
+}
+
 // IsEnabled returns true if [s] indicates that it has permission to access the resource.
 func (s AllowListRole) IsEnabled() bool {
 	switch s {
-	case AllowListAdmin, AllowListEnabled:
+	case AllowListAdmin, AllowListManager, AllowListEnabled:
+		return true
+	default:
+		return false
+	}
+}
+
+// CanModify returns true if a caller with role [s] is allowed to change the role of an
+// address from [from] to [to].
+// Admins may make any change, while managers may only switch addresses between
+// [AllowListNoRole] and [AllowListEnabled].
+func (s AllowListRole) CanModify(from, to AllowListRole) bool {
+	switch s {
+	case AllowListAdmin:
 		return true
+	case AllowListManager:
+		return (from == AllowListNoRole || from == AllowListEnabled) && (to == AllowListNoRole || to == AllowListEnabled)
 	default:
 		return false
 	}
 }
 
+// allowListExpiryKey returns the state key of the expiry of the role of [address].
+func allowListExpiryKey(address common.Address) common.Hash {
+	return crypto.Keccak256Hash(allowListExpiryKeyPrefix, address.Bytes())
+}
+
 // getAllowListStatus returns the allow list role of [address] for the precompile
-// at [precompileAddr]
-func getAllowListStatus(state StateDB, precompileAddr common.Address, address common.Address) AllowListRole {
+// at [precompileAddr] in the block with [blockTimestamp].
+// A time-bound role is returned as [AllowListNoRole] once [blockTimestamp] reaches its expiry.
+func getAllowListStatus(state StateDB, precompileAddr common.Address, address common.Address, blockTimestamp *big.Int) AllowListRole {
 	// Generate the state key for [address]
 	addressKey := address.Hash()
-	return AllowListRole(state.GetState(precompileAddr, addressKey))
+	role := AllowListRole(state.GetState(precompileAddr, addressKey))
+	if role.IsNoRole() {
+		return role
+	}
+	// Note: expiries can only be set after the AllowListRoles upgrade, so this
+	// does not change the role of any address before it.
+	expiry := state.GetState(precompileAddr, allowListExpiryKey(address))
+	if expiry != (common.Hash{}) && blockTimestamp.Cmp(expiry.Big()) >= 0 {
+		return AllowListNoRole
+	}
+	return role
 }
 
 // setAllowListRole sets the permissions of [address] to [role] for the precompile
-// at [precompileAddr].
+// at [precompileAddr], removing the expiry of any previous time-bound role.
 // assumes [role] has already been verified as valid.
 func setAllowListRole(stateDB StateDB, precompileAddr, address common.Address, role AllowListRole) {
+	setAllowListRoleUntil(stateDB, precompileAddr, address, role, 0)
+}
+
+// setAllowListRoleUntil sets the permissions of [address] to [role] for the precompile
+// at [precompileAddr] until the block timestamp reaches [expiry]. An [expiry] of 0
+// grants [role] without a time bound.
+// assumes [role] has already been verified as valid.
+func setAllowListRoleUntil(stateDB StateDB, precompileAddr, address common.Address, role AllowListRole, expiry uint64) {
 	// Generate the state key for [address]
 	addressKey := address.Hash()
 	// Assign [role] to the address
 	stateDB.SetState(precompileAddr, addressKey, common.Hash(role))
+	// Setting a role to its current value is a no-op, so this does not modify the state
+	// unless an expiry is set or removed.
+	stateDB.SetState(precompileAddr, allowListExpiryKey(address), common.BigToHash(new(big.Int).SetUint64(expiry)))
 }
 
 // PackModifyAllowList packs [address] and [role] into the appropriate arguments for modifying the allow list.
@@ -128,6 +237,8 @@ func PackModifyAllowList(address common.Address, role AllowListRole) ([]byte, er
 	switch role {
 	case AllowListAdmin:
 		input = append(input, setAdminSignature...)
+	case AllowListManager:
+		input = append(input, setManagerSignature...)
 	case AllowListEnabled:
 		input = append(input, setEnabledSignature...)
 	case AllowListNoRole:
@@ -140,6 +251,30 @@ func PackModifyAllowList(address common.Address, role AllowListRole) ([]byte, er
 	return input, nil
 }
 
+// PackSetEnabledUntil packs [address] and [expiry] into the input data to the setEnabledUntil function.
+func PackSetEnabledUntil(address common.Address, expiry uint64) []byte {
+	input := make([]byte, selectorLen+allowListEnabledUntilInputLen)
+	packOrderedHashesWithSelector(input, setEnabledUntilSignature, []common.Hash{
+		address.Hash(),
+		common.BigToHash(new(big.Int).SetUint64(expiry)),
+	})
+	return input
+}
+
+// UnpackSetEnabledUntilInput attempts to unpack [input] into the address and the expiry
+// arguments of the setEnabledUntil function.
+func UnpackSetEnabledUntilInput(input []byte) (common.Address, uint64, error) {
+	if len(input) != allowListEnabledUntilInputLen {
+		return common.Address{}, 0, fmt.Errorf("invalid input length for setEnabledUntil: %d", len(input))
+	}
+	address := common.BytesToAddress(returnPackedHash(input, 0))
+	expiry := new(big.Int).SetBytes(returnPackedHash(input, 1))
+	if !expiry.IsUint64() {
+		return common.Address{}, 0, fmt.Errorf("invalid expiry for setEnabledUntil: %s", expiry)
+	}
+	return address, expiry.Uint64(), nil
+}
+
 // PackReadAllowList packs [address] into the input data to the read allow list function
 func PackReadAllowList(address common.Address) []byte {
 	input := make([]byte, 0, selectorLen+common.HashLength)
@@ -148,10 +283,59 @@ func PackReadAllowList(address common.Address) []byte {
 	return input
 }
 
+// allowListRolesEnabled returns true if the AllowListRoles upgrade is activated in the block
+// described by [accessibleState].
+func allowListRolesEnabled(accessibleState PrecompileAccessibleState) bool {
+	return accessibleState.GetChainConfig().IsAllowListRoles(accessibleState.GetBlockContext().Timestamp())
+}
+
+// modifyAllowList sets the role of [modifyAddress] to [role] until [expiry] on behalf of [callerAddr],
+// after verifying that the role of [callerAddr] permits the change.
+// Before the AllowListRoles upgrade, only admins may modify the allow list.
+func modifyAllowList(evm PrecompileAccessibleState, precompileAddr, callerAddr, modifyAddress common.Address, role AllowListRole, expiry uint64, suppliedGas uint64) (uint64, error) {
+	var (
+		stateDB        = evm.GetStateDB()
+		blockTimestamp = evm.GetBlockContext().Timestamp()
+		remainingGas   = suppliedGas
+		err            error
+	)
+	// Verify that the caller is in the allow list and therefore has the right to modify it
+	callerStatus := getAllowListStatus(stateDB, precompileAddr, callerAddr, blockTimestamp)
+	if !allowListRolesEnabled(evm) {
+		if !callerStatus.IsAdmin() {
+			return remainingGas, fmt.Errorf("%w: %s", ErrCannotModifyAllowList, callerAddr)
+		}
+	} else if !callerStatus.IsAdmin() {
+		// Managers additionally pay to read the current role of [modifyAddress].
+		if remainingGas, err = deductGas(remainingGas, ReadAllowListGasCost); err != nil {
+			return 0, err
+		}
+		currentStatus := getAllowListStatus(stateDB, precompileAddr, modifyAddress, blockTimestamp)
+		if !callerStatus.CanModify(currentStatus, role) {
+			return remainingGas, fmt.Errorf("%w: %s", ErrCannotModifyAllowList, callerAddr)
+		}
+	}
+
+	setAllowListRoleUntil(stateDB, precompileAddr, modifyAddress, role, expiry)
+	if logsEnabled(evm) {
+		topics, data := PackRoleSetEvent(role, modifyAddress, callerAddr)
+		if remainingGas, err = addLog(evm, precompileAddr, topics, data, remainingGas); err != nil {
+			return 0, err
+		}
+	}
+	return remainingGas, nil
+}
+
 // createAllowListRoleSetter returns an execution function for setting the allow list status of the input address argument to [role].
 // This execution function is speciifc to [precompileAddr].
 func createAllowListRoleSetter(precompileAddr common.Address, role AllowListRole) RunStatefulPrecompileFunc {
 	return func(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+		// The manager role cannot be granted before the AllowListRoles upgrade, in which case
+		// setManager behaves as if it did not exist.
+		if role == AllowListManager && !allowListRolesEnabled(evm) {
+			return nil, suppliedGas, fmt.Errorf("invalid function selector %#x", setManagerSignature)
+		}
+
 		if remainingGas, err = deductGas(suppliedGas, ModifyAllowListGasCost); err != nil {
 			return nil, 0, err
 		}
@@ -166,15 +350,43 @@ func createAllowListRoleSetter(precompileAddr common.Address, role AllowListRole
 			return nil, remainingGas, vmerrs.ErrWriteProtection
 		}
 
-		stateDB := evm.GetStateDB()
+		if remainingGas, err = modifyAllowList(evm, precompileAddr, callerAddr, modifyAddress, role, 0, remainingGas); err != nil {
+			return nil, remainingGas, err
+		}
+		// Return an empty output and the remaining gas
+		return []byte{}, remainingGas, nil
+	}
+}
 
-		// Verify that the caller is in the allow list and therefore has the right to modify it
-		callerStatus := getAllowListStatus(stateDB, precompileAddr, callerAddr)
-		if !callerStatus.IsAdmin() {
-			return nil, remainingGas, fmt.Errorf("%w: %s", ErrCannotModifyAllowList, callerAddr)
+// createAllowListEnabledUntilSetter returns an execution function for enabling the input address argument
+// until the input expiry argument. This execution function is specific to [precompileAddr].
+func createAllowListEnabledUntilSetter(precompileAddr common.Address) RunStatefulPrecompileFunc {
+	return func(evm PrecompileAccessibleState, callerAddr, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+		// setEnabledUntil behaves as if it did not exist before the AllowListRoles upgrade.
+		if !allowListRolesEnabled(evm) {
+			return nil, suppliedGas, fmt.Errorf("invalid function selector %#x", setEnabledUntilSignature)
+		}
+
+		if remainingGas, err = deductGas(suppliedGas, ModifyAllowListWithExpiryGasCost); err != nil {
+			return nil, 0, err
 		}
 
-		setAllowListRole(stateDB, precompileAddr, modifyAddress, role)
+		modifyAddress, expiry, err := UnpackSetEnabledUntilInput(input)
+		if err != nil {
+			return nil, remainingGas, err
+		}
+
+		if readOnly {
+			return nil, remainingGas, vmerrs.ErrWriteProtection
+		}
+
+		if blockTimestamp := evm.GetBlockContext().Timestamp(); new(big.Int).SetUint64(expiry).Cmp(blockTimestamp) <= 0 {
+			return nil, remainingGas, fmt.Errorf("%w: expiry %d, block timestamp %s", ErrInvalidAllowListExpiry, expiry, blockTimestamp)
+		}
+
+		if remainingGas, err = modifyAllowList(evm, precompileAddr, callerAddr, modifyAddress, AllowListEnabled, expiry, remainingGas); err != nil {
+			return nil, remainingGas, err
+		}
 		// Return an empty output and the remaining gas
 		return []byte{}, remainingGas, nil
 	}
@@ -194,7 +406,7 @@ func createReadAllowList(precompileAddr common.Address) RunStatefulPrecompileFun
 		}
 
 		readAddress := common.BytesToAddress(input)
-		role := getAllowListStatus(evm.GetStateDB(), precompileAddr, readAddress)
+		role := getAllowListStatus(evm.GetStateDB(), precompileAddr, readAddress, evm.GetBlockContext().Timestamp())
 		roleBytes := common.Hash(role).Bytes()
 		return roleBytes, remainingGas, nil
 	}
@@ -213,6 +425,8 @@ func createAllowListFunctions(precompileAddr common.Address) []*statefulPrecompi
 	setEnabled := newStatefulPrecompileFunction(setEnabledSignature, createAllowListRoleSetter(precompileAddr, AllowListEnabled))
 	setNone := newStatefulPrecompileFunction(setNoneSignature, createAllowListRoleSetter(precompileAddr, AllowListNoRole))
 	read := newStatefulPrecompileFunction(readAllowListSignature, createReadAllowList(precompileAddr))
+	setManager := newStatefulPrecompileFunction(setManagerSignature, createAllowListRoleSetter(precompileAddr, AllowListManager))
+	setEnabledUntil := newStatefulPrecompileFunction(setEnabledUntilSignature, createAllowListEnabledUntilSetter(precompileAddr))
 
-	return []*statefulPrecompileFunction{setAdmin, setEnabled, setNone, read}
+	return []*statefulPrecompileFunction{setAdmin, setEnabled, setNone, read, setManager, setEnabledUntil}
 }
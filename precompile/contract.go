@@ -21,14 +21,23 @@ type RunStatefulPrecompileFunc func(accessibleState PrecompileAccessibleState, c
 type PrecompileAccessibleState interface {
 	GetStateDB() StateDB
 	GetBlockContext() BlockContext
+	GetChainConfig() ChainConfig
+	// GetTxOrigin returns the originator of the transaction calling the precompile (tx.origin).
+	GetTxOrigin() common.Address
+	// GetChainID returns the chain ID of the chain the precompile is executed on.
+	GetChainID() *big.Int
 }
 
 // BlockContext defines an interface that provides information to a stateful precompile
-// about the block that activates the upgrade. The precompile can access this information
-// to initialize its state.
+// about the block that activates the upgrade or that the precompile is executed in.
+// The precompile can access this information to initialize its state.
 type BlockContext interface {
 	Number() *big.Int
 	Timestamp() *big.Int
+	// Coinbase returns the fee recipient of the block.
+	Coinbase() common.Address
+	// BaseFee returns the base fee of the block, or nil if the block does not have a base fee.
+	BaseFee() *big.Int
 }
 
 // ChainContext defines an interface that provides information to a stateful precompile
@@ -37,6 +46,17 @@ type BlockContext interface {
 type ChainConfig interface {
 	// GetFeeConfig returns the original FeeConfig that was set in the genesis.
 	GetFeeConfig() commontype.FeeConfig
+	// AllowedFeeRecipients returns true if block producers may choose their fee recipient
+	// according to the genesis chain config.
+	AllowedFeeRecipients() bool
+	// IsPrecompileLogs returns true if stateful precompiles emit logs at [blockTimestamp].
+	IsPrecompileLogs(blockTimestamp *big.Int) bool
+	// IsAllowListRoles returns true if allow lists support the manager role and
+	// time-bound grants at [blockTimestamp].
+	IsAllowListRoles(blockTimestamp *big.Int) bool
+	// IsNativeMinterSupply returns true if the native minter tracks and limits the minted
+	// supply and supports burning at [blockTimestamp].
+	IsNativeMinterSupply(blockTimestamp *big.Int) bool
 }
 
 // StateDB is the interface for accessing EVM state
@@ -57,6 +77,8 @@ type StateDB interface {
 	Exist(common.Address) bool
 
 	Suicide(common.Address) bool
+
+	AddLog(addr common.Address, topics []common.Hash, data []byte, blockNumber uint64)
 }
 
 // StatefulPrecompiledContract is the interface for executing a precompiled contract
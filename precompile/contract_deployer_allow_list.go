@@ -15,6 +15,19 @@ var (
 	ContractDeployerAllowListPrecompile StatefulPrecompiledContract = createAllowListPrecompile(ContractDeployerAllowListAddress)
 )
 
+// ContractDeployerAllowListConfigKey is the key of the contract deployer allow list precompile's config in the
+// chain config and in precompile upgrades.
+const ContractDeployerAllowListConfigKey = "contractDeployerAllowListConfig"
+
+func init() {
+	mustRegisterModule(StatefulPrecompileModule{
+		ConfigKey: ContractDeployerAllowListConfigKey,
+		Address:   ContractDeployerAllowListAddress,
+		NewConfig: func() StatefulPrecompileConfig { return &ContractDeployerAllowListConfig{} },
+		Order:     1,
+	})
+}
+
 // ContractDeployerAllowListConfig wraps [AllowListConfig] and uses it to implement the StatefulPrecompileConfig
 // interface while adding in the contract deployer specific precompile address.
 type ContractDeployerAllowListConfig struct {
@@ -68,9 +81,9 @@ func (c *ContractDeployerAllowListConfig) Equal(s StatefulPrecompileConfig) bool
 }
 
 // GetContractDeployerAllowListStatus returns the role of [address] for the contract deployer
-// allow list.
-func GetContractDeployerAllowListStatus(stateDB StateDB, address common.Address) AllowListRole {
-	return getAllowListStatus(stateDB, ContractDeployerAllowListAddress, address)
+// allow list in the block with [blockTimestamp].
+func GetContractDeployerAllowListStatus(stateDB StateDB, address common.Address, blockTimestamp *big.Int) AllowListRole {
+	return getAllowListStatus(stateDB, ContractDeployerAllowListAddress, address, blockTimestamp)
 }
 
 // SetContractDeployerAllowListStatus sets the permissions of [address] to [role] for the
@@ -8,15 +8,20 @@ import (
 	"fmt"
 	"math/big"
 
+	"github.com/ava-labs/subnet-evm/utils"
 	"github.com/ava-labs/subnet-evm/vmerrs"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 const (
 	mintInputAddressSlot = iota
 	mintInputAmountSlot
 
-	mintInputLen = common.HashLength + common.HashLength
+	mintInputLen           = common.HashLength + common.HashLength
+	burnInputLen           = common.HashLength
+	remainingQuotaInputLen = common.HashLength
 )
 
 var (
@@ -25,14 +30,60 @@ var (
 	ContractNativeMinterPrecompile StatefulPrecompiledContract = createNativeMinterPrecompile(ContractNativeMinterAddress)
 
 	mintSignature = CalculateFunctionSelector("mintNativeCoin(address,uint256)") // address, amount
-	ErrCannotMint = errors.New("non-enabled cannot mint")
+
+	// Native minter function signatures added by the NativeMinterSupply upgrade
+	burnSignature           = CalculateFunctionSelector("burnNativeCoin(uint256)") // amount
+	totalMintedSignature    = CalculateFunctionSelector("totalMinted()")
+	remainingQuotaSignature = CalculateFunctionSelector("remainingQuota(address)") // address
+
+	ErrCannotMint             = errors.New("non-enabled cannot mint")
+	ErrCannotBurn             = errors.New("non-enabled cannot burn")
+	ErrMaxTotalSupplyExceeded = errors.New("mint exceeds max total supply")
+	ErrMinterQuotaExceeded    = errors.New("mint exceeds minter quota")
+	ErrInvalidMaxTotalSupply  = errors.New("max total supply must be positive")
+	ErrInvalidMinterQuota     = errors.New("minter quota must be positive")
+	errInvalidMinterInputSize = errors.New("invalid input length for native minter function")
+
+	// Storage keys of the supply tracked by the native minter after the NativeMinterSupply upgrade.
+	// Since addresses are stored left padded, these keys cannot collide with allow list roles.
+	maxTotalSupplyStorageKey = common.Hash{'m', 't', 's', 'k'}
+	totalMintedStorageKey    = common.Hash{'t', 'm', 's', 'k'}
+	quotaWindowStorageKey    = common.Hash{'q', 'w', 's', 'k'}
+
+	minterQuotaKeyPrefix        = []byte("minterQuota")
+	minterWindowStartKeyPrefix  = []byte("minterWindowStart")
+	minterWindowMintedKeyPrefix = []byte("minterWindowMinted")
 )
 
+// ContractNativeMinterConfigKey is the key of the native minter precompile's config in the
+// chain config and in precompile upgrades.
+const ContractNativeMinterConfigKey = "contractNativeMinterConfig"
+
+func init() {
+	mustRegisterModule(StatefulPrecompileModule{
+		ConfigKey: ContractNativeMinterConfigKey,
+		Address:   ContractNativeMinterAddress,
+		NewConfig: func() StatefulPrecompileConfig { return &ContractNativeMinterConfig{} },
+		Order:     2,
+	})
+}
+
 // ContractNativeMinterConfig wraps [AllowListConfig] and uses it to implement the StatefulPrecompileConfig
 // interface while adding in the ContractNativeMinter specific precompile address.
+//
+// The supply limits are only enforced once the NativeMinterSupply upgrade is activated.
 type ContractNativeMinterConfig struct {
 	AllowListConfig
 	UpgradeableConfig
+	// MaxTotalSupply caps the total amount minted by the precompile less the amount burned
+	// through it. If nil, the supply is not capped.
+	MaxTotalSupply *big.Int `json:"maxTotalSupply,omitempty"`
+	// MinterQuotas caps the amount each listed minter may mint within a quota window.
+	// Minters without a quota are only limited by [MaxTotalSupply].
+	MinterQuotas map[common.Address]*big.Int `json:"minterQuotas,omitempty"`
+	// QuotaWindow is the length of a quota window in seconds. Windows are aligned to multiples
+	// of [QuotaWindow] since the unix epoch. If 0, quotas apply to everything a minter mints.
+	QuotaWindow uint64 `json:"quotaWindow,omitempty"`
 }
 
 // NewContractNativeMinterConfig returns a config for a network upgrade at [blockTimestamp] that enables
@@ -60,9 +111,34 @@ func (c *ContractNativeMinterConfig) Address() common.Address {
 	return ContractNativeMinterAddress
 }
 
-// Configure configures [state] with the desired admins based on [c].
+// Verify returns an error if [c] specifies a non-positive supply cap or minter quota.
+func (c *ContractNativeMinterConfig) Verify() error {
+	if err := c.AllowListConfig.Verify(); err != nil {
+		return err
+	}
+	if c.MaxTotalSupply != nil && (c.MaxTotalSupply.Sign() <= 0 || c.MaxTotalSupply.BitLen() > 256) {
+		return fmt.Errorf("%w: %v", ErrInvalidMaxTotalSupply, c.MaxTotalSupply)
+	}
+	for minter, quota := range c.MinterQuotas {
+		if quota == nil || quota.Sign() <= 0 || quota.BitLen() > 256 {
+			return fmt.Errorf("%w: %v for %s", ErrInvalidMinterQuota, quota, minter)
+		}
+	}
+	return nil
+}
+
+// Configure configures [state] with the desired admins and supply limits based on [c].
 func (c *ContractNativeMinterConfig) Configure(_ ChainConfig, state StateDB, _ BlockContext) {
 	c.AllowListConfig.Configure(state, ContractNativeMinterAddress)
+	if c.MaxTotalSupply != nil {
+		state.SetState(ContractNativeMinterAddress, maxTotalSupplyStorageKey, common.BigToHash(c.MaxTotalSupply))
+	}
+	if c.QuotaWindow != 0 {
+		state.SetState(ContractNativeMinterAddress, quotaWindowStorageKey, common.BigToHash(new(big.Int).SetUint64(c.QuotaWindow)))
+	}
+	for minter, quota := range c.MinterQuotas {
+		state.SetState(ContractNativeMinterAddress, minterStorageKey(minterQuotaKeyPrefix, minter), common.BigToHash(quota))
+	}
 }
 
 // Contract returns the singleton stateful precompiled contract to be used for the native minter.
@@ -77,12 +153,27 @@ func (c *ContractNativeMinterConfig) Equal(s StatefulPrecompileConfig) bool {
 	if !ok {
 		return false
 	}
-	return c.UpgradeableConfig.Equal(&other.UpgradeableConfig) && c.AllowListConfig.Equal(&other.AllowListConfig)
+	if !c.UpgradeableConfig.Equal(&other.UpgradeableConfig) || !c.AllowListConfig.Equal(&other.AllowListConfig) {
+		return false
+	}
+	if !utils.BigNumEqual(c.MaxTotalSupply, other.MaxTotalSupply) || c.QuotaWindow != other.QuotaWindow {
+		return false
+	}
+	if len(c.MinterQuotas) != len(other.MinterQuotas) {
+		return false
+	}
+	for minter, quota := range c.MinterQuotas {
+		otherQuota, ok := other.MinterQuotas[minter]
+		if !ok || !utils.BigNumEqual(quota, otherQuota) {
+			return false
+		}
+	}
+	return true
 }
 
-// GetContractNativeMinterStatus returns the role of [address] for the minter list.
-func GetContractNativeMinterStatus(stateDB StateDB, address common.Address) AllowListRole {
-	return getAllowListStatus(stateDB, ContractNativeMinterAddress, address)
+// GetContractNativeMinterStatus returns the role of [address] for the minter list in the block with [blockTimestamp].
+func GetContractNativeMinterStatus(stateDB StateDB, address common.Address, blockTimestamp *big.Int) AllowListRole {
+	return getAllowListStatus(stateDB, ContractNativeMinterAddress, address, blockTimestamp)
 }
 
 // SetContractNativeMinterStatus sets the permissions of [address] to [role] for the
@@ -115,10 +206,131 @@ func UnpackMintInput(input []byte) (common.Address, *big.Int, error) {
 	return to, assetAmount, nil
 }
 
+// PackBurnInput packs [amount] into the appropriate arguments for the burn operation.
+// Assumes that [amount] can be represented by 32 bytes.
+func PackBurnInput(amount *big.Int) []byte {
+	res := make([]byte, selectorLen+burnInputLen)
+	packOrderedHashesWithSelector(res, burnSignature, []common.Hash{common.BigToHash(amount)})
+	return res
+}
+
+// PackTotalMinted packs the totalMinted signature
+func PackTotalMinted() []byte {
+	return totalMintedSignature
+}
+
+// PackRemainingQuota packs [address] with the selector into the input for remainingQuota
+func PackRemainingQuota(address common.Address) []byte {
+	input := make([]byte, 0, selectorLen+remainingQuotaInputLen)
+	input = append(input, remainingQuotaSignature...)
+	input = append(input, address.Hash().Bytes()...)
+	return input
+}
+
+// minterStorageKey returns the storage key of the value with [prefix] kept for [minter].
+func minterStorageKey(prefix []byte, minter common.Address) common.Hash {
+	return crypto.Keccak256Hash(prefix, minter.Bytes())
+}
+
+// nativeMinterSupplyEnabled returns true if the NativeMinterSupply upgrade is activated in the block
+// described by [accessibleState].
+func nativeMinterSupplyEnabled(accessibleState PrecompileAccessibleState) bool {
+	return accessibleState.GetChainConfig().IsNativeMinterSupply(accessibleState.GetBlockContext().Timestamp())
+}
+
+// GetMaxTotalSupply returns the cap on the total supply minted by the native minter stored in
+// [stateDB], or 2^256-1 if the supply is not capped.
+func GetMaxTotalSupply(stateDB StateDB) *big.Int {
+	maxTotalSupply := stateDB.GetState(ContractNativeMinterAddress, maxTotalSupplyStorageKey).Big()
+	if maxTotalSupply.Sign() == 0 {
+		return new(big.Int).Set(math.MaxBig256)
+	}
+	return maxTotalSupply
+}
+
+// GetTotalMinted returns the total amount minted by the native minter less the amount burned
+// through it since the NativeMinterSupply upgrade.
+func GetTotalMinted(stateDB StateDB) *big.Int {
+	return stateDB.GetState(ContractNativeMinterAddress, totalMintedStorageKey).Big()
+}
+
+// setTotalMinted stores [totalMinted] as the total supply minted by the native minter.
+func setTotalMinted(stateDB StateDB, totalMinted *big.Int) {
+	stateDB.SetState(ContractNativeMinterAddress, totalMintedStorageKey, common.BigToHash(totalMinted))
+}
+
+// GetRemainingQuota returns the amount [minter] may still mint in the block with [blockTimestamp]
+// under both its quota and the supply cap. Returns 0 if [minter] is not enabled to mint.
+func GetRemainingQuota(stateDB StateDB, minter common.Address, blockTimestamp *big.Int) *big.Int {
+	if !getAllowListStatus(stateDB, ContractNativeMinterAddress, minter, blockTimestamp).IsEnabled() {
+		return new(big.Int)
+	}
+	remaining := new(big.Int).Sub(GetMaxTotalSupply(stateDB), GetTotalMinted(stateDB))
+	if quota := getMinterQuota(stateDB, minter); quota.Sign() != 0 {
+		remainingQuota := new(big.Int).Sub(quota, getMintedInWindow(stateDB, minter, quotaWindowStart(stateDB, blockTimestamp)))
+		if remainingQuota.Cmp(remaining) < 0 {
+			remaining = remainingQuota
+		}
+	}
+	if remaining.Sign() < 0 {
+		return new(big.Int)
+	}
+	return remaining
+}
+
+// getMinterQuota returns the amount [minter] may mint per quota window, or 0 if [minter] has no quota.
+func getMinterQuota(stateDB StateDB, minter common.Address) *big.Int {
+	return stateDB.GetState(ContractNativeMinterAddress, minterStorageKey(minterQuotaKeyPrefix, minter)).Big()
+}
+
+// quotaWindowStart returns the start of the quota window that contains [blockTimestamp].
+func quotaWindowStart(stateDB StateDB, blockTimestamp *big.Int) *big.Int {
+	window := stateDB.GetState(ContractNativeMinterAddress, quotaWindowStorageKey).Big()
+	if window.Sign() == 0 {
+		return new(big.Int)
+	}
+	return new(big.Int).Sub(blockTimestamp, new(big.Int).Mod(blockTimestamp, window))
+}
+
+// getMintedInWindow returns the amount [minter] has minted in the quota window starting at [windowStart].
+func getMintedInWindow(stateDB StateDB, minter common.Address, windowStart *big.Int) *big.Int {
+	if stateDB.GetState(ContractNativeMinterAddress, minterStorageKey(minterWindowStartKeyPrefix, minter)).Big().Cmp(windowStart) != 0 {
+		return new(big.Int)
+	}
+	return stateDB.GetState(ContractNativeMinterAddress, minterStorageKey(minterWindowMintedKeyPrefix, minter)).Big()
+}
+
+// recordMint adds [amount] minted by [minter] in the block with [blockTimestamp] to the supply
+// tracked by the native minter. Returns an error if this exceeds the supply cap or the quota of [minter].
+func recordMint(stateDB StateDB, minter common.Address, amount *big.Int, blockTimestamp *big.Int) error {
+	totalMinted := new(big.Int).Add(GetTotalMinted(stateDB), amount)
+	if maxTotalSupply := GetMaxTotalSupply(stateDB); totalMinted.Cmp(maxTotalSupply) > 0 {
+		return fmt.Errorf("%w: total minted %s exceeds %s", ErrMaxTotalSupplyExceeded, totalMinted, maxTotalSupply)
+	}
+	if quota := getMinterQuota(stateDB, minter); quota.Sign() != 0 {
+		windowStart := quotaWindowStart(stateDB, blockTimestamp)
+		minted := new(big.Int).Add(getMintedInWindow(stateDB, minter, windowStart), amount)
+		if minted.Cmp(quota) > 0 {
+			return fmt.Errorf("%w: %s minted %s of %s", ErrMinterQuotaExceeded, minter, minted, quota)
+		}
+		stateDB.SetState(ContractNativeMinterAddress, minterStorageKey(minterWindowStartKeyPrefix, minter), common.BigToHash(windowStart))
+		stateDB.SetState(ContractNativeMinterAddress, minterStorageKey(minterWindowMintedKeyPrefix, minter), common.BigToHash(minted))
+	}
+	setTotalMinted(stateDB, totalMinted)
+	return nil
+}
+
 // mintNativeCoin checks if the caller is permissioned for minting operation.
 // The execution function parses the [input] into native coin amount and receiver address.
+// After the NativeMinterSupply upgrade, the minted amount is also checked against the supply
+// cap and the quota of the caller.
 func mintNativeCoin(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
-	if remainingGas, err = deductGas(suppliedGas, MintGasCost); err != nil {
+	gasCost := uint64(MintGasCost)
+	supplyEnabled := nativeMinterSupplyEnabled(accessibleState)
+	if supplyEnabled {
+		gasCost += MintSupplyGasCost
+	}
+	if remainingGas, err = deductGas(suppliedGas, gasCost); err != nil {
 		return nil, 0, err
 	}
 
@@ -132,29 +344,127 @@ func mintNativeCoin(accessibleState PrecompileAccessibleState, caller common.Add
 	}
 
 	stateDB := accessibleState.GetStateDB()
+	blockTimestamp := accessibleState.GetBlockContext().Timestamp()
 	// Verify that the caller is in the allow list and therefore has the right to modify it
-	callerStatus := getAllowListStatus(stateDB, ContractNativeMinterAddress, caller)
+	callerStatus := getAllowListStatus(stateDB, ContractNativeMinterAddress, caller, blockTimestamp)
 	if !callerStatus.IsEnabled() {
 		return nil, remainingGas, fmt.Errorf("%w: %s", ErrCannotMint, caller)
 	}
 
+	if supplyEnabled {
+		if err := recordMint(stateDB, caller, amount, blockTimestamp); err != nil {
+			return nil, remainingGas, err
+		}
+	}
+
 	// if there is no address in the state, create one.
 	if !stateDB.Exist(to) {
 		stateDB.CreateAccount(to)
 	}
 
 	stateDB.AddBalance(to, amount)
+	if logsEnabled(accessibleState) {
+		topics, data := PackNativeCoinMintedEvent(caller, to, amount)
+		if remainingGas, err = addLog(accessibleState, ContractNativeMinterAddress, topics, data, remainingGas); err != nil {
+			return nil, 0, err
+		}
+	}
 	// Return an empty output and the remaining gas
 	return []byte{}, remainingGas, nil
 }
 
+// burnNativeCoin burns the amount parsed from [input] from the balance of the caller, if the
+// caller is permissioned for minting, and deducts it from the supply tracked by the native minter.
+// Burning more than the tracked supply reduces it to 0.
+func burnNativeCoin(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, BurnGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	if readOnly {
+		return nil, remainingGas, vmerrs.ErrWriteProtection
+	}
+
+	if len(input) != burnInputLen {
+		return nil, remainingGas, fmt.Errorf("%w: %d", errInvalidMinterInputSize, len(input))
+	}
+	amount := new(big.Int).SetBytes(input)
+
+	stateDB := accessibleState.GetStateDB()
+	// Verify that the caller is in the allow list and therefore has the right to burn
+	callerStatus := getAllowListStatus(stateDB, ContractNativeMinterAddress, caller, accessibleState.GetBlockContext().Timestamp())
+	if !callerStatus.IsEnabled() {
+		return nil, remainingGas, fmt.Errorf("%w: %s", ErrCannotBurn, caller)
+	}
+
+	if stateDB.GetBalance(caller).Cmp(amount) < 0 {
+		return nil, remainingGas, vmerrs.ErrInsufficientBalance
+	}
+	stateDB.SubBalance(caller, amount)
+
+	totalMinted := new(big.Int).Sub(GetTotalMinted(stateDB), amount)
+	if totalMinted.Sign() < 0 {
+		totalMinted.SetUint64(0)
+	}
+	setTotalMinted(stateDB, totalMinted)
+
+	if logsEnabled(accessibleState) {
+		topics, data := PackNativeCoinBurnedEvent(caller, amount)
+		if remainingGas, err = addLog(accessibleState, ContractNativeMinterAddress, topics, data, remainingGas); err != nil {
+			return nil, 0, err
+		}
+	}
+	// Return an empty output and the remaining gas
+	return []byte{}, remainingGas, nil
+}
+
+// totalMinted returns the total amount minted by the native minter less the amount burned through it.
+func totalMinted(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, TotalMintedGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	return common.BigToHash(GetTotalMinted(accessibleState.GetStateDB())).Bytes(), remainingGas, nil
+}
+
+// remainingQuota returns the amount the address parsed from [input] may still mint in the current block.
+func remainingQuota(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, RemainingQuotaGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	if len(input) != remainingQuotaInputLen {
+		return nil, remainingGas, fmt.Errorf("%w: %d", errInvalidMinterInputSize, len(input))
+	}
+	minter := common.BytesToAddress(input)
+
+	remaining := GetRemainingQuota(accessibleState.GetStateDB(), minter, accessibleState.GetBlockContext().Timestamp())
+	return common.BigToHash(remaining).Bytes(), remainingGas, nil
+}
+
+// nativeMinterSupplyFunction returns a function with [selector] that runs [execute] and behaves as if
+// it did not exist before the NativeMinterSupply upgrade.
+func nativeMinterSupplyFunction(selector []byte, execute RunStatefulPrecompileFunc) *statefulPrecompileFunction {
+	return newStatefulPrecompileFunction(selector, func(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+		if !nativeMinterSupplyEnabled(accessibleState) {
+			return nil, suppliedGas, fmt.Errorf("invalid function selector %#x", selector)
+		}
+		return execute(accessibleState, caller, addr, input, suppliedGas, readOnly)
+	})
+}
+
 // createNativeMinterPrecompile returns a StatefulPrecompiledContract with R/W control of an allow list at [precompileAddr] and a native coin minter.
 func createNativeMinterPrecompile(precompileAddr common.Address) StatefulPrecompiledContract {
 	enabledFuncs := createAllowListFunctions(precompileAddr)
 
 	mintFunc := newStatefulPrecompileFunction(mintSignature, mintNativeCoin)
 
-	enabledFuncs = append(enabledFuncs, mintFunc)
+	enabledFuncs = append(enabledFuncs,
+		mintFunc,
+		nativeMinterSupplyFunction(burnSignature, burnNativeCoin),
+		nativeMinterSupplyFunction(totalMintedSignature, totalMinted),
+		nativeMinterSupplyFunction(remainingQuotaSignature, remainingQuota),
+	)
 	// Construct the contract with no fallback function.
 	contract := newStatefulPrecompileWithFunctionSelectors(nil, enabledFuncs)
 	return contract
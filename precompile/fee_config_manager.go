@@ -4,6 +4,7 @@
 package precompile
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"math/big"
@@ -47,11 +48,27 @@ var (
 	ErrCannotChangeFee = errors.New("non-enabled cannot change fee config")
 )
 
+// FeeConfigManagerConfigKey is the key of the fee manager precompile's config in the
+// chain config and in precompile upgrades.
+const FeeConfigManagerConfigKey = "feeManagerConfig"
+
+func init() {
+	mustRegisterModule(StatefulPrecompileModule{
+		ConfigKey: FeeConfigManagerConfigKey,
+		Address:   FeeConfigManagerAddress,
+		NewConfig: func() StatefulPrecompileConfig { return &FeeConfigManagerConfig{} },
+		Order:     4,
+	})
+}
+
 // FeeConfigManagerConfig wraps [AllowListConfig] and uses it to implement the StatefulPrecompileConfig
 // interface while adding in the FeeConfigManager specific precompile address.
 type FeeConfigManagerConfig struct {
 	AllowListConfig // Config for the fee config manager allow list
 	UpgradeableConfig
+	// InitialFeeConfig is stored when the fee config manager activates. If nil, the fee
+	// config in the chain config is stored instead.
+	InitialFeeConfig *commontype.FeeConfig `json:"initialFeeConfig,omitempty"`
 }
 
 // NewFeeManagerConfig returns a config for a network upgrade at [blockTimestamp] that enables
@@ -86,14 +103,33 @@ func (c *FeeConfigManagerConfig) Equal(s StatefulPrecompileConfig) bool {
 	if !ok {
 		return false
 	}
-	return c.UpgradeableConfig.Equal(&other.UpgradeableConfig) && c.AllowListConfig.Equal(&other.AllowListConfig)
+	return c.UpgradeableConfig.Equal(&other.UpgradeableConfig) &&
+		c.AllowListConfig.Equal(&other.AllowListConfig) &&
+		c.InitialFeeConfig.Equal(other.InitialFeeConfig)
+}
+
+// Verify returns an error if [c] specifies an invalid allow list or initial fee config.
+func (c *FeeConfigManagerConfig) Verify() error {
+	if err := c.AllowListConfig.Verify(); err != nil {
+		return err
+	}
+	if c.InitialFeeConfig != nil {
+		if err := c.InitialFeeConfig.Verify(); err != nil {
+			return fmt.Errorf("invalid initial fee config: %w", err)
+		}
+	}
+	return nil
 }
 
-// Configure configures [state] with the desired admins based on [c].
+// Configure configures [state] with the desired admins and initial fee config based on [c].
 func (c *FeeConfigManagerConfig) Configure(chainConfig ChainConfig, state StateDB, blockContext BlockContext) {
 	// Store the initial fee config into the state when the fee config manager activates.
-	if err := StoreFeeConfig(state, chainConfig.GetFeeConfig(), blockContext); err != nil {
-		panic(fmt.Sprintf("fee config should have been verified in genesis: %s", err))
+	feeConfig := chainConfig.GetFeeConfig()
+	if c.InitialFeeConfig != nil {
+		feeConfig = *c.InitialFeeConfig
+	}
+	if err := StoreFeeConfig(state, feeConfig, blockContext); err != nil {
+		panic(fmt.Sprintf("fee config should have been verified in genesis or the precompile config: %s", err))
 	}
 	c.AllowListConfig.Configure(state, FeeConfigManagerAddress)
 }
@@ -103,9 +139,9 @@ func (c *FeeConfigManagerConfig) Contract() StatefulPrecompiledContract {
 	return FeeConfigManagerPrecompile
 }
 
-// GetFeeConfigManagerStatus returns the role of [address] for the fee config manager list.
-func GetFeeConfigManagerStatus(stateDB StateDB, address common.Address) AllowListRole {
-	return getAllowListStatus(stateDB, FeeConfigManagerAddress, address)
+// GetFeeConfigManagerStatus returns the role of [address] for the fee config manager list in the block with [blockTimestamp].
+func GetFeeConfigManagerStatus(stateDB StateDB, address common.Address, blockTimestamp *big.Int) AllowListRole {
+	return getAllowListStatus(stateDB, FeeConfigManagerAddress, address, blockTimestamp)
 }
 
 // SetFeeConfigManagerStatus sets the permissions of [address] to [role] for the
@@ -124,6 +160,11 @@ func PackGetLastChangedAtInput() []byte {
 	return getFeeConfigLastChangedAtSignature
 }
 
+// IsSetFeeConfigInput returns true if [input] calls setFeeConfig.
+func IsSetFeeConfigInput(input []byte) bool {
+	return len(input) >= selectorLen && bytes.Equal(input[:selectorLen], setFeeConfigSignature)
+}
+
 // PackFeeConfig packs [feeConfig] without the selector into the appropriate arguments for fee config operations.
 func PackFeeConfig(feeConfig commontype.FeeConfig) ([]byte, error) {
 	//  input(feeConfig)
@@ -286,15 +327,32 @@ func setFeeConfig(accessibleState PrecompileAccessibleState, caller common.Addre
 
 	stateDB := accessibleState.GetStateDB()
 	// Verify that the caller is in the allow list and therefore has the right to modify it
-	callerStatus := getAllowListStatus(stateDB, FeeConfigManagerAddress, caller)
+	callerStatus := getAllowListStatus(stateDB, FeeConfigManagerAddress, caller, accessibleState.GetBlockContext().Timestamp())
 	if !callerStatus.IsEnabled() {
 		return nil, remainingGas, fmt.Errorf("%w: %s", ErrCannotChangeFee, caller)
 	}
 
+	// Read the previous fee config for the FeeConfigChanged event before it is overwritten.
+	emitLog := logsEnabled(accessibleState)
+	var oldFeeConfig commontype.FeeConfig
+	if emitLog {
+		if remainingGas, err = deductGas(remainingGas, GetFeeConfigGasCost); err != nil {
+			return nil, 0, err
+		}
+		oldFeeConfig = GetStoredFeeConfig(stateDB)
+	}
+
 	if err := StoreFeeConfig(stateDB, feeConfig, accessibleState.GetBlockContext()); err != nil {
 		return nil, remainingGas, err
 	}
 
+	if emitLog {
+		topics, data := PackFeeConfigChangedEvent(caller, oldFeeConfig, feeConfig)
+		if remainingGas, err = addLog(accessibleState, FeeConfigManagerAddress, topics, data, remainingGas); err != nil {
+			return nil, 0, err
+		}
+	}
+
 	// Return an empty output and the remaining gas
 	return []byte{}, remainingGas, nil
 }
@@ -0,0 +1,676 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompile
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/subnet-evm/vmerrs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	SponsorDepositGasCost      = 2*readGasCostPerSlot + writeGasCostPerSlot // deposit and whether the sponsor is listed as a depositor
+	AddDepositorGasCost        = readGasCostPerSlot + 3*writeGasCostPerSlot // charged on top of [SponsorDepositGasCost] for the first deposit of a sponsor
+	SponsorWithdrawGasCost     = readGasCostPerSlot + writeGasCostPerSlot
+	SetSponsoredGasCost        = ReadAllowListGasCost + 3*readGasCostPerSlot + writeGasCostPerSlot // role, current sponsor and the deposits of the caller and the current sponsor
+	SetSpendingLimitGasCost    = writeGasCostPerSlot
+	DepositOfGasCost           = readGasCostPerSlot
+	SponsorOfGasCost           = 2 * readGasCostPerSlot
+	RemainingAllowanceGasCost  = 4 * readGasCostPerSlot // deposit, spending limit, default spending limit and spent
+	sponsorAmountInputLen      = common.HashLength
+	sponsorAddressInputLen     = common.HashLength
+	sponsorTwoArgumentInputLen = 2 * common.HashLength
+)
+
+var (
+	_ StatefulPrecompileConfig       = &GasSponsorConfig{}
+	_ StatefulPrecompileDeconfigurer = &GasSponsorConfig{}
+
+	// Singleton StatefulPrecompiledContract for sponsoring the gas of transactions.
+	GasSponsorPrecompile StatefulPrecompiledContract = createGasSponsorPrecompile(GasSponsorAddress)
+
+	sponsorDepositSignature     = CalculateFunctionSelector("deposit(uint256)")                    // amount
+	sponsorWithdrawSignature    = CalculateFunctionSelector("withdraw(uint256)")                   // amount
+	setSponsoredTargetSignature = CalculateFunctionSelector("setSponsoredTarget(address,bool)")    // target, sponsored
+	setSponsoredSenderSignature = CalculateFunctionSelector("setSponsoredSender(address,bool)")    // sender, sponsored
+	setSpendingLimitSignature   = CalculateFunctionSelector("setSpendingLimit(address,uint256)")   // sender, limit
+	depositOfSignature          = CalculateFunctionSelector("depositOf(address)")                  // sponsor
+	sponsorOfSignature          = CalculateFunctionSelector("sponsorOf(address,address)")          // sender, target
+	remainingAllowanceSignature = CalculateFunctionSelector("remainingAllowance(address,address)") // sponsor, sender
+
+	// SponsorDeposited(address indexed sponsor, uint256 amount)
+	SponsorDepositedEventID = CalculateEventID("SponsorDeposited(address,uint256)")
+	// SponsorWithdrawn(address indexed sponsor, uint256 amount)
+	SponsorWithdrawnEventID = CalculateEventID("SponsorWithdrawn(address,uint256)")
+	// SponsoredTargetSet(address indexed sponsor, address indexed target, bool sponsored)
+	SponsoredTargetSetEventID = CalculateEventID("SponsoredTargetSet(address,address,bool)")
+	// SponsoredSenderSet(address indexed sponsor, address indexed sender, bool sponsored)
+	SponsoredSenderSetEventID = CalculateEventID("SponsoredSenderSet(address,address,bool)")
+	// SpendingLimitSet(address indexed sponsor, address indexed sender, uint256 limit)
+	SpendingLimitSetEventID = CalculateEventID("SpendingLimitSet(address,address,uint256)")
+
+	// The storage keys of the gas sponsor are derived by hashing one of these prefixes together
+	// with the addresses the value is kept for, so that they cannot collide with allow list roles.
+	sponsorDepositKeyPrefix       = []byte("sponsorDeposit")
+	sponsoredTargetKeyPrefix      = []byte("sponsoredTarget")
+	sponsoredSenderKeyPrefix      = []byte("sponsoredSender")
+	sponsorSpendingLimitKeyPrefix = []byte("sponsorSpendingLimit")
+	sponsorSpentKeyPrefix         = []byte("sponsorSpent")
+	depositorIndexKeyPrefix       = []byte("depositorIndex")
+	depositorAtKeyPrefix          = []byte("depositorAt")
+	depositorCountKey             = crypto.Keccak256Hash([]byte("depositorCount"))
+
+	ErrCannotSponsor              = errors.New("non-enabled cannot register sponsorships")
+	ErrAlreadySponsored           = errors.New("already sponsored by another sponsor")
+	ErrNotSponsor                 = errors.New("caller is not the sponsor or an admin")
+	ErrInsufficientSponsorDeposit = errors.New("insufficient sponsor deposit")
+	errInvalidSponsorInputSize    = errors.New("invalid input length for gas sponsor function")
+	errInvalidSponsorBool         = errors.New("invalid bool input for gas sponsor function")
+)
+
+// GasSponsorConfigKey is the key of the gas sponsor precompile's config in the chain config and
+// in precompile upgrades.
+const GasSponsorConfigKey = "gasSponsorConfig"
+
+func init() {
+	mustRegisterModule(StatefulPrecompileModule{
+		ConfigKey: GasSponsorConfigKey,
+		Address:   GasSponsorAddress,
+		NewConfig: func() StatefulPrecompileConfig { return &GasSponsorConfig{} },
+		Order:     7,
+	})
+}
+
+// GasSponsorConfig wraps [AllowListConfig] and uses it to implement the StatefulPrecompileConfig
+// interface while adding in the gas sponsor specific precompile address.
+//
+// Any address may deposit native coin with the gas sponsor, but only addresses enabled on its
+// allow list may register the targets and senders whose transactions they pay for.
+type GasSponsorConfig struct {
+	AllowListConfig
+	UpgradeableConfig
+}
+
+// NewGasSponsorConfig returns a config for a network upgrade at [blockTimestamp] that enables
+// the gas sponsor with the given [admins] as members of the allowlist.
+func NewGasSponsorConfig(blockTimestamp *big.Int, admins []common.Address) *GasSponsorConfig {
+	return &GasSponsorConfig{
+		AllowListConfig:   AllowListConfig{AllowListAdmins: admins},
+		UpgradeableConfig: UpgradeableConfig{BlockTimestamp: blockTimestamp},
+	}
+}
+
+// NewDisableGasSponsorConfig returns config for a network upgrade at [blockTimestamp]
+// that disables the gas sponsor.
+func NewDisableGasSponsorConfig(blockTimestamp *big.Int) *GasSponsorConfig {
+	return &GasSponsorConfig{
+		UpgradeableConfig: UpgradeableConfig{
+			BlockTimestamp: blockTimestamp,
+			Disable:        true,
+		},
+	}
+}
+
+// Address returns the address of the gas sponsor contract.
+func (c *GasSponsorConfig) Address() common.Address {
+	return GasSponsorAddress
+}
+
+// Equal returns true if [s] is a [*GasSponsorConfig] and it has been configured identical to [c].
+func (c *GasSponsorConfig) Equal(s StatefulPrecompileConfig) bool {
+	// typecast before comparison
+	other, ok := (s).(*GasSponsorConfig)
+	if !ok {
+		return false
+	}
+	return c.UpgradeableConfig.Equal(&other.UpgradeableConfig) && c.AllowListConfig.Equal(&other.AllowListConfig)
+}
+
+// Configure configures [state] with the desired admins based on [c].
+func (c *GasSponsorConfig) Configure(_ ChainConfig, state StateDB, _ BlockContext) {
+	c.AllowListConfig.Configure(state, GasSponsorAddress)
+}
+
+// Deconfigure refunds the deposit of every sponsor, since the deposits are held in the
+// balance of the gas sponsor, which is cleared when it is disabled.
+func (c *GasSponsorConfig) Deconfigure(_ ChainConfig, state StateDB, _ BlockContext) {
+	count := state.GetState(GasSponsorAddress, depositorCountKey).Big().Uint64()
+	for i := uint64(0); i < count; i++ {
+		sponsor := common.BytesToAddress(state.GetState(GasSponsorAddress, depositorAtKey(i)).Bytes())
+		deposit := GetSponsorDeposit(state, sponsor)
+		if deposit.Sign() == 0 {
+			continue
+		}
+		setSponsorDeposit(state, sponsor, new(big.Int))
+		state.SubBalance(GasSponsorAddress, deposit)
+		state.AddBalance(sponsor, deposit)
+	}
+}
+
+// Contract returns the singleton stateful precompiled contract to be used for the gas sponsor.
+func (c *GasSponsorConfig) Contract() StatefulPrecompiledContract {
+	return GasSponsorPrecompile
+}
+
+// GetGasSponsorAllowListStatus returns the role of [address] for the gas sponsor list in the block with [blockTimestamp].
+func GetGasSponsorAllowListStatus(stateDB StateDB, address common.Address, blockTimestamp *big.Int) AllowListRole {
+	return getAllowListStatus(stateDB, GasSponsorAddress, address, blockTimestamp)
+}
+
+// SetGasSponsorAllowListStatus sets the permissions of [address] to [role] for the
+// gas sponsor list. assumes [role] has already been verified as valid.
+func SetGasSponsorAllowListStatus(stateDB StateDB, address common.Address, role AllowListRole) {
+	setAllowListRole(stateDB, GasSponsorAddress, address, role)
+}
+
+// sponsorStorageKey returns the storage key of the value with [prefix] kept for [addresses].
+func sponsorStorageKey(prefix []byte, addresses ...common.Address) common.Hash {
+	data := make([][]byte, 0, len(addresses)+1)
+	data = append(data, prefix)
+	for _, address := range addresses {
+		data = append(data, address.Bytes())
+	}
+	return crypto.Keccak256Hash(data...)
+}
+
+// depositorAtKey returns the storage key of the [i]th sponsor that made a deposit.
+func depositorAtKey(i uint64) common.Hash {
+	return crypto.Keccak256Hash(depositorAtKeyPrefix, common.BigToHash(new(big.Int).SetUint64(i)).Bytes())
+}
+
+// isDepositor returns true if [sponsor] is in the list of sponsors that made a deposit.
+func isDepositor(stateDB StateDB, sponsor common.Address) bool {
+	return stateDB.GetState(GasSponsorAddress, sponsorStorageKey(depositorIndexKeyPrefix, sponsor)) != (common.Hash{})
+}
+
+// addDepositor adds [sponsor] to the list of sponsors that made a deposit, which are refunded
+// when the gas sponsor is disabled. Assumes that [sponsor] is not in the list yet.
+func addDepositor(stateDB StateDB, sponsor common.Address) {
+	indexKey := sponsorStorageKey(depositorIndexKeyPrefix, sponsor)
+	count := stateDB.GetState(GasSponsorAddress, depositorCountKey).Big().Uint64()
+	stateDB.SetState(GasSponsorAddress, depositorAtKey(count), sponsor.Hash())
+	// Indices are stored incremented by one, so that the zero value means not listed.
+	stateDB.SetState(GasSponsorAddress, indexKey, common.BigToHash(new(big.Int).SetUint64(count+1)))
+	stateDB.SetState(GasSponsorAddress, depositorCountKey, common.BigToHash(new(big.Int).SetUint64(count+1)))
+}
+
+// GetSponsorDeposit returns the native coin [sponsor] has deposited to pay for the gas of
+// sponsored transactions.
+func GetSponsorDeposit(stateDB StateDB, sponsor common.Address) *big.Int {
+	return stateDB.GetState(GasSponsorAddress, sponsorStorageKey(sponsorDepositKeyPrefix, sponsor)).Big()
+}
+
+// setSponsorDeposit stores [amount] as the deposit of [sponsor].
+func setSponsorDeposit(stateDB StateDB, sponsor common.Address, amount *big.Int) {
+	stateDB.SetState(GasSponsorAddress, sponsorStorageKey(sponsorDepositKeyPrefix, sponsor), common.BigToHash(amount))
+}
+
+// getSponsor returns the sponsor registered for the address with [prefix], or the zero address
+// if there is none.
+func getSponsor(stateDB StateDB, prefix []byte, address common.Address) common.Address {
+	return common.BytesToAddress(stateDB.GetState(GasSponsorAddress, sponsorStorageKey(prefix, address)).Bytes())
+}
+
+// GetSponsorOf returns the sponsor registered to pay for transactions from [sender] to [to],
+// regardless of whether it can afford them. A sponsor of the target takes precedence over a
+// sponsor of the sender. Contract creations, where [to] is nil, can only be sponsored through
+// their sender. Returns false if the transaction has no sponsor.
+func GetSponsorOf(stateDB StateDB, sender common.Address, to *common.Address) (common.Address, bool) {
+	candidates := GetSponsorCandidates(stateDB, sender, to)
+	if len(candidates) == 0 {
+		return common.Address{}, false
+	}
+	return candidates[0], true
+}
+
+// GetSponsorCandidates returns the sponsors registered for transactions from [sender] to [to] in
+// order of precedence.
+func GetSponsorCandidates(stateDB StateDB, sender common.Address, to *common.Address) []common.Address {
+	candidates := make([]common.Address, 0, 2)
+	if to != nil {
+		if sponsor := getSponsor(stateDB, sponsoredTargetKeyPrefix, *to); sponsor != (common.Address{}) {
+			candidates = append(candidates, sponsor)
+		}
+	}
+	if sponsor := getSponsor(stateDB, sponsoredSenderKeyPrefix, sender); sponsor != (common.Address{}) {
+		candidates = append(candidates, sponsor)
+	}
+	return candidates
+}
+
+// getSpendingLimit returns the total amount [sponsor] is willing to spend on the gas of [sender].
+// If [sponsor] has not set a limit for [sender], its default limit, set for the zero address,
+// applies. Returns 0 if the spending is not limited.
+func getSpendingLimit(stateDB StateDB, sponsor common.Address, sender common.Address) *big.Int {
+	limit := stateDB.GetState(GasSponsorAddress, sponsorStorageKey(sponsorSpendingLimitKeyPrefix, sponsor, sender)).Big()
+	if limit.Sign() == 0 && sender != (common.Address{}) {
+		return getSpendingLimit(stateDB, sponsor, common.Address{})
+	}
+	return limit
+}
+
+// getSpent returns the amount [sponsor] has spent on the gas of [sender].
+func getSpent(stateDB StateDB, sponsor common.Address, sender common.Address) *big.Int {
+	return stateDB.GetState(GasSponsorAddress, sponsorStorageKey(sponsorSpentKeyPrefix, sponsor, sender)).Big()
+}
+
+// setSpent stores [amount] as the amount [sponsor] has spent on the gas of [sender].
+func setSpent(stateDB StateDB, sponsor common.Address, sender common.Address, amount *big.Int) {
+	stateDB.SetState(GasSponsorAddress, sponsorStorageKey(sponsorSpentKeyPrefix, sponsor, sender), common.BigToHash(amount))
+}
+
+// GetRemainingAllowance returns the amount [sponsor] may still spend on the gas of [sender]
+// under both its deposit and its spending limit for [sender].
+func GetRemainingAllowance(stateDB StateDB, sponsor common.Address, sender common.Address) *big.Int {
+	return GetRemainingAllowanceAfter(stateDB, sponsor, sender, common.Big0, common.Big0)
+}
+
+// GetRemainingAllowanceAfter is like GetRemainingAllowance, except that [sponsor] is assumed
+// to have spent another [spentBySponsor] of its deposit, [spentOnSender] of which on the gas
+// of [sender], that is not charged in [stateDB] yet.
+func GetRemainingAllowanceAfter(stateDB StateDB, sponsor common.Address, sender common.Address, spentBySponsor *big.Int, spentOnSender *big.Int) *big.Int {
+	remaining := new(big.Int).Sub(GetSponsorDeposit(stateDB, sponsor), spentBySponsor)
+	if limit := getSpendingLimit(stateDB, sponsor, sender); limit.Sign() != 0 {
+		remainingLimit := new(big.Int).Sub(limit, getSpent(stateDB, sponsor, sender))
+		remainingLimit.Sub(remainingLimit, spentOnSender)
+		if remainingLimit.Cmp(remaining) < 0 {
+			remaining = remainingLimit
+		}
+	}
+	if remaining.Sign() < 0 {
+		return new(big.Int)
+	}
+	return remaining
+}
+
+// GetGasSponsor returns the sponsor that pays for the gas of a transaction from [sender] to [to]
+// costing up to [gasCost]. The first registered sponsor, in order of precedence, that can afford
+// [gasCost] pays for the transaction. Returns false if no sponsor pays for the transaction, in
+// which case the sender pays for its own gas.
+// Assumes that the gas sponsor precompile is enabled.
+func GetGasSponsor(stateDB StateDB, sender common.Address, to *common.Address, gasCost *big.Int) (common.Address, bool) {
+	for _, sponsor := range GetSponsorCandidates(stateDB, sender, to) {
+		if GetRemainingAllowance(stateDB, sponsor, sender).Cmp(gasCost) >= 0 {
+			return sponsor, true
+		}
+	}
+	return common.Address{}, false
+}
+
+// GetGasSponsorAllowance returns the most that a sponsor would pay for the gas of a transaction
+// from [sender] to [to], or 0 if the transaction has no sponsor.
+// Assumes that the gas sponsor precompile is enabled.
+func GetGasSponsorAllowance(stateDB StateDB, sender common.Address, to *common.Address) *big.Int {
+	allowance := new(big.Int)
+	for _, sponsor := range GetSponsorCandidates(stateDB, sender, to) {
+		if remaining := GetRemainingAllowance(stateDB, sponsor, sender); remaining.Cmp(allowance) > 0 {
+			allowance = remaining
+		}
+	}
+	return allowance
+}
+
+// ChargeGasSponsor charges [amount] for the gas of a transaction from [sender] to the deposit of
+// [sponsor]. Assumes that [sponsor] can afford [amount], as verified by [GetGasSponsor].
+func ChargeGasSponsor(stateDB StateDB, sponsor common.Address, sender common.Address, amount *big.Int) {
+	setSponsorDeposit(stateDB, sponsor, new(big.Int).Sub(GetSponsorDeposit(stateDB, sponsor), amount))
+	setSpent(stateDB, sponsor, sender, new(big.Int).Add(getSpent(stateDB, sponsor, sender), amount))
+	stateDB.SubBalance(GasSponsorAddress, amount)
+}
+
+// RefundGasSponsor refunds [amount] of the gas charged to [sponsor] for a transaction from [sender].
+func RefundGasSponsor(stateDB StateDB, sponsor common.Address, sender common.Address, amount *big.Int) {
+	setSponsorDeposit(stateDB, sponsor, new(big.Int).Add(GetSponsorDeposit(stateDB, sponsor), amount))
+	spent := new(big.Int).Sub(getSpent(stateDB, sponsor, sender), amount)
+	if spent.Sign() < 0 {
+		spent.SetUint64(0)
+	}
+	setSpent(stateDB, sponsor, sender, spent)
+	stateDB.AddBalance(GasSponsorAddress, amount)
+}
+
+// PackSponsorDeposit packs [amount] with the selector into the input for deposit.
+// Assumes that [amount] can be represented by 32 bytes.
+func PackSponsorDeposit(amount *big.Int) []byte {
+	res := make([]byte, selectorLen+sponsorAmountInputLen)
+	packOrderedHashesWithSelector(res, sponsorDepositSignature, []common.Hash{common.BigToHash(amount)})
+	return res
+}
+
+// PackSponsorWithdraw packs [amount] with the selector into the input for withdraw.
+// Assumes that [amount] can be represented by 32 bytes.
+func PackSponsorWithdraw(amount *big.Int) []byte {
+	res := make([]byte, selectorLen+sponsorAmountInputLen)
+	packOrderedHashesWithSelector(res, sponsorWithdrawSignature, []common.Hash{common.BigToHash(amount)})
+	return res
+}
+
+// PackSetSponsoredTarget packs [target] and [sponsored] with the selector into the input for setSponsoredTarget.
+func PackSetSponsoredTarget(target common.Address, sponsored bool) []byte {
+	return packSponsoredInput(setSponsoredTargetSignature, target, sponsored)
+}
+
+// PackSetSponsoredSender packs [sender] and [sponsored] with the selector into the input for setSponsoredSender.
+func PackSetSponsoredSender(sender common.Address, sponsored bool) []byte {
+	return packSponsoredInput(setSponsoredSenderSignature, sender, sponsored)
+}
+
+// packSponsoredInput packs [address] and [sponsored] with [selector].
+func packSponsoredInput(selector []byte, address common.Address, sponsored bool) []byte {
+	sponsoredHash := common.Hash{}
+	if sponsored {
+		sponsoredHash = common.BigToHash(common.Big1)
+	}
+	res := make([]byte, selectorLen+sponsorTwoArgumentInputLen)
+	packOrderedHashesWithSelector(res, selector, []common.Hash{address.Hash(), sponsoredHash})
+	return res
+}
+
+// PackSetSpendingLimit packs [sender] and [limit] with the selector into the input for setSpendingLimit.
+// Assumes that [limit] can be represented by 32 bytes.
+func PackSetSpendingLimit(sender common.Address, limit *big.Int) []byte {
+	res := make([]byte, selectorLen+sponsorTwoArgumentInputLen)
+	packOrderedHashesWithSelector(res, setSpendingLimitSignature, []common.Hash{sender.Hash(), common.BigToHash(limit)})
+	return res
+}
+
+// PackDepositOf packs [sponsor] with the selector into the input for depositOf.
+func PackDepositOf(sponsor common.Address) []byte {
+	res := make([]byte, selectorLen+sponsorAddressInputLen)
+	packOrderedHashesWithSelector(res, depositOfSignature, []common.Hash{sponsor.Hash()})
+	return res
+}
+
+// PackSponsorOf packs [sender] and [target] with the selector into the input for sponsorOf.
+func PackSponsorOf(sender common.Address, target common.Address) []byte {
+	res := make([]byte, selectorLen+sponsorTwoArgumentInputLen)
+	packOrderedHashesWithSelector(res, sponsorOfSignature, []common.Hash{sender.Hash(), target.Hash()})
+	return res
+}
+
+// PackRemainingAllowance packs [sponsor] and [sender] with the selector into the input for remainingAllowance.
+func PackRemainingAllowance(sponsor common.Address, sender common.Address) []byte {
+	res := make([]byte, selectorLen+sponsorTwoArgumentInputLen)
+	packOrderedHashesWithSelector(res, remainingAllowanceSignature, []common.Hash{sponsor.Hash(), sender.Hash()})
+	return res
+}
+
+// PackSponsorDepositedEvent packs the topics and data of the SponsorDeposited event emitted
+// when [sponsor] deposits [amount].
+func PackSponsorDepositedEvent(sponsor common.Address, amount *big.Int) ([]common.Hash, []byte) {
+	return []common.Hash{SponsorDepositedEventID, sponsor.Hash()}, common.BigToHash(amount).Bytes()
+}
+
+// PackSponsorWithdrawnEvent packs the topics and data of the SponsorWithdrawn event emitted
+// when [sponsor] withdraws [amount].
+func PackSponsorWithdrawnEvent(sponsor common.Address, amount *big.Int) ([]common.Hash, []byte) {
+	return []common.Hash{SponsorWithdrawnEventID, sponsor.Hash()}, common.BigToHash(amount).Bytes()
+}
+
+// PackSpendingLimitSetEvent packs the topics and data of the SpendingLimitSet event emitted
+// when [sponsor] sets its spending limit for [sender] to [limit].
+func PackSpendingLimitSetEvent(sponsor common.Address, sender common.Address, limit *big.Int) ([]common.Hash, []byte) {
+	return []common.Hash{SpendingLimitSetEventID, sponsor.Hash(), sender.Hash()}, common.BigToHash(limit).Bytes()
+}
+
+// packSponsoredSetEvent packs the topics and data of the event with [eventID] emitted when
+// [sponsor] starts or stops sponsoring [address].
+func packSponsoredSetEvent(eventID common.Hash, sponsor common.Address, address common.Address, sponsored bool) ([]common.Hash, []byte) {
+	sponsoredHash := common.Hash{}
+	if sponsored {
+		sponsoredHash = common.BigToHash(common.Big1)
+	}
+	return []common.Hash{eventID, sponsor.Hash(), address.Hash()}, sponsoredHash.Bytes()
+}
+
+// unpackAmountInput unpacks the single uint256 argument in [input].
+func unpackAmountInput(input []byte) (*big.Int, error) {
+	if len(input) != sponsorAmountInputLen {
+		return nil, fmt.Errorf("%w: %d", errInvalidSponsorInputSize, len(input))
+	}
+	return new(big.Int).SetBytes(input), nil
+}
+
+// sponsorDeposit moves the amount parsed from [input] from the balance of the caller to its
+// deposit with the gas sponsor.
+func sponsorDeposit(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, SponsorDepositGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	if readOnly {
+		return nil, remainingGas, vmerrs.ErrWriteProtection
+	}
+
+	amount, err := unpackAmountInput(input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	stateDB := accessibleState.GetStateDB()
+	// The first deposit of a sponsor lists it to be refunded if the gas sponsor is disabled.
+	listed := isDepositor(stateDB, caller)
+	if !listed {
+		if remainingGas, err = deductGas(remainingGas, AddDepositorGasCost); err != nil {
+			return nil, 0, err
+		}
+	}
+	if stateDB.GetBalance(caller).Cmp(amount) < 0 {
+		return nil, remainingGas, vmerrs.ErrInsufficientBalance
+	}
+	stateDB.SubBalance(caller, amount)
+	stateDB.AddBalance(GasSponsorAddress, amount)
+	setSponsorDeposit(stateDB, caller, new(big.Int).Add(GetSponsorDeposit(stateDB, caller), amount))
+	if !listed {
+		addDepositor(stateDB, caller)
+	}
+
+	if logsEnabled(accessibleState) {
+		topics, data := PackSponsorDepositedEvent(caller, amount)
+		if remainingGas, err = addLog(accessibleState, GasSponsorAddress, topics, data, remainingGas); err != nil {
+			return nil, 0, err
+		}
+	}
+	// Return an empty output and the remaining gas
+	return []byte{}, remainingGas, nil
+}
+
+// sponsorWithdraw moves the amount parsed from [input] from the deposit of the caller back to
+// its balance.
+func sponsorWithdraw(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, SponsorWithdrawGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	if readOnly {
+		return nil, remainingGas, vmerrs.ErrWriteProtection
+	}
+
+	amount, err := unpackAmountInput(input)
+	if err != nil {
+		return nil, remainingGas, err
+	}
+
+	stateDB := accessibleState.GetStateDB()
+	deposit := GetSponsorDeposit(stateDB, caller)
+	if deposit.Cmp(amount) < 0 {
+		return nil, remainingGas, fmt.Errorf("%w: %s has %s, want %s", ErrInsufficientSponsorDeposit, caller, deposit, amount)
+	}
+	setSponsorDeposit(stateDB, caller, deposit.Sub(deposit, amount))
+	stateDB.SubBalance(GasSponsorAddress, amount)
+	stateDB.AddBalance(caller, amount)
+
+	if logsEnabled(accessibleState) {
+		topics, data := PackSponsorWithdrawnEvent(caller, amount)
+		if remainingGas, err = addLog(accessibleState, GasSponsorAddress, topics, data, remainingGas); err != nil {
+			return nil, 0, err
+		}
+	}
+	// Return an empty output and the remaining gas
+	return []byte{}, remainingGas, nil
+}
+
+// createSponsoredSetter returns an execution function that registers the caller as the sponsor
+// of the address parsed from the input, or removes its sponsorship, where [prefix] distinguishes
+// targets from senders. An address can only have one sponsor at a time, which must be enabled on
+// the gas sponsor allow list and have a deposit to register it. The sponsorship of a sponsor
+// whose deposit is empty can be taken over by another sponsor, and admins can remove any
+// sponsorship, so that an address cannot be claimed by a sponsor that does not pay for it.
+func createSponsoredSetter(prefix []byte, eventID common.Hash) RunStatefulPrecompileFunc {
+	return func(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+		if remainingGas, err = deductGas(suppliedGas, SetSponsoredGasCost); err != nil {
+			return nil, 0, err
+		}
+
+		if readOnly {
+			return nil, remainingGas, vmerrs.ErrWriteProtection
+		}
+
+		if len(input) != sponsorTwoArgumentInputLen {
+			return nil, remainingGas, fmt.Errorf("%w: %d", errInvalidSponsorInputSize, len(input))
+		}
+		address := common.BytesToAddress(returnPackedHash(input, 0))
+		var sponsored bool
+		switch common.BytesToHash(returnPackedHash(input, 1)) {
+		case common.Hash{}:
+		case common.BigToHash(common.Big1):
+			sponsored = true
+		default:
+			return nil, remainingGas, errInvalidSponsorBool
+		}
+
+		stateDB := accessibleState.GetStateDB()
+		// Verify that the caller is in the allow list and therefore has the right to register sponsorships
+		callerStatus := getAllowListStatus(stateDB, GasSponsorAddress, caller, accessibleState.GetBlockContext().Timestamp())
+		if !callerStatus.IsEnabled() {
+			return nil, remainingGas, fmt.Errorf("%w: %s", ErrCannotSponsor, caller)
+		}
+
+		current := getSponsor(stateDB, prefix, address)
+		if sponsored {
+			if GetSponsorDeposit(stateDB, caller).Sign() == 0 {
+				return nil, remainingGas, fmt.Errorf("%w: %s has no deposit", ErrInsufficientSponsorDeposit, caller)
+			}
+			if current != (common.Address{}) && current != caller && GetSponsorDeposit(stateDB, current).Sign() != 0 {
+				return nil, remainingGas, fmt.Errorf("%w: %s is sponsored by %s", ErrAlreadySponsored, address, current)
+			}
+		} else if current != caller && !callerStatus.IsAdmin() {
+			return nil, remainingGas, fmt.Errorf("%w: %s of %s", ErrNotSponsor, caller, address)
+		}
+		value := common.Hash{}
+		if sponsored {
+			value = caller.Hash()
+		}
+		stateDB.SetState(GasSponsorAddress, sponsorStorageKey(prefix, address), value)
+
+		if logsEnabled(accessibleState) {
+			topics, data := packSponsoredSetEvent(eventID, caller, address, sponsored)
+			if remainingGas, err = addLog(accessibleState, GasSponsorAddress, topics, data, remainingGas); err != nil {
+				return nil, 0, err
+			}
+		}
+		// Return an empty output and the remaining gas
+		return []byte{}, remainingGas, nil
+	}
+}
+
+// setSpendingLimit sets the total amount the caller is willing to spend on the gas of the sender
+// parsed from [input]. A limit of 0 removes the limit, and the limit set for the zero address
+// applies to all senders without a limit of their own.
+func setSpendingLimit(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, SetSpendingLimitGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	if readOnly {
+		return nil, remainingGas, vmerrs.ErrWriteProtection
+	}
+
+	if len(input) != sponsorTwoArgumentInputLen {
+		return nil, remainingGas, fmt.Errorf("%w: %d", errInvalidSponsorInputSize, len(input))
+	}
+	sender := common.BytesToAddress(returnPackedHash(input, 0))
+	limit := new(big.Int).SetBytes(returnPackedHash(input, 1))
+
+	accessibleState.GetStateDB().SetState(GasSponsorAddress, sponsorStorageKey(sponsorSpendingLimitKeyPrefix, caller, sender), common.BigToHash(limit))
+
+	if logsEnabled(accessibleState) {
+		topics, data := PackSpendingLimitSetEvent(caller, sender, limit)
+		if remainingGas, err = addLog(accessibleState, GasSponsorAddress, topics, data, remainingGas); err != nil {
+			return nil, 0, err
+		}
+	}
+	// Return an empty output and the remaining gas
+	return []byte{}, remainingGas, nil
+}
+
+// depositOf returns the deposit of the sponsor parsed from [input].
+func depositOf(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, DepositOfGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	if len(input) != sponsorAddressInputLen {
+		return nil, remainingGas, fmt.Errorf("%w: %d", errInvalidSponsorInputSize, len(input))
+	}
+	sponsor := common.BytesToAddress(input)
+
+	return common.BigToHash(GetSponsorDeposit(accessibleState.GetStateDB(), sponsor)).Bytes(), remainingGas, nil
+}
+
+// sponsorOf returns the sponsor registered for transactions from the sender to the target parsed
+// from [input], or the zero address if there is none.
+func sponsorOf(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, SponsorOfGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	if len(input) != sponsorTwoArgumentInputLen {
+		return nil, remainingGas, fmt.Errorf("%w: %d", errInvalidSponsorInputSize, len(input))
+	}
+	sender := common.BytesToAddress(returnPackedHash(input, 0))
+	target := common.BytesToAddress(returnPackedHash(input, 1))
+
+	sponsor, _ := GetSponsorOf(accessibleState.GetStateDB(), sender, &target)
+	return sponsor.Hash().Bytes(), remainingGas, nil
+}
+
+// remainingAllowance returns the amount the sponsor parsed from [input] may still spend on the
+// gas of the sender parsed from [input].
+func remainingAllowance(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, RemainingAllowanceGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	if len(input) != sponsorTwoArgumentInputLen {
+		return nil, remainingGas, fmt.Errorf("%w: %d", errInvalidSponsorInputSize, len(input))
+	}
+	sponsor := common.BytesToAddress(returnPackedHash(input, 0))
+	sender := common.BytesToAddress(returnPackedHash(input, 1))
+
+	remaining := GetRemainingAllowance(accessibleState.GetStateDB(), sponsor, sender)
+	return common.BigToHash(remaining).Bytes(), remainingGas, nil
+}
+
+// createGasSponsorPrecompile returns a StatefulPrecompiledContract with R/W control of an allow list at [precompileAddr] and
+// functions for sponsors to manage their deposits and the transactions they pay for.
+func createGasSponsorPrecompile(precompileAddr common.Address) StatefulPrecompiledContract {
+	functions := createAllowListFunctions(precompileAddr)
+	functions = append(functions,
+		newStatefulPrecompileFunction(sponsorDepositSignature, sponsorDeposit),
+		newStatefulPrecompileFunction(sponsorWithdrawSignature, sponsorWithdraw),
+		newStatefulPrecompileFunction(setSponsoredTargetSignature, createSponsoredSetter(sponsoredTargetKeyPrefix, SponsoredTargetSetEventID)),
+		newStatefulPrecompileFunction(setSponsoredSenderSignature, createSponsoredSetter(sponsoredSenderKeyPrefix, SponsoredSenderSetEventID)),
+		newStatefulPrecompileFunction(setSpendingLimitSignature, setSpendingLimit),
+		newStatefulPrecompileFunction(depositOfSignature, depositOf),
+		newStatefulPrecompileFunction(sponsorOfSignature, sponsorOf),
+		newStatefulPrecompileFunction(remainingAllowanceSignature, remainingAllowance),
+	)
+	// Construct the contract with no fallback function.
+	return newStatefulPrecompileWithFunctionSelectors(nil, functions)
+}
@@ -0,0 +1,89 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompile
+
+import (
+	"math/big"
+
+	"github.com/ava-labs/subnet-evm/commontype"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Event IDs (topic 0) of the Solidity events emitted by the stateful precompiles.
+var (
+	// RoleSet(uint256 indexed role, address indexed account, address indexed sender)
+	RoleSetEventID = CalculateEventID("RoleSet(uint256,address,address)")
+	// NativeCoinMinted(address indexed sender, address indexed recipient, uint256 amount)
+	NativeCoinMintedEventID = CalculateEventID("NativeCoinMinted(address,address,uint256)")
+	// NativeCoinBurned(address indexed sender, uint256 amount)
+	NativeCoinBurnedEventID = CalculateEventID("NativeCoinBurned(address,uint256)")
+	// FeeConfigChanged(address indexed sender, FeeConfig oldFeeConfig, FeeConfig newFeeConfig)
+	// where FeeConfig is the static tuple of the 8 uint256 fee config fields in the order
+	// they are passed to setFeeConfig.
+	FeeConfigChangedEventID = CalculateEventID("FeeConfigChanged(address," + feeConfigTupleType + "," + feeConfigTupleType + ")")
+)
+
+const feeConfigTupleType = "(uint256,uint256,uint256,uint256,uint256,uint256,uint256,uint256)"
+
+// CalculateEventID returns the event ID (the first topic of the log) that results from
+// [eventSignature].
+// Ex. the event Transfer(address indexed from, address indexed to, uint256 value) should be
+// passed in as the string: "Transfer(address,address,uint256)"
+func CalculateEventID(eventSignature string) common.Hash {
+	return crypto.Keccak256Hash([]byte(eventSignature))
+}
+
+// logGasCost returns the gas cost of emitting a log with [numTopics] topics and [dataLen]
+// bytes of data. This matches the cost of the LOG opcodes.
+func logGasCost(numTopics int, dataLen int) uint64 {
+	return LogGas + uint64(numTopics)*LogTopicGas + uint64(dataLen)*LogDataGas
+}
+
+// logsEnabled returns true if stateful precompiles should emit logs in the block
+// described by [accessibleState].
+func logsEnabled(accessibleState PrecompileAccessibleState) bool {
+	return accessibleState.GetChainConfig().IsPrecompileLogs(accessibleState.GetBlockContext().Timestamp())
+}
+
+// addLog charges the gas to emit a log from [precompileAddr] with [topics] and [data] to
+// [suppliedGas] and adds the log to the state.
+// Note: the caller is expected to check [logsEnabled] first, so that precompile calls made
+// before the PrecompileLogs upgrade neither emit logs nor consume the additional gas.
+func addLog(accessibleState PrecompileAccessibleState, precompileAddr common.Address, topics []common.Hash, data []byte, suppliedGas uint64) (uint64, error) {
+	remainingGas, err := deductGas(suppliedGas, logGasCost(len(topics), len(data)))
+	if err != nil {
+		return 0, err
+	}
+	blockNumber := accessibleState.GetBlockContext().Number().Uint64()
+	accessibleState.GetStateDB().AddLog(precompileAddr, topics, data, blockNumber)
+	return remainingGas, nil
+}
+
+// PackRoleSetEvent packs the topics and data of the RoleSet event emitted when [sender]
+// sets the role of [account] to [role].
+func PackRoleSetEvent(role AllowListRole, account common.Address, sender common.Address) ([]common.Hash, []byte) {
+	return []common.Hash{RoleSetEventID, common.Hash(role), account.Hash(), sender.Hash()}, []byte{}
+}
+
+// PackNativeCoinMintedEvent packs the topics and data of the NativeCoinMinted event
+// emitted when [sender] mints [amount] to [recipient].
+func PackNativeCoinMintedEvent(sender common.Address, recipient common.Address, amount *big.Int) ([]common.Hash, []byte) {
+	return []common.Hash{NativeCoinMintedEventID, sender.Hash(), recipient.Hash()}, common.BigToHash(amount).Bytes()
+}
+
+// PackNativeCoinBurnedEvent packs the topics and data of the NativeCoinBurned event
+// emitted when [sender] burns [amount] of its balance.
+func PackNativeCoinBurnedEvent(sender common.Address, amount *big.Int) ([]common.Hash, []byte) {
+	return []common.Hash{NativeCoinBurnedEventID, sender.Hash()}, common.BigToHash(amount).Bytes()
+}
+
+// PackFeeConfigChangedEvent packs the topics and data of the FeeConfigChanged event
+// emitted when [sender] changes the fee config from [oldFeeConfig] to [newFeeConfig].
+func PackFeeConfigChangedEvent(sender common.Address, oldFeeConfig commontype.FeeConfig, newFeeConfig commontype.FeeConfig) ([]common.Hash, []byte) {
+	data := make([]byte, 0, 2*feeConfigInputLen)
+	data = append(data, packFeeConfigHelper(oldFeeConfig, false)...)
+	data = append(data, packFeeConfigHelper(newFeeConfig, false)...)
+	return []common.Hash{FeeConfigChangedEventID, sender.Hash()}, data
+}
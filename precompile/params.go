@@ -4,8 +4,6 @@
 package precompile
 
 import (
-	"fmt"
-
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -14,14 +12,28 @@ const (
 	writeGasCostPerSlot = 20_000
 	readGasCostPerSlot  = 5_000
 
-	ModifyAllowListGasCost = writeGasCostPerSlot
-	ReadAllowListGasCost   = readGasCostPerSlot
+	ModifyAllowListGasCost           = writeGasCostPerSlot
+	ModifyAllowListWithExpiryGasCost = 2 * writeGasCostPerSlot // role and expiry slots
+	ReadAllowListGasCost             = readGasCostPerSlot
 
 	MintGasCost = 30_000
 
+	// Gas costs of the native minter once the NativeMinterSupply upgrade is activated.
+	// MintSupplyGasCost is charged in addition to MintGasCost for tracking the minted supply.
+	MintSupplyGasCost     = 6*readGasCostPerSlot + 3*writeGasCostPerSlot
+	BurnGasCost           = MintGasCost + readGasCostPerSlot + writeGasCostPerSlot
+	TotalMintedGasCost    = readGasCostPerSlot
+	RemainingQuotaGasCost = 7 * readGasCostPerSlot
+
 	SetFeeConfigGasCost     = writeGasCostPerSlot * (numFeeConfigField + 1) // plus one for setting last changed at
 	GetFeeConfigGasCost     = readGasCostPerSlot * numFeeConfigField
 	GetLastChangedAtGasCost = readGasCostPerSlot
+
+	// Gas costs for emitting logs once the PrecompileLogs upgrade is activated.
+	// These match the gas costs of the LOG opcodes.
+	LogGas      uint64 = 375
+	LogTopicGas uint64 = 375
+	LogDataGas  uint64 = 8
 )
 
 // Designated addresses of stateful precompiles
@@ -40,13 +52,10 @@ var (
 	ContractNativeMinterAddress      = common.HexToAddress("0x0200000000000000000000000000000000000001")
 	TxAllowListAddress               = common.HexToAddress("0x0200000000000000000000000000000000000002")
 	FeeConfigManagerAddress          = common.HexToAddress("0x0200000000000000000000000000000000000003")
+	RewardManagerAddress             = common.HexToAddress("0x0200000000000000000000000000000000000004")
+	PauserAddress                    = common.HexToAddress("0x0200000000000000000000000000000000000005")
+	GasSponsorAddress                = common.HexToAddress("0x0200000000000000000000000000000000000006")
 
-	UsedAddresses = []common.Address{
-		ContractDeployerAllowListAddress,
-		ContractNativeMinterAddress,
-		TxAllowListAddress,
-		FeeConfigManagerAddress,
-	}
 	reservedRanges = []AddressRange{
 		{
 			common.HexToAddress("0x0100000000000000000000000000000000000000"),
@@ -73,12 +82,3 @@ func ReservedAddress(addr common.Address) bool {
 
 	return false
 }
-
-func init() {
-	// Ensure that every address used by a precompile is in a reserved range.
-	for _, addr := range UsedAddresses {
-		if !ReservedAddress(addr) {
-			panic(fmt.Errorf("address %s used for stateful precompile but not specified in any reserved range", addr))
-		}
-	}
-}
@@ -0,0 +1,271 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompile
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/subnet-evm/vmerrs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	PauseGasCost    = writeGasCostPerSlot
+	UnpauseGasCost  = writeGasCostPerSlot
+	IsPausedGasCost = readGasCostPerSlot
+)
+
+var (
+	_ StatefulPrecompileConfig = &PauserConfig{}
+
+	// Singleton StatefulPrecompiledContract for pausing the chain by permissioned callers.
+	PauserPrecompile StatefulPrecompiledContract = createPauserPrecompile(PauserAddress)
+
+	pauseSignature    = CalculateFunctionSelector("pause()")
+	unpauseSignature  = CalculateFunctionSelector("unpause()")
+	isPausedSignature = CalculateFunctionSelector("isPaused()")
+
+	// Paused(address indexed sender)
+	PausedEventID = CalculateEventID("Paused(address)")
+	// Unpaused(address indexed sender)
+	UnpausedEventID = CalculateEventID("Unpaused(address)")
+
+	pausedStorageKey = common.Hash{'p', 's', 'k'}
+	// pausedValue is stored under [pausedStorageKey] while the chain is paused.
+	pausedValue = common.BigToHash(common.Big1)
+	// exemptValue is stored under the exemption key of exempt senders and targets.
+	exemptValue = common.BigToHash(common.Big1)
+
+	// pauseExemptSenderKeyPrefix and pauseExemptTargetKeyPrefix are hashed together with an
+	// address to derive its exemption key, so that they cannot collide with allow list roles.
+	pauseExemptSenderKeyPrefix = []byte("pauseExemptSender")
+	pauseExemptTargetKeyPrefix = []byte("pauseExemptTarget")
+
+	ErrChainPaused   = errors.New("chain is paused")
+	ErrCannotPause   = errors.New("non-enabled cannot call pause")
+	ErrCannotUnpause = errors.New("non-enabled cannot call unpause")
+)
+
+// PauserConfigKey is the key of the pauser precompile's config in the chain config and in
+// precompile upgrades.
+const PauserConfigKey = "pauserConfig"
+
+func init() {
+	mustRegisterModule(StatefulPrecompileModule{
+		ConfigKey: PauserConfigKey,
+		Address:   PauserAddress,
+		NewConfig: func() StatefulPrecompileConfig { return &PauserConfig{} },
+		Order:     6,
+	})
+}
+
+// PauserConfig wraps [AllowListConfig] and uses it to implement the StatefulPrecompileConfig
+// interface while adding in the Pauser specific precompile address.
+//
+// While the chain is paused, only transactions from an exempt sender to an exempt target are
+// accepted. Addresses with a role on the pauser allow list are always exempt senders and the
+// pauser precompile is always an exempt target, so that the chain can be unpaused.
+type PauserConfig struct {
+	AllowListConfig
+	UpgradeableConfig
+	ExemptSenders []common.Address `json:"exemptSenders,omitempty"`
+	ExemptTargets []common.Address `json:"exemptTargets,omitempty"`
+}
+
+// NewPauserConfig returns a config for a network upgrade at [blockTimestamp] that enables
+// Pauser with the given [admins] as members of the allowlist.
+func NewPauserConfig(blockTimestamp *big.Int, admins []common.Address) *PauserConfig {
+	return &PauserConfig{
+		AllowListConfig:   AllowListConfig{AllowListAdmins: admins},
+		UpgradeableConfig: UpgradeableConfig{BlockTimestamp: blockTimestamp},
+	}
+}
+
+// NewDisablePauserConfig returns config for a network upgrade at [blockTimestamp]
+// that disables Pauser.
+func NewDisablePauserConfig(blockTimestamp *big.Int) *PauserConfig {
+	return &PauserConfig{
+		UpgradeableConfig: UpgradeableConfig{
+			BlockTimestamp: blockTimestamp,
+			Disable:        true,
+		},
+	}
+}
+
+// Address returns the address of the pauser contract.
+func (c *PauserConfig) Address() common.Address {
+	return PauserAddress
+}
+
+// Equal returns true if [s] is a [*PauserConfig] and it has been configured identical to [c].
+func (c *PauserConfig) Equal(s StatefulPrecompileConfig) bool {
+	// typecast before comparison
+	other, ok := (s).(*PauserConfig)
+	if !ok {
+		return false
+	}
+	return c.UpgradeableConfig.Equal(&other.UpgradeableConfig) &&
+		c.AllowListConfig.Equal(&other.AllowListConfig) &&
+		addressesEqual(c.ExemptSenders, other.ExemptSenders) &&
+		addressesEqual(c.ExemptTargets, other.ExemptTargets)
+}
+
+// Configure configures [state] with the desired admins and exemptions based on [c].
+// The chain is not paused when the pauser activates.
+func (c *PauserConfig) Configure(_ ChainConfig, state StateDB, _ BlockContext) {
+	c.AllowListConfig.Configure(state, PauserAddress)
+	for _, sender := range c.ExemptSenders {
+		state.SetState(PauserAddress, pauseExemptionKey(pauseExemptSenderKeyPrefix, sender), exemptValue)
+	}
+	for _, target := range c.ExemptTargets {
+		state.SetState(PauserAddress, pauseExemptionKey(pauseExemptTargetKeyPrefix, target), exemptValue)
+	}
+}
+
+// Contract returns the singleton stateful precompiled contract to be used for the pauser.
+func (c *PauserConfig) Contract() StatefulPrecompiledContract {
+	return PauserPrecompile
+}
+
+// GetPauserAllowListStatus returns the role of [address] for the pauser list in the block with [blockTimestamp].
+func GetPauserAllowListStatus(stateDB StateDB, address common.Address, blockTimestamp *big.Int) AllowListRole {
+	return getAllowListStatus(stateDB, PauserAddress, address, blockTimestamp)
+}
+
+// SetPauserAllowListStatus sets the permissions of [address] to [role] for the
+// pauser list. assumes [role] has already been verified as valid.
+func SetPauserAllowListStatus(stateDB StateDB, address common.Address, role AllowListRole) {
+	setAllowListRole(stateDB, PauserAddress, address, role)
+}
+
+// IsPaused returns true if the chain is paused in [stateDB].
+func IsPaused(stateDB StateDB) bool {
+	return stateDB.GetState(PauserAddress, pausedStorageKey) == pausedValue
+}
+
+// setPaused pauses or unpauses the chain in [stateDB].
+func setPaused(stateDB StateDB, paused bool) {
+	value := common.Hash{}
+	if paused {
+		value = pausedValue
+	}
+	stateDB.SetState(PauserAddress, pausedStorageKey, value)
+}
+
+// pauseExemptionKey returns the storage key of the exemption with [prefix] of [address].
+func pauseExemptionKey(prefix []byte, address common.Address) common.Hash {
+	return crypto.Keccak256Hash(prefix, address.Bytes())
+}
+
+// CheckPaused returns an error if the chain is paused in [stateDB] and a transaction from
+// [from] to [to] is not exempt from the pause in the block with [blockTimestamp].
+// Contract creations, where [to] is nil, are never exempt.
+// Assumes that the pauser precompile is enabled.
+func CheckPaused(stateDB StateDB, from common.Address, to *common.Address, blockTimestamp *big.Int) error {
+	if !IsPaused(stateDB) {
+		return nil
+	}
+	senderExempt := stateDB.GetState(PauserAddress, pauseExemptionKey(pauseExemptSenderKeyPrefix, from)) == exemptValue ||
+		GetPauserAllowListStatus(stateDB, from, blockTimestamp).IsEnabled()
+	targetExempt := to != nil &&
+		(*to == PauserAddress || stateDB.GetState(PauserAddress, pauseExemptionKey(pauseExemptTargetKeyPrefix, *to)) == exemptValue)
+	if !senderExempt || !targetExempt {
+		return fmt.Errorf("%w: transaction from %s is not exempt", ErrChainPaused, from)
+	}
+	return nil
+}
+
+// PackPause packs the pause signature
+func PackPause() []byte {
+	return pauseSignature
+}
+
+// PackUnpause packs the unpause signature
+func PackUnpause() []byte {
+	return unpauseSignature
+}
+
+// PackIsPaused packs the isPaused signature
+func PackIsPaused() []byte {
+	return isPausedSignature
+}
+
+// PackPausedEvent packs the topics and data of the Paused event emitted when [sender]
+// pauses the chain.
+func PackPausedEvent(sender common.Address) ([]common.Hash, []byte) {
+	return []common.Hash{PausedEventID, sender.Hash()}, []byte{}
+}
+
+// PackUnpausedEvent packs the topics and data of the Unpaused event emitted when [sender]
+// unpauses the chain.
+func PackUnpausedEvent(sender common.Address) ([]common.Hash, []byte) {
+	return []common.Hash{UnpausedEventID, sender.Hash()}, []byte{}
+}
+
+// createPauseSetter returns an execution function that verifies the caller is enabled on
+// the pauser allow list, then pauses or unpauses the chain according to [paused].
+func createPauseSetter(gasCost uint64, paused bool, errCannotSet error) RunStatefulPrecompileFunc {
+	return func(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+		if remainingGas, err = deductGas(suppliedGas, gasCost); err != nil {
+			return nil, 0, err
+		}
+
+		if readOnly {
+			return nil, remainingGas, vmerrs.ErrWriteProtection
+		}
+
+		if len(input) != 0 {
+			return nil, remainingGas, fmt.Errorf("invalid input length for pauser function: %d", len(input))
+		}
+
+		stateDB := accessibleState.GetStateDB()
+		// Verify that the caller is in the allow list and therefore has the right to modify it
+		callerStatus := getAllowListStatus(stateDB, PauserAddress, caller, accessibleState.GetBlockContext().Timestamp())
+		if !callerStatus.IsEnabled() {
+			return nil, remainingGas, fmt.Errorf("%w: %s", errCannotSet, caller)
+		}
+
+		setPaused(stateDB, paused)
+		if logsEnabled(accessibleState) {
+			topics, data := PackUnpausedEvent(caller)
+			if paused {
+				topics, data = PackPausedEvent(caller)
+			}
+			if remainingGas, err = addLog(accessibleState, PauserAddress, topics, data, remainingGas); err != nil {
+				return nil, 0, err
+			}
+		}
+		// Return an empty output and the remaining gas
+		return []byte{}, remainingGas, nil
+	}
+}
+
+// isPaused returns true (1) if the chain is paused.
+func isPaused(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, IsPausedGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	output := common.Hash{}
+	if IsPaused(accessibleState.GetStateDB()) {
+		output = common.BigToHash(common.Big1)
+	}
+	return output.Bytes(), remainingGas, nil
+}
+
+// createPauserPrecompile returns a StatefulPrecompiledContract with R/W control of an allow list at [precompileAddr] and
+// functions to pause and unpause the chain.
+func createPauserPrecompile(precompileAddr common.Address) StatefulPrecompiledContract {
+	functions := createAllowListFunctions(precompileAddr)
+	functions = append(functions,
+		newStatefulPrecompileFunction(pauseSignature, createPauseSetter(PauseGasCost, true, ErrCannotPause)),
+		newStatefulPrecompileFunction(unpauseSignature, createPauseSetter(UnpauseGasCost, false, ErrCannotUnpause)),
+		newStatefulPrecompileFunction(isPausedSignature, isPaused),
+	)
+	// Construct the contract with no fallback function.
+	return newStatefulPrecompileWithFunctionSelectors(nil, functions)
+}
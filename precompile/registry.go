@@ -0,0 +1,120 @@
+// (c) 2022 Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompile
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/ava-labs/subnet-evm/constants"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// StatefulPrecompileModule describes a stateful precompile that can be enabled
+// in the genesis or as a network upgrade.
+type StatefulPrecompileModule struct {
+	// ConfigKey is the key of the precompile's config in the chain config
+	// and in the precompile upgrades specified in upgradeBytes.
+	ConfigKey string
+	// Address is the address where the stateful precompile is accessible.
+	Address common.Address
+	// NewConfig returns a new empty config for the precompile, which the
+	// JSON config is unmarshalled into.
+	NewConfig func() StatefulPrecompileConfig
+	// Order determines the order in which precompiles activating in the same
+	// block are configured. Modules are configured in increasing [Order].
+	Order int
+}
+
+var (
+	registryLock sync.RWMutex
+	// registeredModules is sorted by [Order], with ties broken by address.
+	registeredModules []StatefulPrecompileModule
+)
+
+// RegisterModule adds [module] to the registry of stateful precompiles. It is
+// expected to be called from the init function of the package implementing
+// the precompile.
+// Returns an error if the config key or address is already registered, or if
+// the address is not within a reserved range for stateful precompiles.
+func RegisterModule(module StatefulPrecompileModule) error {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	if len(module.ConfigKey) == 0 {
+		return fmt.Errorf("cannot register precompile at %s with empty config key", module.Address)
+	}
+	if module.NewConfig == nil {
+		return fmt.Errorf("cannot register precompile %s without a config constructor", module.ConfigKey)
+	}
+	if !ReservedAddress(module.Address) {
+		return fmt.Errorf("address %s used for stateful precompile %s but not specified in any reserved range", module.Address, module.ConfigKey)
+	}
+	if module.Address == constants.BlackholeAddr {
+		return fmt.Errorf("cannot use address %s for stateful precompile %s - overlaps with blackhole address", module.Address, module.ConfigKey)
+	}
+	for _, registered := range registeredModules {
+		if registered.ConfigKey == module.ConfigKey {
+			return fmt.Errorf("config key %s already registered for precompile at %s", module.ConfigKey, registered.Address)
+		}
+		if registered.Address == module.Address {
+			return fmt.Errorf("address %s already registered for precompile %s", module.Address, registered.ConfigKey)
+		}
+	}
+
+	registeredModules = append(registeredModules, module)
+	sort.SliceStable(registeredModules, func(i, j int) bool {
+		if registeredModules[i].Order != registeredModules[j].Order {
+			return registeredModules[i].Order < registeredModules[j].Order
+		}
+		return bytes.Compare(registeredModules[i].Address[:], registeredModules[j].Address[:]) < 0
+	})
+	return nil
+}
+
+// RegisteredModules returns the registered stateful precompiles in the order
+// they should be configured.
+func RegisteredModules() []StatefulPrecompileModule {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	modules := make([]StatefulPrecompileModule, len(registeredModules))
+	copy(modules, registeredModules)
+	return modules
+}
+
+// GetModule returns the registered stateful precompile with config key [key].
+func GetModule(key string) (StatefulPrecompileModule, bool) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	for _, module := range registeredModules {
+		if module.ConfigKey == key {
+			return module, true
+		}
+	}
+	return StatefulPrecompileModule{}, false
+}
+
+// GetModuleByAddress returns the registered stateful precompile at [address].
+func GetModuleByAddress(address common.Address) (StatefulPrecompileModule, bool) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	for _, module := range registeredModules {
+		if module.Address == address {
+			return module, true
+		}
+	}
+	return StatefulPrecompileModule{}, false
+}
+
+// mustRegisterModule registers [module] and panics if it cannot be registered.
+func mustRegisterModule(module StatefulPrecompileModule) {
+	if err := RegisterModule(module); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,78 @@
+// (c) 2022 Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompile
+
+import (
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/constants"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisteredModulesOrder(t *testing.T) {
+	modules := RegisteredModules()
+	expectedKeys := []string{
+		ContractDeployerAllowListConfigKey,
+		ContractNativeMinterConfigKey,
+		TxAllowListConfigKey,
+		FeeConfigManagerConfigKey,
+		RewardManagerConfigKey,
+		PauserConfigKey,
+		GasSponsorConfigKey,
+	}
+	assert.Len(t, modules, len(expectedKeys))
+	for i, module := range modules {
+		assert.Equal(t, expectedKeys[i], module.ConfigKey)
+		byKey, ok := GetModule(module.ConfigKey)
+		assert.True(t, ok)
+		assert.Equal(t, module.Address, byKey.Address)
+		byAddress, ok := GetModuleByAddress(module.Address)
+		assert.True(t, ok)
+		assert.Equal(t, module.ConfigKey, byAddress.ConfigKey)
+		assert.Equal(t, module.Address, module.NewConfig().Address())
+	}
+}
+
+func TestRegisterModuleErrors(t *testing.T) {
+	newConfig := func() StatefulPrecompileConfig { return &TxAllowListConfig{} }
+	tests := map[string]StatefulPrecompileModule{
+		"empty config key": {
+			Address:   common.HexToAddress("0x0300000000000000000000000000000000000000"),
+			NewConfig: newConfig,
+		},
+		"missing config constructor": {
+			ConfigKey: "missingConstructorConfig",
+			Address:   common.HexToAddress("0x0300000000000000000000000000000000000000"),
+		},
+		"address not reserved": {
+			ConfigKey: "unreservedConfig",
+			Address:   common.HexToAddress("0x0400000000000000000000000000000000000000"),
+			NewConfig: newConfig,
+		},
+		"blackhole address": {
+			ConfigKey: "blackholeConfig",
+			Address:   constants.BlackholeAddr,
+			NewConfig: newConfig,
+		},
+		"duplicate config key": {
+			ConfigKey: TxAllowListConfigKey,
+			Address:   common.HexToAddress("0x0300000000000000000000000000000000000000"),
+			NewConfig: newConfig,
+		},
+		"duplicate address": {
+			ConfigKey: "duplicateAddressConfig",
+			Address:   TxAllowListAddress,
+			NewConfig: newConfig,
+		},
+	}
+	numModules := len(RegisteredModules())
+	for name, module := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, RegisterModule(module))
+		})
+	}
+	// None of the invalid modules should have been registered
+	assert.Len(t, RegisteredModules(), numModules)
+}
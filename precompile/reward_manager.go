@@ -0,0 +1,371 @@
+// (c) 2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package precompile
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ava-labs/subnet-evm/constants"
+	"github.com/ava-labs/subnet-evm/vmerrs"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	AllowFeeRecipientsGasCost      = writeGasCostPerSlot
+	AreFeeRecipientsAllowedGasCost = readGasCostPerSlot
+	CurrentRewardAddressGasCost    = readGasCostPerSlot
+	DisableRewardsGasCost          = writeGasCostPerSlot
+	SetRewardAddressGasCost        = writeGasCostPerSlot
+
+	setRewardAddressInputLen = common.HashLength
+)
+
+var (
+	_ StatefulPrecompileConfig = &RewardManagerConfig{}
+
+	// Singleton StatefulPrecompiledContract for controlling the fee distribution by permissioned callers.
+	RewardManagerPrecompile StatefulPrecompiledContract = createRewardManagerPrecompile(RewardManagerAddress)
+
+	allowFeeRecipientsSignature      = CalculateFunctionSelector("allowFeeRecipients()")
+	areFeeRecipientsAllowedSignature = CalculateFunctionSelector("areFeeRecipientsAllowed()")
+	currentRewardAddressSignature    = CalculateFunctionSelector("currentRewardAddress()")
+	disableRewardsSignature          = CalculateFunctionSelector("disableRewards()")
+	setRewardAddressSignature        = CalculateFunctionSelector("setRewardAddress(address)")
+
+	// RewardAddressChanged(address indexed sender, address indexed oldRewardAddress, address indexed newRewardAddress)
+	RewardAddressChangedEventID = CalculateEventID("RewardAddressChanged(address,address,address)")
+	// FeeRecipientsAllowed(address indexed sender)
+	FeeRecipientsAllowedEventID = CalculateEventID("FeeRecipientsAllowed(address)")
+	// RewardsDisabled(address indexed sender)
+	RewardsDisabledEventID = CalculateEventID("RewardsDisabled(address)")
+
+	rewardAddressStorageKey = common.Hash{'r', 'a', 's', 'k'}
+	// allowFeeRecipientsAddressValue is stored under [rewardAddressStorageKey] when block producers
+	// may choose their own fee recipient. Since addresses are stored left padded, this value cannot
+	// collide with a stored reward address.
+	allowFeeRecipientsAddressValue = common.Hash{1}
+
+	ErrCannotAllowFeeRecipients      = errors.New("non-enabled cannot call allowFeeRecipients")
+	ErrCannotDisableRewards          = errors.New("non-enabled cannot call disableRewards")
+	ErrCannotSetRewardAddress        = errors.New("non-enabled cannot call setRewardAddress")
+	ErrCannotEnableBothRewards       = errors.New("cannot enable both fee recipients and reward address at the same time")
+	ErrEmptyRewardAddress            = errors.New("reward address cannot be empty")
+	errInvalidRewardManagerInputSize = errors.New("invalid input length for reward manager function")
+)
+
+// RewardManagerConfigKey is the key of the reward manager precompile's config in the
+// chain config and in precompile upgrades.
+const RewardManagerConfigKey = "rewardManagerConfig"
+
+func init() {
+	mustRegisterModule(StatefulPrecompileModule{
+		ConfigKey: RewardManagerConfigKey,
+		Address:   RewardManagerAddress,
+		NewConfig: func() StatefulPrecompileConfig { return &RewardManagerConfig{} },
+		Order:     5,
+	})
+}
+
+// InitialRewardConfig specifies how fees are distributed when the reward manager activates.
+// If neither field is set, fees are burned.
+type InitialRewardConfig struct {
+	AllowFeeRecipients bool           `json:"allowFeeRecipients"`
+	RewardAddress      common.Address `json:"rewardAddress,omitempty"`
+}
+
+// Verify returns an error if [i] enables both fee recipients and a reward address.
+func (i *InitialRewardConfig) Verify() error {
+	if i.AllowFeeRecipients && i.RewardAddress != (common.Address{}) {
+		return ErrCannotEnableBothRewards
+	}
+	return nil
+}
+
+// Equal returns true iff [other] configures the same fee distribution as [i].
+func (i *InitialRewardConfig) Equal(other *InitialRewardConfig) bool {
+	if i == nil || other == nil {
+		return i == other
+	}
+	return i.AllowFeeRecipients == other.AllowFeeRecipients && i.RewardAddress == other.RewardAddress
+}
+
+// Configure stores the fee distribution specified by [i] in [state].
+func (i *InitialRewardConfig) Configure(state StateDB) {
+	switch {
+	case i.AllowFeeRecipients:
+		EnableAllowFeeRecipients(state)
+	case i.RewardAddress == (common.Address{}):
+		DisableFeeRewards(state)
+	default:
+		StoreRewardAddress(state, i.RewardAddress)
+	}
+}
+
+// RewardManagerConfig wraps [AllowListConfig] and uses it to implement the StatefulPrecompileConfig
+// interface while adding in the RewardManager specific precompile address.
+type RewardManagerConfig struct {
+	AllowListConfig
+	UpgradeableConfig
+	InitialRewardConfig *InitialRewardConfig `json:"initialRewardConfig,omitempty"`
+}
+
+// NewRewardManagerConfig returns a config for a network upgrade at [blockTimestamp] that enables
+// RewardManager with the given [admins] as members of the allowlist and [initialConfig] as the
+// fee distribution. If [initialConfig] is nil, the fee distribution follows AllowFeeRecipients
+// in the chain config.
+func NewRewardManagerConfig(blockTimestamp *big.Int, admins []common.Address, initialConfig *InitialRewardConfig) *RewardManagerConfig {
+	return &RewardManagerConfig{
+		AllowListConfig:     AllowListConfig{AllowListAdmins: admins},
+		UpgradeableConfig:   UpgradeableConfig{BlockTimestamp: blockTimestamp},
+		InitialRewardConfig: initialConfig,
+	}
+}
+
+// NewDisableRewardManagerConfig returns config for a network upgrade at [blockTimestamp]
+// that disables RewardManager.
+func NewDisableRewardManagerConfig(blockTimestamp *big.Int) *RewardManagerConfig {
+	return &RewardManagerConfig{
+		UpgradeableConfig: UpgradeableConfig{
+			BlockTimestamp: blockTimestamp,
+			Disable:        true,
+		},
+	}
+}
+
+// Address returns the address of the reward manager contract.
+func (c *RewardManagerConfig) Address() common.Address {
+	return RewardManagerAddress
+}
+
+// Verify returns an error if [c] specifies an invalid initial reward config.
+func (c *RewardManagerConfig) Verify() error {
+	if err := c.AllowListConfig.Verify(); err != nil {
+		return err
+	}
+	if c.InitialRewardConfig != nil {
+		return c.InitialRewardConfig.Verify()
+	}
+	return nil
+}
+
+// Equal returns true if [s] is a [*RewardManagerConfig] and it has been configured identical to [c].
+func (c *RewardManagerConfig) Equal(s StatefulPrecompileConfig) bool {
+	// typecast before comparison
+	other, ok := (s).(*RewardManagerConfig)
+	if !ok {
+		return false
+	}
+	return c.UpgradeableConfig.Equal(&other.UpgradeableConfig) &&
+		c.AllowListConfig.Equal(&other.AllowListConfig) &&
+		c.InitialRewardConfig.Equal(other.InitialRewardConfig)
+}
+
+// Configure configures [state] with the desired admins and fee distribution based on [c].
+// If [c] does not specify an initial reward config, fee recipients are allowed iff they are
+// allowed by [chainConfig]. Otherwise fees are burned.
+func (c *RewardManagerConfig) Configure(chainConfig ChainConfig, state StateDB, _ BlockContext) {
+	c.AllowListConfig.Configure(state, RewardManagerAddress)
+	switch {
+	case c.InitialRewardConfig != nil:
+		c.InitialRewardConfig.Configure(state)
+	case chainConfig.AllowedFeeRecipients():
+		EnableAllowFeeRecipients(state)
+	default:
+		DisableFeeRewards(state)
+	}
+}
+
+// Contract returns the singleton stateful precompiled contract to be used for the reward manager.
+func (c *RewardManagerConfig) Contract() StatefulPrecompiledContract {
+	return RewardManagerPrecompile
+}
+
+// GetRewardManagerAllowListStatus returns the role of [address] for the reward manager list in the block with [blockTimestamp].
+func GetRewardManagerAllowListStatus(stateDB StateDB, address common.Address, blockTimestamp *big.Int) AllowListRole {
+	return getAllowListStatus(stateDB, RewardManagerAddress, address, blockTimestamp)
+}
+
+// SetRewardManagerAllowListStatus sets the permissions of [address] to [role] for the
+// reward manager list. assumes [role] has already been verified as valid.
+func SetRewardManagerAllowListStatus(stateDB StateDB, address common.Address, role AllowListRole) {
+	setAllowListRole(stateDB, RewardManagerAddress, address, role)
+}
+
+// GetStoredRewardAddress returns the reward address stored in [stateDB] and whether block
+// producers are allowed to choose their own fee recipient. If fee recipients are allowed,
+// the returned address should be ignored.
+func GetStoredRewardAddress(stateDB StateDB) (common.Address, bool) {
+	val := stateDB.GetState(RewardManagerAddress, rewardAddressStorageKey)
+	return common.BytesToAddress(val.Bytes()), val == allowFeeRecipientsAddressValue
+}
+
+// StoreRewardAddress stores [rewardAddress] as the address that receives the fees of every block.
+func StoreRewardAddress(stateDB StateDB, rewardAddress common.Address) {
+	stateDB.SetState(RewardManagerAddress, rewardAddressStorageKey, rewardAddress.Hash())
+}
+
+// EnableAllowFeeRecipients allows block producers to choose the fee recipient of their blocks.
+func EnableAllowFeeRecipients(stateDB StateDB) {
+	stateDB.SetState(RewardManagerAddress, rewardAddressStorageKey, allowFeeRecipientsAddressValue)
+}
+
+// DisableFeeRewards burns the fees of every block by sending them to the blackhole address.
+func DisableFeeRewards(stateDB StateDB) {
+	StoreRewardAddress(stateDB, constants.BlackholeAddr)
+}
+
+// PackAllowFeeRecipients packs the allowFeeRecipients signature
+func PackAllowFeeRecipients() []byte {
+	return allowFeeRecipientsSignature
+}
+
+// PackAreFeeRecipientsAllowed packs the areFeeRecipientsAllowed signature
+func PackAreFeeRecipientsAllowed() []byte {
+	return areFeeRecipientsAllowedSignature
+}
+
+// PackCurrentRewardAddress packs the currentRewardAddress signature
+func PackCurrentRewardAddress() []byte {
+	return currentRewardAddressSignature
+}
+
+// PackDisableRewards packs the disableRewards signature
+func PackDisableRewards() []byte {
+	return disableRewardsSignature
+}
+
+// PackSetRewardAddress packs [rewardAddress] with the selector into the input for setRewardAddress
+func PackSetRewardAddress(rewardAddress common.Address) []byte {
+	input := make([]byte, 0, selectorLen+common.HashLength)
+	input = append(input, setRewardAddressSignature...)
+	input = append(input, rewardAddress.Hash().Bytes()...)
+	return input
+}
+
+// PackRewardAddressChangedEvent packs the topics and data of the RewardAddressChanged event
+// emitted when [sender] changes the reward address from [oldRewardAddress] to [newRewardAddress].
+func PackRewardAddressChangedEvent(sender, oldRewardAddress, newRewardAddress common.Address) ([]common.Hash, []byte) {
+	return []common.Hash{RewardAddressChangedEventID, sender.Hash(), oldRewardAddress.Hash(), newRewardAddress.Hash()}, []byte{}
+}
+
+// PackFeeRecipientsAllowedEvent packs the topics and data of the FeeRecipientsAllowed event
+// emitted when [sender] allows fee recipients.
+func PackFeeRecipientsAllowedEvent(sender common.Address) ([]common.Hash, []byte) {
+	return []common.Hash{FeeRecipientsAllowedEventID, sender.Hash()}, []byte{}
+}
+
+// PackRewardsDisabledEvent packs the topics and data of the RewardsDisabled event emitted
+// when [sender] disables rewards.
+func PackRewardsDisabledEvent(sender common.Address) ([]common.Hash, []byte) {
+	return []common.Hash{RewardsDisabledEventID, sender.Hash()}, []byte{}
+}
+
+// createRewardManagerSetter returns an execution function that verifies the caller is enabled on
+// the reward manager allow list, then calls [set] to update the fee distribution.
+// [set] returns the topics and data of the log to emit.
+func createRewardManagerSetter(gasCost uint64, inputLen int, errCannotSet error, set func(stateDB StateDB, caller common.Address, input []byte) ([]common.Hash, []byte, error)) RunStatefulPrecompileFunc {
+	return func(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+		if remainingGas, err = deductGas(suppliedGas, gasCost); err != nil {
+			return nil, 0, err
+		}
+
+		if readOnly {
+			return nil, remainingGas, vmerrs.ErrWriteProtection
+		}
+
+		if len(input) != inputLen {
+			return nil, remainingGas, fmt.Errorf("%w: %d", errInvalidRewardManagerInputSize, len(input))
+		}
+
+		stateDB := accessibleState.GetStateDB()
+		// Verify that the caller is in the allow list and therefore has the right to modify it
+		callerStatus := getAllowListStatus(stateDB, RewardManagerAddress, caller, accessibleState.GetBlockContext().Timestamp())
+		if !callerStatus.IsEnabled() {
+			return nil, remainingGas, fmt.Errorf("%w: %s", errCannotSet, caller)
+		}
+
+		topics, data, err := set(stateDB, caller, input)
+		if err != nil {
+			return nil, remainingGas, err
+		}
+		if logsEnabled(accessibleState) {
+			if remainingGas, err = addLog(accessibleState, RewardManagerAddress, topics, data, remainingGas); err != nil {
+				return nil, 0, err
+			}
+		}
+		// Return an empty output and the remaining gas
+		return []byte{}, remainingGas, nil
+	}
+}
+
+// allowFeeRecipients allows block producers to choose the fee recipient of their blocks.
+func allowFeeRecipients(stateDB StateDB, caller common.Address, _ []byte) ([]common.Hash, []byte, error) {
+	EnableAllowFeeRecipients(stateDB)
+	topics, data := PackFeeRecipientsAllowedEvent(caller)
+	return topics, data, nil
+}
+
+// disableRewards burns the fees of every block.
+func disableRewards(stateDB StateDB, caller common.Address, _ []byte) ([]common.Hash, []byte, error) {
+	DisableFeeRewards(stateDB)
+	topics, data := PackRewardsDisabledEvent(caller)
+	return topics, data, nil
+}
+
+// setRewardAddress parses [input] into the address that receives the fees of every block.
+func setRewardAddress(stateDB StateDB, caller common.Address, input []byte) ([]common.Hash, []byte, error) {
+	rewardAddress := common.BytesToAddress(input)
+	if rewardAddress == (common.Address{}) {
+		return nil, nil, ErrEmptyRewardAddress
+	}
+	oldRewardAddress, _ := GetStoredRewardAddress(stateDB)
+	StoreRewardAddress(stateDB, rewardAddress)
+	topics, data := PackRewardAddressChangedEvent(caller, oldRewardAddress, rewardAddress)
+	return topics, data, nil
+}
+
+// areFeeRecipientsAllowed returns true (1) if block producers may choose their fee recipient.
+func areFeeRecipientsAllowed(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, AreFeeRecipientsAllowedGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	_, allowFeeRecipients := GetStoredRewardAddress(accessibleState.GetStateDB())
+	output := common.Hash{}
+	if allowFeeRecipients {
+		output = common.BigToHash(common.Big1)
+	}
+	return output.Bytes(), remainingGas, nil
+}
+
+// currentRewardAddress returns the address that receives the fees of every block. If fee
+// recipients are allowed, this is the zero address.
+func currentRewardAddress(accessibleState PrecompileAccessibleState, caller common.Address, addr common.Address, input []byte, suppliedGas uint64, readOnly bool) (ret []byte, remainingGas uint64, err error) {
+	if remainingGas, err = deductGas(suppliedGas, CurrentRewardAddressGasCost); err != nil {
+		return nil, 0, err
+	}
+
+	rewardAddress, allowFeeRecipients := GetStoredRewardAddress(accessibleState.GetStateDB())
+	if allowFeeRecipients {
+		rewardAddress = common.Address{}
+	}
+	return rewardAddress.Hash().Bytes(), remainingGas, nil
+}
+
+// createRewardManagerPrecompile returns a StatefulPrecompiledContract with R/W control of an allow list at [precompileAddr] and
+// functions to control the fee distribution.
+func createRewardManagerPrecompile(precompileAddr common.Address) StatefulPrecompiledContract {
+	functions := createAllowListFunctions(precompileAddr)
+	functions = append(functions,
+		newStatefulPrecompileFunction(allowFeeRecipientsSignature, createRewardManagerSetter(AllowFeeRecipientsGasCost, 0, ErrCannotAllowFeeRecipients, allowFeeRecipients)),
+		newStatefulPrecompileFunction(areFeeRecipientsAllowedSignature, areFeeRecipientsAllowed),
+		newStatefulPrecompileFunction(currentRewardAddressSignature, currentRewardAddress),
+		newStatefulPrecompileFunction(disableRewardsSignature, createRewardManagerSetter(DisableRewardsGasCost, 0, ErrCannotDisableRewards, disableRewards)),
+		newStatefulPrecompileFunction(setRewardAddressSignature, createRewardManagerSetter(SetRewardAddressGasCost, setRewardAddressInputLen, ErrCannotSetRewardAddress, setRewardAddress)),
+	)
+	// Construct the contract with no fallback function.
+	return newStatefulPrecompileWithFunctionSelectors(nil, functions)
+}
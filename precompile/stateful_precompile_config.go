@@ -22,6 +22,8 @@ type StatefulPrecompileConfig interface {
 	IsDisabled() bool
 	// Equal returns true if the provided argument configures the same precompile with the same parameters.
 	Equal(StatefulPrecompileConfig) bool
+	// Verify returns an error if the config contains invalid parameters.
+	Verify() error
 	// Configure is called on the first block where the stateful precompile should be enabled.
 	// This allows the stateful precompile to configure its own state via [StateDB] and [BlockContext] as necessary.
 	// This function must be deterministic since it will impact the EVM state. If a change to the
@@ -37,6 +39,15 @@ type StatefulPrecompileConfig interface {
 	Contract() StatefulPrecompiledContract
 }
 
+// StatefulPrecompileDeconfigurer is implemented by the configs of stateful precompiles that
+// need to update the state before they are disabled, such as to return native coin held in
+// their balance, which is cleared when they are disabled.
+type StatefulPrecompileDeconfigurer interface {
+	// Deconfigure is called on the first block where the stateful precompile should be disabled,
+	// before its state is cleared. Like Configure, it must be deterministic.
+	Deconfigure(ChainConfig, StateDB, BlockContext)
+}
+
 // Configure sets the nonce and code to non-empty values then calls Configure on [precompileConfig] to make the necessary
 // state update to enable the StatefulPrecompile.
 // Assumes that [precompileConfig] is non-nil.
@@ -50,3 +61,13 @@ func Configure(chainConfig ChainConfig, blockContext BlockContext, precompileCon
 	state.SetCode(precompileConfig.Address(), []byte{0x1})
 	precompileConfig.Configure(chainConfig, state, blockContext)
 }
+
+// Deconfigure calls Deconfigure on [precompileConfig] if it implements [StatefulPrecompileDeconfigurer]
+// then clears the state of the precompile's address to disable the StatefulPrecompile.
+// Assumes that [precompileConfig] is non-nil.
+func Deconfigure(chainConfig ChainConfig, blockContext BlockContext, precompileConfig StatefulPrecompileConfig, state StateDB) {
+	if deconfigurer, ok := precompileConfig.(StatefulPrecompileDeconfigurer); ok {
+		deconfigurer.Deconfigure(chainConfig, state, blockContext)
+	}
+	state.Suicide(precompileConfig.Address())
+}
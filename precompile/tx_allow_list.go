@@ -18,6 +18,19 @@ var (
 	ErrSenderAddressNotAllowListed = errors.New("cannot issue transaction from non-allow listed address")
 )
 
+// TxAllowListConfigKey is the key of the tx allow list precompile's config in the
+// chain config and in precompile upgrades.
+const TxAllowListConfigKey = "txAllowListConfig"
+
+func init() {
+	mustRegisterModule(StatefulPrecompileModule{
+		ConfigKey: TxAllowListConfigKey,
+		Address:   TxAllowListAddress,
+		NewConfig: func() StatefulPrecompileConfig { return &TxAllowListConfig{} },
+		Order:     3,
+	})
+}
+
 // TxAllowListConfig wraps [AllowListConfig] and uses it to implement the StatefulPrecompileConfig
 // interface while adding in the TxAllowList specific precompile address.
 type TxAllowListConfig struct {
@@ -71,9 +84,9 @@ func (c *TxAllowListConfig) Equal(s StatefulPrecompileConfig) bool {
 }
 
 // GetTxAllowListStatus returns the role of [address] for the contract deployer
-// allow list.
-func GetTxAllowListStatus(stateDB StateDB, address common.Address) AllowListRole {
-	return getAllowListStatus(stateDB, TxAllowListAddress, address)
+// allow list in the block with [blockTimestamp].
+func GetTxAllowListStatus(stateDB StateDB, address common.Address, blockTimestamp *big.Int) AllowListRole {
+	return getAllowListStatus(stateDB, TxAllowListAddress, address, blockTimestamp)
 }
 
 // SetTxAllowListStatus sets the permissions of [address] to [role] for the
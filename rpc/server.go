@@ -57,7 +57,7 @@ type Server struct {
 	idgen           func() ID
 	run             int32
 	codecs          mapset.Set
-	maximumDuration time.Duration
+	maximumDuration int64 // atomic, nanoseconds
 }
 
 // NewServer creates a new server instance with no registered handlers.
@@ -70,7 +70,7 @@ func NewServer(maximumDuration time.Duration) *Server {
 		idgen:           randomIDGenerator(),
 		codecs:          mapset.NewSet(),
 		run:             1,
-		maximumDuration: maximumDuration,
+		maximumDuration: int64(maximumDuration),
 	}
 	// Register the default service providing meta information about the RPC service such
 	// as the services and methods it offers.
@@ -79,6 +79,12 @@ func NewServer(maximumDuration time.Duration) *Server {
 	return server
 }
 
+// SetMaximumDuration updates the maximum duration of incoming requests. It
+// applies to requests received after it returns.
+func (s *Server) SetMaximumDuration(maximumDuration time.Duration) {
+	atomic.StoreInt64(&s.maximumDuration, int64(maximumDuration))
+}
+
 // RegisterName creates a service for the given receiver type under the given name. When no
 // methods on the given receiver match the criteria to be either a RPC method or a
 // subscription an error is returned. Otherwise a new service is created and added to the
@@ -119,7 +125,7 @@ func (s *Server) serveSingleRequest(ctx context.Context, codec ServerCodec) {
 	}
 
 	h := newHandler(ctx, codec, s.idgen, &s.services)
-	h.deadlineContext = s.maximumDuration
+	h.deadlineContext = time.Duration(atomic.LoadInt64(&s.maximumDuration))
 	h.allowSubscribe = false
 	defer h.close(io.EOF, nil)
 
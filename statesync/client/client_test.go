@@ -0,0 +1,263 @@
+// (c) 2021-2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package statesyncclient
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/ava-labs/subnet-evm/consensus/dummy"
+	"github.com/ava-labs/subnet-evm/core"
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/ethdb/memorydb"
+	"github.com/ava-labs/subnet-evm/params"
+	"github.com/ava-labs/subnet-evm/plugin/evm/message"
+	clientstats "github.com/ava-labs/subnet-evm/statesync/client/stats"
+	"github.com/ava-labs/subnet-evm/statesync/handlers"
+	handlerstats "github.com/ava-labs/subnet-evm/statesync/handlers/stats"
+	"github.com/ava-labs/subnet-evm/trie"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+)
+
+type testBlockParser struct{}
+
+func (testBlockParser) ParseEthBlock(b []byte) (*types.Block, error) {
+	block := new(types.Block)
+	if err := rlp.DecodeBytes(b, block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// newTestClient returns a client backed by a MockNetwork serving requests
+// from the given trie database, code database and blocks.
+func newTestClient(t *testing.T, trieDB *trie.Database, codeDB *memorydb.Database, blocks []*types.Block) (*client, *MockNetwork) {
+	codec, err := message.BuildCodec()
+	if err != nil {
+		t.Fatal("error building codec", err)
+	}
+	blocksDB := make(map[common.Hash]*types.Block, len(blocks))
+	for _, blk := range blocks {
+		blocksDB[blk.Hash()] = blk
+	}
+	handlerStats := handlerstats.NewNoopHandlerStats()
+	handler := handlers.NewSyncHandler(
+		handlers.NewLeafsRequestHandler(trieDB, nil, handlerStats, codec),
+		handlers.NewBlockRequestHandler(func(hash common.Hash, height uint64) *types.Block {
+			blk, ok := blocksDB[hash]
+			if !ok || blk.NumberU64() != height {
+				return nil
+			}
+			return blk
+		}, codec, handlerStats),
+		handlers.NewCodeRequestHandler(codeDB, handlerStats, codec),
+	)
+	network := NewMockNetwork(codec, handler)
+	return NewClient(&ClientConfig{
+		NetworkClient: network,
+		Codec:         codec,
+		Stats:         clientstats.NewNoOpStats(),
+		BlockParser:   testBlockParser{},
+	}), network
+}
+
+func fillTestTrie(t *testing.T, trieDB *trie.Database, numKeys int) (common.Hash, [][]byte) {
+	tr, err := trie.New(common.Hash{}, trieDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// set random seed to ensure consistent data every time
+	rand.Seed(1)
+
+	keys := make([][]byte, 0, numKeys)
+	for i := 0; i < numKeys; i++ {
+		data := make([]byte, rand.Intn(32)+32)
+		if _, err := rand.Read(data); err != nil {
+			t.Fatal("error reading random bytes", err)
+		}
+		key := crypto.Keccak256(data)
+		assert.NoError(t, tr.TryUpdate(key, data))
+		keys = append(keys, key)
+	}
+	root, _, err := tr.Commit(nil)
+	if err != nil {
+		t.Fatal("could not commit trie", err)
+	}
+	if err := trieDB.Commit(root, false, nil); err != nil {
+		t.Fatal("error committing trieDB", err)
+	}
+	return root, keys
+}
+
+func TestGetLeafs(t *testing.T) {
+	trieDB := trie.NewDatabase(memorydb.New())
+	root, keys := fillTestTrie(t, trieDB, 5_000)
+	client, _ := newTestClient(t, trieDB, memorydb.New(), nil)
+
+	var (
+		start    = bytes.Repeat([]byte{0x00}, common.HashLength)
+		end      = bytes.Repeat([]byte{0xff}, common.HashLength)
+		received = 0
+	)
+	for {
+		response, err := client.GetLeafs(context.Background(), message.LeafsRequest{
+			Root:  root,
+			Start: start,
+			End:   end,
+			Limit: 1024,
+		})
+		assert.NoError(t, err)
+		assert.LessOrEqual(t, len(response.Keys), 1024)
+		received += len(response.Keys)
+		if !response.More {
+			break
+		}
+		start = common.CopyBytes(response.Keys[len(response.Keys)-1])
+		start[len(start)-1]++ // keys are hashes, so this never overflows in practice
+	}
+	assert.Equal(t, len(keys), received)
+}
+
+func TestGetLeafsRetriesInvalidResponse(t *testing.T) {
+	trieDB := trie.NewDatabase(memorydb.New())
+	root, _ := fillTestTrie(t, trieDB, 1_000)
+	client, network := newTestClient(t, trieDB, memorydb.New(), nil)
+
+	tests := map[string]struct {
+		modifyFn    func(response *message.LeafsResponse)
+		expectedErr error
+	}{
+		"missing key": {
+			modifyFn: func(response *message.LeafsResponse) {
+				response.Keys = append(response.Keys[:10], response.Keys[11:]...)
+				response.Vals = append(response.Vals[:10], response.Vals[11:]...)
+			},
+			expectedErr: errInvalidRangeProof,
+		},
+		"modified value": {
+			modifyFn: func(response *message.LeafsResponse) {
+				response.Vals[5] = []byte("modified")
+			},
+			expectedErr: errInvalidRangeProof,
+		},
+		"missing proof": {
+			modifyFn: func(response *message.LeafsResponse) {
+				response.ProofKeys = nil
+				response.ProofVals = nil
+			},
+			expectedErr: errInvalidRangeProof,
+		},
+		"too many leaves": {
+			modifyFn: func(response *message.LeafsResponse) {
+				response.Keys = append(response.Keys, response.Keys[0])
+				response.Vals = append(response.Vals, response.Vals[0])
+			},
+			expectedErr: errTooManyLeaves,
+		},
+	}
+	for name, test := range tests {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			network.Intercept = func(_ message.Request, responseBytes []byte) ([]byte, error) {
+				var response message.LeafsResponse
+				if _, err := client.codec.Unmarshal(responseBytes, &response); err != nil {
+					return nil, err
+				}
+				test.modifyFn(&response)
+				return client.codec.Marshal(message.Version, response)
+			}
+			reported := len(network.InvalidResponses())
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			_, err := client.GetLeafs(ctx, message.LeafsRequest{
+				Root:  root,
+				Start: bytes.Repeat([]byte{0x00}, common.HashLength),
+				End:   bytes.Repeat([]byte{0xff}, common.HashLength),
+				Limit: 100,
+			})
+			assert.ErrorIs(t, err, test.expectedErr)
+
+			// every invalid response is reported against the peer that sent it
+			invalidResponses := network.InvalidResponses()[reported:]
+			assert.NotEmpty(t, invalidResponses)
+			for _, nodeID := range invalidResponses {
+				assert.Equal(t, network.NodeID, nodeID)
+			}
+		})
+	}
+}
+
+func TestGetCode(t *testing.T) {
+	codeDB := memorydb.New()
+	code := []byte("some code goes here")
+	codeHash := crypto.Keccak256Hash(code)
+	rawdb.WriteCode(codeDB, codeHash, code)
+
+	client, network := newTestClient(t, trie.NewDatabase(memorydb.New()), codeDB, nil)
+
+	received, err := client.GetCode(context.Background(), codeHash)
+	assert.NoError(t, err)
+	assert.Equal(t, code, received)
+
+	// a response that does not match the requested hash is retried until the context expires
+	network.Intercept = func(_ message.Request, _ []byte) ([]byte, error) {
+		return client.codec.Marshal(message.Version, message.CodeResponse{Data: []byte("other code")})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = client.GetCode(ctx, codeHash)
+	assert.ErrorIs(t, err, errHashMismatch)
+	assert.Greater(t, network.NumCalls(), uint32(2))
+
+	// code larger than the maximum code size is rejected
+	network.Intercept = func(_ message.Request, _ []byte) ([]byte, error) {
+		return client.codec.Marshal(message.Version, message.CodeResponse{Data: make([]byte, params.MaxCodeSize+1)})
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = client.GetCode(ctx, crypto.Keccak256Hash(make([]byte, params.MaxCodeSize+1)))
+	assert.ErrorIs(t, err, errMaxCodeSizeExceeded)
+}
+
+func TestGetBlocks(t *testing.T) {
+	gspec := &core.Genesis{
+		Config: params.TestChainConfig,
+	}
+	memdb := memorydb.New()
+	genesis := gspec.MustCommit(memdb)
+	engine := dummy.NewETHFaker()
+	blocks, _, err := core.GenerateChain(params.TestChainConfig, genesis, engine, memdb, 96, 0, func(i int, b *core.BlockGen) {})
+	if err != nil {
+		t.Fatal("unexpected error when generating test blockchain", err)
+	}
+	client, network := newTestClient(t, trie.NewDatabase(memorydb.New()), memorydb.New(), blocks)
+
+	last := blocks[len(blocks)-1]
+	received, err := client.GetBlocks(context.Background(), last.Hash(), last.NumberU64(), 32)
+	assert.NoError(t, err)
+	assert.Len(t, received, 32)
+	for i, blk := range received {
+		assert.Equal(t, blocks[len(blocks)-1-i].Hash(), blk.Hash())
+	}
+
+	// a response that does not start with the requested block is retried until the context expires
+	network.Intercept = func(request message.Request, _ []byte) ([]byte, error) {
+		blockBytes, err := rlp.EncodeToBytes(blocks[0])
+		if err != nil {
+			return nil, err
+		}
+		return client.codec.Marshal(message.Version, message.BlockResponse{Blocks: [][]byte{blockBytes}})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = client.GetBlocks(ctx, last.Hash(), last.NumberU64(), 32)
+	assert.ErrorIs(t, err, errHashMismatch)
+}
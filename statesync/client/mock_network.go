@@ -0,0 +1,109 @@
+// (c) 2021-2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package statesyncclient
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ava-labs/avalanchego/codec"
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/version"
+
+	"github.com/ava-labs/subnet-evm/peer"
+	"github.com/ava-labs/subnet-evm/plugin/evm/message"
+)
+
+var _ peer.Client = &MockNetwork{}
+
+var errNoResponse = errors.New("handler dropped request")
+
+// MockNetwork is a peer.Client that serves requests locally with a
+// message.RequestHandler. It is intended for use in tests.
+type MockNetwork struct {
+	codec   codec.Manager
+	handler message.RequestHandler
+
+	// Intercept, if set, is called with each request and the response produced by
+	// the handler. The returned response and error are passed to the caller instead.
+	Intercept func(request message.Request, response []byte) ([]byte, error)
+
+	// NodeID is the peer that answers the requests sent with RequestAny.
+	NodeID ids.NodeID
+
+	numCalls uint32
+
+	lock             sync.Mutex
+	invalidResponses []ids.NodeID
+}
+
+// NewMockNetwork returns a MockNetwork serving requests with [handler]
+func NewMockNetwork(codec codec.Manager, handler message.RequestHandler) *MockNetwork {
+	return &MockNetwork{
+		codec:   codec,
+		handler: handler,
+		NodeID:  ids.GenerateTestNodeID(),
+	}
+}
+
+func (m *MockNetwork) RequestAny(_ *version.Application, request []byte) ([]byte, ids.NodeID, error) {
+	response, err := m.Request(m.NodeID, request)
+	return response, m.NodeID, err
+}
+
+func (m *MockNetwork) RequestAnyWithContext(ctx context.Context, _ *version.Application, request []byte) ([]byte, ids.NodeID, error) {
+	response, err := m.RequestWithContext(ctx, m.NodeID, request)
+	return response, m.NodeID, err
+}
+
+func (m *MockNetwork) Request(nodeID ids.NodeID, requestBytes []byte) ([]byte, error) {
+	return m.RequestWithContext(context.Background(), nodeID, requestBytes)
+}
+
+func (m *MockNetwork) RequestWithContext(ctx context.Context, nodeID ids.NodeID, requestBytes []byte) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	atomic.AddUint32(&m.numCalls, 1)
+
+	request, err := message.BytesToRequest(m.codec, requestBytes)
+	if err != nil {
+		return nil, err
+	}
+	// A peer serves requests with its own deadline, not the one of the requester.
+	response, err := request.Handle(context.Background(), nodeID, 0, m.handler)
+	if err == nil && len(response) == 0 {
+		err = errNoResponse
+	}
+	if m.Intercept != nil {
+		return m.Intercept(request, response)
+	}
+	return response, err
+}
+
+func (m *MockNetwork) Gossip([]byte) error {
+	return nil
+}
+
+func (m *MockNetwork) ReportInvalidResponse(nodeID ids.NodeID) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.invalidResponses = append(m.invalidResponses, nodeID)
+}
+
+// NumCalls returns the number of requests sent through the network
+func (m *MockNetwork) NumCalls() uint32 {
+	return atomic.LoadUint32(&m.numCalls)
+}
+
+// InvalidResponses returns the nodeIDs reported for each invalid response
+func (m *MockNetwork) InvalidResponses() []ids.NodeID {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return append([]ids.NodeID(nil), m.invalidResponses...)
+}
@@ -0,0 +1,99 @@
+// (c) 2021-2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package statesync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/ethdb"
+	statesyncclient "github.com/ava-labs/subnet-evm/statesync/client"
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/sync/errgroup"
+)
+
+// codeSyncer fetches contract code from peers for the code hashes it
+// receives. A marker is persisted for each code hash before it is handed
+// to the codeSyncer, so pending code survives restarts.
+type codeSyncer struct {
+	db         ethdb.Database
+	client     statesyncclient.Client
+	numWorkers int
+	codeHashes chan common.Hash
+}
+
+func newCodeSyncer(db ethdb.Database, client statesyncclient.Client, numWorkers int) *codeSyncer {
+	return &codeSyncer{
+		db:         db,
+		client:     client,
+		numWorkers: numWorkers,
+		codeHashes: make(chan common.Hash, numWorkers),
+	}
+}
+
+// start launches the workers of the code syncer in [eg]. The workers exit
+// once [notifyAccountTrieCompleted] is called and all queued code has
+// been fetched.
+func (c *codeSyncer) start(ctx context.Context, eg *errgroup.Group) {
+	for i := 0; i < c.numWorkers; i++ {
+		eg.Go(func() error {
+			for codeHash := range c.codeHashes {
+				if err := c.fetchCode(ctx, codeHash); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+}
+
+// addPendingCode queues the code markers persisted by a previous run
+// of the syncer.
+func (c *codeSyncer) addPendingCode(ctx context.Context) error {
+	it := rawdb.NewCodeToFetchIterator(c.db)
+	defer it.Release()
+
+	var codeHashes []common.Hash
+	for it.Next() {
+		codeHashes = append(codeHashes, common.BytesToHash(it.Key()[len(rawdb.CodeToFetchPrefix):]))
+	}
+	if err := it.Error(); err != nil {
+		return fmt.Errorf("failed to iterate code markers: %w", err)
+	}
+	return c.addCode(ctx, codeHashes)
+}
+
+// addCode queues [codeHashes] to be fetched. The caller must have
+// persisted a marker for each code hash.
+func (c *codeSyncer) addCode(ctx context.Context, codeHashes []common.Hash) error {
+	for _, codeHash := range codeHashes {
+		select {
+		case c.codeHashes <- codeHash:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// notifyAccountTrieCompleted signals that no more code will be queued.
+func (c *codeSyncer) notifyAccountTrieCompleted() {
+	close(c.codeHashes)
+}
+
+// fetchCode fetches the code for [codeHash] unless it is already present
+// and removes its marker.
+func (c *codeSyncer) fetchCode(ctx context.Context, codeHash common.Hash) error {
+	batch := c.db.NewBatch()
+	if !rawdb.HasCodeWithPrefix(c.db, codeHash) {
+		code, err := c.client.GetCode(ctx, codeHash)
+		if err != nil {
+			return err
+		}
+		rawdb.WriteCode(batch, codeHash, code)
+	}
+	rawdb.DeleteCodeToFetch(batch, codeHash)
+	return batch.Write()
+}
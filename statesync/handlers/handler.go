@@ -41,3 +41,9 @@ func (s *syncHandler) HandleBlockRequest(ctx context.Context, nodeID ids.NodeID,
 func (s *syncHandler) HandleCodeRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, codeRequest message.CodeRequest) ([]byte, error) {
 	return s.codeRequestHandler.OnCodeRequest(ctx, nodeID, requestID, codeRequest)
 }
+
+// HandleTxsPullRequest drops the request, since mempool transactions are not
+// served by the state sync handlers.
+func (s *syncHandler) HandleTxsPullRequest(ctx context.Context, nodeID ids.NodeID, requestID uint32, request message.TxsPullRequest) ([]byte, error) {
+	return nil, nil
+}
@@ -8,6 +8,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/ava-labs/subnet-evm/core/state/snapshot"
 	"github.com/ava-labs/subnet-evm/core/types"
 
 	"github.com/ava-labs/avalanchego/codec"
@@ -19,6 +20,7 @@ import (
 	"github.com/ava-labs/subnet-evm/trie"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 // Maximum number of leaves to return in a message.LeafsResponse
@@ -26,19 +28,30 @@ import (
 // in message.LeafsRequest if it is greater than this value
 const maxLeavesLimit = uint16(1024)
 
+// SnapshotProvider provides access to the snapshot tree used to serve
+// leafs requests without iterating the trie
+type SnapshotProvider interface {
+	Snapshots() *snapshot.Tree
+}
+
 // LeafsRequestHandler is a peer.RequestHandler for types.LeafsRequest
 // serving requested trie data
 type LeafsRequestHandler struct {
-	trieDB *trie.Database
-	stats  stats.HandlerStats
-	codec  codec.Manager
+	trieDB           *trie.Database
+	snapshotProvider SnapshotProvider
+	stats            stats.HandlerStats
+	codec            codec.Manager
 }
 
-func NewLeafsRequestHandler(trieDB *trie.Database, syncerStats stats.HandlerStats, codec codec.Manager) *LeafsRequestHandler {
+// NewLeafsRequestHandler returns a LeafsRequestHandler serving leafs from [trieDB].
+// If [snapshotProvider] is non-nil, leafs of the account trie are read from the
+// snapshot when it has a layer matching the requested root.
+func NewLeafsRequestHandler(trieDB *trie.Database, snapshotProvider SnapshotProvider, syncerStats stats.HandlerStats, codec codec.Manager) *LeafsRequestHandler {
 	return &LeafsRequestHandler{
-		trieDB: trieDB,
-		stats:  syncerStats,
-		codec:  codec,
+		trieDB:           trieDB,
+		snapshotProvider: snapshotProvider,
+		stats:            syncerStats,
+		codec:            codec,
 	}
 }
 
@@ -78,11 +91,6 @@ func (lrh *LeafsRequestHandler) OnLeafsRequest(ctx context.Context, nodeID ids.N
 		lrh.stats.UpdateLeafsReturned(leafCount)
 	}()
 
-	// create iterator to iterate the trie
-	// Note that leafsRequest.Start could be an original start point
-	// or leafsResponse.NextKey from partial response to previous request
-	it := trie.NewIterator(t.NodeIterator(leafsRequest.Start))
-
 	// override limit if it is greater than the configured maxLeavesLimit
 	limit := leafsRequest.Limit
 	if limit > maxLeavesLimit {
@@ -90,6 +98,24 @@ func (lrh *LeafsRequestHandler) OnLeafsRequest(ctx context.Context, nodeID ids.N
 	}
 
 	var leafsResponse message.LeafsResponse
+	// Only requests for the account trie can be served from the snapshot, which
+	// are recognized by the snapshot having a layer for the requested root.
+	snaps := lrh.snapshots()
+	hasLayer := snaps != nil && snaps.Snapshot(leafsRequest.Root) != nil
+	if hasLayer {
+		if lrh.fillFromSnapshot(ctx, snaps, t, leafsRequest, limit, &leafsResponse) {
+			lrh.stats.IncSnapshotReadHit()
+			leafCount = uint16(len(leafsResponse.Keys))
+			return lrh.marshalResponse(nodeID, requestID, leafsRequest, leafsResponse, startTime)
+		}
+		lrh.stats.IncSnapshotReadMiss()
+		leafsResponse = message.LeafsResponse{}
+	}
+
+	// create iterator to iterate the trie
+	// Note that leafsRequest.Start could be an original start point
+	// or leafsResponse.NextKey from partial response to previous request
+	it := trie.NewIterator(t.NodeIterator(leafsRequest.Start))
 	for it.Next() {
 		// if we're at the end, break this loop
 		if bytes.Compare(it.Key, leafsRequest.End) > 0 {
@@ -123,16 +149,118 @@ func (lrh *LeafsRequestHandler) OnLeafsRequest(ctx context.Context, nodeID ids.N
 		return nil, nil
 	}
 
+	// Requests for an account trie whose root has no snapshot layer, such as a
+	// root older than the snapshot, could not be served from the snapshot either.
+	// Unlike storage tries, these are recognized by their leafs being accounts.
+	if snaps != nil && !hasLayer && isAccountLeaf(leafsResponse.Vals) {
+		lrh.stats.IncSnapshotReadMiss()
+	}
+
+	return lrh.marshalResponse(nodeID, requestID, leafsRequest, leafsResponse, startTime)
+}
+
+func (lrh *LeafsRequestHandler) marshalResponse(nodeID ids.NodeID, requestID uint32, leafsRequest message.LeafsRequest, leafsResponse message.LeafsResponse, startTime time.Time) ([]byte, error) {
 	responseBytes, err := lrh.codec.Marshal(message.Version, leafsResponse)
 	if err != nil {
 		log.Debug("failed to marshal LeafsResponse, dropping request", "nodeID", nodeID, "requestID", requestID, "request", leafsRequest, "err", err)
 		return nil, nil
 	}
 
-	log.Debug("handled leafsRequest", "time", time.Since(startTime), "leafs", leafCount, "proofLen", len(leafsResponse.ProofKeys))
+	log.Debug("handled leafsRequest", "time", time.Since(startTime), "leafs", len(leafsResponse.Keys), "proofLen", len(leafsResponse.ProofKeys))
 	return responseBytes, nil
 }
 
+// snapshots returns the snapshot tree of [snapshotProvider], or nil if there is none.
+func (lrh *LeafsRequestHandler) snapshots() *snapshot.Tree {
+	if lrh.snapshotProvider == nil {
+		return nil
+	}
+	return lrh.snapshotProvider.Snapshots()
+}
+
+// isAccountLeaf returns true if the first of [vals] is an RLP encoded account,
+// meaning that the leafs are from an account trie rather than a storage trie.
+func isAccountLeaf(vals [][]byte) bool {
+	if len(vals) == 0 {
+		return false
+	}
+	var acc types.StateAccount
+	return rlp.DecodeBytes(vals[0], &acc) == nil
+}
+
+// fillFromSnapshot reads up to [limit] leafs of the account trie at the requested
+// root from [snaps] into [leafsResponse] and adds the edge proofs from [t].
+// Returns false if the snapshot is still being generated or became stale during
+// iteration, or if the leafs read from the snapshot cannot be verified against
+// the proof. In that case the caller should discard [leafsResponse] and serve the
+// request from the trie.
+func (lrh *LeafsRequestHandler) fillFromSnapshot(ctx context.Context, snaps *snapshot.Tree, t *trie.Trie, leafsRequest message.LeafsRequest, limit uint16, leafsResponse *message.LeafsResponse) bool {
+	// Snapshot keys are account hashes, so only requests for hash length keys
+	// can be served by seeking the snapshot.
+	if len(leafsRequest.Start) != 0 && len(leafsRequest.Start) != common.HashLength {
+		return false
+	}
+	it, err := snaps.AccountIterator(leafsRequest.Root, common.BytesToHash(leafsRequest.Start), false)
+	if err != nil {
+		return false
+	}
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Hash().Bytes()
+		if bytes.Compare(key, leafsRequest.End) > 0 {
+			break
+		}
+		if len(leafsResponse.Keys) >= int(limit) || ctx.Err() != nil {
+			break
+		}
+		// The snapshot stores accounts in the slim format, whereas the trie
+		// leafs are the full RLP encoded accounts.
+		val, err := snapshot.FullAccountRLP(it.Account())
+		if err != nil {
+			return false
+		}
+		leafsResponse.Keys = append(leafsResponse.Keys, key)
+		leafsResponse.Vals = append(leafsResponse.Vals, val)
+	}
+	if it.Error() != nil {
+		return false
+	}
+	if len(leafsResponse.Keys) == 0 && ctx.Err() != nil {
+		return false
+	}
+
+	if err := lrh.addProofKeys(t, &leafsRequest, leafsResponse); err != nil {
+		return false
+	}
+	return verifyLeafsResponse(leafsRequest, leafsResponse) == nil
+}
+
+// verifyLeafsResponse checks the leafs in [leafsResponse] are exactly the leafs of the
+// trie at the requested root between the request start and the last key, as proven by
+// the proof in the response.
+func verifyLeafsResponse(leafsRequest message.LeafsRequest, leafsResponse *message.LeafsResponse) error {
+	proof := memorydb.New()
+	defer proof.Close() // Closing the memorydb should never error
+
+	for i, proofKey := range leafsResponse.ProofKeys {
+		if err := proof.Put(proofKey, leafsResponse.ProofVals[i]); err != nil {
+			return err
+		}
+	}
+
+	start := leafsRequest.Start
+	end := leafsRequest.End
+	if len(leafsResponse.Keys) > 0 {
+		end = leafsResponse.Keys[len(leafsResponse.Keys)-1]
+		if len(start) == 0 {
+			start = bytes.Repeat([]byte{0x00}, len(end))
+		}
+	}
+	_, err := trie.VerifyRangeProof(leafsRequest.Root, start, end, leafsResponse.Keys, leafsResponse.Vals, proof)
+	return err
+}
+
 func (lrh *LeafsRequestHandler) addProofKeys(t *trie.Trie, leafsRequest *message.LeafsRequest, leafsResponse *message.LeafsResponse) error {
 	proof := memorydb.New()
 	defer proof.Close() // Closing the memorydb should never error
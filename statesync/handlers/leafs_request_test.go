@@ -6,16 +6,21 @@ package handlers
 import (
 	"bytes"
 	"context"
+	"math/big"
 	"math/rand"
 	"testing"
 
 	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/state/snapshot"
+	"github.com/ava-labs/subnet-evm/core/types"
 	"github.com/ava-labs/subnet-evm/ethdb/memorydb"
 	"github.com/ava-labs/subnet-evm/plugin/evm/message"
 	"github.com/ava-labs/subnet-evm/statesync/handlers/stats"
 	"github.com/ava-labs/subnet-evm/trie"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -56,7 +61,7 @@ func TestLeafsRequestHandler_OnLeafsRequest(t *testing.T) {
 		t.Fatal("error committing trieDB", err)
 	}
 
-	leafsHandler := NewLeafsRequestHandler(trieDB, stats, codec)
+	leafsHandler := NewLeafsRequestHandler(trieDB, nil, stats, codec)
 
 	tests := map[string]struct {
 		prepareTestFn    func() (context.Context, message.LeafsRequest)
@@ -237,3 +242,163 @@ func TestLeafsRequestHandler_OnLeafsRequest(t *testing.T) {
 		})
 	}
 }
+
+type snapshotTestStats struct {
+	stats.HandlerStats
+	hits, misses int
+}
+
+func (s *snapshotTestStats) IncSnapshotReadHit()  { s.hits++ }
+func (s *snapshotTestStats) IncSnapshotReadMiss() { s.misses++ }
+
+type testSnapshotProvider struct {
+	snapshots *snapshot.Tree
+}
+
+func (t *testSnapshotProvider) Snapshots() *snapshot.Tree {
+	return t.snapshots
+}
+
+func TestLeafsRequestHandler_OnLeafsRequestFromSnapshot(t *testing.T) {
+	codec, err := message.BuildCodec()
+	if err != nil {
+		t.Fatal("unexpected error building codec", err)
+	}
+
+	memdb := memorydb.New()
+	trieDB := trie.NewDatabase(memdb)
+	tr, err := trie.New(common.Hash{}, trieDB)
+	assert.NoError(t, err)
+
+	// populate the account trie and the snapshot with the same accounts
+	emptyCodeHash := crypto.Keccak256(nil)
+	for i := 0; i < 2_000; i++ {
+		acc := types.StateAccount{
+			Nonce:    uint64(i),
+			Balance:  big.NewInt(int64(i + 1)),
+			Root:     types.EmptyRootHash,
+			CodeHash: emptyCodeHash,
+		}
+		accBytes, err := rlp.EncodeToBytes(&acc)
+		assert.NoError(t, err)
+
+		key := crypto.Keccak256Hash(big.NewInt(int64(i)).Bytes())
+		assert.NoError(t, tr.TryUpdate(key[:], accBytes))
+		rawdb.WriteAccountSnapshot(memdb, key, snapshot.SlimAccountRLP(acc.Nonce, acc.Balance, acc.Root, acc.CodeHash))
+	}
+	root, _, err := tr.Commit(nil)
+	assert.NoError(t, err)
+	assert.NoError(t, trieDB.Commit(root, false, nil))
+
+	// populate a storage trie, which has no snapshot layer
+	storageTrie, err := trie.New(common.Hash{}, trieDB)
+	assert.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		val, err := rlp.EncodeToBytes(big.NewInt(int64(i + 1)).Bytes())
+		assert.NoError(t, err)
+		key := crypto.Keccak256Hash(big.NewInt(int64(i)).Bytes())
+		assert.NoError(t, storageTrie.TryUpdate(key[:], val))
+	}
+	storageRoot, _, err := storageTrie.Commit(nil)
+	assert.NoError(t, err)
+	assert.NoError(t, trieDB.Commit(storageRoot, false, nil))
+
+	snapshotProvider := &testSnapshotProvider{snapshots: snapshot.NewTestTree(memdb, common.Hash{1}, root)}
+	trieHandler := NewLeafsRequestHandler(trieDB, nil, stats.NewNoopHandlerStats(), codec)
+
+	tests := map[string]struct {
+		request        message.LeafsRequest
+		prepareFn      func(t *testing.T)
+		expectedHits   int
+		expectedMisses int
+	}{
+		"full range served from snapshot": {
+			request: message.LeafsRequest{
+				Root:  root,
+				Start: bytes.Repeat([]byte{0x00}, common.HashLength),
+				End:   bytes.Repeat([]byte{0xff}, common.HashLength),
+				Limit: maxLeavesLimit,
+			},
+			expectedHits: 1,
+		},
+		"partial range served from snapshot": {
+			request: message.LeafsRequest{
+				Root:  root,
+				Start: bytes.Repeat([]byte{0x40}, common.HashLength),
+				End:   bytes.Repeat([]byte{0x80}, common.HashLength),
+				Limit: 100,
+			},
+			expectedHits: 1,
+		},
+		"account root without a snapshot layer is served from trie": {
+			request: message.LeafsRequest{
+				Root:  root,
+				Start: bytes.Repeat([]byte{0x00}, common.HashLength),
+				End:   bytes.Repeat([]byte{0xff}, common.HashLength),
+				Limit: maxLeavesLimit,
+			},
+			prepareFn: func(t *testing.T) {
+				snapshotProvider.snapshots = snapshot.NewTestTree(memdb, common.Hash{1}, common.Hash{2})
+				t.Cleanup(func() {
+					snapshotProvider.snapshots = snapshot.NewTestTree(memdb, common.Hash{1}, root)
+				})
+			},
+			expectedMisses: 1,
+		},
+		"storage root is served from trie": {
+			request: message.LeafsRequest{
+				Root:  storageRoot,
+				Start: bytes.Repeat([]byte{0x00}, common.HashLength),
+				End:   bytes.Repeat([]byte{0xff}, common.HashLength),
+				Limit: maxLeavesLimit,
+			},
+		},
+		"stale snapshot falls back to trie": {
+			request: message.LeafsRequest{
+				Root:  root,
+				Start: bytes.Repeat([]byte{0x00}, common.HashLength),
+				End:   bytes.Repeat([]byte{0xff}, common.HashLength),
+				Limit: maxLeavesLimit,
+			},
+			prepareFn: func(t *testing.T) {
+				// overwrite the first account in the snapshot so it no longer
+				// matches the trie
+				it := memdb.NewIterator(rawdb.SnapshotAccountPrefix, nil)
+				assert.True(t, it.Next())
+				key := common.CopyBytes(it.Key())
+				val := common.CopyBytes(it.Value())
+				it.Release()
+				assert.NoError(t, memdb.Put(key, snapshot.SlimAccountRLP(1_000_000, big.NewInt(1), types.EmptyRootHash, emptyCodeHash)))
+				t.Cleanup(func() {
+					assert.NoError(t, memdb.Put(key, val))
+				})
+			},
+			expectedMisses: 1,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if test.prepareFn != nil {
+				test.prepareFn(t)
+			}
+			handlerStats := &snapshotTestStats{HandlerStats: stats.NewNoopHandlerStats()}
+			snapshotHandler := NewLeafsRequestHandler(trieDB, snapshotProvider, handlerStats, codec)
+
+			response, err := snapshotHandler.OnLeafsRequest(context.Background(), ids.GenerateTestNodeID(), 1, test.request)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedHits, handlerStats.hits)
+			assert.Equal(t, test.expectedMisses, handlerStats.misses)
+
+			// the response must be identical to the one served from the trie
+			expectedResponse, err := trieHandler.OnLeafsRequest(context.Background(), ids.GenerateTestNodeID(), 1, test.request)
+			assert.NoError(t, err)
+			assert.NotEmpty(t, response)
+			assert.Equal(t, expectedResponse, response)
+
+			var leafsResponse message.LeafsResponse
+			_, err = codec.Unmarshal(response, &leafsResponse)
+			assert.NoError(t, err)
+			assert.NoError(t, verifyLeafsResponse(test.request, &leafsResponse))
+		})
+	}
+}
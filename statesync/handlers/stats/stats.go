@@ -28,6 +28,8 @@ type HandlerStats interface {
 	UpdateLeafsReturned(numLeafs uint16)
 	UpdateLeafsRequestProcessingTime(duration time.Duration)
 	IncMissingRoot()
+	IncSnapshotReadHit()
+	IncSnapshotReadMiss()
 }
 
 type handlerStats struct {
@@ -48,6 +50,8 @@ type handlerStats struct {
 	leafsReturned              metrics.Histogram
 	leafsRequestProcessingTime metrics.Timer
 	missingRoot                metrics.Counter
+	snapshotReadHit            metrics.Counter
+	snapshotReadMiss           metrics.Counter
 }
 
 func (h *handlerStats) IncBlockRequest() {
@@ -98,6 +102,14 @@ func (h *handlerStats) IncMissingRoot() {
 	h.missingRoot.Inc(1)
 }
 
+func (h *handlerStats) IncSnapshotReadHit() {
+	h.snapshotReadHit.Inc(1)
+}
+
+func (h *handlerStats) IncSnapshotReadMiss() {
+	h.snapshotReadMiss.Inc(1)
+}
+
 func NewHandlerStats() HandlerStats {
 	return &handlerStats{
 		// initialise block request stats
@@ -117,6 +129,8 @@ func NewHandlerStats() HandlerStats {
 		leafsRequestProcessingTime: metrics.GetOrRegisterTimer("leafs_request_processing_time", nil),
 		leafsReturned:              metrics.GetOrRegisterHistogram("leafs_returned", nil, metrics.NewExpDecaySample(1028, 0.015)),
 		missingRoot:                metrics.GetOrRegisterCounter("missing_root", nil),
+		snapshotReadHit:            metrics.GetOrRegisterCounter("leafs_snapshot_read_hit", nil),
+		snapshotReadMiss:           metrics.GetOrRegisterCounter("leafs_snapshot_read_miss", nil),
 	}
 }
 
@@ -140,3 +154,5 @@ func (n *noopHandlerStats) IncLeafsRequest()                               {}
 func (n *noopHandlerStats) UpdateLeafsRequestProcessingTime(time.Duration) {}
 func (n *noopHandlerStats) UpdateLeafsReturned(uint16)                     {}
 func (n *noopHandlerStats) IncMissingRoot()                                {}
+func (n *noopHandlerStats) IncSnapshotReadHit()                            {}
+func (n *noopHandlerStats) IncSnapshotReadMiss()                           {}
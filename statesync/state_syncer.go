@@ -0,0 +1,243 @@
+// (c) 2021-2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package statesync
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/ethdb"
+	statesyncclient "github.com/ava-labs/subnet-evm/statesync/client"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"golang.org/x/sync/errgroup"
+)
+
+var emptyCodeHash = crypto.Keccak256Hash(nil)
+
+const (
+	defaultNumCodeWorkers    = 4
+	defaultNumStorageWorkers = 4
+	defaultLeafsRequestLimit = 1024
+)
+
+// StateSyncerConfig holds the parameters of the state syncer
+type StateSyncerConfig struct {
+	Root              common.Hash // root of the account trie to sync
+	Client            statesyncclient.Client
+	DB                ethdb.Database
+	BatchSize         int    // size in bytes of the batches written to [DB]
+	NumCodeWorkers    int    // number of concurrent code requests, defaults to 4
+	NumStorageWorkers int    // number of storage tries fetched concurrently, defaults to 4
+	LeafsRequestLimit uint16 // number of leafs requested in each LeafsRequest, defaults to 1024
+}
+
+// stateSyncer syncs the account trie with [root], the storage tries of all
+// accounts and the code of all contracts. Progress is persisted to disk so
+// the sync resumes where it left off after a restart:
+//   - the account and storage tries are reconstructed with a stack trie,
+//     whose serialized state is written along with the trie nodes.
+//   - a marker is written for each storage trie and code hash that must be
+//     fetched, in the same batch as the account that references it.
+type stateSyncer struct {
+	config *StateSyncerConfig
+
+	codeSyncer *codeSyncer
+	done       chan error
+}
+
+// NewStateSyncer returns a syncer for the state with [config.Root].
+// If the persisted progress belongs to a different root, it is discarded.
+func NewStateSyncer(config *StateSyncerConfig) (*stateSyncer, error) {
+	if config.NumCodeWorkers == 0 {
+		config.NumCodeWorkers = defaultNumCodeWorkers
+	}
+	if config.NumStorageWorkers == 0 {
+		config.NumStorageWorkers = defaultNumStorageWorkers
+	}
+	if config.LeafsRequestLimit == 0 {
+		config.LeafsRequestLimit = defaultLeafsRequestLimit
+	}
+
+	syncRoot, err := rawdb.ReadSyncRoot(config.DB)
+	if err != nil {
+		return nil, err
+	}
+	if syncRoot != config.Root {
+		if syncRoot != (common.Hash{}) {
+			log.Info("discarding progress of previous state sync", "previousRoot", syncRoot, "root", config.Root)
+		}
+		if err := clearSyncProgress(config.DB); err != nil {
+			return nil, err
+		}
+		if err := rawdb.WriteSyncRoot(config.DB, config.Root); err != nil {
+			return nil, err
+		}
+	}
+
+	return &stateSyncer{
+		config:     config,
+		codeSyncer: newCodeSyncer(config.DB, config.Client, config.NumCodeWorkers),
+		done:       make(chan error, 1),
+	}, nil
+}
+
+// clearSyncProgress removes all markers and progress of a previous sync
+func clearSyncProgress(db ethdb.KeyValueStore) error {
+	if err := rawdb.ClearAllSyncStorageTries(db); err != nil {
+		return err
+	}
+	if err := rawdb.ClearAllSyncTrieProgress(db); err != nil {
+		return err
+	}
+	return rawdb.ClearAllCodeToFetch(db)
+}
+
+// Start starts the state sync in the background. The result of the sync
+// is sent on the channel returned by Done.
+func (s *stateSyncer) Start(ctx context.Context) error {
+	eg, egCtx := errgroup.WithContext(ctx)
+	s.codeSyncer.start(egCtx, eg)
+	eg.Go(func() error {
+		if err := s.syncAccountTrie(egCtx); err != nil {
+			return err
+		}
+		return s.syncStorageTries(egCtx)
+	})
+
+	go func() {
+		err := eg.Wait()
+		if err == nil {
+			err = rawdb.DeleteSyncRoot(s.config.DB)
+		}
+		s.done <- err
+	}()
+	return nil
+}
+
+// Done returns a channel which produces the result of the sync once it
+// has completed.
+func (s *stateSyncer) Done() <-chan error { return s.done }
+
+// syncAccountTrie syncs the account trie, writing a marker for each storage
+// trie and code hash missing from disk. The code syncer is notified of new
+// code hashes after the markers have been committed.
+func (s *stateSyncer) syncAccountTrie(ctx context.Context) error {
+	defer s.codeSyncer.notifyAccountTrieCompleted()
+
+	if err := s.codeSyncer.addPendingCode(ctx); err != nil {
+		return err
+	}
+
+	var (
+		pendingCode []common.Hash
+		seenCode    = make(map[common.Hash]struct{})
+	)
+	syncer := &trieSyncer{
+		root:      s.config.Root,
+		db:        s.config.DB,
+		client:    s.config.Client,
+		batchSize: s.config.BatchSize,
+		limit:     s.config.LeafsRequestLimit,
+		onLeaf: func(batch ethdb.Batch, key, val []byte) error {
+			var acc types.StateAccount
+			if err := rlp.DecodeBytes(val, &acc); err != nil {
+				return fmt.Errorf("failed to decode account (hash: %s): %w", common.BytesToHash(key), err)
+			}
+			if !isTrieOnDisk(s.config.DB, acc.Root) {
+				if err := rawdb.WriteSyncStorageTrie(batch, acc.Root, common.BytesToHash(key)); err != nil {
+					return err
+				}
+			}
+			codeHash := common.BytesToHash(acc.CodeHash)
+			if codeHash == emptyCodeHash {
+				return nil
+			}
+			if _, seen := seenCode[codeHash]; seen || rawdb.HasCodeWithPrefix(s.config.DB, codeHash) {
+				return nil
+			}
+			seenCode[codeHash] = struct{}{}
+			rawdb.AddCodeToFetch(batch, codeHash)
+			pendingCode = append(pendingCode, codeHash)
+			return nil
+		},
+		onFlush: func(ctx context.Context) error {
+			codeHashes := pendingCode
+			pendingCode = nil
+			return s.codeSyncer.addCode(ctx, codeHashes)
+		},
+	}
+	log.Info("syncing account trie", "root", s.config.Root)
+	return syncer.sync(ctx)
+}
+
+// syncStorageTries syncs the storage tries for which a marker was written
+// while syncing the account trie. Accounts with identical storage are
+// grouped by root, so each storage trie is only fetched once.
+func (s *stateSyncer) syncStorageTries(ctx context.Context) error {
+	roots, err := s.pendingStorageRoots()
+	if err != nil {
+		return err
+	}
+	log.Info("syncing storage tries", "count", len(roots))
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	rootsCh := make(chan common.Hash)
+	for i := 0; i < s.config.NumStorageWorkers; i++ {
+		eg.Go(func() error {
+			for root := range rootsCh {
+				syncer := &trieSyncer{
+					root:      root,
+					db:        s.config.DB,
+					client:    s.config.Client,
+					batchSize: s.config.BatchSize,
+					limit:     s.config.LeafsRequestLimit,
+				}
+				if err := syncer.sync(egCtx); err != nil {
+					return err
+				}
+				// The markers are only removed once the trie is on disk. If this is
+				// interrupted, the trie is found on disk after restarting.
+				if err := rawdb.ClearSyncStorageTrie(s.config.DB, root); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	eg.Go(func() error {
+		defer close(rootsCh)
+		for _, root := range roots {
+			select {
+			case rootsCh <- root:
+			case <-egCtx.Done():
+				return egCtx.Err()
+			}
+		}
+		return nil
+	})
+	return eg.Wait()
+}
+
+// pendingStorageRoots returns the distinct roots of the storage tries
+// that must be fetched.
+func (s *stateSyncer) pendingStorageRoots() ([]common.Hash, error) {
+	it := rawdb.NewSyncStorageTriesIterator(s.config.DB, nil)
+	defer it.Release()
+
+	var roots []common.Hash
+	for it.Next() {
+		root, _ := rawdb.UnpackSyncStorageTrieKey(it.Key())
+		// Keys are sorted by root, so identical roots are adjacent
+		if len(roots) > 0 && roots[len(roots)-1] == root {
+			continue
+		}
+		roots = append(roots, root)
+	}
+	return roots, it.Error()
+}
@@ -0,0 +1,234 @@
+// (c) 2021-2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package statesync
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/state"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/ethdb"
+	"github.com/ava-labs/subnet-evm/ethdb/memorydb"
+	"github.com/ava-labs/subnet-evm/plugin/evm/message"
+	statesyncclient "github.com/ava-labs/subnet-evm/statesync/client"
+	clientstats "github.com/ava-labs/subnet-evm/statesync/client/stats"
+	"github.com/ava-labs/subnet-evm/statesync/handlers"
+	handlerstats "github.com/ava-labs/subnet-evm/statesync/handlers/stats"
+	"github.com/ava-labs/subnet-evm/trie"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+)
+
+var errInterrupted = errors.New("interrupted")
+
+// fillAccounts creates [numAccounts] accounts in a new state on [db]. Some
+// of the accounts have storage and code, and several accounts share the same
+// storage and code to exercise deduplication in the syncer.
+func fillAccounts(t *testing.T, db ethdb.Database, numAccounts int) common.Hash {
+	stateDB := state.NewDatabase(db)
+	statedb, err := state.New(common.Hash{}, stateDB, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < numAccounts; i++ {
+		addr := common.BigToAddress(big.NewInt(int64(i + 1)))
+		statedb.SetBalance(addr, big.NewInt(r.Int63()))
+		statedb.SetNonce(addr, uint64(i))
+		switch i % 4 {
+		case 1:
+			// unique storage and code
+			for j := 0; j < 1+r.Intn(64); j++ {
+				statedb.SetState(addr, common.BigToHash(big.NewInt(int64(j))), common.BigToHash(big.NewInt(r.Int63())))
+			}
+			statedb.SetCode(addr, []byte{byte(i), byte(i >> 8), 0x60, 0x00})
+		case 2:
+			// storage and code shared with other accounts
+			for j := 0; j < 16; j++ {
+				statedb.SetState(addr, common.BigToHash(big.NewInt(int64(j))), common.BigToHash(big.NewInt(int64(j+1))))
+			}
+			statedb.SetCode(addr, []byte{0x60, 0x00, 0x60, 0x00})
+		}
+	}
+	root, err := statedb.Commit(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stateDB.TrieDB().Commit(root, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+// newTestNetwork returns a MockNetwork serving state from [serverDB]
+func newTestNetwork(t *testing.T, serverDB ethdb.Database) *statesyncclient.MockNetwork {
+	codec, err := message.BuildCodec()
+	if err != nil {
+		t.Fatal("error building codec", err)
+	}
+	handlerStats := handlerstats.NewNoopHandlerStats()
+	handler := handlers.NewSyncHandler(
+		handlers.NewLeafsRequestHandler(trie.NewDatabase(serverDB), nil, handlerStats, codec),
+		handlers.NewBlockRequestHandler(func(common.Hash, uint64) *types.Block { return nil }, codec, handlerStats),
+		handlers.NewCodeRequestHandler(serverDB, handlerStats, codec),
+	)
+	return statesyncclient.NewMockNetwork(codec, handler)
+}
+
+func newTestStateSyncer(t *testing.T, network *statesyncclient.MockNetwork, clientDB ethdb.Database, root common.Hash) *stateSyncer {
+	codec, err := message.BuildCodec()
+	if err != nil {
+		t.Fatal("error building codec", err)
+	}
+	syncer, err := NewStateSyncer(&StateSyncerConfig{
+		Root: root,
+		Client: statesyncclient.NewClient(&statesyncclient.ClientConfig{
+			NetworkClient: network,
+			Codec:         codec,
+			Stats:         clientstats.NewNoOpStats(),
+		}),
+		DB:                clientDB,
+		BatchSize:         1, // flush progress after every request
+		LeafsRequestLimit: 32,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return syncer
+}
+
+func runSyncer(ctx context.Context, t *testing.T, syncer *stateSyncer) error {
+	if err := syncer.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return <-syncer.Done()
+}
+
+// assertDBConsistency asserts that the complete state with [root] is
+// available in [clientDB] and all sync markers have been removed.
+func assertDBConsistency(t *testing.T, root common.Hash, clientDB ethdb.Database) {
+	trieDB := trie.NewDatabase(clientDB)
+	accountTrie, err := trie.New(root, trieDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	numAccounts := 0
+	it := trie.NewIterator(accountTrie.NodeIterator(nil))
+	for it.Next() {
+		numAccounts++
+		var acc types.StateAccount
+		if err := rlp.DecodeBytes(it.Value, &acc); err != nil {
+			t.Fatal(err)
+		}
+		if codeHash := common.BytesToHash(acc.CodeHash); codeHash != emptyCodeHash {
+			assert.True(t, rawdb.HasCodeWithPrefix(clientDB, codeHash), "missing code %s", codeHash)
+		}
+		storageTrie, err := trie.New(acc.Root, trieDB)
+		if err != nil {
+			t.Fatal(err)
+		}
+		storageIt := trie.NewIterator(storageTrie.NodeIterator(nil))
+		for storageIt.Next() {
+		}
+		assert.NoError(t, storageIt.Err)
+	}
+	assert.NoError(t, it.Err)
+	assert.Positive(t, numAccounts)
+
+	syncRoot, err := rawdb.ReadSyncRoot(clientDB)
+	assert.NoError(t, err)
+	assert.Equal(t, common.Hash{}, syncRoot)
+	for _, prefix := range [][]byte{[]byte("sync_storage"), []byte("sync_progress"), rawdb.CodeToFetchPrefix} {
+		it := clientDB.NewIterator(prefix, nil)
+		assert.False(t, it.Next(), "found leftover sync marker with prefix %s", prefix)
+		it.Release()
+	}
+}
+
+func TestSyncer(t *testing.T) {
+	tests := map[string]int{
+		"single account": 1,
+		"few accounts":   50,
+		"many accounts":  2_000,
+	}
+	for name, numAccounts := range tests {
+		numAccounts := numAccounts
+		t.Run(name, func(t *testing.T) {
+			serverDB := memorydb.New()
+			root := fillAccounts(t, serverDB, numAccounts)
+
+			clientDB := memorydb.New()
+			syncer := newTestStateSyncer(t, newTestNetwork(t, serverDB), clientDB, root)
+			assert.NoError(t, runSyncer(context.Background(), t, syncer))
+			assertDBConsistency(t, root, clientDB)
+		})
+	}
+}
+
+func TestResumeSyncAfterInterruption(t *testing.T) {
+	serverDB := memorydb.New()
+	root := fillAccounts(t, serverDB, 2_000)
+
+	// Measure the number of requests needed to sync from scratch
+	network := newTestNetwork(t, serverDB)
+	assert.NoError(t, runSyncer(context.Background(), t, newTestStateSyncer(t, network, memorydb.New(), root)))
+	fullSyncRequests := network.NumCalls()
+
+	// Interrupt the sync halfway through
+	clientDB := memorydb.New()
+	network = newTestNetwork(t, serverDB)
+	ctx, cancel := context.WithCancel(context.Background())
+	var numRequests uint32
+	network.Intercept = func(_ message.Request, response []byte) ([]byte, error) {
+		if atomic.AddUint32(&numRequests, 1) > fullSyncRequests/2 {
+			cancel()
+			return nil, errInterrupted
+		}
+		return response, nil
+	}
+	assert.Error(t, runSyncer(ctx, t, newTestStateSyncer(t, network, clientDB, root)))
+
+	// Resume the sync with a new syncer and ensure it does not start over
+	network = newTestNetwork(t, serverDB)
+	assert.NoError(t, runSyncer(context.Background(), t, newTestStateSyncer(t, network, clientDB, root)))
+	assert.Less(t, network.NumCalls(), fullSyncRequests)
+	assertDBConsistency(t, root, clientDB)
+}
+
+func TestSyncDiscardsProgressOfPreviousRoot(t *testing.T) {
+	serverDB := memorydb.New()
+	previousRoot := fillAccounts(t, serverDB, 1_000)
+
+	// Interrupt a sync to [previousRoot]
+	clientDB := memorydb.New()
+	network := newTestNetwork(t, serverDB)
+	ctx, cancel := context.WithCancel(context.Background())
+	var numRequests uint32
+	network.Intercept = func(_ message.Request, response []byte) ([]byte, error) {
+		if atomic.AddUint32(&numRequests, 1) > 10 {
+			cancel()
+			return nil, errInterrupted
+		}
+		return response, nil
+	}
+	assert.Error(t, runSyncer(ctx, t, newTestStateSyncer(t, network, clientDB, previousRoot)))
+	progress := rawdb.ReadSyncTrieProgress(clientDB, previousRoot)
+	assert.NotEmpty(t, progress)
+
+	// Sync to a different root
+	serverDB = memorydb.New()
+	root := fillAccounts(t, serverDB, 500)
+	syncer := newTestStateSyncer(t, newTestNetwork(t, serverDB), clientDB, root)
+	assert.Empty(t, rawdb.ReadSyncTrieProgress(clientDB, previousRoot))
+	assert.NoError(t, runSyncer(context.Background(), t, syncer))
+	assertDBConsistency(t, root, clientDB)
+}
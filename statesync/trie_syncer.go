@@ -0,0 +1,185 @@
+// (c) 2021-2022, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package statesync
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/ava-labs/subnet-evm/core/rawdb"
+	"github.com/ava-labs/subnet-evm/core/types"
+	"github.com/ava-labs/subnet-evm/ethdb"
+	"github.com/ava-labs/subnet-evm/plugin/evm/message"
+	statesyncclient "github.com/ava-labs/subnet-evm/statesync/client"
+	"github.com/ava-labs/subnet-evm/trie"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// trieProgress is the serialized progress of a trie being synced.
+// It is persisted in the same batch as the trie nodes written by the
+// stack trie, so the sync of the trie can resume after a restart.
+type trieProgress struct {
+	LastKey   []byte
+	StackTrie []byte
+}
+
+// onLeafFn is called for each leaf of a trie being synced. Any writes
+// made to [batch] are committed atomically with the progress of the trie.
+type onLeafFn func(batch ethdb.Batch, key, val []byte) error
+
+// onFlushFn is called after each batch of the trie being synced has been
+// committed to disk.
+type onFlushFn func(ctx context.Context) error
+
+// trieSyncer fetches the leafs of a single trie from peers, verifying each
+// response against the trie root, and reconstructs the trie on disk.
+type trieSyncer struct {
+	root      common.Hash
+	db        ethdb.Database
+	client    statesyncclient.Client
+	batchSize int
+	limit     uint16
+	onLeaf    onLeafFn  // optional
+	onFlush   onFlushFn // optional
+}
+
+// isTrieOnDisk returns true if the trie with [root] is already available
+// locally. The stack trie writes nodes bottom-up, so the presence of the
+// root node implies the presence of the entire trie.
+func isTrieOnDisk(db ethdb.KeyValueReader, root common.Hash) bool {
+	return root == types.EmptyRootHash || rawdb.HasTrieNode(db, root)
+}
+
+// sync fetches the trie, resuming from the persisted progress if any.
+func (t *trieSyncer) sync(ctx context.Context) error {
+	if isTrieOnDisk(t.db, t.root) {
+		rawdb.DeleteSyncTrieProgress(t.db, t.root)
+		return nil
+	}
+
+	batch := t.db.NewBatch()
+	stackTrie, start, err := t.loadProgress(batch)
+	if err != nil {
+		return err
+	}
+	end := bytes.Repeat([]byte{0xff}, common.HashLength)
+	for {
+		leafsResponse, err := t.client.GetLeafs(ctx, message.LeafsRequest{
+			Root:  t.root,
+			Start: start,
+			End:   end,
+			Limit: t.limit,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to fetch leafs for trie (root: %s): %w", t.root, err)
+		}
+
+		for i, key := range leafsResponse.Keys {
+			val := leafsResponse.Vals[i]
+			if err := stackTrie.TryUpdate(key, val); err != nil {
+				return err
+			}
+			if t.onLeaf != nil {
+				if err := t.onLeaf(batch, key, val); err != nil {
+					return err
+				}
+			}
+		}
+
+		if !leafsResponse.More {
+			break
+		}
+		lastKey := leafsResponse.Keys[len(leafsResponse.Keys)-1]
+		start = common.CopyBytes(lastKey)
+		if !incrOne(start) {
+			break // last key was the maximum possible key
+		}
+
+		if batch.ValueSize() < t.batchSize {
+			continue
+		}
+		if err := t.writeProgress(batch, stackTrie, lastKey); err != nil {
+			return err
+		}
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+		if t.onFlush != nil {
+			if err := t.onFlush(ctx); err != nil {
+				return err
+			}
+		}
+	}
+
+	root, err := stackTrie.Commit()
+	if err != nil {
+		return err
+	}
+	if root != t.root {
+		return fmt.Errorf("synced trie root mismatch: expected %s, got %s", t.root, root)
+	}
+	rawdb.DeleteSyncTrieProgress(batch, t.root)
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	if t.onFlush != nil {
+		return t.onFlush(ctx)
+	}
+	return nil
+}
+
+// loadProgress returns the stack trie and the next key to request for the
+// trie being synced. If no progress was persisted, an empty stack trie and
+// the minimum key are returned.
+func (t *trieSyncer) loadProgress(batch ethdb.Batch) (*trie.StackTrie, []byte, error) {
+	data := rawdb.ReadSyncTrieProgress(t.db, t.root)
+	if len(data) == 0 {
+		return trie.NewStackTrie(batch), make([]byte, common.HashLength), nil
+	}
+	var progress trieProgress
+	if err := rlp.DecodeBytes(data, &progress); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode progress for trie (root: %s): %w", t.root, err)
+	}
+	stackTrie, err := trie.NewFromBinary(progress.StackTrie, batch)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to restore progress for trie (root: %s): %w", t.root, err)
+	}
+	start := common.CopyBytes(progress.LastKey)
+	incrOne(start)
+	log.Info("resuming trie sync", "root", t.root, "lastKey", common.Bytes2Hex(progress.LastKey))
+	return stackTrie, start, nil
+}
+
+// writeProgress adds the serialized [stackTrie] and [lastKey] to [batch].
+func (t *trieSyncer) writeProgress(batch ethdb.Batch, stackTrie *trie.StackTrie, lastKey []byte) error {
+	stackTrieBytes, err := stackTrie.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	progress, err := rlp.EncodeToBytes(trieProgress{
+		LastKey:   lastKey,
+		StackTrie: stackTrieBytes,
+	})
+	if err != nil {
+		return err
+	}
+	rawdb.WriteSyncTrieProgress(batch, t.root, progress)
+	return nil
+}
+
+// incrOne increments bytes value by one, treating [key] as a big-endian
+// unsigned integer. Returns false if [key] overflowed.
+func incrOne(key []byte) bool {
+	for i := len(key) - 1; i >= 0; i-- {
+		key[i]++
+		if key[i] != 0 {
+			return true
+		}
+	}
+	return false
+}